@@ -0,0 +1,416 @@
+// Package afm parses Adobe Font Metrics files, which provide the
+// metrics of PostScript fonts (such as Type 1 fonts, which have no
+// metrics tables): the glyph widths and bounding boxes, the ligatures,
+// the kerning pairs and the composite characters.
+//
+// See the Adobe Font Metrics File Format Specification, version 4.1.
+package afm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	errMissingHeader = errors.New("missing StartFontMetrics")
+	errInvalidNumber = errors.New("invalid number")
+	errMissingValue  = errors.New("missing value")
+	errUnexpectedEOF = errors.New("unexpected end of file")
+)
+
+// Font is the content of an AFM file.
+// The distances are expressed in the units of the character
+// coordinate system, usually 1/1000 em.
+type Font struct {
+	FontName       string
+	FullName       string
+	FamilyName     string
+	Weight         string
+	Version        string
+	Notice         string
+	EncodingScheme string
+	CharacterSet   string
+
+	FontBBox           [4]float64 // xMin, yMin, xMax, yMax
+	ItalicAngle        float64
+	IsFixedPitch       bool
+	UnderlinePosition  float64
+	UnderlineThickness float64
+	CapHeight          float64
+	XHeight            float64
+	Ascender           float64
+	Descender          float64
+	StdHW              float64
+	StdVW              float64
+
+	CharMetrics []CharMetric
+	KernPairs   []KernPair
+	Composites  []Composite
+}
+
+// CharMetric are the metrics of one character.
+type CharMetric struct {
+	// Code is the character code in the default encoding,
+	// or -1 if the character is not encoded.
+	Code   int
+	Name   string
+	WX, WY float64    // the advance vector
+	BBox   [4]float64 // xMin, yMin, xMax, yMax
+	// Ligatures are the ligatures formed with this
+	// character as the first one.
+	Ligatures []Ligature
+}
+
+// Ligature indicates that the character followed by
+// Successor is replaced by Ligature.
+type Ligature struct {
+	Successor string
+	Ligature  string
+}
+
+// KernPair is the adjustment of the position of the Second
+// character, when it follows the First one.
+type KernPair struct {
+	First, Second string
+	X, Y          float64
+}
+
+// Composite is a character built from other characters.
+type Composite struct {
+	Name  string
+	Parts []CompositePart
+}
+
+// CompositePart is a character used in a composite,
+// with its displacement from the origin.
+type CompositePart struct {
+	Name   string
+	DX, DY float64
+}
+
+// Parse parses an AFM file.
+func Parse(r io.Reader) (*Font, error) {
+	p := parser{scanner: bufio.NewScanner(r)}
+	font, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AFM file, line %d: %w", p.line, err)
+	}
+	return font, nil
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// next returns the keyword and the arguments of the next
+// non empty line, and an empty keyword at the end of the file.
+func (p *parser) next() (keyword, args string, err error) {
+	for p.scanner.Scan() {
+		p.line++
+		text := strings.TrimSpace(p.scanner.Text())
+		if text == "" {
+			continue
+		}
+		keyword, args = text, ""
+		if i := strings.IndexAny(text, " \t"); i != -1 {
+			keyword, args = text[:i], strings.TrimSpace(text[i+1:])
+		}
+		return keyword, args, nil
+	}
+	return "", "", p.scanner.Err()
+}
+
+func (p *parser) parse() (*Font, error) {
+	keyword, _, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if keyword != "StartFontMetrics" {
+		return nil, errMissingHeader
+	}
+
+	font := new(Font)
+	for {
+		keyword, args, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch keyword {
+		case "":
+			return nil, errUnexpectedEOF
+		case "EndFontMetrics":
+			return font, nil
+		case "FontName":
+			font.FontName = args
+		case "FullName":
+			font.FullName = args
+		case "FamilyName":
+			font.FamilyName = args
+		case "Weight":
+			font.Weight = args
+		case "Version":
+			font.Version = args
+		case "Notice":
+			font.Notice = args
+		case "EncodingScheme":
+			font.EncodingScheme = args
+		case "CharacterSet":
+			font.CharacterSet = args
+		case "FontBBox":
+			err = parseNumbers(args, font.FontBBox[:])
+		case "ItalicAngle":
+			font.ItalicAngle, err = parseNumber(args)
+		case "IsFixedPitch":
+			font.IsFixedPitch = args == "true"
+		case "UnderlinePosition":
+			font.UnderlinePosition, err = parseNumber(args)
+		case "UnderlineThickness":
+			font.UnderlineThickness, err = parseNumber(args)
+		case "CapHeight":
+			font.CapHeight, err = parseNumber(args)
+		case "XHeight":
+			font.XHeight, err = parseNumber(args)
+		case "Ascender":
+			font.Ascender, err = parseNumber(args)
+		case "Descender":
+			font.Descender, err = parseNumber(args)
+		case "StdHW":
+			font.StdHW, err = parseNumber(args)
+		case "StdVW":
+			font.StdVW, err = parseNumber(args)
+		case "StartCharMetrics":
+			font.CharMetrics, err = p.parseCharMetrics()
+		case "StartKernPairs", "StartKernPairs0":
+			font.KernPairs, err = p.parseKernPairs(font.KernPairs)
+		case "StartComposites":
+			font.Composites, err = p.parseComposites()
+		}
+		// the other keywords (comments, track kerning, vertical
+		// metrics...) are ignored
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func parseNumber(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errInvalidNumber
+	}
+	return f, nil
+}
+
+// parseNumbers parses exactly len(out) numbers separated by spaces.
+func parseNumbers(s string, out []float64) error {
+	fields := strings.Fields(s)
+	if len(fields) != len(out) {
+		return errMissingValue
+	}
+	for i, field := range fields {
+		var err error
+		if out[i], err = parseNumber(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statements splits a line into the fields of its
+// statements, separated by semicolons.
+func statements(line string) [][]string {
+	var out [][]string
+	for _, statement := range strings.Split(line, ";") {
+		if fields := strings.Fields(statement); len(fields) != 0 {
+			out = append(out, fields)
+		}
+	}
+	return out
+}
+
+func (p *parser) parseCharMetrics() ([]CharMetric, error) {
+	var out []CharMetric
+	for {
+		keyword, args, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "":
+			return nil, errUnexpectedEOF
+		case "EndCharMetrics":
+			return out, nil
+		}
+
+		metric := CharMetric{Code: -1}
+		for _, fields := range statements(keyword + " " + args) {
+			args := fields[1:]
+			switch fields[0] {
+			case "C":
+				err = parseCode(args, 10, &metric.Code)
+			case "CH":
+				if len(args) == 1 {
+					args = []string{strings.Trim(args[0], "<>")}
+				}
+				err = parseCode(args, 16, &metric.Code)
+			case "WX", "W0X":
+				var w [1]float64
+				err = parseNumbers(strings.Join(args, " "), w[:])
+				metric.WX = w[0]
+			case "WY", "W0Y":
+				var w [1]float64
+				err = parseNumbers(strings.Join(args, " "), w[:])
+				metric.WY = w[0]
+			case "W", "W0":
+				var w [2]float64
+				err = parseNumbers(strings.Join(args, " "), w[:])
+				metric.WX, metric.WY = w[0], w[1]
+			case "N":
+				if len(args) != 1 {
+					err = errMissingValue
+				} else {
+					metric.Name = args[0]
+				}
+			case "B":
+				err = parseNumbers(strings.Join(args, " "), metric.BBox[:])
+			case "L":
+				if len(args) != 2 {
+					err = errMissingValue
+				} else {
+					metric.Ligatures = append(metric.Ligatures, Ligature{Successor: args[0], Ligature: args[1]})
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, metric)
+	}
+}
+
+func parseCode(args []string, base int, code *int) error {
+	if len(args) != 1 {
+		return errMissingValue
+	}
+	c, err := strconv.ParseInt(args[0], base, 32)
+	if err != nil {
+		return errInvalidNumber
+	}
+	*code = int(c)
+	return nil
+}
+
+// parseKernPairs appends the pairs to out, since a file
+// may contain several sections of kerning pairs.
+func (p *parser) parseKernPairs(out []KernPair) ([]KernPair, error) {
+	for {
+		keyword, args, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(args)
+		switch keyword {
+		case "":
+			return nil, errUnexpectedEOF
+		case "EndKernPairs":
+			return out, nil
+		case "KPX", "KPY":
+			if len(fields) != 3 {
+				return nil, errMissingValue
+			}
+			value, err := parseNumber(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			pair := KernPair{First: fields[0], Second: fields[1]}
+			if keyword == "KPX" {
+				pair.X = value
+			} else {
+				pair.Y = value
+			}
+			out = append(out, pair)
+		case "KP":
+			if len(fields) != 4 {
+				return nil, errMissingValue
+			}
+			pair := KernPair{First: fields[0], Second: fields[1]}
+			var values [2]float64
+			if err := parseNumbers(fields[2]+" "+fields[3], values[:]); err != nil {
+				return nil, err
+			}
+			pair.X, pair.Y = values[0], values[1]
+			out = append(out, pair)
+		}
+		// the pairs using character codes (KPH) are ignored
+	}
+}
+
+func (p *parser) parseComposites() ([]Composite, error) {
+	var out []Composite
+	for {
+		keyword, args, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "":
+			return nil, errUnexpectedEOF
+		case "EndComposites":
+			return out, nil
+		case "CC":
+			var composite Composite
+			for i, fields := range statements(args) {
+				switch {
+				case i == 0:
+					composite.Name = fields[0]
+				case fields[0] == "PCC" && len(fields) == 4:
+					var delta [2]float64
+					if err := parseNumbers(fields[2]+" "+fields[3], delta[:]); err != nil {
+						return nil, err
+					}
+					composite.Parts = append(composite.Parts, CompositePart{Name: fields[1], DX: delta[0], DY: delta[1]})
+				default:
+					return nil, errMissingValue
+				}
+			}
+			out = append(out, composite)
+		}
+	}
+}
+
+// CharMetric returns the metrics of the character with the given name.
+func (f *Font) CharMetric(name string) (CharMetric, bool) {
+	for _, metric := range f.CharMetrics {
+		if metric.Name == name {
+			return metric, true
+		}
+	}
+	return CharMetric{}, false
+}
+
+// Widths returns the horizontal advance of the characters,
+// by name.
+func (f *Font) Widths() map[string]float64 {
+	out := make(map[string]float64, len(f.CharMetrics))
+	for _, metric := range f.CharMetrics {
+		out[metric.Name] = metric.WX
+	}
+	return out
+}
+
+// Kern returns the horizontal kerning adjustment between
+// the characters first and second, or 0.
+func (f *Font) Kern(first, second string) float64 {
+	for _, pair := range f.KernPairs {
+		if pair.First == first && pair.Second == second {
+			return pair.X
+		}
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package afm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAFM = `StartFontMetrics 4.1
+Comment Generated for the tests
+FontName Test-Regular
+FullName Test Regular
+FamilyName Test
+Weight Regular
+ItalicAngle -12.5
+IsFixedPitch false
+FontBBox -10 -200 700 800
+UnderlinePosition -100
+UnderlineThickness 50
+Version 001.000
+Notice Copyright (c) test
+EncodingScheme AdobeStandardEncoding
+CapHeight 700
+XHeight 500
+Ascender 750
+Descender -250
+StdHW 40
+StdVW 80
+StartCharMetrics 5
+C 32 ; WX 250 ; N space ; B 0 0 0 0 ;
+C 65 ; WX 630 ; N A ; B 10 0 620 700 ;
+CH <42> ; W0X 600 ; N B ; B 50 0 550 700 ;
+C 102 ; WX 300 ; N f ; B 20 0 320 720 ; L i fi ; L l fl ;
+C -1 ; W 400 10 ; N Aacute ; B 10 0 620 900 ;
+EndCharMetrics
+StartKernData
+StartKernPairs 3
+KPX A V -80
+KP A W -60 5
+
+KPY A T 10
+EndKernPairs
+EndKernData
+StartComposites 1
+CC Aacute 2 ; PCC A 0 0 ; PCC acute 195 212 ;
+EndComposites
+EndFontMetrics
+`
+
+func TestParse(t *testing.T) {
+	font, err := Parse(strings.NewReader(testAFM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if font.FontName != "Test-Regular" || font.FullName != "Test Regular" || font.FamilyName != "Test" ||
+		font.Weight != "Regular" || font.Version != "001.000" || font.Notice != "Copyright (c) test" ||
+		font.EncodingScheme != "AdobeStandardEncoding" {
+		t.Errorf("unexpected names %+v", font)
+	}
+	if font.ItalicAngle != -12.5 || font.IsFixedPitch || font.FontBBox != [4]float64{-10, -200, 700, 800} ||
+		font.UnderlinePosition != -100 || font.UnderlineThickness != 50 || font.CapHeight != 700 || font.XHeight != 500 ||
+		font.Ascender != 750 || font.Descender != -250 || font.StdHW != 40 || font.StdVW != 80 {
+		t.Errorf("unexpected metrics %+v", font)
+	}
+
+	expectedMetrics := []CharMetric{
+		{Code: 32, Name: "space", WX: 250},
+		{Code: 65, Name: "A", WX: 630, BBox: [4]float64{10, 0, 620, 700}},
+		{Code: 66, Name: "B", WX: 600, BBox: [4]float64{50, 0, 550, 700}},
+		{Code: 102, Name: "f", WX: 300, BBox: [4]float64{20, 0, 320, 720}, Ligatures: []Ligature{{"i", "fi"}, {"l", "fl"}}},
+		{Code: -1, Name: "Aacute", WX: 400, WY: 10, BBox: [4]float64{10, 0, 620, 900}},
+	}
+	if !reflect.DeepEqual(font.CharMetrics, expectedMetrics) {
+		t.Errorf("expected %v, got %v", expectedMetrics, font.CharMetrics)
+	}
+
+	expectedPairs := []KernPair{{"A", "V", -80, 0}, {"A", "W", -60, 5}, {"A", "T", 0, 10}}
+	if !reflect.DeepEqual(font.KernPairs, expectedPairs) {
+		t.Errorf("expected %v, got %v", expectedPairs, font.KernPairs)
+	}
+
+	expectedComposites := []Composite{{Name: "Aacute", Parts: []CompositePart{{"A", 0, 0}, {"acute", 195, 212}}}}
+	if !reflect.DeepEqual(font.Composites, expectedComposites) {
+		t.Errorf("expected %v, got %v", expectedComposites, font.Composites)
+	}
+
+	if metric, ok := font.CharMetric("f"); !ok || metric.WX != 300 {
+		t.Errorf("unexpected metric %v %v", metric, ok)
+	}
+	if _, ok := font.CharMetric("g"); ok {
+		t.Error("unexpected metric for g")
+	}
+	if widths := font.Widths(); widths["A"] != 630 || len(widths) != 5 {
+		t.Errorf("unexpected widths %v", widths)
+	}
+	if kern := font.Kern("A", "V"); kern != -80 {
+		t.Errorf("expected -80, got %v", kern)
+	}
+	if kern := font.Kern("V", "A"); kern != 0 {
+		t.Errorf("expected 0, got %v", kern)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, test := range []struct {
+		afm, err string
+	}{
+		{"", "line 0: missing StartFontMetrics"},
+		{"StartFontMetrics 4.1\nFontName Test\n", "line 2: unexpected end of file"},
+		{"StartFontMetrics 4.1\nItalicAngle slanted\nEndFontMetrics\n", "line 2: invalid number"},
+		{"StartFontMetrics 4.1\nStartCharMetrics 1\nC 32 ; B 0 0 ;\nEndCharMetrics\nEndFontMetrics\n", "line 3: missing value"},
+		{"StartFontMetrics 4.1\nStartKernPairs 1\nKPX A V\nEndKernPairs\nEndFontMetrics\n", "line 3: missing value"},
+	} {
+		_, err := Parse(strings.NewReader(test.afm))
+		if err == nil || !strings.HasSuffix(err.Error(), test.err) {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	font, err := Parse(strings.NewReader(testAFM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	written, err := Parse(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written, font) {
+		t.Errorf("expected %v, got %v", font, written)
+	}
+}
@@ -0,0 +1,102 @@
+package afm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// number formats a value without exponent, as expected by AFM readers.
+func number(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+// WriteTo serializes the metrics as an AFM file (version 4.1).
+// The empty names are omitted.
+// It implements io.WriterTo.
+func (f *Font) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString("StartFontMetrics 4.1\n")
+
+	for _, entry := range [...]struct{ keyword, value string }{
+		{"FontName", f.FontName},
+		{"FullName", f.FullName},
+		{"FamilyName", f.FamilyName},
+		{"Weight", f.Weight},
+		{"Version", f.Version},
+		{"Notice", f.Notice},
+		{"EncodingScheme", f.EncodingScheme},
+		{"CharacterSet", f.CharacterSet},
+	} {
+		if entry.value != "" {
+			fmt.Fprintf(&buf, "%s %s\n", entry.keyword, entry.value)
+		}
+	}
+	fmt.Fprintf(&buf, "ItalicAngle %s\n", number(f.ItalicAngle))
+	fmt.Fprintf(&buf, "IsFixedPitch %t\n", f.IsFixedPitch)
+	fmt.Fprintf(&buf, "FontBBox %s %s %s %s\n", number(f.FontBBox[0]), number(f.FontBBox[1]), number(f.FontBBox[2]), number(f.FontBBox[3]))
+	for _, entry := range [...]struct {
+		keyword string
+		value   float64
+	}{
+		{"UnderlinePosition", f.UnderlinePosition},
+		{"UnderlineThickness", f.UnderlineThickness},
+		{"CapHeight", f.CapHeight},
+		{"XHeight", f.XHeight},
+		{"Ascender", f.Ascender},
+		{"Descender", f.Descender},
+		{"StdHW", f.StdHW},
+		{"StdVW", f.StdVW},
+	} {
+		if entry.value != 0 {
+			fmt.Fprintf(&buf, "%s %s\n", entry.keyword, number(entry.value))
+		}
+	}
+
+	fmt.Fprintf(&buf, "StartCharMetrics %d\n", len(f.CharMetrics))
+	for _, metric := range f.CharMetrics {
+		fmt.Fprintf(&buf, "C %d ;", metric.Code)
+		if metric.WY != 0 {
+			fmt.Fprintf(&buf, " W %s %s ;", number(metric.WX), number(metric.WY))
+		} else {
+			fmt.Fprintf(&buf, " WX %s ;", number(metric.WX))
+		}
+		fmt.Fprintf(&buf, " N %s ; B %s %s %s %s ;", metric.Name,
+			number(metric.BBox[0]), number(metric.BBox[1]), number(metric.BBox[2]), number(metric.BBox[3]))
+		for _, ligature := range metric.Ligatures {
+			fmt.Fprintf(&buf, " L %s %s ;", ligature.Successor, ligature.Ligature)
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("EndCharMetrics\n")
+
+	if len(f.KernPairs) != 0 {
+		fmt.Fprintf(&buf, "StartKernData\nStartKernPairs %d\n", len(f.KernPairs))
+		for _, pair := range f.KernPairs {
+			switch {
+			case pair.Y == 0:
+				fmt.Fprintf(&buf, "KPX %s %s %s\n", pair.First, pair.Second, number(pair.X))
+			case pair.X == 0:
+				fmt.Fprintf(&buf, "KPY %s %s %s\n", pair.First, pair.Second, number(pair.Y))
+			default:
+				fmt.Fprintf(&buf, "KP %s %s %s %s\n", pair.First, pair.Second, number(pair.X), number(pair.Y))
+			}
+		}
+		buf.WriteString("EndKernPairs\nEndKernData\n")
+	}
+
+	if len(f.Composites) != 0 {
+		fmt.Fprintf(&buf, "StartComposites %d\n", len(f.Composites))
+		for _, composite := range f.Composites {
+			fmt.Fprintf(&buf, "CC %s %d ;", composite.Name, len(composite.Parts))
+			for _, part := range composite.Parts {
+				fmt.Fprintf(&buf, " PCC %s %s %s ;", part.Name, number(part.DX), number(part.DY))
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("EndComposites\n")
+	}
+
+	buf.WriteString("EndFontMetrics\n")
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
@@ -0,0 +1,290 @@
+package instance
+
+import (
+	"errors"
+	"math"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var errInvalidMaxpTable = errors.New("invalid maxp table")
+
+// simple glyph flags
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/glyf#simple-glyph-description
+const (
+	onCurvePoint      = 0x01
+	xShortVector      = 0x02
+	yShortVector      = 0x04
+	repeatFlag        = 0x08
+	xIsSameOrPositive = 0x10
+	yIsSameOrPositive = 0x20
+)
+
+// offsets in the 'maxp' table (version 1.0)
+const (
+	maxpNumGlyphsOffset            = 4
+	maxpMaxPointsOffset            = 6
+	maxpMaxContoursOffset          = 8
+	maxpMaxCompositePointsOffset   = 10
+	maxpMaxCompositeContoursOffset = 12
+	maxpMaxComponentElementsOffset = 28
+	maxpMaxComponentDepthOffset    = 30
+	maxpVersion1Size               = 32
+)
+
+// glyph is an encoded glyph of the instance, with its metrics.
+type glyph struct {
+	data    []byte // empty for glyphs without outline
+	advance uint16
+
+	xMin, yMin, xMax, yMax int16
+	numPoints, numContours int
+}
+
+type glyphs []glyph
+
+func readNumGlyphs(font *sfnt.Font) (int, error) {
+	maxp, err := font.Table(sfnt.TagMaxp)
+	if err != nil {
+		return 0, err
+	}
+	b := maxp.Bytes()
+	if len(b) < 6 {
+		return 0, errInvalidMaxpTable
+	}
+	return int(be.Uint16(b[maxpNumGlyphsOffset:])), nil
+}
+
+// instanceGlyphs computes the outlines and advances of all
+// the glyphs of the font, at the given normalized coordinates.
+func instanceGlyphs(font *sfnt.Font, coords []float32) (glyphs, error) {
+	numGlyphs, err := readNumGlyphs(font)
+	if err != nil {
+		return nil, err
+	}
+
+	// the metrics are parsed once for all the glyphs
+	var (
+		widths []int
+		hvar   *sfnt.TableHvar
+	)
+	if font.HasTable(sfnt.TagHmtx) && font.HasTable(sfnt.TagHhea) {
+		widths, err = font.HtmxTable()
+		if err != nil {
+			return nil, err
+		}
+		if font.HasTable(sfnt.TagHvar) {
+			hvar, err = font.HvarTable()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out := make(glyphs, numGlyphs)
+	for i := range out {
+		gi := sfnt.GlyphIndex(i)
+		outline, err := font.VariedGlyphOutline(gi, coords)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = encodeGlyph(outline)
+		if i >= len(widths) {
+			continue
+		}
+		advance := float32(widths[i])
+		if hvar != nil {
+			advance += hvar.AdvanceDelta(gi, coords)
+		} else {
+			// the variations are read from the 'gvar' phantom points
+			advance, err = font.GlyphHAdvance(gi, coords)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out[i].advance = uint16(math.Max(0, math.Round(float64(advance))))
+	}
+	return out, nil
+}
+
+func roundPoint(p sfnt.GlyphPoint) (int16, int16) {
+	return int16(math.Round(float64(p.X))), int16(math.Round(float64(p.Y)))
+}
+
+// encodeGlyph returns the simple glyph description of the
+// outline, whose coordinates are rounded. The glyph has no instructions.
+func encodeGlyph(outline sfnt.GlyphOutline) glyph {
+	if len(outline.Points) == 0 {
+		return glyph{}
+	}
+
+	out := glyph{
+		numPoints:   len(outline.Points),
+		numContours: len(outline.EndPoints),
+		xMin:        math.MaxInt16,
+		yMin:        math.MaxInt16,
+		xMax:        math.MinInt16,
+		yMax:        math.MinInt16,
+	}
+	var (
+		flags    []byte
+		xs, ys   []byte
+		prevX    int16
+		prevY    int16
+		lastFlag byte
+		repeated int // number of repetitions of the last flag
+	)
+	for _, p := range outline.Points {
+		x, y := roundPoint(p)
+		if x < out.xMin {
+			out.xMin = x
+		}
+		if x > out.xMax {
+			out.xMax = x
+		}
+		if y < out.yMin {
+			out.yMin = y
+		}
+		if y > out.yMax {
+			out.yMax = y
+		}
+
+		var flag byte
+		if p.OnCurve {
+			flag |= onCurvePoint
+		}
+		flag, xs = appendCoordinate(flag, xs, int(x)-int(prevX), xShortVector, xIsSameOrPositive)
+		flag, ys = appendCoordinate(flag, ys, int(y)-int(prevY), yShortVector, yIsSameOrPositive)
+		prevX, prevY = x, y
+
+		if len(flags) != 0 && flag == lastFlag && repeated < 0xFF {
+			if repeated == 0 {
+				flags[len(flags)-1] |= repeatFlag
+				flags = append(flags, 1)
+			} else {
+				flags[len(flags)-1]++
+			}
+			repeated++
+			continue
+		}
+		flags = append(flags, flag)
+		lastFlag, repeated = flag, 0
+	}
+
+	data := make([]byte, 10+2*len(outline.EndPoints)+2, 12+2*len(outline.EndPoints)+len(flags)+len(xs)+len(ys))
+	be.PutUint16(data, uint16(len(outline.EndPoints)))
+	be.PutUint16(data[2:], uint16(out.xMin))
+	be.PutUint16(data[4:], uint16(out.yMin))
+	be.PutUint16(data[6:], uint16(out.xMax))
+	be.PutUint16(data[8:], uint16(out.yMax))
+	for i, end := range outline.EndPoints {
+		be.PutUint16(data[10+2*i:], uint16(end))
+	}
+	// the instruction length is zero
+	data = append(data, flags...)
+	data = append(data, xs...)
+	out.data = append(data, ys...)
+	return out
+}
+
+// appendCoordinate encodes the delta, using the short format
+// when possible, and returns the updated flag.
+func appendCoordinate(flag byte, buf []byte, delta int, short, sameOrPositive byte) (byte, []byte) {
+	switch {
+	case delta == 0:
+		return flag | sameOrPositive, buf
+	case -0xFF <= delta && delta <= 0xFF:
+		flag |= short
+		if delta > 0 {
+			flag |= sameOrPositive
+		} else {
+			delta = -delta
+		}
+		return flag, append(buf, byte(delta))
+	default:
+		return flag, append(buf, byte(uint16(delta)>>8), byte(delta))
+	}
+}
+
+// addTables adds the glyf, loca, head and maxp tables to out.
+// The glyphs are padded to 4 bytes, and the short loca format is used when possible.
+func (gs glyphs) addTables(font, out *sfnt.Font) error {
+	head, err := font.HeadTable()
+	if err != nil {
+		return err
+	}
+	maxp, err := font.Table(sfnt.TagMaxp)
+	if err != nil {
+		return err
+	}
+
+	newHead := *head
+	newHead.XMin, newHead.YMin = math.MaxInt16, math.MaxInt16
+	newHead.XMax, newHead.YMax = math.MinInt16, math.MinInt16
+	var maxPoints, maxContours int
+	var glyf []byte
+	offsets := make([]int, len(gs)+1)
+	for i, g := range gs {
+		glyf = append(glyf, g.data...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		offsets[i+1] = len(glyf)
+
+		if len(g.data) == 0 {
+			continue
+		}
+		if g.xMin < newHead.XMin {
+			newHead.XMin = g.xMin
+		}
+		if g.yMin < newHead.YMin {
+			newHead.YMin = g.yMin
+		}
+		if g.xMax > newHead.XMax {
+			newHead.XMax = g.xMax
+		}
+		if g.yMax > newHead.YMax {
+			newHead.YMax = g.yMax
+		}
+		if g.numPoints > maxPoints {
+			maxPoints = g.numPoints
+		}
+		if g.numContours > maxContours {
+			maxContours = g.numContours
+		}
+	}
+	if newHead.XMin > newHead.XMax { // no outlines
+		newHead.XMin, newHead.YMin, newHead.XMax, newHead.YMax = 0, 0, 0, 0
+	}
+
+	var loca []byte
+	if len(glyf) <= 2*0xFFFF {
+		loca = make([]byte, 2*len(offsets))
+		for i, offset := range offsets {
+			be.PutUint16(loca[2*i:], uint16(offset/2))
+		}
+		newHead.IndexToLocFormat = 0
+	} else {
+		loca = make([]byte, 4*len(offsets))
+		for i, offset := range offsets {
+			be.PutUint32(loca[4*i:], uint32(offset))
+		}
+		newHead.IndexToLocFormat = 1
+	}
+	out.AddTable(tagGlyf, sfnt.NewTable(tagGlyf, glyf))
+	out.AddTable(tagLoca, sfnt.NewTable(tagLoca, loca))
+	out.AddTable(sfnt.TagHead, &newHead)
+
+	// the composite glyphs are flattened
+	newMaxp := append([]byte(nil), maxp.Bytes()...)
+	if len(newMaxp) >= maxpVersion1Size {
+		be.PutUint16(newMaxp[maxpMaxPointsOffset:], uint16(maxPoints))
+		be.PutUint16(newMaxp[maxpMaxContoursOffset:], uint16(maxContours))
+		be.PutUint16(newMaxp[maxpMaxCompositePointsOffset:], 0)
+		be.PutUint16(newMaxp[maxpMaxCompositeContoursOffset:], 0)
+		be.PutUint16(newMaxp[maxpMaxComponentElementsOffset:], 0)
+		be.PutUint16(newMaxp[maxpMaxComponentDepthOffset:], 0)
+	}
+	out.AddTable(sfnt.TagMaxp, sfnt.NewTable(sfnt.TagMaxp, newMaxp))
+	return nil
+}
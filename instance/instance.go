@@ -0,0 +1,110 @@
+// Package instance builds static fonts from variable fonts, by pinning
+// all the axes of variation to given coordinates.
+//
+// Only fonts with TrueType outlines (glyf and loca tables) are supported.
+package instance
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var be = binary.BigEndian
+
+var (
+	tagGlyf = sfnt.MustNamedTag("glyf")
+	tagLoca = sfnt.MustNamedTag("loca")
+	tagPost = sfnt.MustNamedTag("post")
+	tagCvt  = sfnt.MustNamedTag("cvt ")
+	tagCvar = sfnt.MustNamedTag("cvar")
+	tagFpgm = sfnt.MustNamedTag("fpgm")
+	tagPrep = sfnt.MustNamedTag("prep")
+	tagVvar = sfnt.MustNamedTag("VVAR")
+)
+
+// ErrUnsupportedOutlines is returned when the font has no TrueType outlines.
+var ErrUnsupportedOutlines = errors.New("instancing is only supported for TrueType outlines")
+
+// tables which are not copied in the instance: the variations are
+// applied, and the hinting is dropped since the glyphs are not hinted anymore
+var droppedTables = []sfnt.Tag{
+	sfnt.TagFvar, sfnt.TagAvar, sfnt.TagGvar, sfnt.TagHvar, sfnt.TagMvar, tagVvar,
+	tagCvt, tagCvar, tagFpgm, tagPrep, sfnt.TagDSIG,
+}
+
+// Pin returns a static font, which is the instance of the variable font
+// at the given location, in user space (such as 430 for the weight 'wght').
+// The missing axes use their default value.
+// It returns sfnt.ErrMissingTable if the font is not a variable font.
+//
+// The glyf, loca, hmtx, hhea, head and maxp tables are rebuilt for the
+// instance, and the variations of the 'MVAR' table are applied to the OS/2 and
+// post tables. The names of the font are updated with the name of the style.
+// The composite glyphs are flattened and the hinting is dropped.
+// The variation tables (fvar, avar, gvar, HVAR, MVAR, etc.) are dropped, and the
+// other tables (such as the layout tables) are copied without modification.
+func Pin(font *sfnt.Font, location map[sfnt.Tag]float32) (*sfnt.Font, error) {
+	if !font.HasTable(tagGlyf) || !font.HasTable(tagLoca) {
+		return nil, ErrUnsupportedOutlines
+	}
+	axes, err := font.VariationAxes()
+	if err != nil {
+		return nil, err
+	}
+	userCoords := make([]float32, len(axes))
+	for i, axis := range axes {
+		userCoords[i] = axis.Default
+		if value, ok := location[axis.Tag]; ok {
+			userCoords[i] = value
+		}
+	}
+	coords, err := font.NormalizeCoordinates(userCoords)
+	if err != nil {
+		return nil, err
+	}
+
+	out := sfnt.New(font.Type())
+	for _, tag := range font.Tags() {
+		if isDropped(tag) {
+			continue
+		}
+		table, err := font.Table(tag)
+		if err != nil {
+			return nil, err
+		}
+		out.AddTable(tag, table)
+	}
+
+	glyphs, err := instanceGlyphs(font, coords)
+	if err != nil {
+		return nil, err
+	}
+	if err := glyphs.addTables(font, out); err != nil {
+		return nil, err
+	}
+	if err := glyphs.addMetrics(font, out); err != nil {
+		return nil, err
+	}
+	if err := instanceGlobalMetrics(font, out, coords, axes, userCoords); err != nil {
+		return nil, err
+	}
+	if font.HasTable(sfnt.TagName) {
+		name, err := instanceName(font, userCoords)
+		if err != nil {
+			return nil, err
+		}
+		out.AddTable(sfnt.TagName, name)
+	}
+	return out, nil
+}
+
+func isDropped(tag sfnt.Tag) bool {
+	for _, dropped := range droppedTables {
+		if tag == dropped {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,309 @@
+package instance
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+func parseFont(t *testing.T, filename string) *sfnt.Font {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	font, err := sfnt.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+// roundTrip writes and parses the font.
+func roundTrip(t *testing.T, font *sfnt.Font) *sfnt.Font {
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := sfnt.StrictParse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// variableFont returns a font with a 'wght' axis, from 100 to 900, and
+// a "Bold" named instance. Glyph 1 is a square whose top right corner
+// moves with the weight, and glyph 2 is a composite using glyph 1.
+func variableFont(t *testing.T) *sfnt.Font {
+	square := []byte{
+		0, 1, // numberOfContours
+		0, 0, 0, 0, 0, 100, 0, 100, // bounding box
+		0, 3, // endPtsOfContours
+		0, 0, // instructionLength
+		1, 1, 1, 1, // flags: on curve
+		0, 0, 0, 100, 0, 0, 0xFF, 0x9C, // x: 0, 100, 100, 0
+		0, 0, 0, 0, 0, 100, 0, 0, // y: 0, 0, 100, 100
+	}
+	composite := []byte{
+		0xFF, 0xFF, // numberOfContours
+		0, 0, 0, 0, 0, 0, 0, 0, // bounding box
+		0, 0x01 | 0x02, // flags: args are words and XY values
+		0, 1, // glyph
+		0, 10, 0, 20, // offset
+	}
+	glyf := append(append([]byte(nil), square...), composite...)
+	loca := []byte{0, 0, 0, 0, 0, byte(len(square) / 2), 0, byte(len(glyf) / 2)}
+
+	gvar := []byte{
+		0, 1, 0, 0, // version
+		0, 1, // axisCount
+		0, 1, // sharedTupleCount
+		0, 0, 0, 28, // sharedTuplesOffset
+		0, 3, // glyphCount
+		0, 0, // flags
+		0, 0, 0, 30, // glyphVariationDataArrayOffset
+		0, 0, 0, 0, 0, 9, 0, 15, // offsets
+		0x40, 0, // shared tuple: 1
+		// square: points 1 and 2 are moved
+		0, 1, 0, 8, // tupleVariationCount, dataOffset
+		0, 10, 0x20, 0, // variationDataSize, tupleIndex (private point numbers)
+		2, 1, 1, 1, // points
+		1, 50, 50, // x deltas
+		1, 0, 50, // y deltas
+		// composite: the component is moved
+		0, 1, 0, 8, // tupleVariationCount, dataOffset
+		0, 4, 0, 0, // variationDataSize, tupleIndex
+		0, 10, 0x83, // x deltas
+		0x84, // y deltas
+	}
+
+	fvar := []byte{
+		0, 1, 0, 0, // version
+		0, 16, // axesArrayOffset
+		0, 2, // reserved
+		0, 1, // axisCount
+		0, 20, // axisSize
+		0, 1, // instanceCount
+		0, 8, // instanceSize
+		'w', 'g', 'h', 't',
+		0, 100, 0, 0, // min
+		1, 0x90, 0, 0, // default: 400
+		3, 0x84, 0, 0, // max: 900
+		0, 0, // flags
+		1, 0, // axisNameID
+		1, 1, // subfamilyNameID
+		0, 0, // flags
+		3, 0x84, 0, 0, // coordinates: 900
+	}
+
+	maxp := make([]byte, 32)
+	maxp[0], maxp[5] = 1, 3 // version 1.0, 3 glyphs
+
+	hhea := make([]byte, 36)
+	hhea[1], hhea[35] = 1, 3                                        // version 1.0, 3 metrics
+	hmtx := []byte{0, 0, 0, 0, 0x01, 0xF4, 0, 0, 0x01, 0xF4, 0, 10} // advances: 0, 500, 500
+
+	os2 := make([]byte, 96)
+	os2[1] = 4               // version
+	os2[4], os2[5] = 1, 0x90 // usWeightClass: 400
+	os2[7] = 5               // usWidthClass: normal
+
+	post := make([]byte, 32)
+	post[1] = 3 // version 3.0, without glyph names
+
+	names := sfnt.NewTableName()
+	for nameID, value := range map[sfnt.NameID]string{
+		sfnt.NameFontFamily:    "Test",
+		sfnt.NameFontSubfamily: "Regular",
+		256:                    "Weight",
+		257:                    "Bold",
+	} {
+		if err := names.AddMicrosoftEnglishEntry(nameID, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	font := sfnt.New(sfnt.TypeTrueType)
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head.UnitsPerEm = 1000
+	head.MagicNumber = 0x5F0F3CF5
+	for tag, content := range map[sfnt.Tag][]byte{
+		tagGlyf:      glyf,
+		tagLoca:      loca,
+		sfnt.TagGvar: gvar,
+		sfnt.TagFvar: fvar,
+		sfnt.TagMaxp: maxp,
+		sfnt.TagHhea: hhea,
+		sfnt.TagHmtx: hmtx,
+		sfnt.TagOS2:  os2,
+		sfnt.TagName: names.Bytes(),
+		tagPost:      post,
+		tagPrep:      {0xB0, 0},
+		sfnt.TagDSIG: {0, 0, 0, 1, 0, 0, 0, 0},
+	} {
+		font.AddTable(tag, sfnt.NewTable(tag, content))
+	}
+	return roundTrip(t, font)
+}
+
+func outlinePoints(points ...float32) []sfnt.GlyphPoint {
+	out := make([]sfnt.GlyphPoint, len(points)/2)
+	for i := range out {
+		out[i] = sfnt.GlyphPoint{X: points[2*i], Y: points[2*i+1], OnCurve: true}
+	}
+	return out
+}
+
+func TestPin(t *testing.T) {
+	font := variableFont(t)
+
+	for _, test := range []struct {
+		weight     float32
+		square     []sfnt.GlyphPoint
+		composite  []sfnt.GlyphPoint
+		fullName   string
+		postScript string
+	}{
+		{
+			400,
+			outlinePoints(0, 0, 100, 0, 100, 100, 0, 100),
+			outlinePoints(10, 20, 110, 20, 110, 120, 10, 120),
+			"", "",
+		},
+		{
+			900,
+			outlinePoints(50, 0, 150, 0, 150, 150, 50, 150),
+			outlinePoints(70, 20, 170, 20, 170, 170, 70, 170),
+			"Test Bold", "Test-Bold",
+		},
+		{
+			650, // not a named instance
+			outlinePoints(25, 0, 125, 0, 125, 125, 25, 125),
+			outlinePoints(40, 20, 140, 20, 140, 145, 40, 145),
+			"", "",
+		},
+	} {
+		pinned, err := Pin(font, map[sfnt.Tag]float32{tagWght: test.weight})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := roundTrip(t, pinned)
+
+		for _, tag := range droppedTables {
+			if out.HasTable(tag) {
+				t.Errorf("table %s should be dropped", tag)
+			}
+		}
+
+		for gi, expected := range map[sfnt.GlyphIndex][]sfnt.GlyphPoint{1: test.square, 2: test.composite} {
+			outline, err := out.GlyphOutline(gi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(outline.Points, expected) {
+				t.Errorf("weight %v, glyph %d: expected %v, got %v", test.weight, gi, expected, outline.Points)
+			}
+		}
+
+		widths, err := out.HtmxTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(widths, []int{0, 500, 500}) {
+			t.Errorf("unexpected widths %v", widths)
+		}
+		hhea, err := out.HheaTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if maxExtent := int16(test.composite[2].X); hhea.XMaxExtent != maxExtent {
+			t.Errorf("expected extent %d, got %d", maxExtent, hhea.XMaxExtent)
+		}
+
+		os2, err := out.OS2Table()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if os2.USWeightClass != uint16(test.weight) {
+			t.Errorf("expected weight class %v, got %d", test.weight, os2.USWeightClass)
+		}
+
+		names, err := out.NameTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.fullName == "" {
+			if names.Lookup(sfnt.NameFull) != nil {
+				t.Errorf("unexpected full name")
+			}
+			continue
+		}
+		if got := names.Lookup(sfnt.NameFull).String(); got != test.fullName {
+			t.Errorf("expected full name %q, got %q", test.fullName, got)
+		}
+		if got := names.Lookup(sfnt.NamePostscript).String(); got != test.postScript {
+			t.Errorf("expected PostScript name %q, got %q", test.postScript, got)
+		}
+	}
+
+	if _, err := Pin(parseFont(t, "../sfnt/testdata/Roboto-BoldItalic.ttf"), nil); err != sfnt.ErrMissingTable {
+		t.Errorf("expected ErrMissingTable for a static font, got %v", err)
+	}
+}
+
+func TestEncodeGlyph(t *testing.T) {
+	font := parseFont(t, "../sfnt/testdata/Roboto-BoldItalic.ttf")
+	numGlyphs, err := readNumGlyphs(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gs := make(glyphs, numGlyphs)
+	for i := range gs {
+		outline, err := font.GlyphOutline(sfnt.GlyphIndex(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gs[i] = encodeGlyph(outline)
+	}
+	encoded := sfnt.New(font.Type())
+	for _, tag := range font.Tags() {
+		table, err := font.Table(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded.AddTable(tag, table)
+	}
+	if err := gs.addTables(font, encoded); err != nil {
+		t.Fatal(err)
+	}
+	out := roundTrip(t, encoded)
+
+	for i := range gs {
+		gi := sfnt.GlyphIndex(i)
+		expected, err := font.GlyphOutline(gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := out.GlyphOutline(gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the scaled components may have fractional coordinates
+		for j, p := range expected.Points {
+			x, y := roundPoint(p)
+			expected.Points[j].X, expected.Points[j].Y = float32(x), float32(y)
+		}
+		if !reflect.DeepEqual(expected.Points, got.Points) || !reflect.DeepEqual(expected.EndPoints, got.EndPoints) {
+			t.Fatalf("glyph %d: outline not preserved: expected %v, got %v", gi, expected, got)
+		}
+	}
+}
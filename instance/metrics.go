@@ -0,0 +1,167 @@
+package instance
+
+import (
+	"math"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var (
+	tagWght = sfnt.MustNamedTag("wght")
+	tagWdth = sfnt.MustNamedTag("wdth")
+)
+
+// offsets of the varied fields of the 'OS/2' table
+const (
+	os2WeightClassOffset       = 4
+	os2WidthClassOffset        = 6
+	os2StrikeoutSizeOffset     = 26
+	os2StrikeoutPositionOffset = 28
+	os2TypoAscenderOffset      = 68
+	os2TypoDescenderOffset     = 70
+	os2TypoLineGapOffset       = 72
+	os2WinAscentOffset         = 74
+	os2WinDescentOffset        = 76
+	os2XHeightOffset           = 86
+	os2CapHeightOffset         = 88
+)
+
+// offsets of the varied fields of the 'post' table
+const (
+	postUnderlinePositionOffset  = 8
+	postUnderlineThicknessOffset = 10
+)
+
+// widths (in percent of the normal width) of the usWidthClass values,
+// starting at 1 (ultra-condensed)
+var widthClasses = [...]float32{50, 62.5, 75, 87.5, 100, 112.5, 125, 150, 200}
+
+// addMetrics adds the hhea and hmtx tables to out.
+// All the glyphs are written with a full metric record, and
+// the left side bearings are the minimum x of the instance outlines.
+func (gs glyphs) addMetrics(font, out *sfnt.Font) error {
+	if !font.HasTable(sfnt.TagHhea) || !font.HasTable(sfnt.TagHmtx) {
+		return nil
+	}
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return err
+	}
+
+	newHhea := *hhea
+	newHhea.NumOfLongHorMetrics = int16(len(gs))
+	newHhea.AdvanceWidthMax = 0
+	newHhea.MinLeftSideBearing, newHhea.MinRightSideBearing = math.MaxInt16, math.MaxInt16
+	newHhea.XMaxExtent = math.MinInt16
+	hmtx := make([]byte, 4*len(gs))
+	for i, g := range gs {
+		be.PutUint16(hmtx[4*i:], g.advance)
+		be.PutUint16(hmtx[4*i+2:], uint16(g.xMin))
+
+		if g.advance > newHhea.AdvanceWidthMax {
+			newHhea.AdvanceWidthMax = g.advance
+		}
+		if len(g.data) == 0 {
+			continue
+		}
+		if g.xMin < newHhea.MinLeftSideBearing {
+			newHhea.MinLeftSideBearing = g.xMin
+		}
+		if rsb := int16(int(g.advance) - int(g.xMax)); rsb < newHhea.MinRightSideBearing {
+			newHhea.MinRightSideBearing = rsb
+		}
+		// the extent is xMin + (xMax - xMin)
+		if g.xMax > newHhea.XMaxExtent {
+			newHhea.XMaxExtent = g.xMax
+		}
+	}
+	if newHhea.XMaxExtent < newHhea.MinLeftSideBearing { // no outlines
+		newHhea.MinLeftSideBearing, newHhea.MinRightSideBearing, newHhea.XMaxExtent = 0, 0, 0
+	}
+
+	out.AddTable(sfnt.TagHhea, &newHhea)
+	out.AddTable(sfnt.TagHmtx, sfnt.NewTable(sfnt.TagHmtx, hmtx))
+	return nil
+}
+
+func roundMetric(v float32) uint16 {
+	return uint16(int16(math.Round(float64(v))))
+}
+
+// instanceGlobalMetrics applies the variations of the 'MVAR' table to
+// the OS/2 and post tables of out, and updates the weight and width
+// classes according to the 'wght' and 'wdth' axes.
+func instanceGlobalMetrics(font, out *sfnt.Font, coords []float32, axes []sfnt.VariationAxis, userCoords []float32) error {
+	metrics, err := font.VariedMetrics(coords)
+	if err != nil {
+		return err
+	}
+
+	if font.HasTable(sfnt.TagOS2) {
+		os2, err := font.OS2Table()
+		if err != nil {
+			return err
+		}
+		buf := append([]byte(nil), os2.Bytes()...)
+		for _, field := range [...]struct {
+			offset int
+			value  uint16
+		}{
+			{os2StrikeoutSizeOffset, roundMetric(metrics.StrikeoutSize)},
+			{os2StrikeoutPositionOffset, roundMetric(metrics.StrikeoutPosition)},
+			{os2TypoAscenderOffset, roundMetric(metrics.TypoAscender)},
+			{os2TypoDescenderOffset, roundMetric(metrics.TypoDescender)},
+			{os2TypoLineGapOffset, roundMetric(metrics.TypoLineGap)},
+			{os2WinAscentOffset, uint16(math.Round(float64(metrics.WinAscent)))},
+			{os2WinDescentOffset, uint16(math.Round(float64(metrics.WinDescent)))},
+			{os2XHeightOffset, roundMetric(metrics.XHeight)},
+			{os2CapHeightOffset, roundMetric(metrics.CapHeight)},
+		} {
+			// the fields missing in older versions are skipped
+			if len(buf) >= field.offset+2 {
+				be.PutUint16(buf[field.offset:], field.value)
+			}
+		}
+
+		for i, axis := range axes {
+			switch axis.Tag {
+			case tagWght:
+				weight := math.Round(float64(userCoords[i]))
+				be.PutUint16(buf[os2WeightClassOffset:], uint16(math.Max(1, math.Min(1000, weight))))
+			case tagWdth:
+				be.PutUint16(buf[os2WidthClassOffset:], widthClass(userCoords[i]))
+			}
+		}
+
+		table, err := sfnt.ParseTable(sfnt.TagOS2, buf)
+		if err != nil {
+			return err
+		}
+		out.AddTable(sfnt.TagOS2, table)
+	}
+
+	if font.HasTable(tagPost) {
+		post, err := font.Table(tagPost)
+		if err != nil {
+			return err
+		}
+		buf := append([]byte(nil), post.Bytes()...)
+		if len(buf) >= postUnderlineThicknessOffset+2 {
+			be.PutUint16(buf[postUnderlinePositionOffset:], roundMetric(metrics.UnderlinePosition))
+			be.PutUint16(buf[postUnderlineThicknessOffset:], roundMetric(metrics.UnderlineThickness))
+		}
+		out.AddTable(tagPost, sfnt.NewTable(tagPost, buf))
+	}
+	return nil
+}
+
+// widthClass returns the usWidthClass closest to the given width, in percent.
+func widthClass(width float32) uint16 {
+	best := 0
+	for i, class := range widthClasses {
+		if math.Abs(float64(class-width)) < math.Abs(float64(widthClasses[best]-width)) {
+			best = i
+		}
+	}
+	return uint16(best + 1)
+}
@@ -0,0 +1,156 @@
+package instance
+
+import (
+	"strings"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+// nameVariationsPostScriptPrefix is the name ID of the prefix
+// used to build the PostScript names of the instances.
+const nameVariationsPostScriptPrefix = sfnt.NameID(25)
+
+// names rewritten for the instance
+var instanceNameIDs = []sfnt.NameID{
+	sfnt.NameFontFamily, sfnt.NameFontSubfamily, sfnt.NameFull, sfnt.NamePostscript,
+	sfnt.NamePreferredFamily, sfnt.NamePreferredSubfamily,
+}
+
+// isRIBBI returns true for the styles supported by the legacy family
+// and subfamily names.
+func isRIBBI(style string) bool {
+	switch style {
+	case "Regular", "Italic", "Bold", "Bold Italic":
+		return true
+	}
+	return false
+}
+
+func lookupString(names *sfnt.TableName, nameID sfnt.NameID) string {
+	if entry := names.Lookup(nameID); entry != nil {
+		return entry.String()
+	}
+	return ""
+}
+
+// styleNames returns the name of the style at the given coordinates, and
+// its PostScript name, if defined. The names of the named instances are used
+// if the coordinates match one of them, otherwise the name is built
+// from the 'STAT' table. The style is empty if it can't be named.
+func styleNames(font *sfnt.Font, names *sfnt.TableName, userCoords []float32) (style, postScript string, err error) {
+	instances, err := font.NamedInstances()
+	if err != nil {
+		return "", "", err
+	}
+	for _, instance := range instances {
+		if !equalCoordinates(instance.Coordinates, userCoords) {
+			continue
+		}
+		style = lookupString(names, instance.SubfamilyNameID)
+		if instance.PostScriptNameID != 0xFFFF {
+			postScript = lookupString(names, instance.PostScriptNameID)
+		}
+		if style != "" {
+			return style, postScript, nil
+		}
+	}
+
+	if !font.HasTable(sfnt.TagSTAT) {
+		return "", "", nil
+	}
+	style, err = font.StyleName(userCoords)
+	if err != nil {
+		return "", "", err
+	}
+	if style == "" { // all the values are elided
+		style = "Regular"
+	}
+	return style, "", nil
+}
+
+func equalCoordinates(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// instanceName returns the 'name' table of the instance, whose family, subfamily,
+// full and PostScript names are updated with the name of the style.
+// The updated names are only written for the Microsoft platform, in English.
+// If the style can't be named, the table is returned unchanged.
+func instanceName(font *sfnt.Font, userCoords []float32) (*sfnt.TableName, error) {
+	names, err := font.NameTable()
+	if err != nil {
+		return nil, err
+	}
+	style, postScript, err := styleNames(font, names, userCoords)
+	if err != nil {
+		return nil, err
+	}
+	if style == "" {
+		return names, nil
+	}
+
+	family := lookupString(names, sfnt.NamePreferredFamily)
+	if family == "" {
+		family = lookupString(names, sfnt.NameFontFamily)
+	}
+	if postScript == "" {
+		prefix := lookupString(names, nameVariationsPostScriptPrefix)
+		if prefix == "" {
+			prefix = strings.ReplaceAll(family, " ", "")
+		}
+		postScript = prefix + "-" + strings.ReplaceAll(style, " ", "")
+	}
+
+	out := sfnt.NewTableName()
+	for _, entry := range names.List() {
+		if !isInstanceName(entry.NameID) {
+			out.Add(entry)
+		}
+	}
+
+	values := map[sfnt.NameID]string{
+		sfnt.NameFull:       family + " " + style,
+		sfnt.NamePostscript: postScript,
+	}
+	if isRIBBI(style) {
+		values[sfnt.NameFontFamily] = family
+		values[sfnt.NameFontSubfamily] = style
+	} else {
+		// the legacy names only support the regular and italic styles
+		legacyStyle := "Regular"
+		if strings.HasSuffix(style, " Italic") {
+			legacyStyle = "Italic"
+		}
+		values[sfnt.NameFontFamily] = family + " " + strings.TrimSuffix(style, " Italic")
+		values[sfnt.NameFontSubfamily] = legacyStyle
+		values[sfnt.NamePreferredFamily] = family
+		values[sfnt.NamePreferredSubfamily] = style
+	}
+	for _, nameID := range instanceNameIDs {
+		value, ok := values[nameID]
+		if !ok {
+			continue
+		}
+		if err := out.AddMicrosoftEnglishEntry(nameID, value); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func isInstanceName(nameID sfnt.NameID) bool {
+	for _, id := range instanceNameIDs {
+		if id == nameID {
+			return true
+		}
+	}
+	return false
+}
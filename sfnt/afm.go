@@ -0,0 +1,189 @@
+package sfnt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ConradIrwin/font/afm"
+)
+
+// AFM returns the metrics of the font as an Adobe Font Metrics file,
+// for the legacy typesetting tools which require one: use its
+// WriteTo method to serialize it.
+// The values are scaled to 1000 units per em, the glyph names come from
+// GlyphNames (the glyphs without name are named uni<code point> or
+// gid<index> if they are not mapped), the widths from
+// the 'hmtx' table and the kerning pairs from KernTable(false).
+// The glyphs are encoded by the Unicode code point, if it is lower than 256.
+// The glyph bounding boxes are only provided for TrueType outlines.
+func (font *Font) AFM() (*afm.Font, error) {
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	if head.UnitsPerEm == 0 {
+		return nil, errInvalidHeadTable
+	}
+	scale := 1000 / float64(head.UnitsPerEm)
+	scaled := func(v int) float64 {
+		if v == 0 {
+			return 0 // avoid negative zeros
+		}
+		return math.Round(float64(v) * scale)
+	}
+
+	out := &afm.Font{
+		EncodingScheme: "FontSpecific",
+		FontBBox:       [4]float64{scaled(int(head.XMin)), scaled(int(head.YMin)), scaled(int(head.XMax)), scaled(int(head.YMax))},
+	}
+
+	if font.HasTable(TagName) {
+		names, err := font.NameTable()
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range [...]struct {
+			id    NameID
+			value *string
+		}{
+			{NamePostscript, &out.FontName},
+			{NameFull, &out.FullName},
+			{NameFontFamily, &out.FamilyName},
+			{NameFontSubfamily, &out.Weight},
+			{NameVersion, &out.Version},
+			{NameCopyrightNotice, &out.Notice},
+		} {
+			if entry := names.Lookup(field.id); entry != nil {
+				*field.value = entry.String()
+			}
+		}
+	}
+
+	if font.HasTable(TagPost) {
+		post, err := font.PostTable()
+		if err != nil {
+			return nil, err
+		}
+		out.ItalicAngle = post.ItalicAngle
+		out.IsFixedPitch = post.IsFixedPitch
+		out.UnderlinePosition = scaled(int(post.UnderlinePosition))
+		out.UnderlineThickness = scaled(int(post.UnderlineThickness))
+	}
+
+	if font.HasTable(TagOS2) {
+		os2, err := font.OS2Table()
+		if err != nil {
+			return nil, err
+		}
+		out.Ascender = scaled(int(os2.STypoAscender))
+		out.Descender = scaled(int(os2.STypoDescender))
+		out.CapHeight = scaled(int(os2.SCapHeight))
+		out.XHeight = scaled(int(os2.SxHeigh))
+	} else {
+		hhea, err := font.HheaTable()
+		if err != nil {
+			return nil, err
+		}
+		out.Ascender = scaled(int(hhea.Ascent))
+		out.Descender = scaled(int(hhea.Descent))
+	}
+
+	widths, err := font.HtmxTable()
+	if err != nil {
+		return nil, err
+	}
+	codes, runes, err := font.afmCodes(len(widths))
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := font.GlyphNames()
+	if err != nil && !errors.Is(err, ErrMissingTable) {
+		return nil, err
+	}
+	glyphName := func(gi GlyphIndex) string {
+		if names != nil {
+			if name := names.GlyphName(gi); name != "" {
+				return name
+			}
+		}
+		switch r := runes[gi]; {
+		case r == 0:
+			return fmt.Sprintf("gid%d", gi)
+		case r <= 0xFFFF:
+			return fmt.Sprintf("uni%04X", r)
+		default:
+			return fmt.Sprintf("u%X", r)
+		}
+	}
+	out.CharMetrics = make([]afm.CharMetric, len(widths))
+	for i, width := range widths {
+		gi := GlyphIndex(i)
+		metric := afm.CharMetric{Code: codes[gi], Name: glyphName(gi), WX: scaled(width)}
+		if font.HasTable(TagGlyf) {
+			buf, err := font.glyphBuffer(gi)
+			if err != nil {
+				return nil, err
+			}
+			if len(buf) >= 10 { // the empty glyphs have no header
+				for j := range metric.BBox {
+					metric.BBox[j] = scaled(int(int16(be.Uint16(buf[2+2*j:]))))
+				}
+			}
+		}
+		out.CharMetrics[i] = metric
+	}
+
+	kerns, err := font.KernTable(false)
+	if errors.Is(err, ErrMissingTable) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pairs, keys := resolveKernPairs(kerns)
+	out.KernPairs = make([]afm.KernPair, 0, len(keys))
+	for _, key := range keys {
+		first, second := GlyphIndex(key>>16), GlyphIndex(key)
+		if int(first) >= len(widths) || int(second) >= len(widths) {
+			continue // invalid glyphs in the kerning tables
+		}
+		out.KernPairs = append(out.KernPairs, afm.KernPair{
+			First:  glyphName(first),
+			Second: glyphName(second),
+			X:      scaled(int(pairs[key])),
+		})
+	}
+	return out, nil
+}
+
+// afmCodes returns the character code of each glyph, which is the
+// smallest rune lower than 256 mapped to it, or -1, and the smallest
+// rune mapped to each glyph, or 0.
+func (font *Font) afmCodes(numGlyphs int) (codes []int, runes []rune, err error) {
+	codes = make([]int, numGlyphs)
+	for i := range codes {
+		codes[i] = -1
+	}
+	runes = make([]rune, numGlyphs)
+	if !font.HasTable(TagCmap) {
+		return codes, runes, nil
+	}
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return nil, nil, err
+	}
+	for r, gi := range cmap.Compile() {
+		if gi == 0 || int(gi) >= numGlyphs {
+			continue
+		}
+		if runes[gi] == 0 || r < runes[gi] {
+			runes[gi] = r
+		}
+		if r < 256 && (codes[gi] == -1 || int(r) < codes[gi]) {
+			codes[gi] = int(r)
+		}
+	}
+	return codes, runes, nil
+}
@@ -0,0 +1,93 @@
+package sfnt
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ConradIrwin/font/afm"
+)
+
+func TestAFM(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	metrics, err := font.AFM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := afm.Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.FontName != "Roboto-BoldItalic" || parsed.ItalicAngle >= 0 {
+		t.Errorf("unexpected font %q %v", parsed.FontName, parsed.ItalicAngle)
+	}
+
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gi := cmap.Lookup('A')
+	metric, ok := parsed.CharMetric("uni0041") // Roboto has no glyph names
+	if !ok {
+		t.Fatal("missing A")
+	}
+	if expected := math.Round(float64(widths[gi]) * 1000 / float64(head.UnitsPerEm)); metric.Code != 'A' || metric.WX != expected {
+		t.Errorf("expected code 65 and width %v, got %d %v", expected, metric.Code, metric.WX)
+	}
+	if metric.BBox[2] <= metric.BBox[0] || metric.BBox[3] <= 0 {
+		t.Errorf("unexpected bounding box %v", metric.BBox)
+	}
+	if len(parsed.CharMetrics) != len(widths) {
+		t.Errorf("expected %d glyphs, got %d", len(widths), len(parsed.CharMetrics))
+	}
+
+	snapshot, err := font.MetricsSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.KernPairs) != snapshot.Size() {
+		t.Errorf("expected %d kerning pairs, got %d", snapshot.Size(), len(parsed.KernPairs))
+	}
+	if kern := parsed.Kern("uni0041", "uni0056"); kern >= 0 {
+		t.Errorf("expected a negative kerning for AV, got %v", kern)
+	}
+}
+
+func TestAFMInvalidKerning(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kern, err := NewTableKern(NewKerns(map[[2]GlyphIndex]int16{
+		{cmap.Lookup('A'), cmap.Lookup('V')}: -20,
+		{65441, cmap.Lookup('V')}:            -50, // out of range
+		{cmap.Lookup('A'), 65441}:            -50,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.RemoveTable(TagGpos)
+	font.AddTable(TagKern, kern)
+
+	metrics, err := font.AFM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.KernPairs) != 1 || metrics.KernPairs[0].First != "uni0041" || metrics.KernPairs[0].Second != "uni0056" {
+		t.Errorf("unexpected kerning pairs %v", metrics.KernPairs)
+	}
+}
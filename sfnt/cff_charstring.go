@@ -0,0 +1,405 @@
+package sfnt
+
+import "errors"
+
+var (
+	errInvalidCharstring     = errors.New("invalid CFF charstring")
+	errUnsupportedCharstring = errors.New("unsupported CFF charstring operator")
+)
+
+// PathOp is the kind of a PathSegment.
+type PathOp uint8
+
+const (
+	// MoveTo starts a new contour at Args[0], closing the previous one.
+	MoveTo PathOp = iota
+	// LineTo draws a line to Args[0].
+	LineTo
+	// CubeTo draws a cubic Bézier curve to Args[2],
+	// with the control points Args[0] and Args[1].
+	CubeTo
+)
+
+// PathSegment is an element of a glyph path.
+type PathSegment struct {
+	Op   PathOp
+	Args [3]Point
+}
+
+// GlyphPath is the outline of a glyph stored as a charstring,
+// in font units. The contours are implicitly closed.
+type GlyphPath []PathSegment
+
+const (
+	maxCharstringStack = 513 // the CFF2 limit, higher than the CFF one
+	maxSubrsDepth      = 10
+)
+
+// subrsBias returns the bias applied to the subroutine
+// indices, which depends on the number of subroutines.
+func subrsBias(subrs [][]byte) int {
+	switch n := len(subrs); {
+	case n < 1240:
+		return 107
+	case n < 33900:
+		return 1131
+	default:
+		return 32768
+	}
+}
+
+// charstringInterpreter executes Type 2 (CFF) and CFF2 charstrings.
+type charstringInterpreter struct {
+	globalSubrs, localSubrs [][]byte
+
+	// cff2 enables the blend and vsindex operators,
+	// and disables the width and endchar handling
+	cff2 bool
+	// store and coords are used to resolve the blend operator
+	store   itemVariationStore
+	coords  []float32
+	vsindex int
+	scalars []float32 // cached for vsindex, nil if not computed
+
+	stack     []float64
+	x, y      float64
+	nStems    int
+	seenWidth bool
+	depth     int
+
+	path GlyphPath
+}
+
+func (ci *charstringInterpreter) moveTo(dx, dy float64) {
+	ci.x += dx
+	ci.y += dy
+	ci.path = append(ci.path, PathSegment{Op: MoveTo, Args: [3]Point{{float32(ci.x), float32(ci.y)}}})
+}
+
+func (ci *charstringInterpreter) lineTo(dx, dy float64) {
+	ci.x += dx
+	ci.y += dy
+	ci.path = append(ci.path, PathSegment{Op: LineTo, Args: [3]Point{{float32(ci.x), float32(ci.y)}}})
+}
+
+func (ci *charstringInterpreter) curveTo(dx1, dy1, dx2, dy2, dx3, dy3 float64) {
+	var seg PathSegment
+	seg.Op = CubeTo
+	for i, d := range [3][2]float64{{dx1, dy1}, {dx2, dy2}, {dx3, dy3}} {
+		ci.x += d[0]
+		ci.y += d[1]
+		seg.Args[i] = Point{float32(ci.x), float32(ci.y)}
+	}
+	ci.path = append(ci.path, seg)
+}
+
+// popWidth removes the optional advance width preceding
+// the arguments of the first stack clearing operator.
+func (ci *charstringInterpreter) popWidth(hasWidth bool) {
+	if !ci.seenWidth && !ci.cff2 && hasWidth {
+		ci.stack = ci.stack[1:]
+	}
+	ci.seenWidth = true
+}
+
+func (ci *charstringInterpreter) stems() {
+	ci.popWidth(len(ci.stack)%2 == 1)
+	ci.nStems += len(ci.stack) / 2
+}
+
+// blend implements the CFF2 blend operator.
+func (ci *charstringInterpreter) blend() error {
+	if len(ci.stack) == 0 {
+		return errInvalidCharstring
+	}
+	n := int(ci.stack[len(ci.stack)-1])
+	ci.stack = ci.stack[:len(ci.stack)-1]
+	if ci.scalars == nil {
+		if ci.vsindex >= len(ci.store.data) {
+			if len(ci.store.data) != 0 || ci.vsindex != 0 {
+				return errInvalidCharstring
+			}
+			ci.scalars = []float32{}
+		} else {
+			regionIndexes := ci.store.data[ci.vsindex].regionIndexes
+			ci.scalars = make([]float32, len(regionIndexes))
+			for i, region := range regionIndexes {
+				if int(region) < len(ci.store.regions) {
+					ci.scalars[i] = ci.store.regions[region].scalar(ci.coords)
+				}
+			}
+		}
+	}
+	k := len(ci.scalars)
+	if n < 0 || len(ci.stack) < n*(k+1) {
+		return errInvalidCharstring
+	}
+	base := len(ci.stack) - n*(k+1)
+	values, deltas := ci.stack[base:base+n], ci.stack[base+n:]
+	for i := range values {
+		for j, scalar := range ci.scalars {
+			values[i] += deltas[i*k+j] * float64(scalar)
+		}
+	}
+	ci.stack = ci.stack[:base+n]
+	return nil
+}
+
+// run executes the charstring, returning true when endchar is reached.
+func (ci *charstringInterpreter) run(cs []byte) (bool, error) {
+	ci.depth++
+	defer func() { ci.depth-- }()
+	if ci.depth > maxSubrsDepth {
+		return false, errInvalidCharstring
+	}
+
+	for i := 0; i < len(cs); {
+		b := cs[i]
+		if b == 28 || b >= 32 {
+			value, n, err := parseCharstringNumber(cs[i:])
+			if err != nil {
+				return false, err
+			}
+			if len(ci.stack) >= maxCharstringStack {
+				return false, errInvalidCharstring
+			}
+			ci.stack = append(ci.stack, value)
+			i += n
+			continue
+		}
+		i++
+
+		args := ci.stack
+		switch b {
+		case 1, 3, 18, 23: // hstem, vstem, hstemhm, vstemhm
+			ci.stems()
+		case 19, 20: // hintmask, cntrmask
+			// the stems of an implied vstem
+			ci.stems()
+			i += (ci.nStems + 7) / 8
+			if i > len(cs) {
+				return false, errInvalidCharstring
+			}
+		case 21: // rmoveto
+			ci.popWidth(len(args) > 2)
+			args = ci.stack
+			if len(args) < 2 {
+				return false, errInvalidCharstring
+			}
+			ci.moveTo(args[0], args[1])
+		case 22, 4: // hmoveto, vmoveto
+			ci.popWidth(len(args) > 1)
+			args = ci.stack
+			if len(args) < 1 {
+				return false, errInvalidCharstring
+			}
+			if b == 22 {
+				ci.moveTo(args[0], 0)
+			} else {
+				ci.moveTo(0, args[0])
+			}
+		case 5: // rlineto
+			for ; len(args) >= 2; args = args[2:] {
+				ci.lineTo(args[0], args[1])
+			}
+		case 6, 7: // hlineto, vlineto
+			horizontal := b == 6
+			for _, d := range args {
+				if horizontal {
+					ci.lineTo(d, 0)
+				} else {
+					ci.lineTo(0, d)
+				}
+				horizontal = !horizontal
+			}
+		case 8: // rrcurveto
+			for ; len(args) >= 6; args = args[6:] {
+				ci.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+		case 24: // rcurveline
+			for ; len(args) >= 8; args = args[6:] {
+				ci.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+			if len(args) < 2 {
+				return false, errInvalidCharstring
+			}
+			ci.lineTo(args[0], args[1])
+		case 25: // rlinecurve
+			for ; len(args) >= 8; args = args[2:] {
+				ci.lineTo(args[0], args[1])
+			}
+			if len(args) < 6 {
+				return false, errInvalidCharstring
+			}
+			ci.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		case 26: // vvcurveto
+			var dx1 float64
+			if len(args)%2 == 1 {
+				dx1, args = args[0], args[1:]
+			}
+			for ; len(args) >= 4; args = args[4:] {
+				ci.curveTo(dx1, args[0], args[1], args[2], 0, args[3])
+				dx1 = 0
+			}
+		case 27: // hhcurveto
+			var dy1 float64
+			if len(args)%2 == 1 {
+				dy1, args = args[0], args[1:]
+			}
+			for ; len(args) >= 4; args = args[4:] {
+				ci.curveTo(args[0], dy1, args[1], args[2], args[3], 0)
+				dy1 = 0
+			}
+		case 30, 31: // vhcurveto, hvcurveto
+			horizontal := b == 31
+			for ; len(args) >= 4; args = args[4:] {
+				var last float64
+				if len(args) == 5 {
+					last = args[4]
+				}
+				if horizontal {
+					ci.curveTo(args[0], 0, args[1], args[2], last, args[3])
+				} else {
+					ci.curveTo(0, args[0], args[1], args[2], args[3], last)
+				}
+				horizontal = !horizontal
+			}
+		case 10, 29: // callsubr, callgsubr
+			if len(args) == 0 {
+				return false, errInvalidCharstring
+			}
+			subrs := ci.localSubrs
+			if b == 29 {
+				subrs = ci.globalSubrs
+			}
+			index := int(args[len(args)-1]) + subrsBias(subrs)
+			if index < 0 || index >= len(subrs) {
+				return false, errInvalidCharstring
+			}
+			ci.stack = args[:len(args)-1]
+			ended, err := ci.run(subrs[index])
+			if ended || err != nil {
+				return ended, err
+			}
+			continue // the stack is not cleared
+		case 11: // return
+			return false, nil
+		case 14: // endchar
+			if ci.cff2 {
+				return false, errInvalidCharstring
+			}
+			ci.popWidth(len(args) == 1 || len(args) == 5)
+			if len(ci.stack) >= 4 { // seac-like accented character
+				return false, errUnsupportedCharstring
+			}
+			ci.stack = ci.stack[:0]
+			return true, nil
+		case 15: // vsindex
+			if !ci.cff2 || len(args) < 1 {
+				return false, errInvalidCharstring
+			}
+			ci.vsindex, ci.scalars = int(args[len(args)-1]), nil
+		case 16: // blend
+			if !ci.cff2 {
+				return false, errInvalidCharstring
+			}
+			if err := ci.blend(); err != nil {
+				return false, err
+			}
+			continue // the stack is not cleared
+		case 12:
+			if i >= len(cs) {
+				return false, errInvalidCharstring
+			}
+			op := cs[i]
+			i++
+			if err := ci.flex(op, args); err != nil {
+				return false, err
+			}
+		default:
+			return false, errUnsupportedCharstring
+		}
+		ci.stack = ci.stack[:0]
+	}
+	return false, nil
+}
+
+// parseCharstringNumber parses the number starting at buf[0],
+// returning the number of bytes read.
+func parseCharstringNumber(buf []byte) (float64, int, error) {
+	switch b := buf[0]; {
+	case b == 28:
+		if len(buf) < 3 {
+			return 0, 0, errInvalidCharstring
+		}
+		return float64(int16(be.Uint16(buf[1:]))), 3, nil
+	case 32 <= b && b <= 246:
+		return float64(int(b) - 139), 1, nil
+	case 247 <= b && b <= 254:
+		if len(buf) < 2 {
+			return 0, 0, errInvalidCharstring
+		}
+		if b <= 250 {
+			return float64((int(b)-247)*256 + int(buf[1]) + 108), 2, nil
+		}
+		return float64(-(int(b)-251)*256 - int(buf[1]) - 108), 2, nil
+	case b == 255: // 16.16 fixed
+		if len(buf) < 5 {
+			return 0, 0, errInvalidCharstring
+		}
+		return float64(int32(be.Uint32(buf[1:]))) / (1 << 16), 5, nil
+	}
+	return 0, 0, errInvalidCharstring
+}
+
+// flex implements the flex operators (12 34 to 12 37),
+// which are drawn as two curves.
+func (ci *charstringInterpreter) flex(op byte, args []float64) error {
+	switch op {
+	case 35: // flex
+		if len(args) < 12 {
+			return errInvalidCharstring
+		}
+		ci.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		ci.curveTo(args[6], args[7], args[8], args[9], args[10], args[11])
+	case 34: // hflex
+		if len(args) < 7 {
+			return errInvalidCharstring
+		}
+		ci.curveTo(args[0], 0, args[1], args[2], args[3], 0)
+		ci.curveTo(args[4], 0, args[5], -args[2], args[6], 0)
+	case 36: // hflex1
+		if len(args) < 9 {
+			return errInvalidCharstring
+		}
+		ci.curveTo(args[0], args[1], args[2], args[3], args[4], 0)
+		ci.curveTo(args[5], 0, args[6], args[7], args[8], -(args[1] + args[3] + args[7]))
+	case 37: // flex1
+		if len(args) < 11 {
+			return errInvalidCharstring
+		}
+		var dx, dy float64
+		for j := 0; j < 10; j += 2 {
+			dx += args[j]
+			dy += args[j+1]
+		}
+		dx6, dy6 := args[10], args[10]
+		if abs(dx) > abs(dy) {
+			dy6 = -dy
+		} else {
+			dx6 = -dx
+		}
+		ci.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		ci.curveTo(args[6], args[7], args[8], args[9], dx6, dy6)
+	default:
+		return errUnsupportedCharstring
+	}
+	return nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,264 @@
+package sfnt
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Issue is a problem found by the checks of a font, such as CheckNotdef.
+type Issue struct {
+	Glyph GlyphIndex
+	// Rune is the character concerned by the issue, or -1
+	// if the issue is only about the glyph.
+	Rune    rune
+	Message string
+}
+
+func (is Issue) String() string {
+	if is.Rune == -1 {
+		return fmt.Sprintf("glyph %d: %s", is.Glyph, is.Message)
+	}
+	return fmt.Sprintf("U+%04X (glyph %d): %s", is.Rune, is.Glyph, is.Message)
+}
+
+// glyphBlank returns true if the glyph has no outline, or false if it has
+// one or if the font outlines are not supported.
+func (font *Font) glyphBlank(gi GlyphIndex) (bool, error) {
+	switch {
+	case font.HasTable(TagGlyf):
+		// the components of composite glyphs are resolved
+		outline, err := font.GlyphOutline(gi)
+		if err != nil {
+			return false, err
+		}
+		return len(outline.EndPoints) == 0, nil
+	case font.HasTable(TagCFF):
+		cff, err := font.CFFTable()
+		if err != nil {
+			return false, err
+		}
+		if int(gi) >= len(cff.charStrings) {
+			return false, errInvalidCFFTable
+		}
+		return cffBlankCharstring(cff.charStrings[gi]), nil
+	case font.HasTable(TagCFF2):
+		cff2, err := font.CFF2Table()
+		if err != nil {
+			return false, err
+		}
+		path, err := cff2.GlyphPath(gi, nil)
+		if err != nil {
+			return false, err
+		}
+		return len(path) == 0, nil
+	}
+	return false, nil
+}
+
+// cffBlankCharstring returns true if the Type 2 charstring
+// ends before any moveto operator. The subroutines and
+// the accented characters are assumed to draw something.
+func cffBlankCharstring(cs []byte) bool {
+	numArgs, numStems := 0, 0
+	for i := 0; i < len(cs); {
+		b := cs[i]
+		switch {
+		case b >= 32 && b <= 246:
+			numArgs, i = numArgs+1, i+1
+		case b >= 247 && b <= 254:
+			numArgs, i = numArgs+1, i+2
+		case b == 255:
+			numArgs, i = numArgs+1, i+5
+		case b == 28: // shortint
+			numArgs, i = numArgs+1, i+3
+		case b == 1, b == 3, b == 18, b == 23: // stem hints
+			numStems += numArgs / 2
+			numArgs, i = 0, i+1
+		case b == 19, b == 20: // hintmask and cntrmask, with implicit vstems
+			numStems += numArgs / 2
+			numArgs, i = 0, i+1+(numStems+7)/8
+		case b == 21, b == 22, b == 4, b == 10, b == 29: // moveto and subroutine calls
+			return false
+		case b == 14: // endchar, which may build an accented character
+			return numArgs < 4
+		case b == 12: // escape
+			numArgs, i = 0, i+2
+		default:
+			numArgs, i = 0, i+1
+		}
+	}
+	return true
+}
+
+// CheckNotdef verifies that the missing glyph (glyph 0, named .notdef)
+// is usable: it must have an outline (such as the recommended box) or at
+// least a non zero advance, so that the missing characters are noticed,
+// and no character should be explicitly mapped to it.
+// Only the TrueType and CFF outlines are checked.
+func (font *Font) CheckNotdef() ([]Issue, error) {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	if numGlyphs == 0 {
+		return []Issue{{Rune: -1, Message: "the font has no glyph"}}, nil
+	}
+
+	var issues []Issue
+	blank, err := font.glyphBlank(0)
+	if err != nil {
+		return nil, err
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case blank && widths[0] == 0:
+		issues = append(issues, Issue{Rune: -1, Message: ".notdef is blank and has no advance: the missing characters are invisible"})
+	case widths[0] == 0:
+		issues = append(issues, Issue{Rune: -1, Message: ".notdef has no advance: it overlaps the following glyph"})
+	}
+
+	if names, err := font.GlyphNames(); err == nil {
+		if name := names.GlyphName(0); name != "" && name != ".notdef" {
+			issues = append(issues, Issue{Rune: -1, Message: fmt.Sprintf("glyph 0 is named %q instead of .notdef", name)})
+		}
+	}
+
+	if font.HasTable(TagCmap) {
+		cmap, err := font.CmapTable()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range explicitNotdefRunes(cmap) {
+			issues = append(issues, Issue{Rune: r, Message: "the character is mapped to .notdef"})
+		}
+	}
+	return issues, nil
+}
+
+// explicitNotdefRunes returns the runes mapped to glyph 0 by the
+// segments of the cmap. The zero entries of the glyph arrays
+// (as in format 0 or 6) are not included, since they are the
+// usual way to mark unmapped characters.
+func explicitNotdefRunes(cmap Cmap) []rune {
+	var subtable cmapSubtable = cmap
+	if table, ok := cmap.(cmapTable); ok {
+		subtable = table.cmapSubtable
+	}
+	var out []rune
+	switch subtable := subtable.(type) {
+	case cmap4:
+		for _, entry := range subtable {
+			// 0xFFFF ends the subtables, and is usually mapped to 0
+			if entry.indexes == nil && entry.start != 0xFFFF {
+				if c := -entry.delta; entry.start <= c && c <= entry.end {
+					out = append(out, rune(c))
+				}
+			}
+		}
+	case cmap12:
+		for _, entry := range subtable {
+			if entry.delta == 0 {
+				out = append(out, rune(entry.start))
+			}
+		}
+	case cmap13:
+		for _, entry := range subtable {
+			for c := entry.start; entry.delta == 0 && c <= entry.end && c <= unicode10FFFF; c++ {
+				out = append(out, rune(c))
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// whitespace characters and their expected advance, as a fraction of
+// the em, 0 for any non zero advance, or -1 for the zero width characters
+var whitespaceAdvances = [...]struct {
+	r       rune
+	advance float64
+}{
+	{0x0020, 0},   // space
+	{0x00A0, 0},   // no-break space
+	{0x2000, 0.5}, // en quad
+	{0x2001, 1},   // em quad
+	{0x2002, 0.5}, // en space
+	{0x2003, 1},   // em space
+	{0x2004, 1. / 3},
+	{0x2005, 1. / 4},
+	{0x2006, 1. / 6},
+	{0x2007, 0},  // figure space
+	{0x2008, 0},  // punctuation space
+	{0x2009, 0},  // thin space
+	{0x200A, 0},  // hair space
+	{0x202F, 0},  // narrow no-break space
+	{0x205F, 0},  // medium mathematical space
+	{0x3000, 0},  // ideographic space
+	{0x200B, -1}, // zero width space
+	{0x2060, -1}, // word joiner
+	{0xFEFF, -1}, // zero width no-break space
+}
+
+// CheckWhitespace verifies that the whitespace characters mapped by the
+// font use blank glyphs, with a sane advance: non zero for the spaces
+// (the em based ones having the expected width, and the no-break space
+// the width of the space), and zero for the zero width characters.
+// The space must be mapped.
+// Only the TrueType and CFF outlines are checked.
+func (font *Font) CheckWhitespace() ([]Issue, error) {
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return nil, err
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		return nil, err
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	em := float64(head.UnitsPerEm)
+
+	var issues []Issue
+	spaceAdvance := -1
+	for _, ws := range whitespaceAdvances {
+		gi := cmap.Lookup(ws.r)
+		if gi == 0 {
+			if ws.r == ' ' {
+				issues = append(issues, Issue{Rune: ws.r, Message: "the space is not mapped"})
+			}
+			continue
+		}
+		if int(gi) >= len(widths) {
+			return nil, errInvalidHtmxTable
+		}
+
+		blank, err := font.glyphBlank(gi)
+		if err != nil {
+			return nil, err
+		}
+		if !blank {
+			issues = append(issues, Issue{Glyph: gi, Rune: ws.r, Message: "the whitespace glyph has an outline"})
+		}
+
+		advance := widths[gi]
+		switch {
+		case ws.advance == -1 && advance != 0:
+			issues = append(issues, Issue{Glyph: gi, Rune: ws.r, Message: fmt.Sprintf("the zero width character has an advance of %d", advance)})
+		case ws.advance != -1 && advance == 0:
+			issues = append(issues, Issue{Glyph: gi, Rune: ws.r, Message: "the whitespace glyph has no advance"})
+		case ws.advance > 0 && math.Abs(float64(advance)-ws.advance*em) > em/20:
+			issues = append(issues, Issue{Glyph: gi, Rune: ws.r, Message: fmt.Sprintf("the advance %d differs from the expected %.0f", advance, ws.advance*em)})
+		case ws.r == ' ':
+			spaceAdvance = advance
+		case ws.r == 0xA0 && spaceAdvance != -1 && advance != spaceAdvance:
+			issues = append(issues, Issue{Glyph: gi, Rune: ws.r, Message: fmt.Sprintf("the advance %d differs from the space advance %d", advance, spaceAdvance)})
+		}
+	}
+	return issues, nil
+}
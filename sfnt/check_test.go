@@ -0,0 +1,151 @@
+package sfnt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func loadTestFont(t *testing.T, filename string) *Font {
+	f, err := os.Open("testdata/" + filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+func TestCheckNotdef(t *testing.T) {
+	for _, file := range []string{"Roboto-BoldItalic.ttf", "Raleway-v4020-Regular.otf"} {
+		font := loadTestFont(t, file)
+		issues, err := font.CheckNotdef()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 0 {
+			t.Errorf("%s: unexpected issues %v", file, issues)
+		}
+	}
+
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	// remove the advance and the outline of .notdef
+	hmtx, err := font.Table(TagHmtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmtxBytes := append([]byte(nil), hmtx.Bytes()...)
+	hmtxBytes[0], hmtxBytes[1] = 0, 0
+	font.AddTable(TagHmtx, NewTable(TagHmtx, hmtxBytes))
+	issues, err := font.CheckNotdef()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Message != ".notdef has no advance: it overlaps the following glyph" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loca, err := font.Table(TagLoca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locaBytes := append([]byte(nil), loca.Bytes()...)
+	if head.IndexToLocFormat == 0 {
+		copy(locaBytes[2:4], locaBytes[0:2])
+	} else {
+		copy(locaBytes[4:8], locaBytes[0:4])
+	}
+	font.AddTable(TagLoca, NewTable(TagLoca, locaBytes))
+	issues, err = font.CheckNotdef()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Message != ".notdef is blank and has no advance: the missing characters are invisible" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestCFFBlankCharstring(t *testing.T) {
+	for _, test := range []struct {
+		charstring []byte
+		blank      bool
+	}{
+		{[]byte{14}, true},                                     // endchar
+		{[]byte{139 + 100, 14}, true},                          // width, endchar
+		{[]byte{139, 139 + 10, 1, 19, 0x80, 14}, true},         // hstem, hintmask, endchar
+		{[]byte{139, 139, 21, 139 + 10, 6, 14}, false},         // rmoveto, hlineto, endchar
+		{[]byte{139 + 50, 139 + 10, 1, 19, 21, 22, 14}, false}, // the mask byte is not an operator
+		{[]byte{139, 10, 14}, false},                           // callsubr
+	} {
+		if got := cffBlankCharstring(test.charstring); got != test.blank {
+			t.Errorf("%v: expected blank %v, got %v", test.charstring, test.blank, got)
+		}
+	}
+}
+
+func TestExplicitNotdefRunes(t *testing.T) {
+	cmap := cmapTable{cmapSubtable: cmap4{
+		{start: 0x20, end: 0x30, delta: 0x10000 - 0x25},
+		{start: 0x40, end: 0x41, indexes: []GlyphIndex{0, 3}},
+		{start: 0xFFFF, end: 0xFFFF, delta: 1},
+	}}
+	if got := explicitNotdefRunes(cmap); !reflect.DeepEqual(got, []rune{0x25}) {
+		t.Errorf("unexpected runes %v", got)
+	}
+
+	if got := explicitNotdefRunes(cmapTable{cmapSubtable: cmap12{{0x100, 0x110, 0}, {0x200, 0x210, 4}}}); !reflect.DeepEqual(got, []rune{0x100}) {
+		t.Errorf("unexpected runes %v", got)
+	}
+}
+
+func TestCheckWhitespace(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	issues, err := font.CheckWhitespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	// remove the advance of the space
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	space := cmap.Lookup(' ')
+	hmtx, err := font.Table(TagHmtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmtxBytes := append([]byte(nil), hmtx.Bytes()...)
+	hmtxBytes[4*space], hmtxBytes[4*space+1] = 0, 0
+	font.AddTable(TagHmtx, NewTable(TagHmtx, hmtxBytes))
+	issues, err = font.CheckWhitespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Rune != ' ' || issues[0].Message != "the whitespace glyph has no advance" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	font = loadTestFont(t, "Raleway-v4020-Regular.otf")
+	issues, err = font.CheckWhitespace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Issue{
+		{Glyph: 839, Rune: 0x2002, Message: "the advance 579 differs from the expected 500"},
+		{Glyph: 838, Rune: 0x2003, Message: "the advance 916 differs from the expected 1000"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected %v, got %v", expected, issues)
+	}
+}
@@ -0,0 +1,74 @@
+package sfnt
+
+import "fmt"
+
+// headChecksumAdjustmentOffset is the offset of the
+// checkSumAdjustment field in the 'head' table.
+const headChecksumAdjustmentOffset = 8
+
+// ChecksumMismatch is a checksum stored in a font file
+// which does not match the content of the font.
+type ChecksumMismatch struct {
+	// Tag is the table whose checksum is wrong, or TagHead
+	// with Adjustment set for the whole font checksum.
+	Tag Tag
+	// Adjustment is true for the checkSumAdjustment field of
+	// the 'head' table.
+	Adjustment bool
+	Expected   uint32 // as stored in the file
+	Got        uint32 // as computed from the content
+}
+
+func (cm ChecksumMismatch) String() string {
+	if cm.Adjustment {
+		return fmt.Sprintf("head checkSumAdjustment: expected 0x%08X, got 0x%08X", cm.Expected, cm.Got)
+	}
+	return fmt.Sprintf("table %q: expected checksum 0x%08X, got 0x%08X", cm.Tag, cm.Expected, cm.Got)
+}
+
+// VerifyChecksums checks the checksums stored in the table directory
+// against the content of the tables read from the file, and the
+// checkSumAdjustment of the 'head' table against the whole font.
+// It returns all the mismatches, so that the damaged tables may be
+// identified; the error is only used when the tables can't be read.
+// The tables added with AddTable and the tables of WOFF2 files, which
+// have no stored checksum, are ignored. The whole font checksum is
+// only verified for OpenType files (not for WOFF files or collections).
+func (font *Font) VerifyChecksums() ([]ChecksumMismatch, error) {
+	var (
+		out               []ChecksumMismatch
+		whole, adjustment uint32
+	)
+	if font.header != nil {
+		whole = font.header.checkSum()
+	}
+	verifyWhole := font.header != nil
+	for _, tag := range font.Tags() {
+		s := font.tables[tag]
+		if !s.hasChecksum {
+			verifyWhole = false
+			continue
+		}
+		buf, err := font.readTableBuffer(s)
+		if err != nil {
+			return nil, err
+		}
+		if tag == TagHead && len(buf) >= headChecksumAdjustmentOffset+4 {
+			// the checksum is computed with a zero checkSumAdjustment
+			adjustment = be.Uint32(buf[headChecksumAdjustmentOffset:])
+			buf = append([]byte(nil), buf...)
+			be.PutUint32(buf[headChecksumAdjustmentOffset:], 0)
+		}
+		sum := checkSum(buf)
+		if sum != s.checksum {
+			out = append(out, ChecksumMismatch{Tag: tag, Expected: s.checksum, Got: sum})
+		}
+		entry := directoryEntry{Tag: tag, CheckSum: s.checksum, Offset: s.offset, Length: s.length}
+		whole += sum + entry.checkSum()
+	}
+
+	if expected := 0xB1B0AFBA - whole; verifyWhole && adjustment != expected {
+		out = append(out, ChecksumMismatch{Tag: TagHead, Adjustment: true, Expected: adjustment, Got: expected})
+	}
+	return out, nil
+}
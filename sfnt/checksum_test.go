@@ -0,0 +1,64 @@
+package sfnt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	for _, file := range []string{
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Castoro-Regular.ttf",
+		"testdata/FreeSerif.ttf",
+		"testdata/Raleway-v4020-Regular.otf",
+		"testdata/open-sans-v15-latin-regular.woff",
+	} {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mismatches, err := font.VerifyChecksums()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mismatches) != 0 {
+			t.Errorf("%s: unexpected mismatches %v", file, mismatches)
+		}
+	}
+
+	buf, err := os.ReadFile("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf[font.tables[TagName].offset+10]++
+	font, err = Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := font.VerifyChecksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 2 || mismatches[0].Tag != TagName || !mismatches[1].Adjustment {
+		t.Errorf("unexpected mismatches %v", mismatches)
+	}
+
+	// added tables are not verified
+	font.AddTable(TagName, NewTableName())
+	mismatches, err = font.VerifyChecksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches %v", mismatches)
+	}
+}
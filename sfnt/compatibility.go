@@ -0,0 +1,193 @@
+package sfnt
+
+import "fmt"
+
+// fsSelection (see also fsSelectionItalic) and macStyle flags
+const (
+	fsSelectionBold = 1 << 5
+	macStyleBold    = 1 << 0
+	macStyleItalic  = 1 << 1
+)
+
+// CompatibilityReport lists the problems a font is expected to have
+// with the rasterizers and font managers of each platform.
+// The issues concern the whole font, and use -1 as Rune.
+type CompatibilityReport struct {
+	Windows, Mac, Linux []Issue
+}
+
+// CompatibilityReport predicts the platform specific problems of the font,
+// by combining the checks of the vertical metrics, of the hinting and 'gasp'
+// table, of the cmap subtables and of the name records:
+//   - Windows clips the glyphs outside of usWinAscent and usWinDescent,
+//     uses its own line spacing, and renders the unhinted TrueType
+//     outlines poorly at small sizes,
+//   - macOS uses the 'hhea' metrics and the macStyle flags, and the
+//     legacy applications require Macintosh name records,
+//   - Linux (FreeType and fontconfig) requires a Unicode cmap and a family name.
+func (font *Font) CompatibilityReport() (*CompatibilityReport, error) {
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	var out CompatibilityReport
+	issue := func(list *[]Issue, format string, args ...interface{}) {
+		*list = append(*list, Issue{Rune: -1, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := font.checkCompatibilityMetrics(head, &out, issue); err != nil {
+		return nil, err
+	}
+	if err := font.checkCompatibilityHinting(&out, issue); err != nil {
+		return nil, err
+	}
+
+	// cmap platforms
+	var hasWindows, hasUnicode bool
+	subtables, err := font.CmapSubtables()
+	if err != nil && err != ErrMissingTable {
+		return nil, err
+	}
+	for _, subtable := range subtables {
+		switch {
+		case subtable.Platform == PlatformMicrosoft && (subtable.Encoding == 1 || subtable.Encoding == 10):
+			hasWindows, hasUnicode = true, true
+		case subtable.Platform == PlatformMicrosoft && subtable.Encoding == 0: // symbol
+			hasWindows = true
+		case subtable.Platform == PlatformUnicode:
+			hasUnicode = true
+		}
+	}
+	if !hasWindows {
+		issue(&out.Windows, "no Windows cmap subtable (3, 1), (3, 10) or (3, 0): no character is mapped")
+	}
+	if !hasUnicode {
+		issue(&out.Mac, "no Unicode cmap subtable: the characters are not mapped")
+		issue(&out.Linux, "no Unicode cmap subtable: the characters are not mapped")
+	}
+
+	// name platforms
+	var names []*NameEntry
+	if font.HasTable(TagName) {
+		table, err := font.NameTable()
+		if err != nil {
+			return nil, err
+		}
+		names = table.List()
+	}
+	hasName := map[PlatformID]bool{}
+	for _, entry := range names {
+		if entry.NameID == NameFontFamily {
+			hasName[entry.PlatformID] = true
+		}
+	}
+	if !hasName[PlatformMicrosoft] {
+		issue(&out.Windows, "no Windows family name record: the font is not listed")
+	}
+	if !hasName[PlatformMac] {
+		issue(&out.Mac, "no Macintosh family name record: the legacy applications show no name")
+	}
+	if len(hasName) == 0 {
+		issue(&out.Linux, "no family name record: the font is not listed")
+	}
+	return &out, nil
+}
+
+// checkCompatibilityMetrics compares the vertical metrics used by each
+// platform, and the style flags.
+func (font *Font) checkCompatibilityMetrics(head *TableHead, out *CompatibilityReport, issue func(*[]Issue, string, ...interface{})) error {
+	if !font.HasTable(TagOS2) {
+		issue(&out.Windows, "no OS/2 table: the font is rejected")
+		return nil
+	}
+	os2, err := font.OS2Table()
+	if err != nil {
+		return err
+	}
+
+	if int(head.YMax) > int(os2.UsWinAscent) {
+		issue(&out.Windows, "the glyphs are clipped above usWinAscent (%d), lower than the maximum height %d", os2.UsWinAscent, head.YMax)
+	}
+	if -int(head.YMin) > int(os2.UsWinDescent) {
+		issue(&out.Windows, "the glyphs are clipped below usWinDescent (%d), lower than the maximum depth %d", os2.UsWinDescent, -int(head.YMin))
+	}
+
+	// the line spacings differing by more than 1% of the em
+	tolerance := int(head.UnitsPerEm) / 100
+	win, err := font.BaselineToBaseline(LineSpacingWin)
+	if err != nil {
+		return err
+	}
+	auto, err := font.BaselineToBaseline(LineSpacingAuto)
+	if err != nil {
+		return err
+	}
+	if d := win - auto; d > tolerance || d < -tolerance {
+		issue(&out.Windows, "the GDI line spacing (usWinAscent + usWinDescent = %d) differs from the other applications (%d)", win, auto)
+	}
+	if font.HasTable(TagHhea) {
+		hhea, err := font.BaselineToBaseline(LineSpacingHhea)
+		if err != nil {
+			return err
+		}
+		if d := hhea - auto; d > tolerance || d < -tolerance {
+			issue(&out.Mac, "the line spacing of the 'hhea' table (%d) differs from the typographic metrics used elsewhere (%d)", hhea, auto)
+		}
+	}
+
+	isBold, isItalic := os2.FsSelection&fsSelectionBold != 0, os2.FsSelection&fsSelectionItalic != 0
+	if isBold != (head.MacStyle&macStyleBold != 0) {
+		issue(&out.Mac, "the bold bit of macStyle is inconsistent with fsSelection: the style is misreported")
+	}
+	if isItalic != (head.MacStyle&macStyleItalic != 0) {
+		issue(&out.Mac, "the italic bit of macStyle is inconsistent with fsSelection: the style is misreported")
+	}
+	return nil
+}
+
+// checkCompatibilityHinting verifies that the TrueType outlines are
+// hinted, and that the 'gasp' table supports ClearType.
+func (font *Font) checkCompatibilityHinting(out *CompatibilityReport, issue func(*[]Issue, string, ...interface{})) error {
+	if !font.HasTable(TagGlyf) {
+		return nil
+	}
+	hinted := font.HasTable(TagFpgm) || font.HasTable(TagPrep)
+	if !hinted {
+		numGlyphs, err := font.numGlyphs()
+		if err != nil {
+			return err
+		}
+		for gi := GlyphIndex(0); gi < GlyphIndex(numGlyphs) && !hinted; gi++ {
+			instructions, err := font.GlyphInstructions(gi)
+			if err != nil {
+				return err
+			}
+			hinted = len(instructions) != 0
+		}
+	}
+
+	if !font.HasTable(TagGasp) {
+		if hinted {
+			issue(&out.Windows, "no gasp table: ClearType uses its default rendering, without symmetric smoothing")
+		} else {
+			issue(&out.Windows, "the TrueType outlines are not hinted, and there is no gasp table to request smoothing: the text is distorted at small sizes")
+		}
+		return nil
+	}
+	gasp, err := font.GaspTable()
+	if err != nil {
+		return err
+	}
+	if gasp.Version == 0 {
+		issue(&out.Windows, "the gasp table version 0 has no ClearType flags")
+	}
+	if !hinted {
+		for _, r := range gasp.Ranges {
+			if r.Behavior&(GaspGridFit|GaspSymmetricGridFit) != 0 {
+				issue(&out.Windows, "the gasp table requests grid fitting up to %d ppem, but the TrueType outlines are not hinted", r.MaxPPEM)
+				break
+			}
+		}
+	}
+	return nil
+}
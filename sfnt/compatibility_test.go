@@ -0,0 +1,53 @@
+package sfnt
+
+import (
+	"strings"
+	"testing"
+)
+
+// issuesContain returns true if one of the issues contains text.
+func issuesContain(issues []Issue, text string) bool {
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompatibilityReport(t *testing.T) {
+	report, err := loadTestFont(t, "Roboto-BoldItalic.ttf").CompatibilityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Windows) != 3 || !issuesContain(report.Windows, "clipped above usWinAscent (2146)") ||
+		!issuesContain(report.Windows, "GDI line spacing") || !issuesContain(report.Windows, "not hinted") {
+		t.Errorf("unexpected Windows issues %v", report.Windows)
+	}
+	if len(report.Mac) != 1 || !issuesContain(report.Mac, "no Macintosh family name") {
+		t.Errorf("unexpected Mac issues %v", report.Mac)
+	}
+	if len(report.Linux) != 0 {
+		t.Errorf("unexpected Linux issues %v", report.Linux)
+	}
+
+	font := loadTestFont(t, "Raleway-v4020-Regular.otf")
+	report, err = font.CompatibilityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Windows) != 1 || len(report.Mac) != 0 || len(report.Linux) != 0 {
+		t.Errorf("unexpected issues %v %v %v", report.Windows, report.Mac, report.Linux)
+	}
+
+	font.RemoveTable(TagCmap)
+	font.RemoveTable(TagName)
+	report, err = font.CompatibilityReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Windows) != 3 || len(report.Mac) != 2 || len(report.Linux) != 2 ||
+		!issuesContain(report.Linux, "no Unicode cmap") || !issuesContain(report.Linux, "no family name") {
+		t.Errorf("unexpected issues %v %v %v", report.Windows, report.Mac, report.Linux)
+	}
+}
@@ -1,6 +1,6 @@
 // Package sfnt provides support for sfnt based font formats.
 //
-// This includes OpenType, TrueType, WOFF, WOFF2, and EOT (though EOT is currently unimplemented).
+// This includes OpenType, TrueType, WOFF, WOFF2, and EOT (though EOT is only supported for writing).
 //
 // Usually you will want to parse a font, make modifications, and then output the modified
 // font. If you're really brave, you can build a new font from scratch.
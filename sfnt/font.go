@@ -22,7 +22,8 @@ func (u *unparsedTable) Bytes() []byte {
 // ErrMissingHead is returned by ParseOTF when the font has no head section.
 var ErrMissingHead = errors.New("missing head table in font")
 
-// ErrInvalidChecksum is returned by ParseOTF if the font's checksum is wrong
+// ErrInvalidChecksum is returned by ParseOTF if the font's checksum is wrong.
+// See also VerifyChecksums.
 var ErrInvalidChecksum = errors.New("invalid checksum")
 
 // ErrUnsupportedFormat is returned from Parse if parsing failed
@@ -31,6 +32,9 @@ var ErrUnsupportedFormat = errors.New("unsupported font format")
 // ErrMissingTable is returned from *Table if the table does not exist in the font.
 var ErrMissingTable = errors.New("missing table")
 
+// ErrGlyphNameNotFound is returned by GlyphIndexByName if no glyph has the given name.
+var ErrGlyphNameNotFound = errors.New("glyph name not found")
+
 // Font represents a SFNT font, which is the underlying representation found
 // in .otf and .ttf files (and .woff, .woff2, .eot files)
 // SFNT is a container format, which contains a number of tables identified by
@@ -42,6 +46,24 @@ type Font struct {
 
 	scalerType Tag
 	tables     map[Tag]*tableSection
+
+	// shared stores the tables parsed by the fonts of the
+	// collection the font belongs to, if any
+	shared *tableCache
+
+	// header is the header of OpenType files, used
+	// to verify the whole font checksum
+	header *otfHeader
+
+	// derived stores the values computed from several tables,
+	// reset when a table is added or removed
+	derived derivedValues
+}
+
+// derivedValues are lazily computed from the tables of a font.
+type derivedValues struct {
+	hAdvances    *hAdvances            // used by GlyphHAdvance
+	glyphIndexes map[string]GlyphIndex // used by GlyphIndexByName
 }
 
 // tableSection represents a table within the font file.
@@ -52,6 +74,9 @@ type tableSection struct {
 	offset  uint32 // Offset into the file this table starts.
 	length  uint32 // Length of this table within the file.
 	zLength uint32 // Uncompressed length of this table.
+
+	checksum    uint32 // Checksum stored in the table directory.
+	hasChecksum bool   // False for the added tables and WOFF2 files.
 }
 
 // Tags is the list of tags that are defined in this font, sorted by numeric value.
@@ -78,6 +103,7 @@ func (font *Font) HasTable(tag Tag) bool {
 // AddTable adds a table to the font. If a table with the
 // given tag is already present, it will be overwritten.
 func (font *Font) AddTable(tag Tag, table Table) {
+	font.derived = derivedValues{}
 	font.tables[tag] = &tableSection{
 		tag:   tag,
 		table: table,
@@ -87,6 +113,7 @@ func (font *Font) AddTable(tag Tag, table Table) {
 // RemoveTable removes a table from the font. If the table
 // doesn't exist, this method will do nothing.
 func (font *Font) RemoveTable(tag Tag) {
+	font.derived = derivedValues{}
 	delete(font.tables, tag)
 }
 
@@ -169,7 +196,7 @@ func (font *Font) GsubTable() (*TableLayout, error) {
 
 // CmapTable returns the Character to Glyph Index Mapping table.
 func (font *Font) CmapTable() (Cmap, error) {
-	s, found := font.tables[tagCmap]
+	s, found := font.tables[TagCmap]
 	if !found {
 		return nil, ErrMissingTable
 	}
@@ -182,9 +209,43 @@ func (font *Font) CmapTable() (Cmap, error) {
 	return parseTableCmap(buf)
 }
 
+// CmapTableForMacLanguage is the same as CmapTable, but the subtables
+// specific to the given Macintosh language (such as 0 for English) are
+// selected first. The subtables specific to other languages are
+// only used if there is no other choice.
+func (font *Font) CmapTableForMacLanguage(language PlatformLanguageID) (Cmap, error) {
+	s, found := font.tables[TagCmap]
+	if !found {
+		return nil, ErrMissingTable
+	}
+
+	buf, err := font.findTableBuffer(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTableCmapLanguage(buf, uint32(language)+1)
+}
+
+// CmapSubtables lists the subtables of the Character to Glyph Index Mapping table,
+// in the font order.
+func (font *Font) CmapSubtables() ([]CmapSubtable, error) {
+	s, found := font.tables[TagCmap]
+	if !found {
+		return nil, ErrMissingTable
+	}
+
+	buf, err := font.findTableBuffer(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCmapSubtables(buf)
+}
+
 // PostTable returns the Post table names
 func (font *Font) PostTable() (PostTable, error) {
-	s, found := font.tables[tagPost]
+	s, found := font.tables[TagPost]
 	if !found {
 		return PostTable{}, ErrMissingTable
 	}
@@ -202,6 +263,69 @@ func (font *Font) PostTable() (PostTable, error) {
 	return parseTablePost(buf, numGlyph)
 }
 
+// GlyphNames returns the glyph names stored in the 'post' table,
+// or, if the 'post' table has no names (version 3), in the charset of
+// the 'CFF ' table.
+func (font *Font) GlyphNames() (GlyphNames, error) {
+	if font.HasTable(TagPost) {
+		post, err := font.PostTable()
+		if err != nil {
+			return nil, err
+		}
+		if post.Names != nil {
+			return post.Names, nil
+		}
+	}
+	if font.HasTable(TagCFF) {
+		return font.CFFTable()
+	}
+	return nil, ErrMissingTable
+}
+
+// GlyphIndexByName returns the glyph with the given name, using
+// the names returned by GlyphNames.
+// If several glyphs have the same name, the first one is returned.
+// ErrGlyphNameNotFound is returned if no glyph has this name.
+// The names are indexed on the first call, so that
+// many names may be looked up efficiently.
+func (font *Font) GlyphIndexByName(name string) (GlyphIndex, error) {
+	indexes, err := font.loadGlyphIndexes()
+	if err != nil {
+		return 0, err
+	}
+	gi, ok := indexes[name]
+	if !ok {
+		return 0, ErrGlyphNameNotFound
+	}
+	return gi, nil
+}
+
+// loadGlyphIndexes returns the map from glyph names to glyph
+// indexes, built once until the font is modified.
+func (font *Font) loadGlyphIndexes() (map[string]GlyphIndex, error) {
+	if font.derived.glyphIndexes != nil {
+		return font.derived.glyphIndexes, nil
+	}
+	names, err := font.GlyphNames()
+	if err != nil {
+		return nil, err
+	}
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[string]GlyphIndex, numGlyphs)
+	for gi := GlyphIndex(0); gi < GlyphIndex(numGlyphs); gi++ {
+		name := names.GlyphName(gi)
+		if _, seen := indexes[name]; name == "" || seen {
+			continue // keep the first glyph
+		}
+		indexes[name] = gi
+	}
+	font.derived.glyphIndexes = indexes
+	return indexes, nil
+}
+
 func (font *Font) numGlyphs() (uint16, error) {
 	maxpSection, found := font.tables[TagMaxp]
 	if !found {
@@ -244,6 +368,8 @@ func (font *Font) HtmxTable() ([]int, error) {
 // KernTable returns the kern table, with kerning value expressed in
 // glyph units.
 // Unless `kernFirst` is true, the priority is given to the GPOS table, then to the kern table.
+// Only the horizontal kerning is returned: see KernTableDirection for
+// the other subtables of the kern table.
 func (font *Font) KernTable(kernFirst bool) (kerns Kerns, err error) {
 	if kernFirst {
 		kerns, err = font.kernKerning()
@@ -259,6 +385,31 @@ func (font *Font) KernTable(kernFirst bool) (kerns Kerns, err error) {
 	return
 }
 
+// KernTableForScript is the same as KernTable, but the GPOS kerning is
+// restricted to the lookups of the 'kern' feature enabled for the given script
+// and language (such as 'cyrl' and 'SRB '), so that fonts with script specific
+// kerning return the values matching the text.
+// If the language is not found (or is the zero Tag), the default language of
+// the script is used. If the script is not found, the default script (DFLT) is used.
+// See GposFeatureKerning to select another feature.
+func (font *Font) KernTableForScript(script, language Tag, kernFirst bool) (kerns Kerns, err error) {
+	gposKerning := func() (Kerns, error) {
+		return font.GposFeatureKerning(script, language, featureKern)
+	}
+	if kernFirst {
+		kerns, err = font.kernKerning()
+		if err != nil {
+			kerns, err = gposKerning()
+		}
+	} else {
+		kerns, err = gposKerning()
+		if err != nil {
+			kerns, err = font.kernKerning()
+		}
+	}
+	return
+}
+
 func (font *Font) gposKerning() (Kerns, error) {
 	gpos, err := font.GposTable()
 	if err != nil {
@@ -269,7 +420,18 @@ func (font *Font) gposKerning() (Kerns, error) {
 }
 
 func (font *Font) kernKerning() (Kerns, error) {
-	section, found := font.tables[tagKern]
+	return font.KernTableDirection(HorizontalKern)
+}
+
+// KernTableDirection returns the kerning values of the 'kern' table for
+// the given direction: the horizontal and vertical kerning adjust the
+// advances along the line, while the cross-stream values shift the glyphs
+// perpendicularly to the line. Each direction is stored in its own subtables,
+// and an error is returned if none matches.
+// The 'GPOS' table, where vertical kerning uses the 'vkrn' feature,
+// is not used.
+func (font *Font) KernTableDirection(direction KernDirection) (Kerns, error) {
+	section, found := font.tables[TagKern]
 	if !found {
 		return nil, ErrMissingTable
 	}
@@ -279,7 +441,7 @@ func (font *Font) kernKerning() (Kerns, error) {
 		return nil, err
 	}
 
-	return parseKernTable(buf)
+	return parseKernTableDirection(buf, direction)
 }
 
 func (font *Font) Table(tag Tag) (Table, error) {
@@ -289,7 +451,15 @@ func (font *Font) Table(tag Tag) (Table, error) {
 	}
 
 	if s.table == nil {
-		t, err := font.parseTable(s)
+		var (
+			t   Table
+			err error
+		)
+		if font.shared != nil {
+			t, err = font.shared.parseTable(font, s)
+		} else {
+			t, err = font.parseTable(s)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -318,6 +488,8 @@ type File interface {
 }
 
 // Parse parses an OpenType, TrueType, WOFF, or WOFF2 file and returns a Font.
+// For TrueType Collections, the first font is returned (see ParseCollection
+// to access the other ones).
 // If parsing fails, an error is returned and *Font will be nil.
 func Parse(file File) (*Font, error) {
 	magic, err := ReadTag(file)
@@ -334,6 +506,8 @@ func Parse(file File) (*Font, error) {
 		return parseWOFF2(file)
 	case TypeTrueType, TypeOpenType, TypePostScript1, TypeAppleTrueType:
 		return parseOTF(file)
+	case SignatureTTC:
+		return parseTTC(file)
 	default:
 		return nil, ErrUnsupportedFormat
 	}
@@ -0,0 +1,104 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Format is the file format of a font, as returned by DetectFormat.
+type Format uint8
+
+const (
+	// FormatUnknown is returned for the files which are not fonts.
+	FormatUnknown Format = iota
+	// FormatTrueType is a sfnt font with TrueType outlines (.ttf).
+	FormatTrueType
+	// FormatOpenType is a sfnt font with PostScript outlines (.otf).
+	FormatOpenType
+	// FormatCollection is a TrueType Collection (.ttc, .otc).
+	FormatCollection
+	// FormatWOFF is a Web Open Font Format 1.0 file (.woff).
+	FormatWOFF
+	// FormatWOFF2 is a Web Open Font Format 2.0 file (.woff2).
+	FormatWOFF2
+	// FormatEOT is an Embedded OpenType file (.eot).
+	FormatEOT
+	// FormatType1 is a PostScript Type 1 font, in
+	// binary (.pfb) or ASCII (.pfa) form.
+	FormatType1
+	// FormatCFF is a bare Compact Font Format font, as
+	// found in PDF files.
+	FormatCFF
+)
+
+// String returns the usual file extension of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatTrueType:
+		return "ttf"
+	case FormatOpenType:
+		return "otf"
+	case FormatCollection:
+		return "ttc"
+	case FormatWOFF:
+		return "woff"
+	case FormatWOFF2:
+		return "woff2"
+	case FormatEOT:
+		return "eot"
+	case FormatType1:
+		return "pfb"
+	case FormatCFF:
+		return "cff"
+	}
+	return "unknown"
+}
+
+// the EOT header stores a magic number after
+// variable-length fields
+const (
+	eotMagicOffset = 34
+	eotMagicNumber = 0x504C
+)
+
+// DetectFormat returns the format of the font file, reading only its first
+// bytes, so that the file may be routed without being parsed.
+// ErrUnsupportedFormat is returned for unknown formats. Note that only
+// Parse and ParseCollection support all the sfnt based formats.
+func DetectFormat(file io.ReaderAt) (Format, error) {
+	var header [eotMagicOffset + 2]byte
+	n, err := file.ReadAt(header[:], 0)
+	if err != nil && err != io.EOF {
+		return FormatUnknown, err
+	}
+	buf := header[:n]
+
+	if len(buf) >= 4 {
+		switch NewTag(buf) {
+		case TypeTrueType, TypeAppleTrueType:
+			return FormatTrueType, nil
+		case TypeOpenType, TypePostScript1:
+			return FormatOpenType, nil
+		case SignatureTTC:
+			return FormatCollection, nil
+		case SignatureWOFF:
+			return FormatWOFF, nil
+		case SignatureWOFF2:
+			return FormatWOFF2, nil
+		}
+	}
+
+	switch {
+	case len(buf) >= 2 && buf[0] == 0x80 && buf[1] == 0x01: // PFB ASCII segment
+		return FormatType1, nil
+	case bytes.HasPrefix(buf, []byte("%!PS-AdobeFont")), bytes.HasPrefix(buf, []byte("%!FontType1")):
+		return FormatType1, nil
+	case len(buf) >= 4 && buf[0] == 1 && buf[1] == 0 && buf[2] >= 4 && 1 <= buf[3] && buf[3] <= 4:
+		// CFF header: major and minor version, header size and offset size
+		return FormatCFF, nil
+	case len(buf) == len(header) && binary.LittleEndian.Uint16(buf[eotMagicOffset:]) == eotMagicNumber:
+		return FormatEOT, nil
+	}
+	return FormatUnknown, ErrUnsupportedFormat
+}
@@ -0,0 +1,56 @@
+package sfnt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for file, expected := range map[string]Format{
+		"testdata/Roboto-BoldItalic.ttf":            FormatTrueType,
+		"testdata/Raleway-v4020-Regular.otf":        FormatOpenType,
+		"testdata/open-sans-v15-latin-regular.woff": FormatWOFF,
+	} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format, err := DetectFormat(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != expected {
+			t.Errorf("%s: expected %s, got %s", file, expected, format)
+		}
+	}
+
+	eot := make([]byte, 80)
+	eot[eotMagicOffset], eot[eotMagicOffset+1] = 0x4C, 0x50
+	for _, test := range []struct {
+		content  []byte
+		expected Format
+	}{
+		{[]byte("wOF2\x00\x01\x00\x00"), FormatWOFF2},
+		{[]byte("ttcf\x00\x01\x00\x00"), FormatCollection},
+		{[]byte{0x80, 0x01, 0x10, 0, 0, 0, '%', '!'}, FormatType1},
+		{[]byte("%!PS-AdobeFont-1.0: Times-Roman"), FormatType1},
+		{[]byte{1, 0, 4, 2, 0, 1}, FormatCFF},
+		{eot, FormatEOT},
+	} {
+		format, err := DetectFormat(bytes.NewReader(test.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, format)
+		}
+	}
+
+	for _, content := range [][]byte{nil, []byte("<html>"), make([]byte, 80)} {
+		if _, err := DetectFormat(bytes.NewReader(content)); err != ErrUnsupportedFormat {
+			t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package sfnt
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// number of glyphs stored in the bitset fast path of a GlyphSet
+const glyphSetBitsetSize = 256
+
+// GlyphSet is a set of glyph indices, optimized for the
+// typical content of coverage tables: small glyph indices are stored in
+// a bitset, and the others as sorted, non overlapping ranges.
+// The zero value is an empty set, ready to use.
+type GlyphSet struct {
+	low    [glyphSetBitsetSize / 64]uint64 // glyphs < glyphSetBitsetSize
+	ranges []GlyphRange                    // sorted and merged, glyphs >= glyphSetBitsetSize
+}
+
+// GlyphRange is an inclusive range of glyphs, from Start to End.
+// It is empty if End < Start.
+type GlyphRange struct {
+	Start, End GlyphIndex
+}
+
+// Len returns the number of glyphs in the range.
+func (r GlyphRange) Len() int {
+	if r.End < r.Start {
+		return 0
+	}
+	return int(r.End-r.Start) + 1
+}
+
+// Contains returns true if the glyph is in the range.
+func (r GlyphRange) Contains(gi GlyphIndex) bool {
+	return r.Start <= gi && gi <= r.End
+}
+
+// Glyphs returns the glyphs of the range, in ascending order.
+func (r GlyphRange) Glyphs() []GlyphIndex {
+	out := make([]GlyphIndex, 0, r.Len())
+	for i := 0; i < r.Len(); i++ {
+		out = append(out, r.Start+GlyphIndex(i))
+	}
+	return out
+}
+
+// NewGlyphSet returns a set containing the given glyphs.
+func NewGlyphSet(glyphs ...GlyphIndex) *GlyphSet {
+	var out GlyphSet
+	for _, gi := range glyphs {
+		out.Add(gi)
+	}
+	return &out
+}
+
+// Add adds a glyph to the set.
+func (gs *GlyphSet) Add(gi GlyphIndex) {
+	gs.AddRange(gi, gi)
+}
+
+// AddRange adds all the glyphs from start to end (included) to the set.
+// It does nothing if end < start.
+func (gs *GlyphSet) AddRange(start, end GlyphIndex) {
+	if end < start {
+		return
+	}
+	for ; start < glyphSetBitsetSize && start <= end; start++ {
+		gs.low[start/64] |= 1 << (start % 64)
+	}
+	if start > end { // fully handled by the bitset
+		return
+	}
+
+	// index of the first range which may be merged with [start, end]
+	i := sort.Search(len(gs.ranges), func(i int) bool { return int(gs.ranges[i].End)+1 >= int(start) })
+	// index after the last range which may be merged
+	j := i
+	for j < len(gs.ranges) && int(gs.ranges[j].Start) <= int(end)+1 {
+		j++
+	}
+
+	merged := GlyphRange{start, end}
+	if i < j {
+		if gs.ranges[i].Start < merged.Start {
+			merged.Start = gs.ranges[i].Start
+		}
+		if gs.ranges[j-1].End > merged.End {
+			merged.End = gs.ranges[j-1].End
+		}
+	}
+
+	// replace gs.ranges[i:j] by merged
+	gs.ranges = append(gs.ranges[:i], append([]GlyphRange{merged}, gs.ranges[j:]...)...)
+}
+
+// Contains returns true if the glyph is in the set.
+func (gs *GlyphSet) Contains(gi GlyphIndex) bool {
+	if gi < glyphSetBitsetSize {
+		return gs.low[gi/64]&(1<<(gi%64)) != 0
+	}
+	i := sort.Search(len(gs.ranges), func(i int) bool { return gs.ranges[i].End >= gi })
+	return i < len(gs.ranges) && gs.ranges[i].Start <= gi
+}
+
+// Index returns the position of the glyph in the set, that is the
+// number of glyphs of the set smaller than gi, and true if gi is in the set.
+// As for coverage tables, it may be used to map the glyphs of the
+// set to consecutive indices.
+func (gs *GlyphSet) Index(gi GlyphIndex) (int, bool) {
+	index := 0
+	if gi < glyphSetBitsetSize {
+		word, bit := gi/64, uint64(1)<<(gi%64)
+		for _, w := range gs.low[:word] {
+			index += bits.OnesCount64(w)
+		}
+		index += bits.OnesCount64(gs.low[word] & (bit - 1))
+		return index, gs.low[word]&bit != 0
+	}
+	for _, w := range gs.low {
+		index += bits.OnesCount64(w)
+	}
+	for _, r := range gs.ranges {
+		if gi < r.Start {
+			break
+		}
+		if gi <= r.End {
+			return index + int(gi-r.Start), true
+		}
+		index += r.Len()
+	}
+	return index, false
+}
+
+// Len returns the number of glyphs in the set.
+func (gs *GlyphSet) Len() int {
+	out := 0
+	for _, word := range gs.low {
+		out += bits.OnesCount64(word)
+	}
+	for _, r := range gs.ranges {
+		out += r.Len()
+	}
+	return out
+}
+
+// Union adds all the glyphs of other to the set.
+func (gs *GlyphSet) Union(other *GlyphSet) {
+	for i, word := range other.low {
+		gs.low[i] |= word
+	}
+	for _, r := range other.ranges {
+		gs.AddRange(r.Start, r.End)
+	}
+}
+
+// Intersect removes from the set the glyphs which are not in other.
+func (gs *GlyphSet) Intersect(other *GlyphSet) {
+	for i, word := range other.low {
+		gs.low[i] &= word
+	}
+	var ranges []GlyphRange
+	for i, j := 0, 0; i < len(gs.ranges) && j < len(other.ranges); {
+		a, b := gs.ranges[i], other.ranges[j]
+		r := a
+		if b.Start > r.Start {
+			r.Start = b.Start
+		}
+		if b.End < r.End {
+			r.End = b.End
+		}
+		if r.Start <= r.End {
+			ranges = append(ranges, r)
+		}
+		// advance the range ending first
+		if a.End < b.End {
+			i++
+		} else {
+			j++
+		}
+	}
+	gs.ranges = ranges
+}
+
+// ForEach calls fn for each glyph of the set, in ascending order.
+func (gs *GlyphSet) ForEach(fn func(gi GlyphIndex)) {
+	for i, word := range gs.low {
+		for word != 0 {
+			b := bits.TrailingZeros64(word)
+			fn(GlyphIndex(i*64 + b))
+			word &= word - 1
+		}
+	}
+	for _, r := range gs.ranges {
+		for gi := r.Start; ; gi++ {
+			fn(gi)
+			if gi == r.End { // avoid overflow on 0xFFFF
+				break
+			}
+		}
+	}
+}
+
+// Glyphs returns the content of the set, sorted in ascending order.
+func (gs *GlyphSet) Glyphs() []GlyphIndex {
+	out := make([]GlyphIndex, 0, gs.Len())
+	gs.ForEach(func(gi GlyphIndex) { out = append(out, gi) })
+	return out
+}
+
+// Ranges returns the content of the set as sorted,
+// non overlapping and non adjacent ranges.
+func (gs *GlyphSet) Ranges() []GlyphRange {
+	var out []GlyphRange
+	for gi := GlyphIndex(0); gi < glyphSetBitsetSize; gi++ {
+		if !gs.Contains(gi) {
+			continue
+		}
+		if n := len(out); n != 0 && out[n-1].End+1 == gi {
+			out[n-1].End = gi
+		} else {
+			out = append(out, GlyphRange{gi, gi})
+		}
+	}
+	for _, r := range gs.ranges {
+		if n := len(out); n != 0 && out[n-1].End+1 == r.Start {
+			out[n-1].End = r.End
+		} else {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlyphSet(t *testing.T) {
+	var gs GlyphSet
+	gs.Add(3)
+	gs.AddRange(250, 260)
+	gs.AddRange(300, 310)
+	gs.AddRange(312, 320)
+	gs.Add(311) // merges the two ranges
+	gs.AddRange(0xFFF0, 0xFFFF)
+	gs.AddRange(20, 10) // no-op
+
+	if len(gs.ranges) != 3 {
+		t.Errorf("expected 3 ranges, got %v", gs.ranges)
+	}
+	if exp := 1 + 11 + 21 + 16; gs.Len() != exp {
+		t.Errorf("expected %d glyphs, got %d", exp, gs.Len())
+	}
+	for _, gi := range []GlyphIndex{3, 250, 255, 256, 260, 300, 311, 320, 0xFFFF} {
+		if !gs.Contains(gi) {
+			t.Errorf("expected %d in set", gi)
+		}
+	}
+	for _, gi := range []GlyphIndex{0, 4, 249, 261, 299, 321, 0xFFEF} {
+		if gs.Contains(gi) {
+			t.Errorf("unexpected %d in set", gi)
+		}
+	}
+
+	glyphs := gs.Glyphs()
+	if len(glyphs) != gs.Len() {
+		t.Errorf("inconsistent Glyphs and Len")
+	}
+	if glyphs[0] != 3 || glyphs[len(glyphs)-1] != 0xFFFF {
+		t.Errorf("unexpected glyphs %v", glyphs)
+	}
+
+	other := NewGlyphSet(glyphs...)
+	if !reflect.DeepEqual(other.Glyphs(), glyphs) {
+		t.Errorf("expected %v, got %v", glyphs, other.Glyphs())
+	}
+
+	for i, gi := range glyphs {
+		if index, ok := gs.Index(gi); !ok || index != i {
+			t.Errorf("expected index %d for glyph %d, got %d %v", i, gi, index, ok)
+		}
+	}
+	for gi, expected := range map[GlyphIndex]int{0: 0, 4: 1, 261: 12, 321: 33, 0xFFEF: 33} {
+		if index, ok := gs.Index(gi); ok || index != expected {
+			t.Errorf("expected index %d for missing glyph %d, got %d %v", expected, gi, index, ok)
+		}
+	}
+
+	u := NewGlyphSet(1, 1000)
+	u.Union(&gs)
+	if u.Len() != gs.Len()+2 {
+		t.Errorf("expected %d glyphs, got %d", gs.Len()+2, u.Len())
+	}
+}
+
+func TestGlyphSetOperations(t *testing.T) {
+	var a, b GlyphSet
+	a.AddRange(10, 300)
+	a.AddRange(400, 500)
+	b.AddRange(0, 20)
+	b.AddRange(290, 450)
+	b.Add(0xFFFF)
+
+	expected := []GlyphRange{{0, 500}, {0xFFFF, 0xFFFF}}
+	u := NewGlyphSet()
+	u.Union(&a)
+	u.Union(&b)
+	if got := u.Ranges(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected union %v, got %v", expected, got)
+	}
+
+	a.Intersect(&b)
+	expected = []GlyphRange{{10, 20}, {290, 300}, {400, 450}}
+	if got := a.Ranges(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected intersection %v, got %v", expected, got)
+	}
+	if a.Len() != 11+11+51 {
+		t.Errorf("unexpected length %d", a.Len())
+	}
+
+	var glyphs []GlyphIndex
+	for _, r := range expected {
+		glyphs = append(glyphs, r.Glyphs()...)
+	}
+	var iterated []GlyphIndex
+	a.ForEach(func(gi GlyphIndex) { iterated = append(iterated, gi) })
+	if !reflect.DeepEqual(iterated, glyphs) {
+		t.Errorf("expected %v, got %v", glyphs, iterated)
+	}
+
+	if r := (GlyphRange{5, 3}); r.Len() != 0 || len(r.Glyphs()) != 0 || r.Contains(4) {
+		t.Errorf("expected empty range")
+	}
+}
+
+func TestCoverageGlyphSet(t *testing.T) {
+	cl := coverageList{2, 5, 300}
+	if got := cl.glyphSet().Glyphs(); !reflect.DeepEqual(got, []GlyphIndex(cl)) {
+		t.Errorf("expected %v, got %v", cl, got)
+	}
+	cr := coverageRanges{{start: 130, end: 135}, {start: 137, end: 137, startCoverage: 6}}
+	gs := cr.glyphSet()
+	for _, gi := range []GlyphIndex{130, 133, 135, 137} {
+		if _, ok := cr.tableIndex(gi); ok != gs.Contains(gi) {
+			t.Errorf("inconsistent coverage for %d", gi)
+		}
+	}
+	if gs.Len() != 7 {
+		t.Errorf("expected 7 glyphs, got %d", gs.Len())
+	}
+}
@@ -0,0 +1,138 @@
+package sfnt
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode"
+)
+
+// GlyphStats are statistics about a TrueType glyph.
+type GlyphStats struct {
+	Glyph GlyphIndex
+	// Contours and Points are the number of contours and points
+	// of the outline, the components of composite glyphs being resolved.
+	Contours, Points int
+	// Components is the number of components of a composite glyph,
+	// or 0 for a simple glyph.
+	Components int
+	// InstructionsLength is the size of the glyph program, in bytes.
+	InstructionsLength int
+	// XMin, YMin, XMax and YMax is the bounding box stored
+	// in the glyph header, which is zero for empty glyphs.
+	XMin, YMin, XMax, YMax int16
+}
+
+// GlyphReport gathers the statistics of each glyph, for
+// quality assurance tools.
+type GlyphReport struct {
+	Glyphs []GlyphStats // indexed by glyph
+	// Issues are the anomalies found in the glyphs: empty outlines
+	// mapped from a visible character, bounding boxes inconsistent with
+	// the points or exceeding the bounding box of the 'head' table.
+	Issues []Issue
+}
+
+// GlyphReport returns the statistics of the TrueType glyphs.
+// ErrMissingTable is returned for fonts without 'glyf' table.
+func (font *Font) GlyphReport() (*GlyphReport, error) {
+	if !font.HasTable(TagGlyf) {
+		return nil, ErrMissingTable
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &GlyphReport{Glyphs: make([]GlyphStats, numGlyphs)}
+	for gi := GlyphIndex(0); gi < GlyphIndex(numGlyphs); gi++ {
+		stats, issues, err := font.glyphStats(gi, head)
+		if err != nil {
+			return nil, err
+		}
+		out.Glyphs[gi] = stats
+		out.Issues = append(out.Issues, issues...)
+	}
+
+	if font.HasTable(TagCmap) {
+		cmap, err := font.CmapTable()
+		if err != nil {
+			return nil, err
+		}
+		var runes []rune
+		for r, gi := range cmap.Compile() {
+			if gi != 0 && int(gi) < len(out.Glyphs) && out.Glyphs[gi].Contours == 0 && !invisibleRune(r) {
+				runes = append(runes, r)
+			}
+		}
+		sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+		for _, r := range runes {
+			out.Issues = append(out.Issues, Issue{Glyph: cmap.Lookup(r), Rune: r, Message: "the visible character is mapped to an empty glyph"})
+		}
+	}
+	return out, nil
+}
+
+// invisibleRune returns true for the characters which are
+// expected to have an empty glyph, such as the spaces.
+func invisibleRune(r rune) bool {
+	return unicode.In(r, unicode.Zs, unicode.Zl, unicode.Zp, unicode.Cc, unicode.Cf, unicode.Variation_Selector, unicode.Other_Default_Ignorable_Code_Point)
+}
+
+func (font *Font) glyphStats(gi GlyphIndex, head *TableHead) (GlyphStats, []Issue, error) {
+	stats := GlyphStats{Glyph: gi}
+	buf, err := font.glyphBuffer(gi)
+	if err != nil || len(buf) == 0 {
+		return stats, nil, err
+	}
+	data, err := parseGlyphData(buf)
+	if err != nil {
+		return stats, nil, err
+	}
+	outline, err := font.GlyphOutline(gi)
+	if err != nil {
+		return stats, nil, err
+	}
+	instructions, err := font.GlyphInstructions(gi)
+	if err != nil {
+		return stats, nil, err
+	}
+	stats.Contours, stats.Points = len(outline.EndPoints), len(outline.Points)
+	stats.Components = len(data.components)
+	stats.InstructionsLength = len(instructions)
+	stats.XMin, stats.YMin = int16(be.Uint16(buf[2:])), int16(be.Uint16(buf[4:]))
+	stats.XMax, stats.YMax = int16(be.Uint16(buf[6:])), int16(be.Uint16(buf[8:]))
+
+	var issues []Issue
+	switch {
+	case stats.XMin > stats.XMax || stats.YMin > stats.YMax:
+		issues = append(issues, Issue{Glyph: gi, Rune: -1, Message: "the bounding box is inverted"})
+	case stats.Components == 0 && len(outline.Points) != 0:
+		xMin, yMin, xMax, yMax := pointsBounds(outline.Points)
+		if xMin != stats.XMin || yMin != stats.YMin || xMax != stats.XMax || yMax != stats.YMax {
+			issues = append(issues, Issue{Glyph: gi, Rune: -1, Message: fmt.Sprintf(
+				"the bounding box [%d %d %d %d] differs from the bounds of the points [%d %d %d %d]",
+				stats.XMin, stats.YMin, stats.XMax, stats.YMax, xMin, yMin, xMax, yMax)})
+		}
+	}
+	if stats.XMin < head.XMin || stats.YMin < head.YMin || stats.XMax > head.XMax || stats.YMax > head.YMax {
+		issues = append(issues, Issue{Glyph: gi, Rune: -1, Message: "the bounding box exceeds the font bounding box"})
+	}
+	return stats, issues, nil
+}
+
+// pointsBounds returns the bounding box of the points, which is
+// expected to be non empty.
+func pointsBounds(points []GlyphPoint) (xMin, yMin, xMax, yMax int16) {
+	minX, minY := float64(points[0].X), float64(points[0].Y)
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		minX, maxX = math.Min(minX, float64(p.X)), math.Max(maxX, float64(p.X))
+		minY, maxY = math.Min(minY, float64(p.Y)), math.Max(maxY, float64(p.Y))
+	}
+	return int16(math.Floor(minX)), int16(math.Floor(minY)), int16(math.Ceil(maxX)), int16(math.Ceil(maxY))
+}
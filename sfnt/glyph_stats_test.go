@@ -0,0 +1,53 @@
+package sfnt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlyphReport(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	report, err := font.GlyphReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Glyphs) != int(numGlyphs) {
+		t.Fatalf("expected %d glyphs, got %d", numGlyphs, len(report.Glyphs))
+	}
+
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, expected := range map[rune]GlyphStats{
+		'A': {Contours: 2, Points: 11, XMin: -103, XMax: 1223, YMax: 1456},
+		' ': {},
+		'Å': {Contours: 4, Points: 35, Components: 2, XMin: -103, XMax: 1223, YMax: 1936},
+	} {
+		expected.Glyph = cmap.Lookup(r)
+		if got := report.Glyphs[expected.Glyph]; got != expected {
+			t.Errorf("%q: expected %+v, got %+v", r, expected, got)
+		}
+	}
+
+	for _, issue := range report.Issues {
+		if !strings.Contains(issue.Message, "exceeds the font bounding box") {
+			t.Errorf("unexpected issue %s", issue)
+		}
+	}
+
+	if _, err := loadTestFont(t, "Raleway-v4020-Regular.otf").GlyphReport(); err != ErrMissingTable {
+		t.Errorf("expected ErrMissingTable, got %v", err)
+	}
+}
+
+func TestPointsBounds(t *testing.T) {
+	points := []GlyphPoint{{X: 10, Y: -5}, {X: -2.5, Y: 7.5}, {X: 3, Y: 0}}
+	if xMin, yMin, xMax, yMax := pointsBounds(points); xMin != -3 || yMin != -5 || xMax != 10 || yMax != 8 {
+		t.Errorf("unexpected bounds %d %d %d %d", xMin, yMin, xMax, yMax)
+	}
+}
@@ -0,0 +1,41 @@
+package sfnt
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TableEvent reports the loading of a table, and may be used to find
+// which tables dominate the load time of a set of fonts.
+type TableEvent struct {
+	Tag Tag
+	// Size is the size of the (uncompressed) table, in bytes.
+	Size int
+	// Read is the time spent reading (and decompressing) the table.
+	Read time.Duration
+	// Parse is the time spent parsing the table. It is zero for the
+	// tables decoded on each access (such as 'cmap' or 'hmtx'), for
+	// which only the reading is measured.
+	Parse time.Duration
+}
+
+// tableHook wraps the hook, since atomic.Value can't store nil.
+type tableHook struct {
+	fn func(TableEvent)
+}
+
+var tableEventHook atomic.Value // tableHook
+
+// SetTableHook registers a function called each time a table is loaded
+// from a font file, by any font. Added tables (see Font.AddTable) are not
+// reported. Calling SetTableHook(nil) disables the instrumentation.
+// The function may be called concurrently, and should return quickly.
+func SetTableHook(fn func(TableEvent)) {
+	tableEventHook.Store(tableHook{fn})
+}
+
+// loadTableHook returns the registered hook, or nil.
+func loadTableHook() func(TableEvent) {
+	hook, _ := tableEventHook.Load().(tableHook)
+	return hook.fn
+}
@@ -0,0 +1,54 @@
+package sfnt
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTableHook(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []TableEvent
+	)
+	SetTableHook(func(e TableEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	defer SetTableHook(nil)
+
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	events = nil // ignore the tables used by Parse
+	if _, err := font.OS2Table(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := font.OS2Table(); err != nil { // cached
+		t.Fatal(err)
+	}
+	if _, err := font.CmapTable(); err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagName, NewTableName())
+	if _, err := font.NameTable(); err != nil { // added
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", events)
+	}
+	if e := events[0]; e.Tag != TagOS2 || e.Size != int(font.tables[TagOS2].length) {
+		t.Errorf("unexpected event %v", e)
+	}
+	if e := events[1]; e.Tag != TagCmap || e.Size != int(font.tables[TagCmap].length) || e.Parse != 0 {
+		t.Errorf("unexpected event %v", e)
+	}
+
+	SetTableHook(nil)
+	events = nil
+	if _, err := font.CmapTable(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("unexpected events %v", events)
+	}
+}
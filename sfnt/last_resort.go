@@ -0,0 +1,201 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+// metrics of the last resort font, in font units
+const (
+	lastResortUnitsPerEm = 1000
+	lastResortAscender   = 800
+	lastResortDescender  = -200
+	lastResortNotdefAdv  = 500
+	lastResortSpaceAdv   = 250
+)
+
+// lastResortNotdef is the outline of the .notdef glyph: a box, drawn
+// clockwise, with a counter clockwise hole.
+var lastResortNotdef = [][][2]int16{
+	{{50, 0}, {50, 700}, {450, 700}, {450, 0}},
+	{{100, 50}, {400, 50}, {400, 650}, {100, 650}},
+}
+
+// NewLastResort builds a minimal, valid TrueType font with the given family
+// name, which may be embedded by applications as the ultimate fallback.
+// It has two glyphs: the .notdef box, and the space, mapped to U+0020
+// and U+00A0.
+// The font is built with New and the table builders of this package, and
+// should be serialized with WriteTo, which updates the 'OS/2' table.
+func NewLastResort(family string) (*Font, error) {
+	font := New(TypeTrueType)
+
+	notdef := buildSimpleGlyph(lastResortNotdef)
+	glyf := append(notdef, make([]byte, padding(len(notdef)))...)
+	// the space has no outline: short loca format, with offsets divided by 2
+	loca := make([]byte, 6)
+	be.PutUint16(loca[2:], uint16(len(glyf)/2))
+	be.PutUint16(loca[4:], uint16(len(glyf)/2))
+	font.AddTable(TagGlyf, NewTable(TagGlyf, glyf))
+	font.AddTable(TagLoca, NewTable(TagLoca, loca))
+
+	xMin, yMin, xMax, yMax := int16(be.Uint16(notdef[2:])), int16(be.Uint16(notdef[4:])),
+		int16(be.Uint16(notdef[6:])), int16(be.Uint16(notdef[8:]))
+	font.AddTable(TagHead, &TableHead{
+		baseTable: baseTable(TagHead),
+		tableHeadFields: tableHeadFields{
+			VersionNumber: fixed{Major: 1},
+			FontRevision:  fixed{Major: 1},
+			MagicNumber:   0x5F0F3CF5,
+			Flags:         headBaselineAtZero | 1<<3, // integer ppem
+			UnitsPerEm:    lastResortUnitsPerEm,
+			XMin:          xMin,
+			YMin:          yMin,
+			XMax:          xMax,
+			YMax:          yMax,
+			LowestRecPPEM: 8,
+			FontDirection: 2,
+		},
+	})
+
+	maxp := make([]byte, maxpVersion1Size)
+	be.PutUint32(maxp, 0x00010000)
+	be.PutUint16(maxp[14:], 1) // maxZones: no twilight zone
+	font.AddTable(TagMaxp, NewTable(TagMaxp, maxp))
+	if err := font.UpdateMaxp(); err != nil {
+		return nil, err
+	}
+
+	hmtx := make([]byte, 8)
+	be.PutUint16(hmtx, lastResortNotdefAdv)
+	be.PutUint16(hmtx[2:], uint16(xMin))
+	be.PutUint16(hmtx[4:], lastResortSpaceAdv)
+	font.AddTable(TagHmtx, NewTable(TagHmtx, hmtx))
+	font.AddTable(TagHhea, &TableHhea{
+		baseTable: baseTable(TagHhea),
+		tableHheaFields: tableHheaFields{
+			Version:             fixed{Major: 1},
+			Ascent:              lastResortAscender,
+			Descent:             lastResortDescender,
+			AdvanceWidthMax:     lastResortNotdefAdv,
+			MinLeftSideBearing:  xMin,
+			MinRightSideBearing: lastResortNotdefAdv - xMax,
+			XMaxExtent:          xMax,
+			CaretSlopeRise:      1,
+			NumOfLongHorMetrics: 2,
+		},
+	})
+
+	font.AddTable(TagCmap, NewTable(TagCmap, BuildCmap(map[rune]GlyphIndex{' ': 1, 0xA0: 1})))
+
+	os2, err := buildLastResortOS2()
+	if err != nil {
+		return nil, err
+	}
+	font.AddTable(TagOS2, os2)
+
+	name := NewTableName()
+	for _, entry := range []struct {
+		id    NameID
+		value string
+	}{
+		{NameFontFamily, family},
+		{NameFontSubfamily, "Regular"},
+		{NameFull, family},
+		{NamePostscript, strings.ReplaceAll(family, " ", "")},
+	} {
+		if err := name.AddMicrosoftEnglishEntry(entry.id, entry.value); err != nil {
+			return nil, err
+		}
+	}
+	font.AddTable(TagName, name)
+
+	post := make([]byte, 32)
+	be.PutUint32(post, 0x00030000) // no glyph names
+	post[8], post[9] = 0xFF, 0x9C  // underlinePosition: -100
+	be.PutUint16(post[10:], 50)    // underlineThickness
+	font.AddTable(TagPost, NewTable(TagPost, post))
+
+	return font, nil
+}
+
+// buildLastResortOS2 returns a version 4 'OS/2' table. The character
+// indexes and ranges are filled when writing the font.
+func buildLastResortOS2() (Table, error) {
+	const version4Size = 96
+	fields := tableOS2Fields{
+		Version:             4,
+		XAvgCharWidth:       (lastResortNotdefAdv + lastResortSpaceAdv) / 2,
+		USWeightClass:       400,
+		USWidthClass:        5,
+		YSubscriptXSize:     650,
+		YSubscriptYSize:     600,
+		YSubscriptYOffset:   75,
+		YSuperscriptXSize:   650,
+		YSuperscriptYSize:   600,
+		YSuperscriptYOffset: 350,
+		YStrikeoutSize:      50,
+		YStrikeoutPosition:  250,
+		AchVendID:           MustNamedTag("NONE"),
+		FsSelection:         1<<6 | fsSelectionUseTypoMetrics, // regular
+		STypoAscender:       lastResortAscender,
+		STypoDescender:      lastResortDescender,
+		UsWinAscent:         lastResortAscender,
+		UsWinDescent:        -lastResortDescender,
+		SxHeigh:             500,
+		SCapHeight:          700,
+		UsBreakChar:         ' ',
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, fields); err != nil {
+		return nil, err
+	}
+	return parseTableOS2(TagOS2, buf.Bytes()[:version4Size])
+}
+
+// buildSimpleGlyph returns the 'glyf' data of a simple glyph, without
+// instructions, made of the given contours of on curve points.
+func buildSimpleGlyph(contours [][][2]int16) []byte {
+	const onCurve = 0x01
+	var (
+		endPoints, flags, xs, ys []byte
+		x, y                     int16
+		numPoints                int
+	)
+	xMin, yMin, xMax, yMax := int16(0x7FFF), int16(0x7FFF), int16(-0x8000), int16(-0x8000)
+	for _, contour := range contours {
+		for _, p := range contour {
+			flags = append(flags, onCurve)
+			xs = append(xs, byte(uint16(p[0]-x)>>8), byte(p[0]-x))
+			ys = append(ys, byte(uint16(p[1]-y)>>8), byte(p[1]-y))
+			x, y = p[0], p[1]
+			if p[0] < xMin {
+				xMin = p[0]
+			}
+			if p[0] > xMax {
+				xMax = p[0]
+			}
+			if p[1] < yMin {
+				yMin = p[1]
+			}
+			if p[1] > yMax {
+				yMax = p[1]
+			}
+		}
+		numPoints += len(contour)
+		endPoints = append(endPoints, byte((numPoints-1)>>8), byte(numPoints-1))
+	}
+
+	out := make([]byte, 10)
+	be.PutUint16(out, uint16(len(contours)))
+	be.PutUint16(out[2:], uint16(xMin))
+	be.PutUint16(out[4:], uint16(yMin))
+	be.PutUint16(out[6:], uint16(xMax))
+	be.PutUint16(out[8:], uint16(yMax))
+	out = append(out, endPoints...)
+	out = append(out, 0, 0) // instructionLength
+	out = append(out, flags...)
+	out = append(out, xs...)
+	return append(out, ys...)
+}
@@ -0,0 +1,59 @@
+package sfnt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLastResort(t *testing.T) {
+	font, err := NewLastResort("Last Resort")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	font, err = StrictParse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range []func() ([]Issue, error){font.CheckNotdef, font.CheckWhitespace} {
+		issues, err := check()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 0 {
+			t.Errorf("unexpected issues %v", issues)
+		}
+	}
+
+	outline, err := font.GlyphOutline(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outline.EndPoints) != 2 || len(outline.Points) != 8 {
+		t.Errorf("unexpected .notdef outline %v", outline)
+	}
+	if numGlyphs, err := font.numGlyphs(); err != nil || numGlyphs != 2 {
+		t.Errorf("expected 2 glyphs, got %d (%v)", numGlyphs, err)
+	}
+
+	os2, err := font.OS2Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os2.FsFirstCharIndex != ' ' || os2.FsLastCharIndex != 0xA0 || os2.UlCharRange[0]&3 != 3 {
+		t.Errorf("unexpected OS/2 ranges %d %d %v", os2.FsFirstCharIndex, os2.FsLastCharIndex, os2.UlCharRange)
+	}
+	name, err := font.NameTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range name.List() {
+		if entry.NameID == NamePostscript && entry.String() != "LastResort" {
+			t.Errorf("unexpected PostScript name %s", entry.String())
+		}
+	}
+}
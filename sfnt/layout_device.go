@@ -0,0 +1,75 @@
+package sfnt
+
+// variationIndexFormat is the DeltaFormat of the VariationIndex tables
+const variationIndexFormat = 0x8000
+
+// DeviceTable stores the corrections, in pixels, applied to a value
+// of the layout tables at some sizes, so that the hinted glyphs
+// are positioned on the pixel grid as intended by the designer.
+// In variable fonts, the same structure is used as a VariationIndex
+// table, referencing the variations of the value.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/chapter2#device-and-variationindex-tables
+type DeviceTable struct {
+	StartSize uint16 // the ppem of the first delta
+	Deltas    []int8 // the correction for each size from StartSize
+
+	// IsVariationIndex is true for the VariationIndex tables, which have
+	// no Deltas: VariationIndex is then the [outer, inner] index of the
+	// deltas, in glyph units, stored in the item variation store
+	// of the 'GDEF' table.
+	IsVariationIndex bool
+	VariationIndex   [2]uint16
+}
+
+// Delta returns the correction at the given size, in pixels,
+// which is 0 outside the sizes of the table, or if d is nil.
+func (d *DeviceTable) Delta(ppem uint16) int16 {
+	if d == nil || ppem < d.StartSize || int(ppem-d.StartSize) >= len(d.Deltas) {
+		return 0
+	}
+	return int16(d.Deltas[ppem-d.StartSize])
+}
+
+// variation returns the variation of the value at the given
+// normalized coordinates, which is 0 if d is not a VariationIndex
+// table, or if d is nil.
+func (d *DeviceTable) variation(store *itemVariationStore, coords []float32) float32 {
+	if d == nil || !d.IsVariationIndex || store == nil {
+		return 0
+	}
+	return store.delta(d.VariationIndex[0], d.VariationIndex[1], coords)
+}
+
+// parseDeviceTable reads the Device or VariationIndex table at
+// the start of buf, returning false if it is invalid.
+func parseDeviceTable(buf []byte) (*DeviceTable, bool) {
+	if len(buf) < 6 {
+		return nil, false
+	}
+	startSize, endSize, deltaFormat := be.Uint16(buf), be.Uint16(buf[2:]), be.Uint16(buf[4:])
+	if deltaFormat == variationIndexFormat {
+		// deltaSetOuterIndex, deltaSetInnerIndex, deltaFormat
+		return &DeviceTable{IsVariationIndex: true, VariationIndex: [2]uint16{startSize, endSize}}, true
+	}
+	if deltaFormat < 1 || deltaFormat > 3 || endSize < startSize {
+		return nil, false
+	}
+	// the deltas are packed in uint16, with 2, 4 or 8 bits each
+	bitsPerDelta := 1 << deltaFormat
+	perWord := 16 / bitsPerDelta
+	count := int(endSize-startSize) + 1
+	words := buf[6:]
+	if len(words) < 2*((count+perWord-1)/perWord) {
+		return nil, false
+	}
+
+	out := &DeviceTable{StartSize: startSize, Deltas: make([]int8, count)}
+	for i := range out.Deltas {
+		word := be.Uint16(words[2*(i/perWord):])
+		shift := 16 - bitsPerDelta*(i%perWord+1)
+		// sign extension of the delta
+		delta := int16(word<<(16-bitsPerDelta-shift)) >> (16 - bitsPerDelta)
+		out.Deltas[i] = int8(delta)
+	}
+	return out, true
+}
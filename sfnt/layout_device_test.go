@@ -0,0 +1,47 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDeviceTable(t *testing.T) {
+	for _, test := range []struct {
+		data     []byte
+		expected []int8
+	}{
+		{[]byte{0, 10, 0, 13, 0, 1, 0x72, 0}, []int8{1, -1, 0, -2}},                // 2 bits deltas
+		{[]byte{0, 10, 0, 14, 0, 2, 0x78, 0x1F, 0x30, 0}, []int8{7, -8, 1, -1, 3}}, // 4 bits deltas
+		{[]byte{0, 10, 0, 12, 0, 3, 0x05, 0xFB, 0x80, 0}, []int8{5, -5, -128}},     // 8 bits deltas
+	} {
+		device, ok := parseDeviceTable(test.data)
+		if !ok {
+			t.Fatalf("invalid device table %v", test.data)
+		}
+		if device.StartSize != 10 || !reflect.DeepEqual(device.Deltas, test.expected) {
+			t.Errorf("expected deltas %v, got %v", test.expected, device)
+		}
+		if device.Delta(9) != 0 || device.Delta(11) != int16(test.expected[1]) {
+			t.Errorf("unexpected deltas for %v", device)
+		}
+	}
+
+	for _, data := range [][]byte{
+		{0, 10, 0, 13, 0, 1},       // truncated
+		{0, 10, 0, 9, 0, 1, 0, 0},  // invalid range
+		{0, 10, 0, 13, 0, 4, 0, 0}, // invalid format
+	} {
+		if _, ok := parseDeviceTable(data); ok {
+			t.Errorf("expected an invalid device table for %v", data)
+		}
+	}
+	device, ok := parseDeviceTable([]byte{0, 1, 0, 2, 0x80, 0})
+	if !ok || !device.IsVariationIndex || device.VariationIndex != [2]uint16{1, 2} || device.Delta(1) != 0 {
+		t.Errorf("unexpected VariationIndex table %v", device)
+	}
+
+	var nilDevice *DeviceTable
+	if nilDevice.Delta(12) != 0 {
+		t.Error("expected no correction for a nil device table")
+	}
+}
@@ -0,0 +1,286 @@
+package sfnt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// lookup types handled by lookupCoverage (see also segment.go)
+const (
+	gsubContext      = 5
+	gsubChainContext = 6
+	gposContext      = 7
+	gposChainContext = 8
+	gposExtension    = 9
+)
+
+// LayoutChangeKind describes a difference between the layout
+// tables of two fonts.
+type LayoutChangeKind uint8
+
+const (
+	ScriptAdded LayoutChangeKind = iota
+	ScriptRemoved
+	FeatureAdded
+	FeatureRemoved
+	// CoverageAdded and CoverageRemoved are reported for the features
+	// found in both fonts, whose lookups apply to new characters,
+	// or no longer apply to some characters.
+	CoverageAdded
+	CoverageRemoved
+)
+
+func (k LayoutChangeKind) String() string {
+	switch k {
+	case ScriptAdded:
+		return "script added"
+	case ScriptRemoved:
+		return "script removed"
+	case FeatureAdded:
+		return "feature added"
+	case FeatureRemoved:
+		return "feature removed"
+	case CoverageAdded:
+		return "coverage added"
+	case CoverageRemoved:
+		return "coverage removed"
+	default:
+		return fmt.Sprintf("<LayoutChangeKind %d>", k)
+	}
+}
+
+// LayoutChange is one difference reported by DiffLayout.
+type LayoutChange struct {
+	Table  Tag // TagGsub or TagGpos
+	Script Tag
+	// Feature is the zero Tag for ScriptAdded and ScriptRemoved.
+	Feature Tag
+	Kind    LayoutChangeKind
+	// Runes are the characters gained or lost by the feature,
+	// for CoverageAdded and CoverageRemoved, in increasing order.
+	Runes []rune
+}
+
+func (c LayoutChange) String() string {
+	switch c.Kind {
+	case ScriptAdded, ScriptRemoved:
+		return fmt.Sprintf("%s: %s %q", c.Table, c.Kind, c.Script)
+	case CoverageAdded, CoverageRemoved:
+		return fmt.Sprintf("%s %q %q: %s for %d characters", c.Table, c.Script, c.Feature, c.Kind, len(c.Runes))
+	default:
+		return fmt.Sprintf("%s %q: %s %q", c.Table, c.Script, c.Kind, c.Feature)
+	}
+}
+
+// DiffLayout compares the 'GSUB' and 'GPOS' tables of two versions of
+// a font, and returns the scripts and features added or removed in the
+// after font, as well as the characters gained or lost by each feature.
+// The features of a script are gathered from all its language systems.
+// Since the glyph indices usually change between versions, the coverage
+// of the lookups is compared through the cmap of each font: the glyphs
+// only reachable by substitution are ignored.
+// A missing layout table is handled as an empty one.
+func DiffLayout(before, after *Font) ([]LayoutChange, error) {
+	var out []LayoutChange
+	for _, tag := range [...]Tag{TagGsub, TagGpos} {
+		beforeFeatures, err := before.layoutFeatureRunes(tag)
+		if err != nil {
+			return nil, err
+		}
+		afterFeatures, err := after.layoutFeatureRunes(tag)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, diffLayoutTable(tag, beforeFeatures, afterFeatures)...)
+	}
+	return out, nil
+}
+
+// scriptFeatures maps the scripts to their features, and the features
+// to the characters covered by their lookups.
+type scriptFeatures map[Tag]map[Tag]map[rune]bool
+
+func diffLayoutTable(table Tag, before, after scriptFeatures) []LayoutChange {
+	var out []LayoutChange
+	scripts := map[Tag]bool{}
+	for script := range before {
+		scripts[script] = true
+	}
+	for script := range after {
+		scripts[script] = true
+	}
+	for _, script := range sortedTags(scripts) {
+		beforeFeatures, inBefore := before[script]
+		afterFeatures, inAfter := after[script]
+		switch {
+		case !inAfter:
+			out = append(out, LayoutChange{Table: table, Script: script, Kind: ScriptRemoved})
+			continue
+		case !inBefore:
+			out = append(out, LayoutChange{Table: table, Script: script, Kind: ScriptAdded})
+			continue
+		}
+
+		features := map[Tag]bool{}
+		for feature := range beforeFeatures {
+			features[feature] = true
+		}
+		for feature := range afterFeatures {
+			features[feature] = true
+		}
+		for _, feature := range sortedTags(features) {
+			beforeRunes, inBefore := beforeFeatures[feature]
+			afterRunes, inAfter := afterFeatures[feature]
+			change := LayoutChange{Table: table, Script: script, Feature: feature}
+			switch {
+			case !inAfter:
+				change.Kind = FeatureRemoved
+				out = append(out, change)
+			case !inBefore:
+				change.Kind = FeatureAdded
+				out = append(out, change)
+			default:
+				if runes := runesDifference(beforeRunes, afterRunes); len(runes) != 0 {
+					change.Kind, change.Runes = CoverageRemoved, runes
+					out = append(out, change)
+				}
+				if runes := runesDifference(afterRunes, beforeRunes); len(runes) != 0 {
+					change.Kind, change.Runes = CoverageAdded, runes
+					out = append(out, change)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func sortedTags(tags map[Tag]bool) []Tag {
+	out := make([]Tag, 0, len(tags))
+	for tag := range tags {
+		out = append(out, tag)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// runesDifference returns the sorted runes of s1 not in s2.
+func runesDifference(s1, s2 map[rune]bool) []rune {
+	var out []rune
+	for r := range s1 {
+		if !s2[r] {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// layoutFeatureRunes returns the features of each script of the
+// layout table, and the characters they apply to.
+func (font *Font) layoutFeatureRunes(tag Tag) (scriptFeatures, error) {
+	if !font.HasTable(tag) {
+		return nil, nil
+	}
+	layout, err := font.TableLayout(tag)
+	if err != nil {
+		return nil, err
+	}
+	var glyphRunes map[GlyphIndex][]rune
+	if font.HasTable(TagCmap) {
+		cmap, err := font.CmapTable()
+		if err != nil {
+			return nil, err
+		}
+		glyphRunes = make(map[GlyphIndex][]rune)
+		for r, gi := range cmap.Compile() {
+			glyphRunes[gi] = append(glyphRunes[gi], r)
+		}
+	}
+
+	featureRunes := map[*Feature]map[rune]bool{}
+	for _, feature := range layout.Features {
+		glyphs := NewGlyphSet()
+		for _, lookup := range feature.Lookups {
+			set, err := lookupCoverage(tag, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("feature %q: %s", feature.Tag, err)
+			}
+			glyphs.Union(set)
+		}
+		runes := map[rune]bool{}
+		glyphs.ForEach(func(gi GlyphIndex) {
+			for _, r := range glyphRunes[gi] {
+				runes[r] = true
+			}
+		})
+		featureRunes[feature] = runes
+	}
+
+	out := make(scriptFeatures, len(layout.Scripts))
+	for _, script := range layout.Scripts {
+		features := map[Tag]map[rune]bool{}
+		langs := script.Languages
+		if script.DefaultLanguage != nil {
+			langs = append([]*LangSys{script.DefaultLanguage}, langs...)
+		}
+		for _, lang := range langs {
+			for _, feature := range lang.Features {
+				runes := features[feature.Tag]
+				if runes == nil {
+					runes = map[rune]bool{}
+					features[feature.Tag] = runes
+				}
+				for r := range featureRunes[feature] {
+					runes[r] = true
+				}
+			}
+		}
+		out[script.Tag] = features
+	}
+	return out, nil
+}
+
+// lookupCoverage returns the glyphs to which the subtables of the lookup
+// apply, that is their first coverage table.
+func lookupCoverage(table Tag, lookup *Lookup) (*GlyphSet, error) {
+	extensionType := uint16(gsubExtension)
+	if table == TagGpos {
+		extensionType = gposExtension
+	}
+	subtables, err := lookup.parsedSubtables()
+	if err != nil {
+		return nil, err
+	}
+
+	out := NewGlyphSet()
+	for _, st := range subtables {
+		lookupType := lookup.Type
+		if lookupType == extensionType {
+			lookupType, st, err = st.extensionSubtable(extensionType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		offsetPosition := 2
+		if st.format == 3 {
+			switch {
+			case table == TagGsub && lookupType == gsubContext, table == TagGpos && lookupType == gposContext:
+				// format, glyphCount, seqLookupCount, coverageOffsets
+				offsetPosition = 6
+			case table == TagGsub && lookupType == gsubChainContext, table == TagGpos && lookupType == gposChainContext:
+				// format, backtrackGlyphCount, backtrackCoverageOffsets, inputGlyphCount, inputCoverageOffsets
+				if len(st.data) < 4 {
+					return nil, errInvalidLookupSubtable
+				}
+				offsetPosition = 6 + 2*int(be.Uint16(st.data[2:]))
+			}
+		}
+		cov, err := st.fetchCoverage(offsetPosition)
+		if err != nil {
+			return nil, err
+		}
+		out.Union(cov.glyphSet())
+	}
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package sfnt
+
+import "testing"
+
+func TestDiffLayout(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	changes, err := DiffLayout(font, font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no change between identical fonts, got %v", changes)
+	}
+
+	withoutGPOS := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	withoutGPOS.RemoveTable(TagGpos)
+	changes, err = DiffLayout(font, withoutGPOS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpos, err := font.GposTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(gpos.Scripts) {
+		t.Fatalf("expected %d removed scripts, got %v", len(gpos.Scripts), changes)
+	}
+	for _, change := range changes {
+		if change.Table != TagGpos || change.Kind != ScriptRemoved {
+			t.Errorf("unexpected change %s", change)
+		}
+	}
+
+	regular, italic := loadTestFont(t, "Castoro-Regular.ttf"), loadTestFont(t, "Castoro-Italic.ttf")
+	changes, err = DiffLayout(regular, italic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kernChanged bool
+	for _, change := range changes {
+		if (change.Kind == CoverageAdded || change.Kind == CoverageRemoved) && len(change.Runes) == 0 {
+			t.Errorf("expected characters for %s", change)
+		}
+		kernChanged = kernChanged || change.Script == scriptLatin && change.Feature == MustNamedTag("kern")
+	}
+	if !kernChanged {
+		t.Errorf("expected a change of the kerning coverage, got %v", changes)
+	}
+}
@@ -0,0 +1,77 @@
+package sfnt
+
+import "errors"
+
+var errUnknownLineSpacing = errors.New("unknown line spacing strategy")
+
+// fsSelection flag indicating that the typographic metrics
+// should be used for the line spacing
+const fsSelectionUseTypoMetrics = 1 << 7
+
+// LineSpacing is a strategy to compute the distance between two
+// consecutive baselines. The platforms and applications disagree on
+// the metrics to use, so that the same font may have different line
+// spacings depending on where it is used.
+type LineSpacing uint8
+
+const (
+	// LineSpacingHhea uses the ascender, descender and line gap of the
+	// 'hhea' table, as done on macOS.
+	LineSpacingHhea LineSpacing = iota
+	// LineSpacingTypo uses the typographic ascender, descender and
+	// line gap of the 'OS/2' table.
+	LineSpacingTypo
+	// LineSpacingWin uses the usWinAscent and usWinDescent fields
+	// of the 'OS/2' table, as done by Windows GDI. There is no line gap.
+	LineSpacingWin
+	// LineSpacingAuto uses the typographic metrics if the
+	// USE_TYPO_METRICS flag of the 'OS/2' table is set, and the 'hhea'
+	// metrics otherwise, as done by most browsers and FreeType.
+	// If the selected metrics are all zero, the Windows metrics are used.
+	LineSpacingAuto
+)
+
+// BaselineToBaseline returns the distance between two consecutive
+// baselines, in font units, using the given strategy.
+func (font *Font) BaselineToBaseline(strategy LineSpacing) (int, error) {
+	switch strategy {
+	case LineSpacingHhea:
+		hhea, err := font.HheaTable()
+		if err != nil {
+			return 0, err
+		}
+		// the descender is negative
+		return int(hhea.Ascent) - int(hhea.Descent) + int(hhea.LineGap), nil
+	case LineSpacingTypo:
+		os2, err := font.OS2Table()
+		if err != nil {
+			return 0, err
+		}
+		return int(os2.STypoAscender) - int(os2.STypoDescender) + int(os2.STypoLineGap), nil
+	case LineSpacingWin:
+		os2, err := font.OS2Table()
+		if err != nil {
+			return 0, err
+		}
+		// usWinDescent is positive
+		return int(os2.UsWinAscent) + int(os2.UsWinDescent), nil
+	case LineSpacingAuto:
+		selected := LineSpacingHhea
+		if font.HasTable(TagOS2) {
+			os2, err := font.OS2Table()
+			if err != nil {
+				return 0, err
+			}
+			if os2.FsSelection&fsSelectionUseTypoMetrics != 0 {
+				selected = LineSpacingTypo
+			}
+		}
+		out, err := font.BaselineToBaseline(selected)
+		if err != nil || out == 0 {
+			return font.BaselineToBaseline(LineSpacingWin)
+		}
+		return out, nil
+	default:
+		return 0, errUnknownLineSpacing
+	}
+}
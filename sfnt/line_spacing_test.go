@@ -0,0 +1,52 @@
+package sfnt
+
+import (
+	"testing"
+)
+
+func TestBaselineToBaseline(t *testing.T) {
+	os2 := make([]byte, 96)
+	be.PutUint16(os2[68:], 750)   // sTypoAscender
+	os2[70], os2[71] = 0xFF, 0x06 // sTypoDescender: -250
+	be.PutUint16(os2[72:], 100)   // sTypoLineGap
+	be.PutUint16(os2[74:], 900)   // usWinAscent
+	be.PutUint16(os2[76:], 300)   // usWinDescent
+	os2Table, err := parseTableOS2(TagOS2, os2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagHhea, &TableHhea{baseTable: baseTable(TagHhea), tableHheaFields: tableHheaFields{Ascent: 800, Descent: -200, LineGap: 50}})
+	font.AddTable(TagOS2, os2Table)
+
+	for _, test := range []struct {
+		strategy LineSpacing
+		expected int
+	}{
+		{LineSpacingHhea, 1050},
+		{LineSpacingTypo, 1100},
+		{LineSpacingWin, 1200},
+		{LineSpacingAuto, 1050},
+	} {
+		got, err := font.BaselineToBaseline(test.strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("strategy %d: expected %d, got %d", test.strategy, test.expected, got)
+		}
+	}
+
+	// with USE_TYPO_METRICS
+	be.PutUint16(os2[62:], fsSelectionUseTypoMetrics)
+	os2Table, _ = parseTableOS2(TagOS2, os2)
+	font.AddTable(TagOS2, os2Table)
+	if got, _ := font.BaselineToBaseline(LineSpacingAuto); got != 1100 {
+		t.Errorf("expected typographic metrics, got %d", got)
+	}
+
+	if _, err := font.BaselineToBaseline(LineSpacing(10)); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
@@ -0,0 +1,189 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	errInvalidMetricsSnapshot     = errors.New("invalid metrics snapshot")
+	errUnsupportedMetricsSnapshot = errors.New("unsupported metrics snapshot version")
+)
+
+// Metrics is a compact snapshot of the metrics of a font needed by
+// layout engines, which may be serialized with MarshalBinary and cached,
+// so that services restarting frequently don't need to parse the font again.
+// The values are expressed in font units.
+type Metrics struct {
+	UnitsPerEm uint16
+
+	// Ascent, Descent and LineGap are the line metrics
+	// of the 'hhea' table (Descent is usually negative).
+	Ascent, Descent, LineGap int16
+	// BaselineToBaseline is the distance between two
+	// consecutive baselines, using LineSpacingAuto.
+	BaselineToBaseline int32
+
+	// Advances are the horizontal advances, by glyph.
+	Advances []uint16
+
+	// kerning pairs, sorted by key (left << 16 | right)
+	kernKeys   []uint32
+	kernValues []int16
+}
+
+// MetricsSnapshot returns the metrics of the font. The kerning is
+// built as KernTable(false) does, and is empty if the font has none.
+func (font *Font) MetricsSnapshot() (*Metrics, error) {
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return nil, err
+	}
+	lineHeight, err := font.BaselineToBaseline(LineSpacingAuto)
+	if err != nil {
+		return nil, err
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Metrics{
+		UnitsPerEm:         head.UnitsPerEm,
+		Ascent:             hhea.Ascent,
+		Descent:            hhea.Descent,
+		LineGap:            hhea.LineGap,
+		BaselineToBaseline: int32(lineHeight),
+		Advances:           make([]uint16, len(widths)),
+	}
+	for i, w := range widths {
+		out.Advances[i] = uint16(w)
+	}
+
+	kerns, err := font.KernTable(false)
+	if errors.Is(err, ErrMissingTable) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pairs, keys := resolveKernPairs(kerns)
+	out.kernKeys = keys
+	out.kernValues = make([]int16, len(keys))
+	for i, key := range keys {
+		out.kernValues[i] = pairs[key]
+	}
+	return out, nil
+}
+
+// Advance returns the horizontal advance of the glyph,
+// or 0 if it is out of range.
+func (m *Metrics) Advance(gi GlyphIndex) int {
+	if int(gi) >= len(m.Advances) {
+		return 0
+	}
+	return int(m.Advances[gi])
+}
+
+// KernPair implements Kerns.
+func (m *Metrics) KernPair(left, right GlyphIndex) (int16, bool) {
+	key := uint32(left)<<16 | uint32(right)
+	i := sort.Search(len(m.kernKeys), func(i int) bool { return m.kernKeys[i] >= key })
+	if i < len(m.kernKeys) && m.kernKeys[i] == key {
+		return m.kernValues[i], true
+	}
+	return 0, false
+}
+
+// KernPairAt implements Kerns. The device tables are not
+// stored, so that the correction is always 0.
+func (m *Metrics) KernPairAt(left, right GlyphIndex, _ uint16) (int16, int16, bool) {
+	value, ok := m.KernPair(left, right)
+	return value, 0, ok
+}
+
+// Size implements Kerns, returning the number of kerning pairs.
+func (m *Metrics) Size() int { return len(m.kernKeys) }
+
+// Each implements Kerns.
+func (m *Metrics) Each(fn func(left, right GlyphIndex, value int16)) {
+	for i, key := range m.kernKeys {
+		fn(GlyphIndex(key>>16), GlyphIndex(key), m.kernValues[i])
+	}
+}
+
+const (
+	metricsSnapshotMagic   = "SFMS"
+	metricsSnapshotVersion = 1
+	metricsHeaderLength    = 4 + 2 + 2 + 3*2 + 4 + 2 + 4
+)
+
+// MarshalBinary serializes the metrics in a compact big-endian format,
+// versioned so that UnmarshalBinary rejects the snapshots written by an
+// incompatible version of this package.
+// It implements encoding.BinaryMarshaler.
+func (m *Metrics) MarshalBinary() ([]byte, error) {
+	out := make([]byte, metricsHeaderLength, metricsHeaderLength+2*len(m.Advances)+6*len(m.kernKeys))
+	copy(out, metricsSnapshotMagic)
+	be.PutUint16(out[4:], metricsSnapshotVersion)
+	be.PutUint16(out[6:], m.UnitsPerEm)
+	be.PutUint16(out[8:], uint16(m.Ascent))
+	be.PutUint16(out[10:], uint16(m.Descent))
+	be.PutUint16(out[12:], uint16(m.LineGap))
+	be.PutUint32(out[14:], uint32(m.BaselineToBaseline))
+	be.PutUint16(out[18:], uint16(len(m.Advances)))
+	be.PutUint32(out[20:], uint32(len(m.kernKeys)))
+
+	var buf [6]byte
+	for _, advance := range m.Advances {
+		be.PutUint16(buf[:], advance)
+		out = append(out, buf[:2]...)
+	}
+	for i, key := range m.kernKeys {
+		be.PutUint32(buf[:], key)
+		be.PutUint16(buf[4:], uint16(m.kernValues[i]))
+		out = append(out, buf[:]...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary loads metrics serialized by MarshalBinary.
+// It implements encoding.BinaryUnmarshaler.
+func (m *Metrics) UnmarshalBinary(data []byte) error {
+	r := NewTableReader(Tag{}, data)
+	if magic := r.Bytes(4); r.Err() != nil || string(magic) != metricsSnapshotMagic {
+		return errInvalidMetricsSnapshot
+	}
+	if version := r.Uint16(); r.Err() == nil && version != metricsSnapshotVersion {
+		return errUnsupportedMetricsSnapshot
+	}
+
+	var out Metrics
+	out.UnitsPerEm = r.Uint16()
+	out.Ascent = r.Int16()
+	out.Descent = r.Int16()
+	out.LineGap = r.Int16()
+	out.BaselineToBaseline = r.Int32()
+	numGlyphs := int(r.Uint16())
+	numPairs := int(r.Uint32())
+	if r.Err() != nil || r.Len() != 2*numGlyphs+6*numPairs {
+		return errInvalidMetricsSnapshot
+	}
+
+	out.Advances = r.Uint16s(numGlyphs)
+	out.kernKeys = make([]uint32, numPairs)
+	out.kernValues = make([]int16, numPairs)
+	for i := range out.kernKeys {
+		out.kernKeys[i] = r.Uint32()
+		out.kernValues[i] = r.Int16()
+		if i > 0 && out.kernKeys[i] <= out.kernKeys[i-1] {
+			return errInvalidMetricsSnapshot
+		}
+	}
+	*m = out
+	return nil
+}
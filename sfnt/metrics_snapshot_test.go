@@ -0,0 +1,65 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetricsSnapshot(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	metrics, err := font.MetricsSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kerns, err := font.KernTable(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.UnitsPerEm != head.UnitsPerEm || len(metrics.Advances) != len(widths) {
+		t.Fatalf("unexpected metrics %d %d", metrics.UnitsPerEm, len(metrics.Advances))
+	}
+	for gi, w := range widths {
+		if metrics.Advance(GlyphIndex(gi)) != w {
+			t.Errorf("glyph %d: expected advance %d, got %d", gi, w, metrics.Advance(GlyphIndex(gi)))
+		}
+	}
+	if metrics.Size() == 0 {
+		t.Fatal("expected kerning pairs")
+	}
+	metrics.Each(func(left, right GlyphIndex, value int16) {
+		if expected, _ := kerns.KernPair(left, right); expected != value {
+			t.Errorf("pair (%d, %d): expected %d, got %d", left, right, expected, value)
+		}
+	})
+
+	data, err := metrics.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Metrics
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&loaded, metrics) {
+		t.Error("the snapshot is not preserved")
+	}
+
+	for _, invalid := range [][]byte{
+		nil,
+		data[:len(data)-1],
+		append([]byte("SFMS\x00\x02"), data[6:]...),
+	} {
+		if err := loaded.UnmarshalBinary(invalid); err == nil {
+			t.Errorf("expected an error for %d bytes", len(invalid))
+		}
+	}
+}
@@ -28,7 +28,7 @@ var (
 		"VORG": "Vertical Origin (optional table)",
 
 		// Table related to SVG outlines
-		"SVG": "The SVG (Scalable Vector Graphics) table",
+		"SVG ": "The SVG (Scalable Vector Graphics) table",
 
 		// Tables Related to Bitmap Glyphs
 		"EBDT": "Embedded bitmap data",
@@ -71,6 +71,14 @@ var (
 		"VDMX": "Vertical device metrics",
 		"vhea": "Vertical Metrics header",
 		"vmtx": "Vertical Metrics",
+
+		// Graphite Tables
+		"Feat": "Graphite features",
+		"Sill": "Graphite language defaults",
+
+		// Apple Advanced Typography Tables
+		"feat": "Feature name",
+		"trak": "Tracking",
 	}
 
 	// languageTags contains the registered language names mapped by tag.
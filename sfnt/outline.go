@@ -0,0 +1,255 @@
+package sfnt
+
+import (
+	"fmt"
+	"math"
+)
+
+// Point is a vertex of a flattened outline, in font units.
+type Point struct {
+	X, Y float32
+}
+
+// Contours splits the outline into its contours.
+// The contours with invalid end points are dropped.
+func (o GlyphOutline) Contours() [][]GlyphPoint {
+	var (
+		out   [][]GlyphPoint
+		start int
+	)
+	for _, end := range o.EndPoints {
+		if end < start || end >= len(o.Points) {
+			break
+		}
+		out = append(out, o.Points[start:end+1])
+		start = end + 1
+	}
+	return out
+}
+
+// Flatten approximates the quadratic contours of the outline by
+// closed polygons (the first point is not repeated at the end), with
+// a distance to the curves lower than tolerance, in font units.
+// A non positive tolerance defaults to 0.1.
+func (o GlyphOutline) Flatten(tolerance float32) [][]Point {
+	var out [][]Point
+	for _, contour := range o.Contours() {
+		if polygon := flattenContour(contour, tolerance); len(polygon) != 0 {
+			out = append(out, polygon)
+		}
+	}
+	return out
+}
+
+// curveSteps returns the number of segments needed to approximate
+// the quadratic curve within tolerance: the distance between the curve
+// and a chord spanning a parameter interval h is at most |p0 - 2c + p1| h² / 4.
+func curveSteps(p0 Point, control GlyphPoint, p1 Point, tolerance float32) int {
+	dx, dy := float64(p0.X-2*control.X+p1.X), float64(p0.Y-2*control.Y+p1.Y)
+	if tolerance <= 0 {
+		tolerance = 0.1
+	}
+	steps := int(math.Ceil(math.Sqrt(math.Hypot(dx, dy) / (4 * float64(tolerance)))))
+	if steps < 1 {
+		return 1
+	}
+	return steps
+}
+
+func flattenContour(contour []GlyphPoint, tolerance float32) []Point {
+	if len(contour) == 0 {
+		return nil
+	}
+	// start with an on curve point, which may be implied,
+	// and close the contour by coming back to it
+	first, last := contour[0], contour[len(contour)-1]
+	var start GlyphPoint
+	switch {
+	case first.OnCurve:
+		start, contour = first, append(contour[1:len(contour):len(contour)], first)
+	case last.OnCurve:
+		start = last
+	default:
+		start = GlyphPoint{X: (first.X + last.X) / 2, Y: (first.Y + last.Y) / 2, OnCurve: true}
+		contour = append(contour[:len(contour):len(contour)], start)
+	}
+
+	current := Point{start.X, start.Y}
+	out := []Point{current}
+	addCurve := func(control GlyphPoint, end Point) {
+		steps := curveSteps(current, control, end, tolerance)
+		for step := 1; step <= steps; step++ {
+			t := float32(step) / float32(steps)
+			u := 1 - t
+			out = append(out, Point{
+				u*u*current.X + 2*u*t*control.X + t*t*end.X,
+				u*u*current.Y + 2*u*t*control.Y + t*t*end.Y,
+			})
+		}
+		current = end
+	}
+
+	var control *GlyphPoint
+	for i, p := range contour {
+		if p.OnCurve {
+			if control != nil {
+				addCurve(*control, Point{p.X, p.Y})
+			} else {
+				current = Point{p.X, p.Y}
+				out = append(out, current)
+			}
+			control = nil
+			continue
+		}
+		if control != nil { // implied on curve point
+			addCurve(*control, Point{(control.X + p.X) / 2, (control.Y + p.Y) / 2})
+		}
+		control = &contour[i]
+	}
+	// the last point is the start point
+	return out[:len(out)-1]
+}
+
+// PolygonArea returns the signed area of the closed polygon, which is
+// positive if the polygon is counter-clockwise (the y axis going up).
+func PolygonArea(polygon []Point) float32 {
+	var area float64
+	for i, p := range polygon {
+		q := polygon[(i+1)%len(polygon)]
+		area += float64(p.X)*float64(q.Y) - float64(q.X)*float64(p.Y)
+	}
+	return float32(area / 2)
+}
+
+// Winding is the direction of a closed path.
+type Winding int8
+
+const (
+	// Clockwise is the direction of the outer contours of TrueType
+	// outlines, the holes being counter-clockwise.
+	Clockwise Winding = -1
+	// Degenerate is used for the paths enclosing no area.
+	Degenerate Winding = 0
+	// CounterClockwise is the direction of the outer contours of CFF
+	// outlines, the holes being clockwise.
+	CounterClockwise Winding = 1
+)
+
+func (w Winding) String() string {
+	switch w {
+	case Clockwise:
+		return "clockwise"
+	case CounterClockwise:
+		return "counter-clockwise"
+	default:
+		return "degenerate"
+	}
+}
+
+// PolygonWinding returns the direction of the closed polygon.
+func PolygonWinding(polygon []Point) Winding {
+	switch area := PolygonArea(polygon); {
+	case area < 0:
+		return Clockwise
+	case area > 0:
+		return CounterClockwise
+	default:
+		return Degenerate
+	}
+}
+
+// insidePolygon returns true if p is inside the polygon,
+// according to the even-odd rule.
+func insidePolygon(p Point, polygon []Point) bool {
+	inside := false
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// DirectionIssue is a contour with the same direction as the
+// contour enclosing it: it is filled by the rasterizers using
+// the non zero winding rule, instead of being a hole.
+type DirectionIssue struct {
+	// Contour and Parent are the indices of the
+	// contour and of the enclosing contour.
+	Contour, Parent int
+}
+
+// DirectionIssues returns the contours whose direction is inconsistent
+// with the contour immediately enclosing them, using the polygons returned
+// by Flatten(tolerance). The overlapping contours, which are not nested,
+// are not reported. The degenerate contours are ignored.
+func (o GlyphOutline) DirectionIssues(tolerance float32) []DirectionIssue {
+	polygons := o.Flatten(tolerance)
+	areas := make([]float32, len(polygons))
+	for i, polygon := range polygons {
+		areas[i] = PolygonArea(polygon)
+	}
+
+	var out []DirectionIssue
+	for i, polygon := range polygons {
+		if areas[i] == 0 {
+			continue
+		}
+		// the enclosing contour with the smallest area
+		parent := -1
+		for j, other := range polygons {
+			if j == i || areas[j] == 0 || abs32(areas[j]) <= abs32(areas[i]) || !containsPolygon(other, polygon) {
+				continue
+			}
+			if parent == -1 || abs32(areas[j]) < abs32(areas[parent]) {
+				parent = j
+			}
+		}
+		if parent != -1 && (areas[i] > 0) == (areas[parent] > 0) {
+			out = append(out, DirectionIssue{Contour: i, Parent: parent})
+		}
+	}
+	return out
+}
+
+// containsPolygon returns true if all the vertices of inner are inside outer.
+func containsPolygon(outer, inner []Point) bool {
+	for _, p := range inner {
+		if !insidePolygon(p, outer) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// CheckContourDirections verifies that the contours of the TrueType
+// outlines are consistently oriented, that is that the holes have the
+// opposite direction of the contours enclosing them (see DirectionIssues).
+// The issues are reported by glyph, with the index of the contours.
+func (font *Font) CheckContourDirections() ([]Issue, error) {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	for gi := GlyphIndex(0); gi < GlyphIndex(numGlyphs); gi++ {
+		outline, err := font.GlyphOutline(gi)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range outline.DirectionIssues(1) {
+			issues = append(issues, Issue{Glyph: gi, Rune: -1, Message: fmt.Sprintf(
+				"contour %d has the same direction as its enclosing contour %d: it is filled instead of being a hole",
+				issue.Contour, issue.Parent)})
+		}
+	}
+	return issues, nil
+}
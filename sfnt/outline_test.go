@@ -0,0 +1,73 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutlineFlatten(t *testing.T) {
+	square := GlyphOutline{
+		Points: []GlyphPoint{
+			{0, 0, true}, {0, 100, true}, {100, 100, true}, {100, 0, true}, // clockwise
+			{20, 20, true}, {80, 20, true}, {80, 80, true}, {20, 80, true}, // counter-clockwise
+		},
+		EndPoints: []int{3, 7},
+	}
+	polygons := square.Flatten(0.1)
+	expected := [][]Point{{{0, 0}, {0, 100}, {100, 100}, {100, 0}}, {{20, 20}, {80, 20}, {80, 80}, {20, 80}}}
+	if !reflect.DeepEqual(polygons, expected) {
+		t.Fatalf("expected %v, got %v", expected, polygons)
+	}
+	if area := PolygonArea(polygons[0]); area != -10000 {
+		t.Errorf("expected an area of -10000, got %g", area)
+	}
+	if w0, w1 := PolygonWinding(polygons[0]), PolygonWinding(polygons[1]); w0 != Clockwise || w1 != CounterClockwise {
+		t.Errorf("unexpected windings %s %s", w0, w1)
+	}
+	if w := PolygonWinding([]Point{{0, 0}, {10, 10}}); w != Degenerate {
+		t.Errorf("expected a degenerate polygon, got %s", w)
+	}
+	if issues := square.DirectionIssues(0.1); len(issues) != 0 {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	// reverse the hole
+	square.Points[5], square.Points[7] = square.Points[7], square.Points[5]
+	if issues := square.DirectionIssues(0.1); !reflect.DeepEqual(issues, []DirectionIssue{{Contour: 1, Parent: 0}}) {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	// a curve, with only off curve points
+	circle := GlyphOutline{
+		Points:    []GlyphPoint{{0, 100, false}, {100, 100, false}, {100, 0, false}, {0, 0, false}},
+		EndPoints: []int{3},
+	}
+	coarse, fine := circle.Flatten(10)[0], circle.Flatten(0.01)[0]
+	if len(coarse) >= len(fine) {
+		t.Errorf("expected more points for a lower tolerance, got %d and %d", len(coarse), len(fine))
+	}
+	if area := PolygonArea(fine); area > -8330 || area < -8334 { // the diamond plus 2/3 of the corner triangles
+		t.Errorf("unexpected area %g", area)
+	}
+}
+
+func TestCheckContourDirections(t *testing.T) {
+	for _, file := range []string{"Roboto-BoldItalic.ttf", "Castoro-Regular.ttf"} {
+		font := loadTestFont(t, file)
+		issues, err := font.CheckContourDirections()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 0 {
+			t.Errorf("%s: unexpected issues %v", file, issues)
+		}
+	}
+
+	font, err := NewLastResort("Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues, err := font.CheckContourDirections(); err != nil || len(issues) != 0 {
+		t.Errorf("unexpected issues %v (%v)", issues, err)
+	}
+}
@@ -92,8 +92,17 @@ func readDirectoryEntryFast(r io.Reader, entry *directoryEntry) error {
 // parseOTF reads an OpenTyp (.otf) or TrueType (.ttf) file and returns a Font.
 // If parsing fails, then an error is returned and Font will be nil.
 func parseOTF(file File) (*Font, error) {
+	return parseOTFAt(file, 0)
+}
+
+// parseOTFAt reads a font whose header starts at offset in file,
+// as found in collections. The table offsets are relative to the
+// beginning of the file.
+func parseOTFAt(file File, offset int64) (*Font, error) {
+	r := io.NewSectionReader(file, offset, math.MaxInt64-offset)
+
 	var header otfHeader
-	if err := readOTFHeaderFast(file, &header); err != nil {
+	if err := readOTFHeaderFast(r, &header); err != nil {
 		return nil, err
 	}
 
@@ -102,16 +111,15 @@ func parseOTF(file File) (*Font, error) {
 
 		scalerType: header.ScalerType,
 		tables:     make(map[Tag]*tableSection, header.NumTables),
+		header:     &header,
 	}
 
 	for i := 0; i < int(header.NumTables); i++ {
 		var entry directoryEntry
-		if err := readDirectoryEntryFast(file, &entry); err != nil {
+		if err := readDirectoryEntryFast(r, &entry); err != nil {
 			return nil, err
 		}
 
-		// TODO Check the checksum.
-
 		if _, found := font.tables[entry.Tag]; found {
 			return nil, fmt.Errorf("found multiple %q tables", entry.Tag)
 		}
@@ -121,6 +129,9 @@ func parseOTF(file File) (*Font, error) {
 
 			offset: entry.Offset,
 			length: entry.Length,
+
+			checksum:    entry.CheckSum,
+			hasChecksum: true,
 		}
 	}
 
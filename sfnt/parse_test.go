@@ -2,6 +2,7 @@ package sfnt
 
 import (
 	"bytes"
+	"errors"
 	"math/rand"
 	"testing"
 )
@@ -21,3 +22,55 @@ func TestParseCrashers(t *testing.T) {
 		}
 	}
 }
+
+type testVendorTable struct {
+	baseTable
+	version uint16
+	bytes   []byte
+}
+
+func (t *testVendorTable) Bytes() []byte { return t.bytes }
+
+func TestRegisterTableParser(t *testing.T) {
+	tag := MustNamedTag("Xtst")
+	err := RegisterTableParser(tag, func(tag Tag, content []byte) (Table, error) {
+		if len(content) < 2 {
+			return nil, errors.New("invalid Xtst table")
+		}
+		return &testVendorTable{baseTable(tag), be.Uint16(content), content}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTableParser(TagHead, newUnparsedTable); err == nil {
+		t.Error("expected error when overriding a builtin parser")
+	}
+
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	font.AddTable(tag, NewTable(tag, []byte{0, 3, 0, 0}))
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	font, err = StrictParse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := font.Table(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vendor, ok := table.(*testVendorTable); !ok || vendor.version != 3 {
+		t.Errorf("unexpected table %v", table)
+	}
+
+	font.AddTable(tag, NewTable(tag, []byte{0}))
+	buf.Reset()
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StrictParse(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("expected error for invalid custom table")
+	}
+}
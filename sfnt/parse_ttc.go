@@ -0,0 +1,141 @@
+package sfnt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInvalidCollection is returned by ParseCollection if the
+// collection header is invalid.
+var ErrInvalidCollection = errors.New("invalid font collection")
+
+// the number of fonts is arbitrary limited, to defend
+// against malicious files
+const maxCollectionFonts = 1 << 16
+
+// ttcHeader is the beginning of the header of a TrueType Collection.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/otff#collections
+type ttcHeader struct {
+	Tag          Tag
+	MajorVersion uint16
+	MinorVersion uint16
+	NumFonts     uint32
+}
+
+// Collection is a TrueType Collection (.ttc or .otc file),
+// storing several fonts, which may share some tables.
+type Collection struct {
+	file    File
+	offsets []uint32 // offsets to the header of each font
+	tables  *tableCache
+}
+
+// tableLocation identifies a table in a collection file.
+type tableLocation struct {
+	tag            Tag
+	offset, length uint32
+}
+
+// tableCache stores the tables parsed by the fonts of a collection,
+// so that the tables shared by several fonts are only parsed once.
+type tableCache struct {
+	mu     sync.Mutex
+	tables map[tableLocation]Table
+}
+
+func newTableCache() *tableCache {
+	return &tableCache{tables: make(map[tableLocation]Table)}
+}
+
+// parseTable returns the table of the section, parsing it
+// if no other font of the collection has.
+func (c *tableCache) parseTable(font *Font, s *tableSection) (Table, error) {
+	location := tableLocation{s.tag, s.offset, s.length}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tables[location]; ok {
+		return t, nil
+	}
+	t, err := font.parseTable(s)
+	if err != nil {
+		return nil, err
+	}
+	c.tables[location] = t
+	return t, nil
+}
+
+// ParseCollection parses a TrueType Collection. The fonts are
+// parsed on demand by Collection.Font.
+// For convenience, a single font file (.otf, .ttf) is also accepted, and
+// is returned as a collection with one font.
+func ParseCollection(file File) (*Collection, error) {
+	magic, err := ReadTag(file)
+	if err != nil {
+		return nil, err
+	}
+	file.Seek(0, 0)
+
+	switch magic {
+	case SignatureTTC:
+	case TypeTrueType, TypeOpenType, TypePostScript1, TypeAppleTrueType:
+		return &Collection{file: file, offsets: []uint32{0}, tables: newTableCache()}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	var header ttcHeader
+	if err := binary.Read(file, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.MajorVersion != 1 && header.MajorVersion != 2 {
+		return nil, fmt.Errorf("unsupported collection version %d", header.MajorVersion)
+	}
+	if header.NumFonts == 0 || header.NumFonts > maxCollectionFonts {
+		return nil, ErrInvalidCollection
+	}
+
+	offsets := make([]uint32, header.NumFonts)
+	if err := binary.Read(file, binary.BigEndian, offsets); err != nil {
+		return nil, err
+	}
+
+	return &Collection{file: file, offsets: offsets, tables: newTableCache()}, nil
+}
+
+// NumFonts returns the number of fonts in the collection.
+func (c *Collection) NumFonts() int { return len(c.offsets) }
+
+// Font parses the font at the given index, which must be
+// in [0, NumFonts()[.
+// The fonts share the underlying file, and the tables stored at
+// the same location in the file are only parsed once, and shared
+// between the fonts: modifying such a table affects all the fonts
+// using it. Adding or removing a table only affects one font.
+// The methods of Font don't modify the shared tables, so that
+// the fonts of a collection may be used concurrently.
+func (c *Collection) Font(index int) (*Font, error) {
+	if index < 0 || index >= len(c.offsets) {
+		return nil, fmt.Errorf("invalid font index %d (for %d fonts)", index, len(c.offsets))
+	}
+	font, err := parseOTFAt(c.file, int64(c.offsets[index]))
+	if err != nil {
+		return nil, err
+	}
+	font.shared = c.tables
+	// the tables may be shared, so that the whole font
+	// checksum is not meaningful
+	font.header = nil
+	return font, nil
+}
+
+// parseTTC returns the first font of a collection.
+func parseTTC(file File) (*Font, error) {
+	c, err := ParseCollection(file)
+	if err != nil {
+		return nil, err
+	}
+	return c.Font(0)
+}
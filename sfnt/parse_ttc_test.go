@@ -0,0 +1,305 @@
+package sfnt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+)
+
+// buildCollection concatenates the given sfnt files into a collection,
+// without sharing tables.
+func buildCollection(files ...[]byte) []byte {
+	headerSize := 12 + 4*len(files)
+	out := make([]byte, headerSize)
+	copy(out, "ttcf")
+	be.PutUint16(out[4:], 1)
+	be.PutUint32(out[8:], uint32(len(files)))
+	for i, file := range files {
+		base := len(out)
+		be.PutUint32(out[12+4*i:], uint32(base))
+
+		file = append([]byte(nil), file...)
+		numTables := int(be.Uint16(file[4:]))
+		for j := 0; j < numTables; j++ {
+			entry := file[otfHeaderLength+directoryEntryLength*j:]
+			be.PutUint32(entry[8:], be.Uint32(entry[8:])+uint32(base))
+		}
+		out = append(out, file...)
+	}
+	return out
+}
+
+func TestCollection(t *testing.T) {
+	var files [][]byte
+	var fonts []*Font
+	for _, file := range []string{
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Castoro-Regular.ttf",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, b)
+		fonts = append(fonts, font)
+	}
+
+	ttc := buildCollection(files...)
+	collection, err := ParseCollection(bytes.NewReader(ttc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.NumFonts() != 2 {
+		t.Fatalf("expected 2 fonts, got %d", collection.NumFonts())
+	}
+	for i, exp := range fonts {
+		font, err := collection.Font(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range exp.Tags() {
+			t1, err := exp.Table(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t2, err := font.Table(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(t1.Bytes(), t2.Bytes()) {
+				t.Errorf("font %d: table %s differs", i, tag)
+			}
+		}
+	}
+	if _, err := collection.Font(2); err == nil {
+		t.Error("expected error for invalid index")
+	}
+
+	// Parse returns the first font
+	font, err := Parse(bytes.NewReader(ttc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(font.Tags()) != len(fonts[0].Tags()) {
+		t.Error("unexpected font returned by Parse")
+	}
+}
+
+func TestWriteCollection(t *testing.T) {
+	var fonts []*Font
+	var totalSize int
+	for _, file := range []string{
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Castoro-Regular.ttf",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fonts = append(fonts, font)
+		totalSize += len(b)
+	}
+
+	var buf bytes.Buffer
+	n, err := WriteCollection(&buf, fonts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(n) != buf.Len() {
+		t.Errorf("WriteCollection returned %d, but wrote %d bytes", n, buf.Len())
+	}
+	if buf.Len() > totalSize*3/4 {
+		t.Errorf("tables are not shared: %d bytes (for %d)", buf.Len(), totalSize)
+	}
+
+	collection, err := ParseCollection(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.NumFonts() != len(fonts) {
+		t.Fatalf("expected %d fonts, got %d", len(fonts), collection.NumFonts())
+	}
+	for i, exp := range fonts {
+		font, err := collection.Font(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range exp.Tags() {
+			if tag == TagHead { // checkSumAdjustment is updated
+				continue
+			}
+			t1, _ := exp.Table(tag)
+			t2, err := font.Table(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(t1.Bytes(), t2.Bytes()) {
+				t.Errorf("font %d: table %s differs", i, tag)
+			}
+		}
+	}
+}
+
+func TestWriteCollectionChecksums(t *testing.T) {
+	var fonts []*Font
+	for _, file := range []string{"Roboto-BoldItalic.ttf", "Castoro-Regular.ttf"} {
+		fonts = append(fonts, loadTestFont(t, file))
+	}
+	var buf bytes.Buffer
+	if _, err := WriteCollection(&buf, fonts); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	collection, err := ParseCollection(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fonts {
+		font, err := collection.Font(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mismatches, err := font.VerifyChecksums()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mismatches) != 0 {
+			t.Errorf("font %d: unexpected checksum mismatches %v", i, mismatches)
+		}
+
+		// the whole font checksum, computed from the table directory
+		offset := int(be.Uint32(file[12+4*i:]))
+		directory := file[offset : offset+otfHeaderLength+directoryEntryLength*len(font.Tags())]
+		whole := checkSum(directory)
+		for _, tag := range font.Tags() {
+			whole += font.tables[tag].checksum
+		}
+		head, err := font.HeadTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if whole+head.CheckSumAdjustment != 0xB1B0AFBA {
+			t.Errorf("font %d: invalid checkSumAdjustment %#x", i, head.CheckSumAdjustment)
+		}
+	}
+}
+
+func TestCollectionSharedTables(t *testing.T) {
+	var fonts []*Font
+	for _, file := range []string{
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Castoro-Regular.ttf",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fonts = append(fonts, font)
+	}
+	var buf bytes.Buffer
+	if _, err := WriteCollection(&buf, fonts); err != nil {
+		t.Fatal(err)
+	}
+	collection, err := ParseCollection(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os2Tables := make([]Table, collection.NumFonts())
+	for i := range os2Tables {
+		font, err := collection.Font(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os2Tables[i], err = font.Table(TagOS2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if os2Tables[0] != os2Tables[1] {
+		t.Error("identical tables should be parsed once")
+	}
+	if os2Tables[0] == os2Tables[2] {
+		t.Error("different tables should not be shared")
+	}
+
+	// adding a table only affects one font
+	font, err := collection.Font(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagOS2, &TableOS2{})
+	other, err := collection.Font(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table, _ := other.Table(TagOS2); table != os2Tables[1] {
+		t.Error("unexpected table")
+	}
+}
+
+func TestCollectionConcurrent(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, err := ParseCollection(bytes.NewReader(buildCollection(b, b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fonts [2]*Font
+	for i := range fonts {
+		fonts[i], err = collection.Font(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// load the tables, which are then shared
+	for _, font := range fonts {
+		for _, tag := range font.Tags() {
+			if _, err := font.Table(tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	head, _ := fonts[0].HeadTable()
+	expected := *head
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2*len(fonts))
+	for i, font := range fonts {
+		wg.Add(2)
+		go func(i int, font *Font) {
+			defer wg.Done()
+			_, errs[2*i] = font.WriteTo(ioutil.Discard)
+		}(i, font)
+		go func(i int, font *Font) {
+			defer wg.Done()
+			_, errs[2*i+1] = font.GposKernStats()
+		}(i, font)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *head != expected {
+		t.Error("writing a font should not modify its shared 'head' table")
+	}
+}
@@ -90,8 +90,6 @@ func parseWOFF(file File) (*Font, error) {
 			return nil, err
 		}
 
-		// TODO Check the checksum.
-
 		if _, found := font.tables[entry.Tag]; found {
 			return nil, fmt.Errorf("found multiple %q tables", entry.Tag)
 		}
@@ -102,6 +100,9 @@ func parseWOFF(file File) (*Font, error) {
 			offset:  entry.Offset,
 			length:  entry.CompLength,
 			zLength: entry.OrigLength,
+
+			checksum:    entry.OrigChecksum,
+			hasChecksum: true,
 		}
 	}
 
@@ -0,0 +1,70 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// ParsePDFFont parses a font program embedded in a PDF file, as found
+// in the FontFile2 (TrueType) and FontFile3 (bare CFF or OpenType)
+// streams of the font descriptors.
+//
+// The format is detected from the content, since PDF producers do not
+// always use the right key. The sfnt fonts are parsed leniently: the
+// required tables may be missing (PDF subsets commonly drop 'cmap',
+// 'name' or 'post'), and the tables truncated at the end of the stream
+// are shortened instead of failing. The bare CFF fonts are returned as
+// by ParseCFFFont.
+//
+// ErrUnsupportedFormat is returned for the unknown formats and for
+// the Type 1 fonts (FontFile streams), which are parsed by the
+// ParsePDFFont function of the type1 package.
+func ParsePDFFont(data []byte) (*Font, error) {
+	format, err := DetectFormat(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatTrueType, FormatOpenType:
+		return parsePDFSfnt(data)
+	case FormatCFF:
+		return ParseCFFFont(data)
+	case FormatCollection, FormatWOFF, FormatWOFF2:
+		return Parse(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// parsePDFSfnt is a lenient version of parseOTF.
+func parsePDFSfnt(data []byte) (*Font, error) {
+	if len(data) < otfHeaderLength {
+		return nil, ErrUnsupportedFormat
+	}
+	font := &Font{
+		file:       bytes.NewReader(data),
+		scalerType: NewTag(data),
+		tables:     make(map[Tag]*tableSection),
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		entryStart := otfHeaderLength + directoryEntryLength*i
+		if len(data) < entryStart+directoryEntryLength { // truncated directory
+			break
+		}
+		entry := data[entryStart:]
+		tag := NewTag(entry)
+		offset, length := binary.BigEndian.Uint32(entry[8:]), binary.BigEndian.Uint32(entry[12:])
+		if _, found := font.tables[tag]; found || int64(offset) >= int64(len(data)) {
+			continue
+		}
+		if available := uint32(len(data)) - offset; length > available {
+			length = available
+		}
+		font.tables[tag] = &tableSection{tag: tag, offset: offset, length: length}
+	}
+	if len(font.tables) == 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	return font, nil
+}
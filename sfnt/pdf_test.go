@@ -0,0 +1,58 @@
+package sfnt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestParsePDFFont(t *testing.T) {
+	ttf, err := ioutil.ReadFile("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last table (GSUB) is truncated
+	font, err := ParsePDFFont(ttf[:330000])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := font.HeadTable(); err != nil {
+		t.Fatal(err)
+	}
+	gsub, err := font.findTableBuffer(font.tables[TagGsub])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gsub) != 330000-321048 {
+		t.Errorf("unexpected truncated table length %d", len(gsub))
+	}
+
+	f, err := os.Open("testdata/Raleway-v4020-Regular.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	otf, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cff, err := otf.CFFTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err = ParsePDFFont(cff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare, err := font.CFFTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.FontName != cff.FontName || len(bare.Charset) != len(cff.Charset) {
+		t.Errorf("unexpected CFF font %s", bare.FontName)
+	}
+
+	if _, err := ParsePDFFont([]byte("%!PS-AdobeFont-1.0: Test 001.000\n")); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat for Type1 fonts, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package sfnt
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReadError is the error returned by a TableReader when
+// reading beyond the end of the content.
+type ReadError struct {
+	Tag    Tag
+	Offset int // offset of the failing read, from the start of the table
+	Size   int // size of the failing read
+	Length int // length of the table
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("invalid %q table: reading %d byte(s) at offset %d, beyond the end (%d bytes)",
+		e.Tag, e.Size, e.Offset, e.Length)
+}
+
+// Unwrap returns io.ErrUnexpectedEOF.
+func (e *ReadError) Unwrap() error { return io.ErrUnexpectedEOF }
+
+// TableReader reads the big-endian values of a table, checking the bounds
+// of each read, so that the parsers registered with RegisterTableParser are
+// protected against truncated or malicious content.
+// The first error is kept (see Err): the reads after it return zero values,
+// so that the error may only be checked once, after a group of reads.
+type TableReader struct {
+	tag  Tag
+	data []byte // the whole table
+	pos  int
+	err  error
+}
+
+// NewTableReader returns a reader positioned at the start of content,
+// the tag being used in the error messages.
+func NewTableReader(tag Tag, content []byte) *TableReader {
+	return &TableReader{tag: tag, data: content}
+}
+
+// Err returns the first error (a *ReadError) encountered, if any.
+func (r *TableReader) Err() error { return r.err }
+
+// Pos returns the current offset, from the start of the table.
+func (r *TableReader) Pos() int { return r.pos }
+
+// Len returns the number of bytes after the current offset.
+func (r *TableReader) Len() int { return len(r.data) - r.pos }
+
+// Seek moves to the given offset, from the start of the table, which
+// is typically read from the table.
+func (r *TableReader) Seek(offset int) {
+	if r.err != nil {
+		return
+	}
+	if offset < 0 || offset > len(r.data) {
+		r.fail(offset, 0)
+		return
+	}
+	r.pos = offset
+}
+
+// Skip advances the current offset by n bytes.
+func (r *TableReader) Skip(n int) { r.read(n) }
+
+func (r *TableReader) fail(offset, size int) {
+	r.err = &ReadError{Tag: r.tag, Offset: offset, Size: size, Length: len(r.data)}
+}
+
+// read returns the next n bytes, or nil on error.
+func (r *TableReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data)-r.pos {
+		r.fail(r.pos, n)
+		return nil
+	}
+	out := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return out
+}
+
+// Bytes returns the next n bytes, without copying them.
+func (r *TableReader) Bytes(n int) []byte { return r.read(n) }
+
+// Uint8 reads an unsigned 8-bit integer.
+func (r *TableReader) Uint8() uint8 {
+	if b := r.read(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// Uint16 reads an unsigned 16-bit integer.
+func (r *TableReader) Uint16() uint16 {
+	if b := r.read(2); b != nil {
+		return be.Uint16(b)
+	}
+	return 0
+}
+
+// Int16 reads a signed 16-bit integer (such as a FWORD).
+func (r *TableReader) Int16() int16 { return int16(r.Uint16()) }
+
+// Uint32 reads an unsigned 32-bit integer (such as an Offset32).
+func (r *TableReader) Uint32() uint32 {
+	if b := r.read(4); b != nil {
+		return be.Uint32(b)
+	}
+	return 0
+}
+
+// Int32 reads a signed 32-bit integer.
+func (r *TableReader) Int32() int32 { return int32(r.Uint32()) }
+
+// Fixed reads a 16.16 fixed number.
+func (r *TableReader) Fixed() float32 { return fixedToFloat(r.Uint32()) }
+
+// F2Dot14 reads a 2.14 fixed number.
+func (r *TableReader) F2Dot14() float32 { return f2dot14ToFloat(r.Uint16()) }
+
+// Tag reads a tag.
+func (r *TableReader) Tag() Tag {
+	if b := r.read(4); b != nil {
+		return NewTag(b)
+	}
+	return Tag{}
+}
+
+// Uint16s reads an array of n unsigned 16-bit integers.
+func (r *TableReader) Uint16s(n int) []uint16 {
+	b := r.read(2 * n)
+	if b == nil {
+		return nil
+	}
+	out := make([]uint16, n)
+	for i := range out {
+		out[i] = be.Uint16(b[2*i:])
+	}
+	return out
+}
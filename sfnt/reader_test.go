@@ -0,0 +1,55 @@
+package sfnt
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestTableReader(t *testing.T) {
+	tag := MustNamedTag("Xtst")
+	r := NewTableReader(tag, []byte{
+		0, 1, 0, 0, // fixed 1.0
+		0xFF, 0xFE, // -2
+		'a', 'b', 'c', 'd',
+		0, 1, 0, 2, // array
+		0x40, 0, // 1.0 in 2.14
+	})
+	if v := r.Fixed(); v != 1 {
+		t.Errorf("expected 1, got %g", v)
+	}
+	if v := r.Int16(); v != -2 {
+		t.Errorf("expected -2, got %d", v)
+	}
+	if v := r.Tag(); v != MustNamedTag("abcd") {
+		t.Errorf("unexpected tag %s", v)
+	}
+	if v := r.Uint16s(2); !reflect.DeepEqual(v, []uint16{1, 2}) {
+		t.Errorf("unexpected array %v", v)
+	}
+	if v := r.F2Dot14(); v != 1 || r.Len() != 0 || r.Err() != nil {
+		t.Errorf("unexpected state %g %d %v", v, r.Len(), r.Err())
+	}
+
+	r.Seek(4)
+	if v := r.Uint16(); v != 0xFFFE || r.Pos() != 6 {
+		t.Errorf("unexpected value %d at %d", v, r.Pos())
+	}
+
+	// the errors are sticky
+	r.Skip(8)
+	if v := r.Uint32(); v != 0 {
+		t.Errorf("expected 0, got %d", v)
+	}
+	if v := r.Uint16(); v != 0 {
+		t.Errorf("expected 0, got %d", v)
+	}
+	var readErr *ReadError
+	if err := r.Err(); !errors.As(err, &readErr) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if expected := (ReadError{Tag: tag, Offset: 14, Size: 4, Length: 16}); *readErr != expected {
+		t.Errorf("expected %v, got %v", expected, *readErr)
+	}
+}
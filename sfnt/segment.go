@@ -0,0 +1,280 @@
+package sfnt
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var errInvalidGSUBSubtable = errors.New("invalid GSUB lookup subtable")
+
+// GSUB lookup types supported by Segment
+const (
+	gsubSingle    = 1
+	gsubMultiple  = 2
+	gsubLigature  = 4
+	gsubExtension = 7
+)
+
+// features applied by Segment by default
+var segmentFeatures = []Tag{
+	MustNamedTag("ccmp"), MustNamedTag("locl"), MustNamedTag("rlig"),
+	MustNamedTag("liga"), MustNamedTag("clig"),
+}
+
+// GlyphCluster maps a range of text to the glyphs it produces.
+// A cluster can't be split: a ligature and the characters it
+// replaces belong to the same cluster, for instance.
+type GlyphCluster struct {
+	Start, End int          // byte offsets of the text, End excluded
+	Glyphs     []GlyphIndex // glyphs of the cluster, in logical order
+}
+
+// segmentGlyph is a glyph, with the range of text it comes from.
+type segmentGlyph struct {
+	glyph      GlyphIndex
+	start, end int
+}
+
+// Segment converts text to glyphs with the 'cmap' table, then applies the
+// single, multiple and ligature substitutions of the given GSUB features,
+// selected for the script and language as in KernTableForScript.
+// If no feature is given, the 'ccmp', 'locl', 'rlig', 'liga' and 'clig'
+// features are applied.
+//
+// The returned clusters cover the whole text, in logical order: the
+// characters missing from the font are mapped to the .notdef glyph.
+// Contextual substitutions are not applied, and the lookup flags are ignored.
+func (font *Font) Segment(text string, script, language Tag, features ...Tag) ([]GlyphCluster, error) {
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]segmentGlyph, 0, len(text))
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		buffer = append(buffer, segmentGlyph{glyph: cmap.Lookup(r), start: i, end: i + size})
+		i += size
+	}
+
+	if font.HasTable(TagGsub) {
+		gsub, err := font.GsubTable()
+		if err != nil {
+			return nil, err
+		}
+		if len(features) == 0 {
+			features = segmentFeatures
+		}
+		for _, lookup := range gsub.featureLookups(script, language, features...) {
+			buffer, err = applySubstitution(lookup, buffer)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return segmentClusters(buffer), nil
+}
+
+// segmentClusters merges the glyphs whose text ranges overlap.
+func segmentClusters(buffer []segmentGlyph) []GlyphCluster {
+	var out []GlyphCluster
+	for _, g := range buffer {
+		if n := len(out); n != 0 && g.start < out[n-1].End {
+			last := &out[n-1]
+			last.Glyphs = append(last.Glyphs, g.glyph)
+			if g.end > last.End {
+				last.End = g.end
+			}
+			continue
+		}
+		out = append(out, GlyphCluster{Start: g.start, End: g.end, Glyphs: []GlyphIndex{g.glyph}})
+	}
+	return out
+}
+
+// applySubstitution applies the lookup once, from the start of the buffer,
+// and returns the updated buffer.
+func applySubstitution(lookup *Lookup, buffer []segmentGlyph) ([]segmentGlyph, error) {
+	subtables, err := lookup.parsedSubtables()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]segmentGlyph, 0, len(buffer))
+	for i := 0; i < len(buffer); {
+		var (
+			glyphs   []GlyphIndex
+			consumed int
+		)
+		for _, st := range subtables {
+			lookupType := lookup.Type
+			if lookupType == gsubExtension {
+				lookupType, st, err = st.extensionSubtable(gsubExtension)
+				if err != nil {
+					return nil, err
+				}
+			}
+			glyphs, consumed, err = st.substitute(lookupType, buffer[i:])
+			if err != nil {
+				return nil, err
+			}
+			if consumed != 0 { // only the first matching subtable is applied
+				break
+			}
+		}
+
+		if consumed == 0 {
+			out = append(out, buffer[i])
+			i++
+			continue
+		}
+		// the substitutes share the range of the replaced glyphs
+		start, end := buffer[i].start, buffer[i].end
+		for _, g := range buffer[i+1 : i+consumed] {
+			if g.end > end {
+				end = g.end
+			}
+		}
+		for _, glyph := range glyphs {
+			out = append(out, segmentGlyph{glyph: glyph, start: start, end: end})
+		}
+		i += consumed
+	}
+	return out, nil
+}
+
+// extensionLookup is the content of an extension subtable.
+type extensionLookup struct {
+	lookupType uint16
+	subtable   *lookupSubtable
+}
+
+// extensionSubtable returns the type and the subtable referenced by an extension
+// subtable, whose lookup type is extensionType (7 in GSUB, 9 in GPOS).
+func (st *lookupSubtable) extensionSubtable(extensionType uint16) (uint16, *lookupSubtable, error) {
+	if ext, ok := st.cached().(extensionLookup); ok {
+		return ext.lookupType, ext.subtable, nil
+	}
+	if len(st.data) < 8 {
+		return 0, nil, errInvalidLookupSubtable
+	}
+	lookupType, offset := be.Uint16(st.data[2:]), be.Uint32(st.data[4:])
+	if lookupType == extensionType || uint64(len(st.data)) < uint64(offset)+2 {
+		return 0, nil, errInvalidLookupSubtable
+	}
+	data := st.data[offset:]
+	ext := st.cache(extensionLookup{lookupType, &lookupSubtable{format: be.Uint16(data), data: data}}).(extensionLookup)
+	return ext.lookupType, ext.subtable, nil
+}
+
+// substitute applies the subtable at the start of the buffer, and
+// returns the substitutes and the number of glyphs they replace,
+// which is 0 if the subtable does not apply.
+func (st *lookupSubtable) substitute(lookupType uint16, buffer []segmentGlyph) ([]GlyphIndex, int, error) {
+	switch lookupType {
+	case gsubSingle, gsubMultiple, gsubLigature:
+	default: // not supported
+		return nil, 0, nil
+	}
+
+	cov, err := st.fetchCoverage(2)
+	if err != nil {
+		return nil, 0, err
+	}
+	index, ok := cov.tableIndex(buffer[0].glyph)
+	if !ok {
+		return nil, 0, nil
+	}
+
+	data := st.data
+	switch {
+	case lookupType == gsubSingle && st.format == 1:
+		if len(data) < 6 {
+			return nil, 0, errInvalidGSUBSubtable
+		}
+		delta := be.Uint16(data[4:]) // modulo 65536
+		return []GlyphIndex{buffer[0].glyph + GlyphIndex(delta)}, 1, nil
+	case lookupType == gsubSingle && st.format == 2:
+		if len(data) < 6+2*index+2 {
+			return nil, 0, errInvalidGSUBSubtable
+		}
+		return []GlyphIndex{GlyphIndex(be.Uint16(data[6+2*index:]))}, 1, nil
+	case lookupType == gsubMultiple && st.format == 1:
+		sequence, err := offsetArray(data, index)
+		if err != nil {
+			return nil, 0, err
+		}
+		count := int(be.Uint16(sequence))
+		if len(sequence) < 2+2*count {
+			return nil, 0, errInvalidGSUBSubtable
+		}
+		return readGlyphs(sequence[2:], count), 1, nil
+	case lookupType == gsubLigature && st.format == 1:
+		return substituteLigature(data, index, buffer)
+	}
+	return nil, 0, nil
+}
+
+// substituteLigature applies the first ligature of the set at index
+// matching the buffer.
+func substituteLigature(data []byte, index int, buffer []segmentGlyph) ([]GlyphIndex, int, error) {
+	ligatureSet, err := offsetArray(data, index)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := int(be.Uint16(ligatureSet))
+	if len(ligatureSet) < 2+2*count {
+		return nil, 0, errInvalidGSUBSubtable
+	}
+	for i := 0; i < count; i++ {
+		offset := int(be.Uint16(ligatureSet[2+2*i:]))
+		if len(ligatureSet) < offset+4 {
+			return nil, 0, errInvalidGSUBSubtable
+		}
+		ligature := ligatureSet[offset:]
+		glyph, componentCount := GlyphIndex(be.Uint16(ligature)), int(be.Uint16(ligature[2:]))
+		if componentCount == 0 || len(ligature) < 4+2*(componentCount-1) {
+			return nil, 0, errInvalidGSUBSubtable
+		}
+		if componentCount > len(buffer) {
+			continue
+		}
+		// the first component is the covered glyph
+		components := readGlyphs(ligature[4:], componentCount-1)
+		matched := true
+		for j, component := range components {
+			if buffer[1+j].glyph != component {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return []GlyphIndex{glyph}, componentCount, nil
+		}
+	}
+	return nil, 0, nil
+}
+
+// offsetArray returns the table at the index-th offset of the
+// array starting at data[4:], preceded by its count.
+// The returned table has at least 2 bytes.
+func offsetArray(data []byte, index int) ([]byte, error) {
+	if len(data) < 6 || index >= int(be.Uint16(data[4:])) || len(data) < 6+2*index+2 {
+		return nil, errInvalidGSUBSubtable
+	}
+	offset := int(be.Uint16(data[6+2*index:]))
+	if len(data) < offset+2 {
+		return nil, errInvalidGSUBSubtable
+	}
+	return data[offset:], nil
+}
+
+// readGlyphs reads count glyph indexes, which must fit in data.
+func readGlyphs(data []byte, count int) []GlyphIndex {
+	out := make([]GlyphIndex, count)
+	for i := range out {
+		out[i] = GlyphIndex(be.Uint16(data[2*i:]))
+	}
+	return out
+}
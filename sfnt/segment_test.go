@@ -0,0 +1,59 @@
+package sfnt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	f, err := os.Open("testdata/Raleway-v4020-Regular.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clusters, err := font.Segment("fiffle é", scriptLatin, Tag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []GlyphCluster{
+		{0, 2, []GlyphIndex{473}}, // fi
+		{2, 5, []GlyphIndex{472}}, // ffl
+		{5, 6, []GlyphIndex{270}},
+		{6, 7, []GlyphIndex{843}},
+		{7, 9, []GlyphIndex{271}}, // é is two bytes long
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("expected %v, got %v", expected, clusters)
+	}
+
+	// without ligatures, there is one cluster by character
+	clusters, err = font.Segment("fiffle", scriptLatin, Tag{}, MustNamedTag("ccmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 6 {
+		t.Errorf("expected 6 clusters, got %v", clusters)
+	}
+}
+
+func TestSegmentClusters(t *testing.T) {
+	// a decomposed glyph, whose second part forms a ligature with the next one
+	buffer := []segmentGlyph{
+		{1, 0, 2},
+		{2, 0, 3},
+		{3, 3, 4},
+	}
+	expected := []GlyphCluster{
+		{0, 3, []GlyphIndex{1, 2}},
+		{3, 4, []GlyphIndex{3}},
+	}
+	if got := segmentClusters(buffer); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
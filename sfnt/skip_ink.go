@@ -0,0 +1,154 @@
+package sfnt
+
+import "sort"
+
+// maximum distance between the curves and their approximation, in font units
+const skipInkTolerance = 0.1
+
+// PositionedGlyph is a glyph of a shaped run, with the position
+// of its origin, in font units.
+type PositionedGlyph struct {
+	Glyph GlyphIndex
+	X, Y  float32
+}
+
+// InkInterval is an horizontal interval [Start, End], in font units.
+type InkInterval struct {
+	Start, End float32
+}
+
+// UnderlineIntersections returns the horizontal intervals where the
+// outlines of the glyphs of the run cross the underline rectangle
+// spanning vertically from bottom to top, so that renderers may
+// interrupt the underline around the descenders ('skip-ink'), usually
+// after widening the intervals by some clearance.
+// The underline suggested by the 'post' table spans from
+// UnderlinePosition - UnderlineThickness to UnderlinePosition.
+// The intervals are sorted and don't overlap. Only the fonts with
+// TrueType outlines are supported.
+// The curves are approximated by line segments, so that the intervals
+// may be slightly off (by less than a font unit for usual glyph sizes).
+func (font *Font) UnderlineIntersections(run []PositionedGlyph, bottom, top float32) ([]InkInterval, error) {
+	var out []InkInterval
+	for _, pg := range run {
+		outline, err := font.GlyphOutline(pg.Glyph)
+		if err != nil {
+			return nil, err
+		}
+		for _, interval := range outlineBandIntervals(outline.Flatten(skipInkTolerance), bottom-pg.Y, top-pg.Y) {
+			out = append(out, InkInterval{Start: interval.Start + pg.X, End: interval.End + pg.X})
+		}
+	}
+	return mergeInkIntervals(out), nil
+}
+
+// outlineBandIntervals returns the horizontal extent of the ink
+// of the polygons between the given heights. The result is not merged.
+func outlineBandIntervals(polygons [][]Point, bottom, top float32) []InkInterval {
+	var out []InkInterval
+	// the heights where the polygons are sampled: the band limits and
+	// the vertices in the band, to capture the shape between them
+	ys := []float32{bottom, top}
+	for _, polygon := range polygons {
+		for i, p := range polygon {
+			q := polygon[(i+1)%len(polygon)]
+			if piece, ok := clipSegment(p, q, bottom, top); ok {
+				out = append(out, piece)
+			}
+			if bottom < p.Y && p.Y < top {
+				ys = append(ys, p.Y)
+			}
+		}
+	}
+	sort.Slice(ys, func(i, j int) bool { return ys[i] < ys[j] })
+	for i, y := range ys {
+		out = append(out, scanline(polygons, y)...)
+		if i+1 < len(ys) {
+			out = append(out, scanline(polygons, (y+ys[i+1])/2)...)
+		}
+	}
+	return out
+}
+
+// clipSegment returns the horizontal extent of the part of the
+// segment [p, q] between the given heights, or false if there is none.
+func clipSegment(p, q Point, bottom, top float32) (InkInterval, bool) {
+	if p.Y > q.Y {
+		p, q = q, p
+	}
+	if q.Y < bottom || p.Y > top {
+		return InkInterval{}, false
+	}
+	xAt := func(y float32) float32 {
+		if q.Y == p.Y {
+			return p.X
+		}
+		return p.X + (q.X-p.X)*(y-p.Y)/(q.Y-p.Y)
+	}
+	start, end := p.X, q.X
+	if p.Y < bottom {
+		start = xAt(bottom)
+	}
+	if q.Y > top {
+		end = xAt(top)
+	}
+	if start > end {
+		start, end = end, start
+	}
+	return InkInterval{start, end}, true
+}
+
+// scanline returns the intervals of the horizontal line at y which are
+// inside the polygons, according to the non zero winding rule.
+func scanline(polygons [][]Point, y float32) []InkInterval {
+	type crossing struct {
+		x   float32
+		dir int
+	}
+	var crossings []crossing
+	for _, polygon := range polygons {
+		for i, p := range polygon {
+			q := polygon[(i+1)%len(polygon)]
+			dir := 1
+			if p.Y > q.Y {
+				p, q, dir = q, p, -1
+			}
+			if y < p.Y || y >= q.Y { // half open, to count the vertices once
+				continue
+			}
+			crossings = append(crossings, crossing{p.X + (q.X-p.X)*(y-p.Y)/(q.Y-p.Y), dir})
+		}
+	}
+	sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+
+	var (
+		out     []InkInterval
+		winding int
+	)
+	for _, c := range crossings {
+		if winding == 0 {
+			out = append(out, InkInterval{Start: c.x})
+		}
+		winding += c.dir
+		if winding == 0 {
+			out[len(out)-1].End = c.x
+		}
+	}
+	return out
+}
+
+// mergeInkIntervals sorts and merges the overlapping intervals.
+func mergeInkIntervals(intervals []InkInterval) []InkInterval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start < intervals[j].Start })
+	var out []InkInterval
+	for _, interval := range intervals {
+		if n := len(out); n != 0 && interval.Start <= out[n-1].End {
+			if interval.End > out[n-1].End {
+				out[n-1].End = interval.End
+			}
+			continue
+		}
+		out = append(out, interval)
+	}
+	return out
+}
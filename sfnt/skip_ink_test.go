@@ -0,0 +1,69 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnderlineIntersections(t *testing.T) {
+	font, err := NewLastResort("Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the .notdef box spans [50, 450] x [0, 700], with a [100, 400] x [50, 650] hole
+	run := []PositionedGlyph{{0, 0, 0}, {0, 500, 0}, {1, 1000, 0}, {0, 1250, 400}}
+	for _, test := range []struct {
+		bottom, top float32
+		expected    []InkInterval
+	}{
+		{-10, 20, []InkInterval{{50, 450}, {550, 950}}},
+		{300, 320, []InkInterval{{50, 100}, {400, 450}, {550, 600}, {900, 950}}},
+		{-100, -50, nil},
+	} {
+		intervals, err := font.UnderlineIntersections(run, test.bottom, test.top)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(intervals, test.expected) {
+			t.Errorf("band [%g, %g]: expected %v, got %v", test.bottom, test.top, test.expected, intervals)
+		}
+	}
+
+	// curves
+	font = loadTestFont(t, "Roboto-BoldItalic.ttf")
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := font.PostTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gi := cmap.Lookup('g')
+	outline, err := font.GlyphOutline(gi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xMin, xMax := outline.Points[0].X, outline.Points[0].X
+	for _, p := range outline.Points {
+		if p.X < xMin {
+			xMin = p.X
+		}
+		if p.X > xMax {
+			xMax = p.X
+		}
+	}
+	top := float32(post.UnderlinePosition)
+	intervals, err := font.UnderlineIntersections([]PositionedGlyph{{Glyph: gi}}, top-float32(post.UnderlineThickness), top)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) == 0 {
+		t.Fatal("expected the descender of 'g' to cross the underline")
+	}
+	for _, interval := range intervals {
+		if interval.Start < xMin || interval.End > xMax || interval.Start > interval.End {
+			t.Errorf("invalid interval %v for the bounds [%g, %g]", interval, xMin, xMax)
+		}
+	}
+}
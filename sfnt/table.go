@@ -2,16 +2,48 @@ package sfnt
 
 import (
 	"compress/zlib"
+	"errors"
+	"fmt"
 	"io"
+	"sync"
+	"time"
 )
 
 var parsers = map[Tag]tableParser{
 	TagHead: parseTableHead,
 	TagName: parseTableName,
 	TagHhea: parseTableHhea,
+	TagVhea: parseTableVhea,
 	TagOS2:  parseTableOS2,
 	TagGpos: parseTableLayout,
 	TagGsub: parseTableLayout,
+	TagFvar: parseTableFvar,
+	TagGvar: parseTableGvar,
+	TagAvar: parseTableAvar,
+	TagHvar: parseTableHvar,
+	TagMvar: parseTableMvar,
+	TagSTAT: parseTableSTAT,
+	TagCFF:  parseTableCFF,
+	TagCFF2: parseTableCFF2,
+	TagBASE: parseTableBASE,
+	TagCOLR: parseTableCOLR,
+	TagCPAL: parseTableCPAL,
+	TagSVG:  parseTableSVG,
+	TagSbix: parseTableSbix,
+	TagEBLC: parseTableEBLC,
+	TagEBSC: parseTableEBSC,
+	TagMeta: parseTableMeta,
+	TagGasp: parseTableGasp,
+	TagHdmx: parseTableHdmx,
+	TagLTSH: parseTableLTSH,
+	TagPCLT: parseTablePCLT,
+	TagVORG: parseTableVORG,
+	TagDSIG: parseTableDSIG,
+	TagFeat: parseTableFeat,
+	TagSill: parseTableSill,
+	TagTrak: parseTableTrak,
+
+	TagAATFeat: parseTableAATFeat,
 }
 
 // Table is an interface for each section of the font file.
@@ -39,7 +71,80 @@ func newUnparsedTable(tag Tag, buffer []byte) (Table, error) {
 	return &unparsedTable{baseTable(tag), buffer}, nil
 }
 
+// NewTable returns a table storing the given raw content,
+// which is written as is in the font.
+// It may be used to add tables not (yet) supported by this package.
+func NewTable(tag Tag, content []byte) Table {
+	return &unparsedTable{baseTable(tag), content}
+}
+
+var errNilTableParser = errors.New("nil table parser")
+
+var (
+	customParsersLock sync.RWMutex
+	customParsers     = map[Tag]tableParser{}
+)
+
+// RegisterTableParser registers a parser for the tables with the given tag,
+// so that Font.Table, StrictParse and ParseTable return a custom Table for
+// proprietary tables (such as 'TSIV' or vendor specific tables), instead of
+// storing them as raw bytes. The parser receives the uncompressed content of
+// the table, and the Bytes method of the returned Table is used when writing
+// the font. TableReader may be used to decode the content safely.
+// The tables parsed by this package (such as 'head') may not be overridden,
+// and a later registration replaces the previous parser of the tag.
+// Since the parsed tables are cached, the parsers should be registered
+// before the fonts are parsed, typically in an init function.
+func RegisterTableParser(tag Tag, parser func(tag Tag, content []byte) (Table, error)) error {
+	if parser == nil {
+		return errNilTableParser
+	}
+	if _, builtin := parsers[tag]; builtin {
+		return fmt.Errorf("the %q table parser can't be overridden", tag)
+	}
+	customParsersLock.Lock()
+	defer customParsersLock.Unlock()
+	customParsers[tag] = parser
+	return nil
+}
+
+// ParseTable returns the table parsed from the given raw content, as
+// when reading a font: the known tables (such as 'OS/2' or 'head')
+// and the tables registered with RegisterTableParser are parsed,
+// the other ones are stored as is.
+// It may be used to add a modified copy of a table to a font.
+func ParseTable(tag Tag, content []byte) (Table, error) {
+	parser, found := parsers[tag]
+	if !found {
+		customParsersLock.RLock()
+		parser, found = customParsers[tag]
+		customParsersLock.RUnlock()
+	}
+	if !found {
+		parser = newUnparsedTable
+	}
+	return parser(tag, content)
+}
+
 func (font *Font) findTableBuffer(s *tableSection) ([]byte, error) {
+	if s.table != nil { // table added or already parsed
+		return s.table.Bytes(), nil
+	}
+
+	hook := loadTableHook()
+	if hook == nil {
+		return font.readTableBuffer(s)
+	}
+	start := time.Now()
+	buf, err := font.readTableBuffer(s)
+	if err == nil {
+		hook(TableEvent{Tag: s.tag, Size: len(buf), Read: time.Since(start)})
+	}
+	return buf, err
+}
+
+// readTableBuffer reads the content of the table from the font file.
+func (font *Font) readTableBuffer(s *tableSection) ([]byte, error) {
 	var buf []byte
 
 	if s.length != 0 && s.length < s.zLength {
@@ -64,15 +169,25 @@ func (font *Font) findTableBuffer(s *tableSection) ([]byte, error) {
 }
 
 func (font *Font) parseTable(s *tableSection) (Table, error) {
-	buf, err := font.findTableBuffer(s)
+	hook := loadTableHook()
+	if hook == nil {
+		buf, err := font.readTableBuffer(s)
+		if err != nil {
+			return nil, err
+		}
+		return ParseTable(s.tag, buf)
+	}
+
+	start := time.Now()
+	buf, err := font.readTableBuffer(s)
 	if err != nil {
 		return nil, err
 	}
-
-	parser, found := parsers[s.tag]
-	if !found {
-		parser = newUnparsedTable
+	read := time.Now()
+	t, err := ParseTable(s.tag, buf)
+	if err != nil {
+		return nil, err
 	}
-
-	return parser(s.tag, buf)
+	hook(TableEvent{Tag: s.tag, Size: len(buf), Read: read.Sub(start), Parse: time.Since(read)})
+	return t, nil
 }
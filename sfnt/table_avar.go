@@ -0,0 +1,142 @@
+package sfnt
+
+import (
+	"errors"
+	"math"
+)
+
+var errInvalidAvarTable = errors.New("invalid avar table")
+
+// TableAvar is the axis variations table, which modifies the
+// normalization of the coordinates of a variable font.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/avar
+type TableAvar struct {
+	baseTable
+
+	bytes []byte
+
+	// Segments stores the segment map of each axis, in the order of the 'fvar' table.
+	Segments [][]AxisValueMap
+}
+
+// AxisValueMap maps a normalized coordinate to a modified one.
+type AxisValueMap struct {
+	From, To float32
+}
+
+// Bytes returns the bytes for this table. The TableAvar is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableAvar) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableAvar(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 8
+	if len(buf) < headerSize {
+		return nil, errInvalidAvarTable
+	}
+	axisCount := int(be.Uint16(buf[6:]))
+	out := &TableAvar{
+		baseTable: baseTable(tag),
+		bytes:     buf,
+		Segments:  make([][]AxisValueMap, axisCount),
+	}
+	offset := headerSize
+	for i := range out.Segments {
+		if len(buf) < offset+2 {
+			return nil, errInvalidAvarTable
+		}
+		count := int(be.Uint16(buf[offset:]))
+		offset += 2
+		if len(buf) < offset+4*count {
+			return nil, errInvalidAvarTable
+		}
+		segment := make([]AxisValueMap, count)
+		for j := range segment {
+			segment[j] = AxisValueMap{
+				From: f2dot14ToFloat(be.Uint16(buf[offset:])),
+				To:   f2dot14ToFloat(be.Uint16(buf[offset+2:])),
+			}
+			offset += 4
+		}
+		out.Segments[i] = segment
+	}
+	return out, nil
+}
+
+// mapCoordinate applies the piecewise linear mapping defined
+// by the segment to a normalized coordinate.
+func mapCoordinate(segment []AxisValueMap, coord float32) float32 {
+	// segments with less than the 3 required maps are ignored
+	if len(segment) < 3 {
+		return coord
+	}
+	if coord <= segment[0].From {
+		return segment[0].To
+	}
+	for i := 1; i < len(segment); i++ {
+		prev, next := segment[i-1], segment[i]
+		if coord > next.From {
+			continue
+		}
+		if next.From == prev.From {
+			return next.To
+		}
+		return prev.To + (coord-prev.From)*(next.To-prev.To)/(next.From-prev.From)
+	}
+	return segment[len(segment)-1].To
+}
+
+// AvarTable returns the axis variations table.
+func (font *Font) AvarTable() (*TableAvar, error) {
+	t, err := font.Table(TagAvar)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableAvar), nil
+}
+
+// NormalizeCoordinates converts coordinates in user space (such as 430 for
+// the weight), one for each axis of the 'fvar' table, to the normalized
+// coordinates in [-1, 1] used to apply the variations.
+// The mapping of the 'avar' table is applied, if present.
+// Missing coordinates use the default value of the axis.
+func (font *Font) NormalizeCoordinates(userCoords []float32) ([]float32, error) {
+	axes, err := font.VariationAxes()
+	if err != nil {
+		return nil, err
+	}
+	var avar *TableAvar
+	if font.HasTable(TagAvar) {
+		avar, err = font.AvarTable()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]float32, len(axes))
+	for i, axis := range axes {
+		if i >= len(userCoords) {
+			break
+		}
+		coord := userCoords[i]
+		if coord < axis.Min {
+			coord = axis.Min
+		} else if coord > axis.Max {
+			coord = axis.Max
+		}
+
+		switch {
+		case coord < axis.Default:
+			out[i] = (coord - axis.Default) / (axis.Default - axis.Min)
+		case coord > axis.Default:
+			out[i] = (coord - axis.Default) / (axis.Max - axis.Default)
+		}
+		if avar != nil && i < len(avar.Segments) {
+			out[i] = mapCoordinate(avar.Segments[i], out[i])
+		}
+		// the coordinates are stored as 2.14 numbers
+		out[i] = float32(math.Round(float64(out[i])*(1<<14))) / (1 << 14)
+	}
+	return out, nil
+}
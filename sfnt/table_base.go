@@ -0,0 +1,179 @@
+package sfnt
+
+import "errors"
+
+var errInvalidBASETable = errors.New("invalid BASE table")
+
+// registered baseline tags
+var (
+	// BaselineRoman is the baseline of most alphabetic scripts ('romn').
+	BaselineRoman = MustNamedTag("romn")
+	// BaselineHanging is the baseline of scripts such as Devanagari ('hang').
+	BaselineHanging = MustNamedTag("hang")
+	// BaselineIdeographic is the bottom of the ideographic em-box ('ideo').
+	BaselineIdeographic = MustNamedTag("ideo")
+	// BaselineIdeographicTop is the top of the ideographic em-box ('idtp').
+	BaselineIdeographicTop = MustNamedTag("idtp")
+	// BaselineMath is the center of the math operators ('math').
+	BaselineMath = MustNamedTag("math")
+)
+
+// TableBASE is the baseline table, which gives the position of
+// the baselines used to align the glyphs of different scripts.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/base
+type TableBASE struct {
+	baseTable
+
+	bytes []byte
+
+	// Horizontal and Vertical store the baselines for
+	// each layout direction. They may be nil.
+	Horizontal, Vertical *BaseAxis
+}
+
+// BaseAxis stores the baselines for one layout direction.
+type BaseAxis struct {
+	Scripts []BaseScript
+}
+
+// BaseScript stores the baselines of a script.
+type BaseScript struct {
+	Tag Tag
+	// DefaultBaseline is the baseline used by the
+	// script, such as BaselineRoman for the Latin script.
+	DefaultBaseline Tag
+	// Baselines maps the baseline tags to their
+	// position, expressed in font units.
+	Baselines map[Tag]int16
+}
+
+// Script returns the baselines of the given script,
+// or nil if the script is not found.
+func (a *BaseAxis) Script(script Tag) *BaseScript {
+	if a == nil {
+		return nil
+	}
+	for i, s := range a.Scripts {
+		if s.Tag == script {
+			return &a.Scripts[i]
+		}
+	}
+	return nil
+}
+
+// Bytes returns the bytes for this table. The TableBASE is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableBASE) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableBASE(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 8
+	if len(buf) < headerSize {
+		return nil, errInvalidBASETable
+	}
+	out := &TableBASE{baseTable: baseTable(tag), bytes: buf}
+	var err error
+	if offset := int(be.Uint16(buf[4:])); offset != 0 {
+		out.Horizontal, err = parseBaseAxis(buf, offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if offset := int(be.Uint16(buf[6:])); offset != 0 {
+		out.Vertical, err = parseBaseAxis(buf, offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func parseBaseAxis(buf []byte, offset int) (*BaseAxis, error) {
+	if len(buf) < offset+4 {
+		return nil, errInvalidBASETable
+	}
+	axis := buf[offset:]
+	tagsOffset, scriptsOffset := int(be.Uint16(axis)), int(be.Uint16(axis[2:]))
+
+	var baselines []Tag
+	if tagsOffset != 0 {
+		if len(axis) < tagsOffset+2 {
+			return nil, errInvalidBASETable
+		}
+		tagList := axis[tagsOffset:]
+		count := int(be.Uint16(tagList))
+		if len(tagList) < 2+4*count {
+			return nil, errInvalidBASETable
+		}
+		baselines = make([]Tag, count)
+		for i := range baselines {
+			baselines[i] = NewTag(tagList[2+4*i:])
+		}
+	}
+
+	if len(axis) < scriptsOffset+2 {
+		return nil, errInvalidBASETable
+	}
+	scriptList := axis[scriptsOffset:]
+	count := int(be.Uint16(scriptList))
+	const recordSize = 6
+	if len(scriptList) < 2+recordSize*count {
+		return nil, errInvalidBASETable
+	}
+	out := &BaseAxis{Scripts: make([]BaseScript, count)}
+	for i := range out.Scripts {
+		record := scriptList[2+recordSize*i:]
+		script, err := parseBaseScript(scriptList, int(be.Uint16(record[4:])), baselines)
+		if err != nil {
+			return nil, err
+		}
+		script.Tag = NewTag(record)
+		out.Scripts[i] = script
+	}
+	return out, nil
+}
+
+// parseBaseScript parses the BaseValues of the script. The
+// baselines are the tags of the BaseTagList.
+func parseBaseScript(buf []byte, offset int, baselines []Tag) (BaseScript, error) {
+	if len(buf) < offset+2 {
+		return BaseScript{}, errInvalidBASETable
+	}
+	script := buf[offset:]
+	out := BaseScript{Baselines: map[Tag]int16{}}
+	valuesOffset := int(be.Uint16(script))
+	if valuesOffset == 0 {
+		return out, nil
+	}
+
+	if len(script) < valuesOffset+4 {
+		return BaseScript{}, errInvalidBASETable
+	}
+	values := script[valuesOffset:]
+	defaultIndex, count := int(be.Uint16(values)), int(be.Uint16(values[2:]))
+	if count > len(baselines) || len(values) < 4+2*count {
+		return BaseScript{}, errInvalidBASETable
+	}
+	if defaultIndex < len(baselines) {
+		out.DefaultBaseline = baselines[defaultIndex]
+	}
+	for i := 0; i < count; i++ {
+		coordOffset := int(be.Uint16(values[4+2*i:]))
+		// the coordinate comes after the format, for all the formats
+		if len(values) < coordOffset+4 {
+			return BaseScript{}, errInvalidBASETable
+		}
+		out.Baselines[baselines[i]] = int16(be.Uint16(values[coordOffset+2:]))
+	}
+	return out, nil
+}
+
+// BASETable returns the baseline table.
+func (font *Font) BASETable() (*TableBASE, error) {
+	t, err := font.Table(TagBASE)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableBASE), nil
+}
@@ -0,0 +1,54 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBASE(t *testing.T) {
+	buf := []byte{
+		0, 1, 0, 0, // version
+		0, 8, 0, 0, // horizAxisOffset, vertAxisOffset
+		// Axis
+		0, 4, 0, 18, // baseTagListOffset, baseScriptListOffset
+		0, 3, 'h', 'a', 'n', 'g', 'i', 'd', 'e', 'o', 'r', 'o', 'm', 'n', // BaseTagList
+		// BaseScriptList
+		0, 2,
+		'd', 'e', 'v', 'a', 0, 14,
+		'l', 'a', 't', 'n', 0, 36,
+		// deva BaseScript
+		0, 6, 0, 0, 0, 0, // baseValuesOffset, defaultMinMaxOffset, baseLangSysCount
+		0, 0, 0, 2, 0, 8, 0, 12, // BaseValues: hang is the default
+		0, 1, 0x02, 0xBC, // BaseCoord: 700
+		0, 1, 0xFF, 0x38, // BaseCoord: -200
+		// latn BaseScript
+		0, 6, 0, 0, 0, 0,
+		0, 2, 0, 3, 0, 10, 0, 10, 0, 16,
+		0, 1, 0, 0, 0, 0, // BaseCoord, with an unused value
+		0, 2, 0, 0, 0, 0, 0, 0, // BaseCoord format 2: 0
+	}
+	table, err := parseTableBASE(TagBASE, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := table.(*TableBASE)
+	if base.Vertical != nil {
+		t.Error("unexpected vertical axis")
+	}
+
+	deva := base.Horizontal.Script(MustNamedTag("deva"))
+	expected := &BaseScript{
+		Tag:             MustNamedTag("deva"),
+		DefaultBaseline: BaselineHanging,
+		Baselines:       map[Tag]int16{BaselineHanging: 700, BaselineIdeographic: -200},
+	}
+	if !reflect.DeepEqual(deva, expected) {
+		t.Errorf("expected %v, got %v", expected, deva)
+	}
+	if latn := base.Horizontal.Script(scriptLatin); latn.DefaultBaseline != BaselineRoman || len(latn.Baselines) != 3 {
+		t.Errorf("unexpected latn baselines %v", latn)
+	}
+	if base.Horizontal.Script(MustNamedTag("grek")) != nil || base.Vertical.Script(scriptLatin) != nil {
+		t.Error("unexpected script")
+	}
+}
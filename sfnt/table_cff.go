@@ -0,0 +1,691 @@
+package sfnt
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+var errInvalidCFFTable = errors.New("invalid CFF table")
+
+// Top DICT operators
+const (
+	cffOpFontBBox    = 5
+	cffOpCharset     = 15
+	cffOpEncoding    = 16
+	cffOpCharStrings = 17
+	cffOpPrivate     = 18
+	cffOpFontMatrix  = 1207 // 12 7
+	cffOpROS         = 1230 // 12 30
+	cffOpFDArray     = 1236 // 12 36
+	cffOpFDSelect    = 1237 // 12 37
+)
+
+// Private DICT operators
+const (
+	cffOpSubrs   = 19
+	cffOpVsindex = 22 // CFF2 only
+)
+
+// predefined charsets and encodings
+const (
+	cffISOAdobeCharset    = 0
+	cffExpertCharset      = 1
+	cffExpertSubCharset   = 2
+	cffStandardEncodingID = 0
+	cffExpertEncodingID   = 1
+)
+
+// TableCFF is the Compact Font Format table, which stores
+// PostScript outlines.
+// Only the first font of the table is considered.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/cff
+type TableCFF struct {
+	baseTable
+
+	bytes []byte
+
+	// FontName is the PostScript name of the font.
+	FontName string
+
+	// FontMatrix maps the glyph space to the text space,
+	// usually scaling by 1/1000.
+	FontMatrix [6]float64
+
+	// FontBBox is the bounding box of the glyphs
+	// (xMin, yMin, xMax, yMax), in glyph space.
+	FontBBox [4]float64
+
+	// IsCIDKeyed is true for CID-keyed fonts, whose charset
+	// stores CIDs instead of SIDs.
+	IsCIDKeyed bool
+
+	// Registry, Ordering and Supplement identify the character
+	// collection of the CID-keyed fonts, such as Adobe-Japan1-6.
+	Registry, Ordering string
+	Supplement         int
+
+	// Charset maps each glyph to its string identifier (SID),
+	// or to its CID for CID-keyed fonts.
+	Charset []uint16
+
+	// Encoding maps the character codes to glyphs, using 0 for
+	// the unmapped codes. It is nil for CID-keyed fonts.
+	Encoding *[256]GlyphIndex
+
+	strings     [][]byte // custom strings, starting at SID 391
+	charStrings [][]byte // the Type 2 charstring of each glyph
+	globalSubrs [][]byte
+
+	// the Private DICT of each Font DICT for CID-keyed fonts,
+	// or the Private DICT of the Top DICT
+	privates []cffPrivateDict
+	fdSelect []uint16              // Font DICT index, by glyph, for CID-keyed fonts
+	glyphs   map[uint16]GlyphIndex // by CID, for CID-keyed fonts
+}
+
+// cffPrivateDict stores the values of a Private DICT
+// used by the charstrings.
+type cffPrivateDict struct {
+	subrs   [][]byte
+	vsindex int // CFF2 only
+}
+
+// Bytes returns the bytes for this table. The TableCFF is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableCFF) Bytes() []byte {
+	return t.bytes
+}
+
+// String returns the string with the given identifier, which may be
+// a standard string or a custom one, or an empty string if
+// sid is invalid.
+func (t *TableCFF) String(sid uint16) string {
+	if int(sid) < len(cffStandardStrings) {
+		return cffStandardStrings[sid]
+	}
+	sid -= uint16(len(cffStandardStrings))
+	if int(sid) < len(t.strings) {
+		return string(t.strings[sid])
+	}
+	return ""
+}
+
+// GlyphName returns the name of the glyph, as defined by the charset,
+// or an empty string if the glyph is invalid.
+// For CID-keyed fonts, the name is built from the CID, such as "cid00042".
+func (t *TableCFF) GlyphName(gi GlyphIndex) string {
+	if int(gi) >= len(t.Charset) {
+		return ""
+	}
+	if t.IsCIDKeyed {
+		if gi == 0 {
+			return ".notdef"
+		}
+		cid := strconv.Itoa(int(t.Charset[gi]))
+		for len(cid) < 5 {
+			cid = "0" + cid
+		}
+		return "cid" + cid
+	}
+	return t.String(t.Charset[gi])
+}
+
+// CID returns the CID of the glyph of a CID-keyed font,
+// or false if the font is not CID-keyed or the glyph is invalid.
+func (t *TableCFF) CID(gi GlyphIndex) (uint16, bool) {
+	if !t.IsCIDKeyed || int(gi) >= len(t.Charset) {
+		return 0, false
+	}
+	return t.Charset[gi], true
+}
+
+// GlyphIndexByCID returns the glyph of a CID-keyed font with
+// the given CID, or false if there is none.
+func (t *TableCFF) GlyphIndexByCID(cid uint16) (GlyphIndex, bool) {
+	gi, ok := t.glyphs[cid]
+	return gi, ok
+}
+
+// GlyphPath returns the outline of the glyph. The accented
+// characters built with the seac form of endchar are not supported.
+func (t *TableCFF) GlyphPath(gi GlyphIndex) (GlyphPath, error) {
+	if int(gi) >= len(t.charStrings) {
+		return nil, errInvalidCFFTable
+	}
+	ci := charstringInterpreter{globalSubrs: t.globalSubrs}
+	fd := 0
+	if t.fdSelect != nil {
+		fd = int(t.fdSelect[gi])
+	}
+	if fd < len(t.privates) {
+		ci.localSubrs = t.privates[fd].subrs
+	}
+	if _, err := ci.run(t.charStrings[gi]); err != nil {
+		return nil, err
+	}
+	return ci.path, nil
+}
+
+// cffIndex returns the items of the INDEX starting at buf[offset:],
+// and the offset of the end of the INDEX.
+func cffIndex(buf []byte, offset int) ([][]byte, int, error) {
+	return parseCFFIndex(buf, offset, 2)
+}
+
+// parseCFFIndex parses an INDEX whose count is stored on
+// countSize bytes (2 for CFF, 4 for CFF2).
+func parseCFFIndex(buf []byte, offset int, countSize int) ([][]byte, int, error) {
+	if offset < 0 || len(buf) < offset+countSize {
+		return nil, 0, errInvalidCFFTable
+	}
+	var count int
+	if countSize == 4 {
+		count = int(be.Uint32(buf[offset:]))
+	} else {
+		count = int(be.Uint16(buf[offset:]))
+	}
+	if count == 0 {
+		return nil, offset + countSize, nil
+	}
+	if len(buf) < offset+countSize+1 || count > len(buf) {
+		return nil, 0, errInvalidCFFTable
+	}
+	offSize := int(buf[offset+countSize])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, errInvalidCFFTable
+	}
+	offsetsStart := offset + countSize + 1
+	if len(buf) < offsetsStart+(count+1)*offSize {
+		return nil, 0, errInvalidCFFTable
+	}
+	readOffset := func(i int) int {
+		var out int
+		for _, b := range buf[offsetsStart+i*offSize : offsetsStart+(i+1)*offSize] {
+			out = out<<8 | int(b)
+		}
+		return out
+	}
+
+	// offsets are relative to the byte preceding the data
+	dataStart := offsetsStart + (count+1)*offSize - 1
+	out := make([][]byte, count)
+	for i := range out {
+		start, end := dataStart+readOffset(i), dataStart+readOffset(i+1)
+		if start <= dataStart || start > end || end > len(buf) {
+			return nil, 0, errInvalidCFFTable
+		}
+		out[i] = buf[start:end]
+	}
+	return out, dataStart + readOffset(count), nil
+}
+
+// cffDict maps the operators of a DICT to their operands.
+// Two-byte operators 12 x are stored as 1200 + x.
+type cffDict map[int][]float64
+
+func parseCFFDict(buf []byte) (cffDict, error) {
+	out := cffDict{}
+	var operands []float64
+	for i := 0; i < len(buf); {
+		b := int(buf[i])
+		switch {
+		case b <= 24: // operator (22 to 24 are only used by CFF2)
+			op := b
+			i++
+			if b == 12 {
+				if i >= len(buf) {
+					return nil, errInvalidCFFTable
+				}
+				op = 1200 + int(buf[i])
+				i++
+			}
+			out[op] = operands
+			operands = nil
+		case b == 28:
+			if len(buf) < i+3 {
+				return nil, errInvalidCFFTable
+			}
+			operands = append(operands, float64(int16(be.Uint16(buf[i+1:]))))
+			i += 3
+		case b == 29:
+			if len(buf) < i+5 {
+				return nil, errInvalidCFFTable
+			}
+			operands = append(operands, float64(int32(be.Uint32(buf[i+1:]))))
+			i += 5
+		case b == 30:
+			value, n, err := parseCFFReal(buf[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, value)
+			i += 1 + n
+		case 32 <= b && b <= 246:
+			operands = append(operands, float64(b-139))
+			i++
+		case 247 <= b && b <= 254:
+			if len(buf) < i+2 {
+				return nil, errInvalidCFFTable
+			}
+			if b <= 250 {
+				operands = append(operands, float64((b-247)*256+int(buf[i+1])+108))
+			} else {
+				operands = append(operands, float64(-(b-251)*256-int(buf[i+1])-108))
+			}
+			i += 2
+		default:
+			return nil, errInvalidCFFTable
+		}
+	}
+	return out, nil
+}
+
+// parseCFFReal parses a real number encoded with nibbles,
+// returning the number of bytes read.
+func parseCFFReal(buf []byte) (float64, int, error) {
+	var s []byte
+	for i, b := range buf {
+		for _, nibble := range [2]byte{b >> 4, b & 0x0F} {
+			switch {
+			case nibble <= 9:
+				s = append(s, '0'+nibble)
+			case nibble == 0xA:
+				s = append(s, '.')
+			case nibble == 0xB:
+				s = append(s, 'E')
+			case nibble == 0xC:
+				s = append(s, 'E', '-')
+			case nibble == 0xE:
+				s = append(s, '-')
+			case nibble == 0xF:
+				value, err := strconv.ParseFloat(string(s), 64)
+				if err != nil {
+					return 0, 0, errInvalidCFFTable
+				}
+				return value, i + 1, nil
+			default:
+				return 0, 0, errInvalidCFFTable
+			}
+		}
+	}
+	return 0, 0, errInvalidCFFTable
+}
+
+// int returns the first operand of op, or defaultValue.
+func (d cffDict) int(op int, defaultValue int) int {
+	if operands := d[op]; len(operands) != 0 {
+		return int(operands[0])
+	}
+	return defaultValue
+}
+
+func parseTableCFF(tag Tag, buf []byte) (Table, error) {
+	if len(buf) < 4 || buf[0] != 1 {
+		return nil, errInvalidCFFTable
+	}
+	headerSize := int(buf[2])
+	names, offset, err := cffIndex(buf, headerSize)
+	if err != nil {
+		return nil, err
+	}
+	topDicts, offset, err := cffIndex(buf, offset)
+	if err != nil {
+		return nil, err
+	}
+	stringIndex, offset, err := cffIndex(buf, offset)
+	if err != nil {
+		return nil, err
+	}
+	globalSubrs, _, err := cffIndex(buf, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 || len(topDicts) == 0 {
+		return nil, errInvalidCFFTable
+	}
+
+	topDict, err := parseCFFDict(topDicts[0])
+	if err != nil {
+		return nil, err
+	}
+	charStrings, _, err := cffIndex(buf, topDict.int(cffOpCharStrings, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &TableCFF{
+		baseTable:   baseTable(tag),
+		bytes:       buf,
+		FontName:    string(names[0]),
+		FontMatrix:  [6]float64{0.001, 0, 0, 0.001, 0, 0},
+		IsCIDKeyed:  topDict[cffOpROS] != nil,
+		strings:     stringIndex,
+		charStrings: charStrings,
+		globalSubrs: globalSubrs,
+	}
+	if matrix := topDict[cffOpFontMatrix]; len(matrix) == 6 {
+		copy(out.FontMatrix[:], matrix)
+	}
+	if bbox := topDict[cffOpFontBBox]; len(bbox) == 4 {
+		copy(out.FontBBox[:], bbox)
+	}
+	out.Charset, err = parseCFFCharset(buf, topDict.int(cffOpCharset, cffISOAdobeCharset), len(charStrings))
+	if err != nil {
+		return nil, err
+	}
+	if !out.IsCIDKeyed {
+		out.Encoding, err = parseCFFEncoding(buf, topDict.int(cffOpEncoding, cffStandardEncodingID), out.Charset)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parseCFFPrivateDict(buf, topDict, 2)
+		if err != nil {
+			return nil, err
+		}
+		out.privates = []cffPrivateDict{private}
+		return out, nil
+	}
+
+	if ros := topDict[cffOpROS]; len(ros) == 3 {
+		out.Registry, out.Ordering = out.String(uint16(ros[0])), out.String(uint16(ros[1]))
+		out.Supplement = int(ros[2])
+	}
+	out.glyphs = make(map[uint16]GlyphIndex, len(out.Charset))
+	for gi, cid := range out.Charset {
+		if _, ok := out.glyphs[cid]; !ok {
+			out.glyphs[cid] = GlyphIndex(gi)
+		}
+	}
+	out.privates, err = parseCFFFDArray(buf, topDict.int(cffOpFDArray, 0), 2)
+	if err != nil {
+		return nil, err
+	}
+	out.fdSelect = make([]uint16, len(charStrings))
+	if offset := topDict.int(cffOpFDSelect, 0); offset != 0 {
+		if err := parseCFFFDSelect(buf, offset, out.fdSelect); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// parseCFFFDArray parses the Private DICTs of the Font DICTs
+// stored in the INDEX at buf[offset:], if offset is not 0.
+func parseCFFFDArray(buf []byte, offset int, countSize int) ([]cffPrivateDict, error) {
+	if offset == 0 {
+		return nil, nil
+	}
+	fontDicts, _, err := parseCFFIndex(buf, offset, countSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]cffPrivateDict, len(fontDicts))
+	for i, data := range fontDicts {
+		fontDict, err := parseCFFDict(data)
+		if err != nil {
+			return nil, err
+		}
+		out[i], err = parseCFFPrivateDict(buf, fontDict, countSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// parseCFFPrivateDict parses the Private DICT referenced by
+// the Top DICT or a Font DICT.
+func parseCFFPrivateDict(buf []byte, dict cffDict, countSize int) (cffPrivateDict, error) {
+	var out cffPrivateDict
+	private := dict[cffOpPrivate]
+	if len(private) < 2 {
+		return out, nil
+	}
+	size, offset := int(private[0]), int(private[1])
+	if size < 0 || offset < 0 || len(buf) < offset+size {
+		return out, errInvalidCFFTable
+	}
+	privateDict, err := parseCFFDict(buf[offset : offset+size])
+	if err != nil {
+		return out, err
+	}
+	out.vsindex = privateDict.int(cffOpVsindex, 0)
+	if subrs := privateDict.int(cffOpSubrs, 0); subrs != 0 {
+		// the offset is relative to the Private DICT
+		out.subrs, _, err = parseCFFIndex(buf, offset+subrs, countSize)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// parseCFFFDSelect fills out with the Font DICT index of each glyph.
+// The format 4 is only used by CFF2.
+func parseCFFFDSelect(buf []byte, offset int, out []uint16) error {
+	if offset < 0 || offset >= len(buf) {
+		return errInvalidCFFTable
+	}
+	format := buf[offset]
+	r := NewTableReader(TagCFF, buf[offset+1:])
+	switch format {
+	case 0:
+		fds := r.Bytes(len(out))
+		if r.Err() != nil {
+			return errInvalidCFFTable
+		}
+		for gi, fd := range fds {
+			out[gi] = uint16(fd)
+		}
+	case 3, 4:
+		// ranges of glyphs, ended by a sentinel glyph
+		readFirst := func() int { return int(r.Uint16()) }
+		readFD := func() uint16 { return uint16(r.Uint8()) }
+		if format == 4 {
+			readFirst = func() int { return int(r.Uint32()) }
+			readFD = r.Uint16
+		}
+		nRanges := readFirst()
+		first := readFirst()
+		for i := 0; i < nRanges; i++ {
+			fd := readFD()
+			next := readFirst()
+			if r.Err() != nil || next < first {
+				return errInvalidCFFTable
+			}
+			for gi := first; gi < next && gi < len(out); gi++ {
+				out[gi] = fd
+			}
+			first = next
+		}
+	default:
+		return errInvalidCFFTable
+	}
+	return nil
+}
+
+func parseCFFCharset(buf []byte, offset int, numGlyphs int) ([]uint16, error) {
+	out := make([]uint16, numGlyphs)
+	switch offset {
+	case cffISOAdobeCharset:
+		for i := range out {
+			out[i] = uint16(i)
+		}
+		return out, nil
+	case cffExpertCharset, cffExpertSubCharset:
+		predefined := cffExpertSIDs[:]
+		if offset == cffExpertSubCharset {
+			predefined = cffExpertSubsetSIDs[:]
+		}
+		if numGlyphs > len(predefined) {
+			return nil, errInvalidCFFTable
+		}
+		copy(out, predefined)
+		return out, nil
+	}
+
+	if offset < 0 || offset >= len(buf) || numGlyphs == 0 {
+		return nil, errInvalidCFFTable
+	}
+	format := buf[offset]
+	buf = buf[offset+1:]
+	switch format {
+	case 0:
+		if len(buf) < 2*(numGlyphs-1) {
+			return nil, errInvalidCFFTable
+		}
+		for i := 1; i < numGlyphs; i++ {
+			out[i] = be.Uint16(buf[2*(i-1):])
+		}
+	case 1, 2:
+		rangeSize := 3
+		if format == 2 {
+			rangeSize = 4
+		}
+		for gi := 1; gi < numGlyphs; {
+			if len(buf) < rangeSize {
+				return nil, errInvalidCFFTable
+			}
+			first := int(be.Uint16(buf))
+			nLeft := int(buf[2])
+			if format == 2 {
+				nLeft = int(be.Uint16(buf[2:]))
+			}
+			buf = buf[rangeSize:]
+			for j := 0; j <= nLeft && gi < numGlyphs; j++ {
+				out[gi] = uint16(first + j)
+				gi++
+			}
+		}
+	default:
+		return nil, errInvalidCFFTable
+	}
+	return out, nil
+}
+
+func parseCFFEncoding(buf []byte, offset int, charset []uint16) (*[256]GlyphIndex, error) {
+	var out [256]GlyphIndex
+	switch offset {
+	case cffStandardEncodingID, cffExpertEncodingID:
+		predefined := &cffStandardEncoding
+		if offset == cffExpertEncodingID {
+			predefined = &cffExpertEncoding
+		}
+		glyphs := make(map[uint16]GlyphIndex, len(charset))
+		for gi, sid := range charset {
+			if _, seen := glyphs[sid]; !seen {
+				glyphs[sid] = GlyphIndex(gi)
+			}
+		}
+		for code, sid := range predefined {
+			if sid != 0 {
+				out[code] = glyphs[sid]
+			}
+		}
+		return &out, nil
+	}
+
+	if offset < 0 || len(buf)-offset < 2 {
+		return nil, errInvalidCFFTable
+	}
+	format := buf[offset]
+	buf = buf[offset+1:]
+	switch format & 0x7F {
+	case 0:
+		nCodes := int(buf[0])
+		if len(buf) < 1+nCodes {
+			return nil, errInvalidCFFTable
+		}
+		for i, code := range buf[1 : 1+nCodes] {
+			out[code] = GlyphIndex(i + 1)
+		}
+		buf = buf[1+nCodes:]
+	case 1:
+		nRanges := int(buf[0])
+		if len(buf) < 1+2*nRanges {
+			return nil, errInvalidCFFTable
+		}
+		gi := 1
+		for i := 0; i < nRanges; i++ {
+			first, nLeft := int(buf[1+2*i]), int(buf[2+2*i])
+			for code := first; code <= first+nLeft && code < 256; code++ {
+				out[code] = GlyphIndex(gi)
+				gi++
+			}
+		}
+		buf = buf[1+2*nRanges:]
+	default:
+		return nil, errInvalidCFFTable
+	}
+
+	if format&0x80 != 0 { // supplements
+		if len(buf) < 1 {
+			return nil, errInvalidCFFTable
+		}
+		nSups := int(buf[0])
+		if len(buf) < 1+3*nSups {
+			return nil, errInvalidCFFTable
+		}
+		for i := 0; i < nSups; i++ {
+			code, sid := buf[1+3*i], be.Uint16(buf[2+3*i:])
+			for gi, glyphSID := range charset {
+				if glyphSID == sid {
+					out[code] = GlyphIndex(gi)
+					break
+				}
+			}
+		}
+	}
+	return &out, nil
+}
+
+// CFFTable returns the Compact Font Format table, for
+// fonts with PostScript outlines.
+func (font *Font) CFFTable() (*TableCFF, error) {
+	t, err := font.Table(TagCFF)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableCFF), nil
+}
+
+// ParseCFF parses a bare Compact Font Format font, such as a .cff file or
+// the FontFile3 stream of a PDF file, which has the content of the 'CFF '
+// table of OpenType fonts.
+func ParseCFF(data []byte) (*TableCFF, error) {
+	table, err := parseTableCFF(TagCFF, data)
+	if err != nil {
+		return nil, err
+	}
+	return table.(*TableCFF), nil
+}
+
+// ParseCFFFont parses a bare Compact Font Format font (see ParseCFF) and
+// wraps it in a font with the 'CFF ' table, and the 'head' and 'maxp' tables
+// derived from it, so that it may be used as an OpenType font.
+// The other tables, such as 'cmap' or 'hmtx', are missing.
+func ParseCFFFont(data []byte) (*Font, error) {
+	cff, err := ParseCFF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	head := &TableHead{baseTable: baseTable(TagHead), tableHeadFields: tableHeadFields{UnitsPerEm: 1000}}
+	if scale := cff.FontMatrix[0]; scale > 0 {
+		if unitsPerEm := math.Round(1 / scale); 16 <= unitsPerEm && unitsPerEm <= 16384 {
+			head.UnitsPerEm = uint16(unitsPerEm)
+		}
+	}
+	head.XMin, head.YMin = int16(math.Floor(cff.FontBBox[0])), int16(math.Floor(cff.FontBBox[1]))
+	head.XMax, head.YMax = int16(math.Ceil(cff.FontBBox[2])), int16(math.Ceil(cff.FontBBox[3]))
+
+	// version 0.5, used by CFF fonts
+	maxp := []byte{0, 0, 0x50, 0, byte(len(cff.Charset) >> 8), byte(len(cff.Charset))}
+
+	font := New(TypeOpenType)
+	font.AddTable(TagHead, head)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, maxp))
+	font.AddTable(TagCFF, cff)
+	return font, nil
+}
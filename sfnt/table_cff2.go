@@ -0,0 +1,114 @@
+package sfnt
+
+import "errors"
+
+var errInvalidCFF2Table = errors.New("invalid CFF2 table")
+
+const (
+	cffOpVstore    = 24 // Top DICT operator, CFF2 only
+	cff2HeaderSize = 5
+)
+
+// TableCFF2 is the Compact Font Format 2 table, which stores the
+// PostScript outlines of variable fonts: the charstrings blend
+// their values using an item variation store.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/cff2
+type TableCFF2 struct {
+	baseTable
+
+	bytes []byte
+
+	globalSubrs [][]byte
+	charStrings [][]byte
+	store       itemVariationStore
+	privates    []cffPrivateDict // one per Font DICT
+	fdSelect    []uint16         // Font DICT index, by glyph
+}
+
+// Bytes returns the bytes for this table. The TableCFF2 is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableCFF2) Bytes() []byte {
+	return t.bytes
+}
+
+// NumGlyphs returns the number of charstrings in the table.
+func (t *TableCFF2) NumGlyphs() int {
+	return len(t.charStrings)
+}
+
+// GlyphPath returns the outline of the glyph, at the given normalized
+// coordinates (see NormalizeCoordinates). If coords is empty, the outline
+// of the default instance is returned.
+func (t *TableCFF2) GlyphPath(gi GlyphIndex, coords []float32) (GlyphPath, error) {
+	if int(gi) >= len(t.charStrings) {
+		return nil, errInvalidCFF2Table
+	}
+	ci := charstringInterpreter{
+		globalSubrs: t.globalSubrs,
+		cff2:        true,
+		store:       t.store,
+		coords:      coords,
+	}
+	if fd := int(t.fdSelect[gi]); fd < len(t.privates) {
+		ci.localSubrs = t.privates[fd].subrs
+		ci.vsindex = t.privates[fd].vsindex
+	}
+	if _, err := ci.run(t.charStrings[gi]); err != nil {
+		return nil, err
+	}
+	return ci.path, nil
+}
+
+func parseTableCFF2(tag Tag, buf []byte) (Table, error) {
+	if len(buf) < cff2HeaderSize || buf[0] != 2 {
+		return nil, errInvalidCFF2Table
+	}
+	headerSize, topDictLength := int(buf[2]), int(be.Uint16(buf[3:]))
+	if len(buf) < headerSize+topDictLength {
+		return nil, errInvalidCFF2Table
+	}
+	topDict, err := parseCFFDict(buf[headerSize : headerSize+topDictLength])
+	if err != nil {
+		return nil, err
+	}
+
+	out := &TableCFF2{baseTable: baseTable(tag), bytes: buf}
+	out.globalSubrs, _, err = parseCFFIndex(buf, headerSize+topDictLength, 4)
+	if err != nil {
+		return nil, err
+	}
+	out.charStrings, _, err = parseCFFIndex(buf, topDict.int(cffOpCharStrings, -1), 4)
+	if err != nil {
+		return nil, err
+	}
+	if offset := topDict.int(cffOpVstore, 0); offset != 0 {
+		// the store is preceded by its length
+		out.store, err = parseItemVariationStore(buf, offset+2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out.privates, err = parseCFFFDArray(buf, topDict.int(cffOpFDArray, 0), 4)
+	if err != nil {
+		return nil, err
+	}
+
+	out.fdSelect = make([]uint16, len(out.charStrings))
+	if offset := topDict.int(cffOpFDSelect, 0); offset != 0 {
+		if err := parseCFFFDSelect(buf, offset, out.fdSelect); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// CFF2Table returns the Compact Font Format 2 table, for
+// variable fonts with PostScript outlines.
+func (font *Font) CFF2Table() (*TableCFF2, error) {
+	t, err := font.Table(TagCFF2)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableCFF2), nil
+}
@@ -0,0 +1,136 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// buildCFFIndex builds an INDEX, with one byte offsets and a
+// count stored on countSize bytes (2 for CFF, 4 for CFF2).
+func buildCFFIndex(countSize int, items ...[]byte) []byte {
+	out := make([]byte, countSize, countSize+2+len(items))
+	out[countSize-1] = byte(len(items))
+	out = append(out, 1, 1)
+	var data []byte
+	for _, item := range items {
+		data = append(data, item...)
+		out = append(out, byte(1+len(data)))
+	}
+	return append(out, data...)
+}
+
+// cffInt32 encodes an operand on five bytes.
+func cffInt32(v int) []byte {
+	return []byte{29, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func buildTestCFF2() []byte {
+	const topDictLength = 4*5 + 1 + 1 + 2 + 2
+	globalSubrs := buildCFFIndex(4, []byte{139, 239, 5}) // 0 100 rlineto
+	store := []byte{
+		0, 30, // length
+		0, 1, 0, 0, 0, 12, 0, 1, 0, 0, 0, 22, // header
+		0, 1, 0, 1, 0, 0, 0x40, 0, 0x40, 0, // one region, peaking at 1
+		0, 0, 0, 0, 0, 1, 0, 0, // the data used by blend
+	}
+	charStrings := buildCFFIndex(4,
+		nil,
+		[]byte{
+			189, 149, 140, 16, // 50 10 1 blend
+			139, 21, // 0 rmoveto
+			247, 92, 6, // 200 hlineto
+			32, 29, // -107 callgsubr
+			32, 10, // -107 callsubr
+		},
+	)
+
+	storeOffset := cff2HeaderSize + topDictLength + len(globalSubrs)
+	charStringsOffset := storeOffset + len(store)
+	fdArrayOffset := charStringsOffset + len(charStrings)
+	fdArray := buildCFFIndex(4, []byte{143, 28, 0, 0, 18}) // private size and offset, set below
+	privateOffset := fdArrayOffset + len(fdArray)
+	fdArray[len(fdArray)-3], fdArray[len(fdArray)-2] = byte(privateOffset>>8), byte(privateOffset)
+	private := []byte{143, 19, 139, 22}                // Subrs and vsindex
+	localSubrs := buildCFFIndex(4, []byte{89, 139, 5}) // -50 0 rlineto
+	fdSelectOffset := privateOffset + len(private) + len(localSubrs)
+	fdSelect := []byte{3, 0, 1, 0, 0, 0, 0, 2}
+
+	out := []byte{2, 0, cff2HeaderSize, 0, topDictLength}
+	out = append(append(out, cffInt32(charStringsOffset)...), 17)
+	out = append(append(out, cffInt32(storeOffset)...), 24)
+	out = append(append(out, cffInt32(fdArrayOffset)...), 12, 36)
+	out = append(append(out, cffInt32(fdSelectOffset)...), 12, 37)
+	for _, part := range [][]byte{globalSubrs, store, charStrings, fdArray, private, localSubrs, fdSelect} {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func TestCFF2GlyphPath(t *testing.T) {
+	table, err := parseTableCFF2(TagCFF2, buildTestCFF2())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cff2 := table.(*TableCFF2)
+	if cff2.NumGlyphs() != 2 {
+		t.Fatalf("expected 2 glyphs, got %d", cff2.NumGlyphs())
+	}
+
+	path, err := cff2.GlyphPath(0, nil)
+	if err != nil || len(path) != 0 {
+		t.Errorf("expected an empty glyph, got %v %v", path, err)
+	}
+
+	for _, test := range []struct {
+		coords []float32
+		x      float32
+	}{
+		{nil, 50},
+		{[]float32{0.5}, 55},
+		{[]float32{1}, 60},
+		{[]float32{-1}, 50},
+	} {
+		path, err := cff2.GlyphPath(1, test.coords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := test.x
+		expected := GlyphPath{
+			{Op: MoveTo, Args: [3]Point{{x, 0}}},
+			{Op: LineTo, Args: [3]Point{{x + 200, 0}}},
+			{Op: LineTo, Args: [3]Point{{x + 200, 100}}},
+			{Op: LineTo, Args: [3]Point{{x + 150, 100}}},
+		}
+		if !reflect.DeepEqual(path, expected) {
+			t.Errorf("coords %v: expected %v, got %v", test.coords, expected, path)
+		}
+	}
+
+	if _, err := cff2.GlyphPath(2, nil); err == nil {
+		t.Error("expected an error for an invalid glyph")
+	}
+}
+
+func TestCharstringInterpreter(t *testing.T) {
+	ci := charstringInterpreter{}
+	ended, err := ci.run([]byte{
+		149, 139, 139, 21, // 10 (width) 0 0 rmoveto
+		149, 159, 169, 179, 31, // 10 20 30 40 hvcurveto
+		14, // endchar
+	})
+	if err != nil || !ended {
+		t.Fatal(err, ended)
+	}
+	expected := GlyphPath{
+		{Op: MoveTo},
+		{Op: CubeTo, Args: [3]Point{{10, 0}, {30, 30}, {30, 70}}},
+	}
+	if !reflect.DeepEqual(ci.path, expected) {
+		t.Errorf("expected %v, got %v", expected, ci.path)
+	}
+
+	ci = charstringInterpreter{cff2: true}
+	if _, err := ci.run([]byte{141, 16}); err == nil { // 2 blend
+		t.Error("expected an error for a blend with missing operands")
+	}
+}
@@ -0,0 +1,758 @@
+package sfnt
+
+// cffStandardStrings are the predefined strings of the CFF format,
+// indexed by SID.
+// See Appendix A of the Compact Font Format Specification.
+var cffStandardStrings = [...]string{
+	".notdef",
+	"space",
+	"exclam",
+	"quotedbl",
+	"numbersign",
+	"dollar",
+	"percent",
+	"ampersand",
+	"quoteright",
+	"parenleft",
+	"parenright",
+	"asterisk",
+	"plus",
+	"comma",
+	"hyphen",
+	"period",
+	"slash",
+	"zero",
+	"one",
+	"two",
+	"three",
+	"four",
+	"five",
+	"six",
+	"seven",
+	"eight",
+	"nine",
+	"colon",
+	"semicolon",
+	"less",
+	"equal",
+	"greater",
+	"question",
+	"at",
+	"A",
+	"B",
+	"C",
+	"D",
+	"E",
+	"F",
+	"G",
+	"H",
+	"I",
+	"J",
+	"K",
+	"L",
+	"M",
+	"N",
+	"O",
+	"P",
+	"Q",
+	"R",
+	"S",
+	"T",
+	"U",
+	"V",
+	"W",
+	"X",
+	"Y",
+	"Z",
+	"bracketleft",
+	"backslash",
+	"bracketright",
+	"asciicircum",
+	"underscore",
+	"quoteleft",
+	"a",
+	"b",
+	"c",
+	"d",
+	"e",
+	"f",
+	"g",
+	"h",
+	"i",
+	"j",
+	"k",
+	"l",
+	"m",
+	"n",
+	"o",
+	"p",
+	"q",
+	"r",
+	"s",
+	"t",
+	"u",
+	"v",
+	"w",
+	"x",
+	"y",
+	"z",
+	"braceleft",
+	"bar",
+	"braceright",
+	"asciitilde",
+	"exclamdown",
+	"cent",
+	"sterling",
+	"fraction",
+	"yen",
+	"florin",
+	"section",
+	"currency",
+	"quotesingle",
+	"quotedblleft",
+	"guillemotleft",
+	"guilsinglleft",
+	"guilsinglright",
+	"fi",
+	"fl",
+	"endash",
+	"dagger",
+	"daggerdbl",
+	"periodcentered",
+	"paragraph",
+	"bullet",
+	"quotesinglbase",
+	"quotedblbase",
+	"quotedblright",
+	"guillemotright",
+	"ellipsis",
+	"perthousand",
+	"questiondown",
+	"grave",
+	"acute",
+	"circumflex",
+	"tilde",
+	"macron",
+	"breve",
+	"dotaccent",
+	"dieresis",
+	"ring",
+	"cedilla",
+	"hungarumlaut",
+	"ogonek",
+	"caron",
+	"emdash",
+	"AE",
+	"ordfeminine",
+	"Lslash",
+	"Oslash",
+	"OE",
+	"ordmasculine",
+	"ae",
+	"dotlessi",
+	"lslash",
+	"oslash",
+	"oe",
+	"germandbls",
+	"onesuperior",
+	"logicalnot",
+	"mu",
+	"trademark",
+	"Eth",
+	"onehalf",
+	"plusminus",
+	"Thorn",
+	"onequarter",
+	"divide",
+	"brokenbar",
+	"degree",
+	"thorn",
+	"threequarters",
+	"twosuperior",
+	"registered",
+	"minus",
+	"eth",
+	"multiply",
+	"threesuperior",
+	"copyright",
+	"Aacute",
+	"Acircumflex",
+	"Adieresis",
+	"Agrave",
+	"Aring",
+	"Atilde",
+	"Ccedilla",
+	"Eacute",
+	"Ecircumflex",
+	"Edieresis",
+	"Egrave",
+	"Iacute",
+	"Icircumflex",
+	"Idieresis",
+	"Igrave",
+	"Ntilde",
+	"Oacute",
+	"Ocircumflex",
+	"Odieresis",
+	"Ograve",
+	"Otilde",
+	"Scaron",
+	"Uacute",
+	"Ucircumflex",
+	"Udieresis",
+	"Ugrave",
+	"Yacute",
+	"Ydieresis",
+	"Zcaron",
+	"aacute",
+	"acircumflex",
+	"adieresis",
+	"agrave",
+	"aring",
+	"atilde",
+	"ccedilla",
+	"eacute",
+	"ecircumflex",
+	"edieresis",
+	"egrave",
+	"iacute",
+	"icircumflex",
+	"idieresis",
+	"igrave",
+	"ntilde",
+	"oacute",
+	"ocircumflex",
+	"odieresis",
+	"ograve",
+	"otilde",
+	"scaron",
+	"uacute",
+	"ucircumflex",
+	"udieresis",
+	"ugrave",
+	"yacute",
+	"ydieresis",
+	"zcaron",
+	"exclamsmall",
+	"Hungarumlautsmall",
+	"dollaroldstyle",
+	"dollarsuperior",
+	"ampersandsmall",
+	"Acutesmall",
+	"parenleftsuperior",
+	"parenrightsuperior",
+	"twodotenleader",
+	"onedotenleader",
+	"zerooldstyle",
+	"oneoldstyle",
+	"twooldstyle",
+	"threeoldstyle",
+	"fouroldstyle",
+	"fiveoldstyle",
+	"sixoldstyle",
+	"sevenoldstyle",
+	"eightoldstyle",
+	"nineoldstyle",
+	"commasuperior",
+	"threequartersemdash",
+	"periodsuperior",
+	"questionsmall",
+	"asuperior",
+	"bsuperior",
+	"centsuperior",
+	"dsuperior",
+	"esuperior",
+	"isuperior",
+	"lsuperior",
+	"msuperior",
+	"nsuperior",
+	"osuperior",
+	"rsuperior",
+	"ssuperior",
+	"tsuperior",
+	"ff",
+	"ffi",
+	"ffl",
+	"parenleftinferior",
+	"parenrightinferior",
+	"Circumflexsmall",
+	"hyphensuperior",
+	"Gravesmall",
+	"Asmall",
+	"Bsmall",
+	"Csmall",
+	"Dsmall",
+	"Esmall",
+	"Fsmall",
+	"Gsmall",
+	"Hsmall",
+	"Ismall",
+	"Jsmall",
+	"Ksmall",
+	"Lsmall",
+	"Msmall",
+	"Nsmall",
+	"Osmall",
+	"Psmall",
+	"Qsmall",
+	"Rsmall",
+	"Ssmall",
+	"Tsmall",
+	"Usmall",
+	"Vsmall",
+	"Wsmall",
+	"Xsmall",
+	"Ysmall",
+	"Zsmall",
+	"colonmonetary",
+	"onefitted",
+	"rupiah",
+	"Tildesmall",
+	"exclamdownsmall",
+	"centoldstyle",
+	"Lslashsmall",
+	"Scaronsmall",
+	"Zcaronsmall",
+	"Dieresissmall",
+	"Brevesmall",
+	"Caronsmall",
+	"Dotaccentsmall",
+	"Macronsmall",
+	"figuredash",
+	"hypheninferior",
+	"Ogoneksmall",
+	"Ringsmall",
+	"Cedillasmall",
+	"questiondownsmall",
+	"oneeighth",
+	"threeeighths",
+	"fiveeighths",
+	"seveneighths",
+	"onethird",
+	"twothirds",
+	"zerosuperior",
+	"foursuperior",
+	"fivesuperior",
+	"sixsuperior",
+	"sevensuperior",
+	"eightsuperior",
+	"ninesuperior",
+	"zeroinferior",
+	"oneinferior",
+	"twoinferior",
+	"threeinferior",
+	"fourinferior",
+	"fiveinferior",
+	"sixinferior",
+	"seveninferior",
+	"eightinferior",
+	"nineinferior",
+	"centinferior",
+	"dollarinferior",
+	"periodinferior",
+	"commainferior",
+	"Agravesmall",
+	"Aacutesmall",
+	"Acircumflexsmall",
+	"Atildesmall",
+	"Adieresissmall",
+	"Aringsmall",
+	"AEsmall",
+	"Ccedillasmall",
+	"Egravesmall",
+	"Eacutesmall",
+	"Ecircumflexsmall",
+	"Edieresissmall",
+	"Igravesmall",
+	"Iacutesmall",
+	"Icircumflexsmall",
+	"Idieresissmall",
+	"Ethsmall",
+	"Ntildesmall",
+	"Ogravesmall",
+	"Oacutesmall",
+	"Ocircumflexsmall",
+	"Otildesmall",
+	"Odieresissmall",
+	"OEsmall",
+	"Oslashsmall",
+	"Ugravesmall",
+	"Uacutesmall",
+	"Ucircumflexsmall",
+	"Udieresissmall",
+	"Yacutesmall",
+	"Thornsmall",
+	"Ydieresissmall",
+	"001.000",
+	"001.001",
+	"001.002",
+	"001.003",
+	"Black",
+	"Bold",
+	"Book",
+	"Light",
+	"Medium",
+	"Regular",
+	"Roman",
+	"Semibold",
+}
+
+// cffStandardEncoding maps the character codes to the SID of the glyph names,
+// for the predefined Standard Encoding.
+// See Appendix B of the Compact Font Format Specification.
+var cffStandardEncoding = [256]uint16{
+	32:  1,   // space
+	33:  2,   // exclam
+	34:  3,   // quotedbl
+	35:  4,   // numbersign
+	36:  5,   // dollar
+	37:  6,   // percent
+	38:  7,   // ampersand
+	39:  8,   // quoteright
+	40:  9,   // parenleft
+	41:  10,  // parenright
+	42:  11,  // asterisk
+	43:  12,  // plus
+	44:  13,  // comma
+	45:  14,  // hyphen
+	46:  15,  // period
+	47:  16,  // slash
+	48:  17,  // zero
+	49:  18,  // one
+	50:  19,  // two
+	51:  20,  // three
+	52:  21,  // four
+	53:  22,  // five
+	54:  23,  // six
+	55:  24,  // seven
+	56:  25,  // eight
+	57:  26,  // nine
+	58:  27,  // colon
+	59:  28,  // semicolon
+	60:  29,  // less
+	61:  30,  // equal
+	62:  31,  // greater
+	63:  32,  // question
+	64:  33,  // at
+	65:  34,  // A
+	66:  35,  // B
+	67:  36,  // C
+	68:  37,  // D
+	69:  38,  // E
+	70:  39,  // F
+	71:  40,  // G
+	72:  41,  // H
+	73:  42,  // I
+	74:  43,  // J
+	75:  44,  // K
+	76:  45,  // L
+	77:  46,  // M
+	78:  47,  // N
+	79:  48,  // O
+	80:  49,  // P
+	81:  50,  // Q
+	82:  51,  // R
+	83:  52,  // S
+	84:  53,  // T
+	85:  54,  // U
+	86:  55,  // V
+	87:  56,  // W
+	88:  57,  // X
+	89:  58,  // Y
+	90:  59,  // Z
+	91:  60,  // bracketleft
+	92:  61,  // backslash
+	93:  62,  // bracketright
+	94:  63,  // asciicircum
+	95:  64,  // underscore
+	96:  65,  // quoteleft
+	97:  66,  // a
+	98:  67,  // b
+	99:  68,  // c
+	100: 69,  // d
+	101: 70,  // e
+	102: 71,  // f
+	103: 72,  // g
+	104: 73,  // h
+	105: 74,  // i
+	106: 75,  // j
+	107: 76,  // k
+	108: 77,  // l
+	109: 78,  // m
+	110: 79,  // n
+	111: 80,  // o
+	112: 81,  // p
+	113: 82,  // q
+	114: 83,  // r
+	115: 84,  // s
+	116: 85,  // t
+	117: 86,  // u
+	118: 87,  // v
+	119: 88,  // w
+	120: 89,  // x
+	121: 90,  // y
+	122: 91,  // z
+	123: 92,  // braceleft
+	124: 93,  // bar
+	125: 94,  // braceright
+	126: 95,  // asciitilde
+	161: 96,  // exclamdown
+	162: 97,  // cent
+	163: 98,  // sterling
+	164: 99,  // fraction
+	165: 100, // yen
+	166: 101, // florin
+	167: 102, // section
+	168: 103, // currency
+	169: 104, // quotesingle
+	170: 105, // quotedblleft
+	171: 106, // guillemotleft
+	172: 107, // guilsinglleft
+	173: 108, // guilsinglright
+	174: 109, // fi
+	175: 110, // fl
+	177: 111, // endash
+	178: 112, // dagger
+	179: 113, // daggerdbl
+	180: 114, // periodcentered
+	182: 115, // paragraph
+	183: 116, // bullet
+	184: 117, // quotesinglbase
+	185: 118, // quotedblbase
+	186: 119, // quotedblright
+	187: 120, // guillemotright
+	188: 121, // ellipsis
+	189: 122, // perthousand
+	191: 123, // questiondown
+	193: 124, // grave
+	194: 125, // acute
+	195: 126, // circumflex
+	196: 127, // tilde
+	197: 128, // macron
+	198: 129, // breve
+	199: 130, // dotaccent
+	200: 131, // dieresis
+	202: 132, // ring
+	203: 133, // cedilla
+	205: 134, // hungarumlaut
+	206: 135, // ogonek
+	207: 136, // caron
+	208: 137, // emdash
+	225: 138, // AE
+	227: 139, // ordfeminine
+	232: 140, // Lslash
+	233: 141, // Oslash
+	234: 142, // OE
+	235: 143, // ordmasculine
+	241: 144, // ae
+	245: 145, // dotlessi
+	248: 146, // lslash
+	249: 147, // oslash
+	250: 148, // oe
+	251: 149, // germandbls
+}
+
+// cffExpertEncoding maps the character codes to the SID of the glyph names,
+// for the predefined Expert Encoding.
+// See Appendix B of the Compact Font Format Specification.
+var cffExpertEncoding = [256]uint16{
+	32:  1,   // space
+	33:  229, // exclamsmall
+	34:  230, // Hungarumlautsmall
+	36:  231, // dollaroldstyle
+	37:  232, // dollarsuperior
+	38:  233, // ampersandsmall
+	39:  234, // Acutesmall
+	40:  235, // parenleftsuperior
+	41:  236, // parenrightsuperior
+	42:  237, // twodotenleader
+	43:  238, // onedotenleader
+	44:  13,  // comma
+	45:  14,  // hyphen
+	46:  15,  // period
+	47:  99,  // fraction
+	48:  239, // zerooldstyle
+	49:  240, // oneoldstyle
+	50:  241, // twooldstyle
+	51:  242, // threeoldstyle
+	52:  243, // fouroldstyle
+	53:  244, // fiveoldstyle
+	54:  245, // sixoldstyle
+	55:  246, // sevenoldstyle
+	56:  247, // eightoldstyle
+	57:  248, // nineoldstyle
+	58:  27,  // colon
+	59:  28,  // semicolon
+	60:  249, // commasuperior
+	61:  250, // threequartersemdash
+	62:  251, // periodsuperior
+	63:  252, // questionsmall
+	65:  253, // asuperior
+	66:  254, // bsuperior
+	67:  255, // centsuperior
+	68:  256, // dsuperior
+	69:  257, // esuperior
+	73:  258, // isuperior
+	76:  259, // lsuperior
+	77:  260, // msuperior
+	78:  261, // nsuperior
+	79:  262, // osuperior
+	82:  263, // rsuperior
+	83:  264, // ssuperior
+	84:  265, // tsuperior
+	86:  266, // ff
+	87:  109, // fi
+	88:  110, // fl
+	89:  267, // ffi
+	90:  268, // ffl
+	91:  269, // parenleftinferior
+	93:  270, // parenrightinferior
+	94:  271, // Circumflexsmall
+	95:  272, // hyphensuperior
+	96:  273, // Gravesmall
+	97:  274, // Asmall
+	98:  275, // Bsmall
+	99:  276, // Csmall
+	100: 277, // Dsmall
+	101: 278, // Esmall
+	102: 279, // Fsmall
+	103: 280, // Gsmall
+	104: 281, // Hsmall
+	105: 282, // Ismall
+	106: 283, // Jsmall
+	107: 284, // Ksmall
+	108: 285, // Lsmall
+	109: 286, // Msmall
+	110: 287, // Nsmall
+	111: 288, // Osmall
+	112: 289, // Psmall
+	113: 290, // Qsmall
+	114: 291, // Rsmall
+	115: 292, // Ssmall
+	116: 293, // Tsmall
+	117: 294, // Usmall
+	118: 295, // Vsmall
+	119: 296, // Wsmall
+	120: 297, // Xsmall
+	121: 298, // Ysmall
+	122: 299, // Zsmall
+	123: 300, // colonmonetary
+	124: 301, // onefitted
+	125: 302, // rupiah
+	126: 303, // Tildesmall
+	161: 304, // exclamdownsmall
+	162: 305, // centoldstyle
+	163: 306, // Lslashsmall
+	166: 307, // Scaronsmall
+	167: 308, // Zcaronsmall
+	168: 309, // Dieresissmall
+	169: 310, // Brevesmall
+	170: 311, // Caronsmall
+	172: 312, // Dotaccentsmall
+	175: 313, // Macronsmall
+	178: 314, // figuredash
+	179: 315, // hypheninferior
+	182: 316, // Ogoneksmall
+	183: 317, // Ringsmall
+	184: 318, // Cedillasmall
+	188: 158, // onequarter
+	189: 155, // onehalf
+	190: 163, // threequarters
+	191: 319, // questiondownsmall
+	192: 320, // oneeighth
+	193: 321, // threeeighths
+	194: 322, // fiveeighths
+	195: 323, // seveneighths
+	196: 324, // onethird
+	197: 325, // twothirds
+	200: 326, // zerosuperior
+	201: 150, // onesuperior
+	202: 164, // twosuperior
+	203: 169, // threesuperior
+	204: 327, // foursuperior
+	205: 328, // fivesuperior
+	206: 329, // sixsuperior
+	207: 330, // sevensuperior
+	208: 331, // eightsuperior
+	209: 332, // ninesuperior
+	210: 333, // zeroinferior
+	211: 334, // oneinferior
+	212: 335, // twoinferior
+	213: 336, // threeinferior
+	214: 337, // fourinferior
+	215: 338, // fiveinferior
+	216: 339, // sixinferior
+	217: 340, // seveninferior
+	218: 341, // eightinferior
+	219: 342, // nineinferior
+	220: 343, // centinferior
+	221: 344, // dollarinferior
+	222: 345, // periodinferior
+	223: 346, // commainferior
+	224: 347, // Agravesmall
+	225: 348, // Aacutesmall
+	226: 349, // Acircumflexsmall
+	227: 350, // Atildesmall
+	228: 351, // Adieresissmall
+	229: 352, // Aringsmall
+	230: 353, // AEsmall
+	231: 354, // Ccedillasmall
+	232: 355, // Egravesmall
+	233: 356, // Eacutesmall
+	234: 357, // Ecircumflexsmall
+	235: 358, // Edieresissmall
+	236: 359, // Igravesmall
+	237: 360, // Iacutesmall
+	238: 361, // Icircumflexsmall
+	239: 362, // Idieresissmall
+	240: 363, // Ethsmall
+	241: 364, // Ntildesmall
+	242: 365, // Ogravesmall
+	243: 366, // Oacutesmall
+	244: 367, // Ocircumflexsmall
+	245: 368, // Otildesmall
+	246: 369, // Odieresissmall
+	247: 370, // OEsmall
+	248: 371, // Oslashsmall
+	249: 372, // Ugravesmall
+	250: 373, // Uacutesmall
+	251: 374, // Ucircumflexsmall
+	252: 375, // Udieresissmall
+	253: 376, // Yacutesmall
+	254: 377, // Thornsmall
+	255: 378, // Ydieresissmall
+}
+
+// cffExpertSIDs maps the glyphs to their SID, for
+// the predefined Expert charset.
+// See Appendix C of the Compact Font Format Specification.
+var cffExpertSIDs = [...]uint16{
+	0, 1, 229, 230, 231, 232, 233, 234, 235, 236, 237, 238,
+	13, 14, 15, 99, 239, 240, 241, 242, 243, 244, 245, 246,
+	247, 248, 27, 28, 249, 250, 251, 252, 253, 254, 255, 256,
+	257, 258, 259, 260, 261, 262, 263, 264, 265, 266, 109, 110,
+	267, 268, 269, 270, 271, 272, 273, 274, 275, 276, 277, 278,
+	279, 280, 281, 282, 283, 284, 285, 286, 287, 288, 289, 290,
+	291, 292, 293, 294, 295, 296, 297, 298, 299, 300, 301, 302,
+	303, 304, 305, 306, 307, 308, 309, 310, 311, 312, 313, 314,
+	315, 316, 317, 318, 158, 155, 163, 319, 320, 321, 322, 323,
+	324, 325, 326, 150, 164, 169, 327, 328, 329, 330, 331, 332,
+	333, 334, 335, 336, 337, 338, 339, 340, 341, 342, 343, 344,
+	345, 346, 347, 348, 349, 350, 351, 352, 353, 354, 355, 356,
+	357, 358, 359, 360, 361, 362, 363, 364, 365, 366, 367, 368,
+	369, 370, 371, 372, 373, 374, 375, 376, 377, 378,
+}
+
+// cffExpertSubsetSIDs maps the glyphs to their SID, for
+// the predefined Expert Subset charset.
+// See Appendix C of the Compact Font Format Specification.
+var cffExpertSubsetSIDs = [...]uint16{
+	0, 1, 231, 232, 235, 236, 237, 238, 13, 14, 15, 99,
+	239, 240, 241, 242, 243, 244, 245, 246, 247, 248, 27, 28,
+	249, 250, 251, 253, 254, 255, 256, 257, 258, 259, 260, 261,
+	262, 263, 264, 265, 266, 109, 110, 267, 268, 269, 270, 272,
+	300, 301, 302, 305, 314, 315, 158, 155, 163, 320, 321, 322,
+	323, 324, 325, 326, 150, 164, 169, 327, 328, 329, 330, 331,
+	332, 333, 334, 335, 336, 337, 338, 339, 340, 341, 342, 343,
+	344, 345, 346,
+}
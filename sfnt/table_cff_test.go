@@ -0,0 +1,327 @@
+package sfnt
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCFFGlyphNames(t *testing.T) {
+	f, err := os.Open("testdata/Raleway-v4020-Regular.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cff, err := font.CFFTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cff.FontName != "Raleway-v4020-Regular" {
+		t.Errorf("unexpected font name %s", cff.FontName)
+	}
+	if cff.IsCIDKeyed || cff.Encoding == nil {
+		t.Error("expected a font with an encoding")
+	}
+
+	names, err := font.GlyphNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, name := range map[rune]string{'A': "A", 'z': "z", '!': "exclam", 'é': "eacute"} {
+		if got := names.GlyphName(cmap.Lookup(r)); got != name {
+			t.Errorf("rune %q: expected %s, got %s", r, name, got)
+		}
+	}
+	if names.GlyphName(0) != ".notdef" {
+		t.Error("expected .notdef for glyph 0")
+	}
+}
+
+func TestCFFPredefinedCharsets(t *testing.T) {
+	for _, test := range []struct {
+		charset int
+		names   []string
+	}{
+		{cffISOAdobeCharset, []string{".notdef", "space", "exclam"}},
+		{cffExpertCharset, []string{".notdef", "space", "exclamsmall"}},
+		{cffExpertSubCharset, []string{".notdef", "space", "dollaroldstyle"}},
+	} {
+		charset, err := parseCFFCharset(nil, test.charset, len(test.names))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cff := TableCFF{Charset: charset}
+		for gi, name := range test.names {
+			if got := cff.GlyphName(GlyphIndex(gi)); got != name {
+				t.Errorf("charset %d, glyph %d: expected %s, got %s", test.charset, gi, name, got)
+			}
+		}
+	}
+
+	if _, err := parseCFFCharset(nil, cffExpertSubCharset, len(cffExpertSubsetSIDs)+1); err == nil {
+		t.Error("expected an error for too many glyphs")
+	}
+}
+
+func TestCFFPredefinedEncodings(t *testing.T) {
+	charset, err := parseCFFCharset(nil, cffExpertCharset, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		encoding int
+		glyphs   map[byte]GlyphIndex
+	}{
+		{cffStandardEncodingID, map[byte]GlyphIndex{' ': 1, '!': 0, '"': 0}},
+		{cffExpertEncodingID, map[byte]GlyphIndex{' ': 1, '!': 2, '"': 3, 'A': 0}},
+	} {
+		encoding, err := parseCFFEncoding(nil, test.encoding, charset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for code, gi := range test.glyphs {
+			if encoding[code] != gi {
+				t.Errorf("encoding %d, code %d: expected glyph %d, got %d", test.encoding, code, gi, encoding[code])
+			}
+		}
+	}
+}
+
+func TestCFFInvalidOffsets(t *testing.T) {
+	buf := []byte{0, 0, 1, 0, 2}
+	for _, offset := range []int{-23122, -1, len(buf), 1 << 40} {
+		if _, err := parseCFFCharset(buf, offset, 3); err == nil {
+			t.Errorf("offset %d: expected an error for an invalid charset", offset)
+		}
+		if _, err := parseCFFEncoding(buf, offset, []uint16{0, 1, 2}); err == nil {
+			t.Errorf("offset %d: expected an error for an invalid encoding", offset)
+		}
+	}
+	// truncated encoding
+	if _, err := parseCFFEncoding(buf, len(buf)-1, []uint16{0, 1, 2}); err == nil {
+		t.Error("expected an error for a truncated encoding")
+	}
+
+	// a negative charset operand in the Top DICT
+	font := buildTestCIDFont()
+	setCFFOperand(t, font, []byte{15}, -23122)
+	if _, err := ParseCFF(font); err == nil {
+		t.Error("expected an error for a negative charset offset")
+	}
+}
+
+// setCFFOperand replaces the five bytes operand (see cffInt32)
+// of the first operator op found in font.
+func setCFFOperand(t *testing.T, font []byte, op []byte, value int) {
+	for i := 0; i+5 < len(font); i++ {
+		if font[i] == 29 && bytes.HasPrefix(font[i+5:], op) {
+			copy(font[i:], cffInt32(value))
+			return
+		}
+	}
+	t.Fatalf("operator %v not found", op)
+}
+
+func TestCFFDict(t *testing.T) {
+	dict, err := parseCFFDict([]byte{
+		139, 28, 0x12, 0x34, 0x0F, // 0 0x1234 charset
+		247, 0, 30, 0xE2, 0xA2, 0x5F, 12, 30, // 108 -2.25 ROS
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops := dict[cffOpCharset]; len(ops) != 2 || ops[0] != 0 || ops[1] != 0x1234 {
+		t.Errorf("unexpected operands %v", ops)
+	}
+	if ops := dict[cffOpROS]; len(ops) != 2 || ops[0] != 108 || ops[1] != -2.25 {
+		t.Errorf("unexpected operands %v", ops)
+	}
+}
+
+func TestParseCFF(t *testing.T) {
+	otf := loadTestFont(t, "Raleway-v4020-Regular.otf")
+	cff, err := otf.CFFTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cff.FontMatrix != [6]float64{0.001, 0, 0, 0.001, 0, 0} {
+		t.Errorf("unexpected font matrix %v", cff.FontMatrix)
+	}
+
+	bare, err := ParseCFF(cff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.FontName != cff.FontName || len(bare.Charset) != len(cff.Charset) || bare.FontBBox != cff.FontBBox {
+		t.Errorf("unexpected CFF font %s", bare.FontName)
+	}
+
+	font, err := ParseCFFFont(cff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.UnitsPerEm != 1000 || float64(head.XMin) != cff.FontBBox[0] || float64(head.YMax) != cff.FontBBox[3] {
+		t.Errorf("unexpected head %+v for the bounding box %v", head, cff.FontBBox)
+	}
+	if numGlyphs, err := font.numGlyphs(); err != nil || int(numGlyphs) != len(cff.Charset) {
+		t.Errorf("expected %d glyphs, got %d %v", len(cff.Charset), numGlyphs, err)
+	}
+
+	if _, err := ParseCFF([]byte{2, 0, 4, 1}); err == nil {
+		t.Error("expected an error for an invalid header")
+	}
+}
+
+func TestCFFGlyphPath(t *testing.T) {
+	font := loadTestFont(t, "Raleway-v4020-Regular.otf")
+	cff, err := font.CFFTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gi := range cff.Charset {
+		path, err := cff.GlyphPath(GlyphIndex(gi))
+		if err != nil {
+			t.Fatalf("glyph %d: %s", gi, err)
+		}
+		for _, segment := range path {
+			// the control points may be outside of the bounding box
+			p := segment.Args[0]
+			if segment.Op == CubeTo {
+				p = segment.Args[2]
+			}
+			if p.X < float32(head.XMin) || p.Y < float32(head.YMin) || p.X > float32(head.XMax) || p.Y > float32(head.YMax) {
+				t.Fatalf("glyph %d: point %v outside of the font bounding box", gi, p)
+			}
+		}
+	}
+
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := cff.GlyphPath(cmap.Lookup('l'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) == 0 || path[0].Op != MoveTo {
+		t.Errorf("unexpected path %v", path)
+	}
+}
+
+// buildTestCIDFont builds a CID-keyed CFF font with three glyphs,
+// using two Font DICTs.
+func buildTestCIDFont() []byte {
+	const topDictLength = 9 + 4*5 + 1 + 1 + 2 + 2
+	header := []byte{1, 0, 4, 1}
+	names := buildCFFIndex(2, []byte("Test"))
+	strings := buildCFFIndex(2, []byte("Adobe"), []byte("Identity"))
+	globalSubrs := []byte{0, 0}
+	charStrings := buildCFFIndex(2,
+		[]byte{14}, // endchar
+		[]byte{
+			239, 139, 139, 21, // 100 (width) 0 0 rmoveto
+			189, 6, // 50 hlineto
+			32, 10, // -107 callsubr
+			14,
+		},
+		[]byte{139, 139, 21, 149, 7, 14}, // 0 0 rmoveto 10 vlineto endchar
+	)
+	charset := []byte{0, 0x01, 0xF4, 0, 10} // CIDs 500 and 10
+	private := []byte{141, 19}              // Subrs
+	localSubrs := buildCFFIndex(2, []byte{139, 239, 5, 11})
+	fdSelect := []byte{3, 0, 2, 0, 0, 0, 0, 2, 1, 0, 3}
+
+	topDictsLength := len(buildCFFIndex(2, make([]byte, topDictLength)))
+	charStringsOffset := len(header) + len(names) + topDictsLength + len(strings) + len(globalSubrs)
+	charsetOffset := charStringsOffset + len(charStrings)
+	fdArrayOffset := charsetOffset + len(charset)
+	fdArrayLength := len(buildCFFIndex(2, make([]byte, 5), nil))
+	privateOffset := fdArrayOffset + fdArrayLength
+	fdSelectOffset := privateOffset + len(private) + len(localSubrs)
+
+	topDict := []byte{28, 0x01, 0x87, 28, 0x01, 0x88, 144, 12, 30} // ROS: Adobe Identity 5
+	topDict = append(append(topDict, cffInt32(charStringsOffset)...), 17)
+	topDict = append(append(topDict, cffInt32(charsetOffset)...), 15)
+	topDict = append(append(topDict, cffInt32(fdArrayOffset)...), 12, 36)
+	topDict = append(append(topDict, cffInt32(fdSelectOffset)...), 12, 37)
+	fdArray := buildCFFIndex(2, []byte{141, 28, byte(privateOffset >> 8), byte(privateOffset), 18}, nil)
+
+	var out []byte
+	for _, part := range [][]byte{header, names, buildCFFIndex(2, topDict), strings, globalSubrs,
+		charStrings, charset, fdArray, private, localSubrs, fdSelect} {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func TestCFFInvalidFDSelect(t *testing.T) {
+	font := buildTestCIDFont()
+	setCFFOperand(t, font, []byte{12, 37}, -10)
+	if _, err := ParseCFF(font); err == nil {
+		t.Error("expected an error for a negative FDSelect offset")
+	}
+	setCFFOperand(t, font, []byte{12, 37}, len(font))
+	if _, err := ParseCFF(font); err == nil {
+		t.Error("expected an error for an out of range FDSelect offset")
+	}
+}
+
+func TestCFFCIDKeyed(t *testing.T) {
+	cff, err := ParseCFF(buildTestCIDFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cff.IsCIDKeyed || cff.Registry != "Adobe" || cff.Ordering != "Identity" || cff.Supplement != 5 {
+		t.Errorf("unexpected character collection %s-%s-%d", cff.Registry, cff.Ordering, cff.Supplement)
+	}
+	if cid, ok := cff.CID(1); !ok || cid != 500 {
+		t.Errorf("expected CID 500, got %d %v", cid, ok)
+	}
+	if gi, ok := cff.GlyphIndexByCID(10); !ok || gi != 2 {
+		t.Errorf("expected glyph 2, got %d %v", gi, ok)
+	}
+	if _, ok := cff.GlyphIndexByCID(7); ok {
+		t.Error("unexpected glyph for CID 7")
+	}
+	if name := cff.GlyphName(1); name != "cid00500" {
+		t.Errorf("unexpected glyph name %s", name)
+	}
+
+	// glyph 1 uses the subroutine of the first Font DICT
+	path, err := cff.GlyphPath(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := GlyphPath{
+		{Op: MoveTo},
+		{Op: LineTo, Args: [3]Point{{50, 0}}},
+		{Op: LineTo, Args: [3]Point{{50, 100}}},
+	}
+	if !reflect.DeepEqual(path, expected) {
+		t.Errorf("expected %v, got %v", expected, path)
+	}
+	if cff.fdSelect[2] != 1 {
+		t.Errorf("expected Font DICT 1 for glyph 2, got %d", cff.fdSelect[2])
+	}
+	if path, err := cff.GlyphPath(2); err != nil || len(path) != 2 {
+		t.Errorf("unexpected path %v %v", path, err)
+	}
+}
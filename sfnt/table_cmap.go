@@ -4,7 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 
-	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding"
 )
 
 const (
@@ -40,6 +40,29 @@ type Cmap interface {
 	// "Character codes that do not correspond to any glyph in the font should be mapped to glyph index 0.
 	// The glyph at this location must be a special glyph representing a missing character, commonly known as .notdef."
 	Lookup(rune) GlyphIndex
+
+	// VariationGlyph returns the glyph for the Unicode Variation Sequence
+	// made of r followed by the variation selector, as defined by a
+	// format 14 subtable. It returns false if the sequence is not supported by
+	// the font, in which case the selector is usually ignored, using Lookup(r).
+	VariationGlyph(r, selector rune) (GlyphIndex, bool)
+}
+
+// cmapSubtable is the mapping of a single cmap subtable.
+type cmapSubtable interface {
+	Compile() map[rune]GlyphIndex
+	Lookup(rune) GlyphIndex
+}
+
+// cmapTable is the best subtable of a cmap,
+// completed by the variation sequences, if any.
+type cmapTable struct {
+	cmapSubtable
+	variations cmap14 // may be nil
+}
+
+func (c cmapTable) VariationGlyph(r, selector rune) (GlyphIndex, bool) {
+	return c.variations.lookup(r, selector, c.cmapSubtable)
 }
 
 type cmap0 map[rune]GlyphIndex
@@ -156,6 +179,13 @@ func (s cmap12) Lookup(r rune) GlyphIndex {
 // https://www.microsoft.com/typography/OTSPEC/cmap.htm
 // direct adaption from golang.org/x/image/font/sfnt
 func parseTableCmap(input []byte) (Cmap, error) {
+	return parseTableCmapLanguage(input, 0)
+}
+
+// parseTableCmapLanguage selects the subtable with the given language field
+// (see CmapSubtable.Language), if any, before the language independent ones.
+// The subtables specific to other languages are only used as a last resort.
+func parseTableCmapLanguage(input []byte, language uint32) (Cmap, error) {
 	const headerSize, entrySize = 4, 8
 	if len(input) < headerSize {
 		return nil, errInvalidCmapTable
@@ -168,20 +198,34 @@ func parseTableCmap(input []byte) (Cmap, error) {
 	}
 
 	var (
+		bestRank   int
 		bestWidth  uint8
 		bestOffset uint32
 		bestLength uint32
 		bestFormat uint16
+		bestPid    uint16
+		bestPsid   uint16
+
+		variations cmap14
+		err        error
 	)
 
-	// Scan all of the subtables, picking the widest supported one. See the
-	// platformEncodingWidth comment for more discussion of width.
+	// Scan all of the subtables, picking the widest supported one, with
+	// the best language. See the platformEncodingWidth comment for more
+	// discussion of width.
 	for i := 0; i < numSubtables; i++ {
 		bufSubtable := input[headerSize+entrySize*i : headerSize+entrySize*(i+1)]
 		pid := be.Uint16(bufSubtable)
 		psid := be.Uint16(bufSubtable[2:])
+		if pid == pidUnicode && psid == psidUnicodeVariationSequences {
+			variations, err = parseCmapFormat14(input, be.Uint32(bufSubtable[4:]))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
 		width := platformEncodingWidth(pid, psid)
-		if width <= bestWidth {
+		if width == 0 {
 			continue
 		}
 		offset := be.Uint32(bufSubtable[4:])
@@ -194,23 +238,102 @@ func parseTableCmap(input []byte) (Cmap, error) {
 		if !supportedCmapFormat(format, pid, psid) {
 			continue
 		}
+		rank := 1 // language independent
+		if subtableLanguage, ok := cmapSubtableLanguage(input, offset, format); ok && subtableLanguage != 0 {
+			if subtableLanguage == language {
+				rank = 2
+			} else {
+				rank = 0
+			}
+		}
+		if rank < bestRank || (rank == bestRank && width <= bestWidth) {
+			continue
+		}
 		length := uint32(be.Uint16(bufFormat[2:]))
 
+		bestRank = rank
 		bestWidth = width
 		bestOffset = offset
 		bestLength = length
 		bestFormat = format
+		bestPid, bestPsid = pid, psid
 	}
 
 	if bestWidth == 0 {
 		return nil, errUnsupportedCmapEncodings
 	}
 
-	m, err := parseCmapIndex(input, bestOffset, bestLength, bestFormat)
+	m, err := parseCmapIndex(input, bestOffset, bestLength, bestFormat, bestPid, bestPsid)
 	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return cmapTable{cmapSubtable: m, variations: variations}, nil
+}
+
+// cmapSubtableLanguage returns the language field of the subtable
+// at offset, or false if the subtable is truncated or has no language.
+func cmapSubtableLanguage(input []byte, offset uint32, format uint16) (uint32, bool) {
+	switch format {
+	case 0, 2, 4, 6:
+		if uint32(len(input)) < offset+6 {
+			return 0, false
+		}
+		return uint32(be.Uint16(input[offset+4:])), true
+	case 8, 10, 12, 13:
+		if uint32(len(input)) < offset+12 {
+			return 0, false
+		}
+		return be.Uint32(input[offset+8:]), true
+	}
+	return 0, false
+}
+
+// CmapSubtable describes a subtable of the cmap table.
+type CmapSubtable struct {
+	Platform PlatformID
+	Encoding PlatformEncodingID
+	Format   uint16
+	// Language is 0 for the subtables which are not language specific.
+	// Otherwise, for the Macintosh platform, it is the Macintosh
+	// language ID plus one.
+	Language uint32
+}
+
+// MacLanguage returns the Macintosh language of a language specific
+// subtable, or false if the subtable is not language specific.
+func (c CmapSubtable) MacLanguage() (PlatformLanguageID, bool) {
+	if c.Platform != PlatformMac || c.Language == 0 {
+		return 0, false
+	}
+	return PlatformLanguageID(c.Language - 1), true
+}
+
+func parseCmapSubtables(input []byte) ([]CmapSubtable, error) {
+	const headerSize, entrySize = 4, 8
+	if len(input) < headerSize {
+		return nil, errInvalidCmapTable
+	}
+	numSubtables := int(be.Uint16(input[2:]))
+	if len(input) < headerSize+entrySize*numSubtables {
+		return nil, errInvalidCmapTable
+	}
+	out := make([]CmapSubtable, numSubtables)
+	for i := range out {
+		record := input[headerSize+entrySize*i:]
+		offset := be.Uint32(record[4:])
+		if offset > uint32(len(input)-2) {
+			return nil, errInvalidCmapTable
+		}
+		format := be.Uint16(input[offset:])
+		language, _ := cmapSubtableLanguage(input, offset, format)
+		out[i] = CmapSubtable{
+			Platform: PlatformID(be.Uint16(record)),
+			Encoding: PlatformEncodingID(be.Uint16(record[2:])),
+			Format:   format,
+			Language: language,
+		}
+	}
+	return out, nil
 }
 
 // Platform IDs and Platform Specific IDs as per
@@ -220,17 +343,28 @@ const (
 	pidMacintosh = 1
 	pidWindows   = 3
 
-	psidUnicode2BMPOnly        = 3
-	psidUnicode2FullRepertoire = 4
+	psidUnicode2BMPOnly           = 3
+	psidUnicode2FullRepertoire    = 4
+	psidUnicodeVariationSequences = 5
+	psidUnicodeFullRepertoire13   = 6 // only used with format 13
 	// Note that FontForge may generate a bogus Platform Specific ID (value 10)
 	// for the Unicode Platform ID (value 0). See
 	// https://github.com/fontforge/fontforge/issues/2728
 
-	psidMacintoshRoman = 0
-
-	psidWindowsSymbol = 0
-	psidWindowsUCS2   = 1
-	psidWindowsUCS4   = 10
+	psidMacintoshRoman       = 0
+	psidMacintoshJapanese    = 1
+	psidMacintoshTradChinese = 2
+	psidMacintoshKorean      = 3
+	psidMacintoshSimpChinese = 25
+
+	psidWindowsSymbol   = 0
+	psidWindowsUCS2     = 1
+	psidWindowsShiftJIS = 2
+	psidWindowsPRC      = 3
+	psidWindowsBig5     = 4
+	psidWindowsWansung  = 5
+	psidWindowsJohab    = 6
+	psidWindowsUCS4     = 10
 )
 
 // The various cmap formats are described at
@@ -238,13 +372,10 @@ const (
 
 func supportedCmapFormat(format, pid, psid uint16) bool {
 	switch format {
-	case 0:
-		return pid == pidMacintosh && psid == psidMacintoshRoman
-	case 4:
-		return true
-	case 6:
-		return true
-	case 12:
+	case 0, 2:
+		// the codes are converted from the legacy encoding
+		return legacyEncoding(pid, psid) != nil
+	case 4, 6, 8, 10, 12, 13:
 		return true
 	}
 	return false
@@ -272,11 +403,17 @@ func platformEncodingWidth(pid, psid uint16) uint8 {
 			return 2
 		case psidUnicode2FullRepertoire:
 			return 4
+		case psidUnicodeFullRepertoire13:
+			// the many-to-one mappings of format 13 are only
+			// used as a last resort
+			return 3
 		}
 
 	case pidMacintosh:
 		switch psid {
-		case psidMacintoshRoman:
+		case psidMacintoshRoman, psidMacintoshJapanese, psidMacintoshTradChinese,
+			psidMacintoshKorean, psidMacintoshSimpChinese:
+			// legacy encodings are only used as a fallback
 			return 1
 		}
 
@@ -286,6 +423,8 @@ func platformEncodingWidth(pid, psid uint16) uint8 {
 			return 2
 		case psidWindowsUCS2:
 			return 2
+		case psidWindowsShiftJIS, psidWindowsPRC, psidWindowsBig5, psidWindowsWansung, psidWindowsJohab:
+			return 1
 		case psidWindowsUCS4:
 			return 4
 		}
@@ -293,21 +432,43 @@ func platformEncodingWidth(pid, psid uint16) uint8 {
 	return 0
 }
 
-func parseCmapIndex(input []byte, offset, length uint32, format uint16) (Cmap, error) {
+func parseCmapIndex(input []byte, offset, length uint32, format, pid, psid uint16) (cmapSubtable, error) {
 	switch format {
 	case 0:
-		return parseCmapFormat0(input, offset, length)
-	case 4:
-		return parseCmapFormat4(input, offset, length)
-	case 6:
-		return parseCmapFormat6(input, offset, length)
+		return parseCmapFormat0(input, offset, length, legacyEncoding(pid, psid))
+	case 2:
+		return parseCmapFormat2(input, offset, legacyEncoding(pid, psid))
+	case 4, 6:
+		var (
+			m   cmapSubtable
+			err error
+		)
+		if format == 4 {
+			m, err = parseCmapFormat4(input, offset, length)
+		} else {
+			m, err = parseCmapFormat6(input, offset, length)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the codes of the legacy encodings are converted
+		if enc := legacyEncoding(pid, psid); enc != nil {
+			return decodeLegacyCmap(m, enc), nil
+		}
+		return m, nil
+	case 8:
+		return parseCmapFormat8(input, offset)
+	case 10:
+		return parseCmapFormat10(input, offset)
 	case 12:
 		return parseCmapFormat12(input, offset)
+	case 13:
+		return parseCmapFormat13(input, offset)
 	}
 	panic("unreachable")
 }
 
-func parseCmapFormat0(input []byte, offset, length uint32) (Cmap, error) {
+func parseCmapFormat0(input []byte, offset, length uint32, enc encoding.Encoding) (cmapSubtable, error) {
 	if length != 6+256 || offset+length > uint32(len(input)) {
 		return nil, errInvalidCmapTable
 	}
@@ -317,8 +478,8 @@ func parseCmapFormat0(input []byte, offset, length uint32) (Cmap, error) {
 
 	chars := cmap0{}
 	for x, index := range table {
-		r := charmap.Macintosh.DecodeByte(byte(x))
-		// The source rune r is not representable in the Macintosh-Roman encoding.
+		r := decodeLegacyCode(enc, uint16(x))
+		// The source rune r is not representable in the encoding.
 		if r != 0 {
 			chars[r] = GlyphIndex(index)
 		}
@@ -326,7 +487,7 @@ func parseCmapFormat0(input []byte, offset, length uint32) (Cmap, error) {
 	return chars, nil
 }
 
-func parseCmapFormat4(input []byte, offset, length uint32) (Cmap, error) {
+func parseCmapFormat4(input []byte, offset, length uint32) (cmapSubtable, error) {
 	const headerSize = 14
 	if offset+headerSize > uint32(len(input)) {
 		return nil, errInvalidCmapTable
@@ -379,7 +540,7 @@ func parseCmapFormat4(input []byte, offset, length uint32) (Cmap, error) {
 	return entries, nil
 }
 
-func parseCmapFormat6(input []byte, offset, length uint32) (Cmap, error) {
+func parseCmapFormat6(input []byte, offset, length uint32) (cmapSubtable, error) {
 	const headerSize = 10
 	if offset+headerSize > uint32(len(input)) {
 		return nil, errInvalidCmapTable
@@ -405,7 +566,7 @@ func parseCmapFormat6(input []byte, offset, length uint32) (Cmap, error) {
 	return cmap6{firstCode: rune(firstCode), entries: entries}, nil
 }
 
-func parseCmapFormat12(input []byte, offset uint32) (Cmap, error) {
+func parseCmapFormat12(input []byte, offset uint32) (cmapSubtable, error) {
 	const headerSize = 16
 	if offset+headerSize > uint32(len(input)) {
 		return nil, errInvalidCmapTable
@@ -0,0 +1,136 @@
+package sfnt
+
+import "sort"
+
+// uint24 reads a 3 bytes big endian integer.
+func uint24(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// unicodeRange is a range of code points [start, start+additionalCount].
+type unicodeRange struct {
+	start           rune
+	additionalCount uint8
+}
+
+// uvsMapping maps a code point, followed by a variation selector, to a glyph.
+type uvsMapping struct {
+	r     rune
+	glyph GlyphIndex
+}
+
+// variationSelector stores the sequences using a selector.
+type variationSelector struct {
+	selector rune
+	// defaults are the sequences using the default glyph of the code point,
+	// sorted by start
+	defaults []unicodeRange
+	// mappings are the sequences using a specific glyph, sorted by code point
+	mappings []uvsMapping
+}
+
+// cmap14 stores the Unicode Variation Sequences of the
+// cmap, sorted by selector.
+type cmap14 []variationSelector
+
+// lookup returns the glyph for the sequence r + selector,
+// using base for the default glyphs.
+func (c cmap14) lookup(r, selector rune, base cmapSubtable) (GlyphIndex, bool) {
+	i := sort.Search(len(c), func(i int) bool { return c[i].selector >= selector })
+	if i == len(c) || c[i].selector != selector {
+		return 0, false
+	}
+	vs := c[i]
+
+	j := sort.Search(len(vs.mappings), func(j int) bool { return vs.mappings[j].r >= r })
+	if j < len(vs.mappings) && vs.mappings[j].r == r {
+		return vs.mappings[j].glyph, true
+	}
+
+	// find the last range starting before r
+	j = sort.Search(len(vs.defaults), func(j int) bool { return vs.defaults[j].start > r }) - 1
+	if j >= 0 && r <= vs.defaults[j].start+rune(vs.defaults[j].additionalCount) {
+		return base.Lookup(r), true
+	}
+	return 0, false
+}
+
+func parseCmapFormat14(input []byte, offset uint32) (cmap14, error) {
+	const headerSize, recordSize = 10, 11
+	if uint64(offset)+headerSize > uint64(len(input)) {
+		return nil, errInvalidCmapTable
+	}
+	buf := input[offset:]
+	count := be.Uint32(buf[6:])
+	if count > maxCmapSegments {
+		return nil, errUnsupportedNumberOfCmapSegments
+	}
+	if len(buf) < headerSize+recordSize*int(count) {
+		return nil, errInvalidCmapTable
+	}
+
+	out := make(cmap14, count)
+	for i := range out {
+		record := buf[headerSize+recordSize*i:]
+		out[i].selector = rune(uint24(record))
+		// offsets are from the beginning of the subtable
+		defaultOffset, mappingsOffset := be.Uint32(record[3:]), be.Uint32(record[7:])
+		var err error
+		if defaultOffset != 0 {
+			out[i].defaults, err = parseDefaultUVS(buf, defaultOffset)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if mappingsOffset != 0 {
+			out[i].mappings, err = parseNonDefaultUVS(buf, mappingsOffset)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].selector < out[j].selector })
+	return out, nil
+}
+
+func parseDefaultUVS(buf []byte, offset uint32) ([]unicodeRange, error) {
+	const entrySize = 4
+	if uint64(offset)+4 > uint64(len(buf)) {
+		return nil, errInvalidCmapTable
+	}
+	buf = buf[offset:]
+	count := be.Uint32(buf)
+	if count > maxCmapSegments {
+		return nil, errUnsupportedNumberOfCmapSegments
+	}
+	if len(buf) < 4+entrySize*int(count) {
+		return nil, errInvalidCmapTable
+	}
+	out := make([]unicodeRange, count)
+	for i := range out {
+		entry := buf[4+entrySize*i:]
+		out[i] = unicodeRange{start: rune(uint24(entry)), additionalCount: entry[3]}
+	}
+	return out, nil
+}
+
+func parseNonDefaultUVS(buf []byte, offset uint32) ([]uvsMapping, error) {
+	const entrySize = 5
+	if uint64(offset)+4 > uint64(len(buf)) {
+		return nil, errInvalidCmapTable
+	}
+	buf = buf[offset:]
+	count := be.Uint32(buf)
+	if count > maxCmapSegments {
+		return nil, errUnsupportedNumberOfCmapSegments
+	}
+	if len(buf) < 4+entrySize*int(count) {
+		return nil, errInvalidCmapTable
+	}
+	out := make([]uvsMapping, count)
+	for i := range out {
+		entry := buf[4+entrySize*i:]
+		out[i] = uvsMapping{r: rune(uint24(entry)), glyph: GlyphIndex(be.Uint16(entry[3:]))}
+	}
+	return out, nil
+}
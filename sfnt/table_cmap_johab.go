@@ -0,0 +1,117 @@
+package sfnt
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/transform"
+)
+
+// johab is the Korean Johab encoding (KS X 1001 annex 3), used by the
+// Windows cmap subtables with encoding 6, and not provided by
+// golang.org/x/text. Only the decoder is implemented.
+var johab encoding.Encoding = johabEncoding{}
+
+type johabEncoding struct{}
+
+func (johabEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: johabDecoder{}}
+}
+
+func (johabEncoding) NewEncoder() *encoding.Encoder {
+	return encoding.Replacement.NewEncoder()
+}
+
+// the jamos indexes of the 5 bits codes of the Hangul syllables,
+// -1 for the fill code, -2 for the invalid codes
+var (
+	johabInitials = [32]int8{
+		-2, -1, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13,
+		14, 15, 16, 17, 18, -2, -2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
+	}
+	johabMedials = [32]int8{
+		-2, -2, -1, 0, 1, 2, 3, 4, -2, -2, 5, 6, 7, 8, 9, 10,
+		-2, -2, 11, 12, 13, 14, 15, 16, -2, -2, 17, 18, 19, 20, -2, -2,
+	}
+	johabFinals = [32]int8{
+		-2, -1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14,
+		15, 16, -2, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, -2, -2,
+	}
+	// the compatibility jamos of the initial consonants
+	johabCompatibilityInitials = [19]rune{
+		0x3131, 0x3132, 0x3134, 0x3137, 0x3138, 0x3139, 0x3141, 0x3142, 0x3143, 0x3145,
+		0x3146, 0x3147, 0x3148, 0x3149, 0x314A, 0x314B, 0x314C, 0x314D, 0x314E,
+	}
+)
+
+// decodeJohab returns the rune for a two bytes code, or utf8.RuneError.
+func decodeJohab(c1, c2 byte) rune {
+	code := uint16(c1)<<8 | uint16(c2)
+	switch {
+	case c1 >= 0x84 && c1 <= 0xD3: // Hangul, with bits 1iiiiimmmmmfffff
+		initial := johabInitials[code>>10&0x1F]
+		medial := johabMedials[code>>5&0x1F]
+		final := johabFinals[code&0x1F]
+		switch {
+		case initial == -2 || medial == -2 || final == -2:
+			return utf8.RuneError
+		case initial >= 0 && medial >= 0:
+			if final < 0 {
+				final = 0
+			}
+			return 0xAC00 + (rune(initial)*21+rune(medial))*28 + rune(final)
+		case initial >= 0 && medial == -1 && final == -1:
+			return johabCompatibilityInitials[initial]
+		case initial == -1 && medial >= 0 && final == -1:
+			return 0x314F + rune(medial)
+		}
+		return utf8.RuneError
+	case (c1 >= 0xD9 && c1 <= 0xDE || c1 >= 0xE0 && c1 <= 0xF9) &&
+		(c2 >= 0x31 && c2 <= 0x7E || c2 >= 0x91 && c2 <= 0xFE) &&
+		!(c1 == 0xDA && c2 >= 0xA1 && c2 <= 0xD3): // symbols and Hanja, mapped to KS X 1001
+		t1 := 2 * int(c1-0xD9)
+		if c1 >= 0xE0 {
+			t1 = 2*int(c1) - 0x197
+		}
+		t2 := int(c2) - 0x31
+		if c2 >= 0x91 {
+			t2 = int(c2) - 0x43
+		}
+		if t2 >= 0x5E {
+			t1, t2 = t1+1, t2-0x5E
+		}
+		euc := []byte{byte(t1+0x21) | 0x80, byte(t2+0x21) | 0x80}
+		out, err := korean.EUCKR.NewDecoder().Bytes(euc)
+		if err != nil {
+			return utf8.RuneError
+		}
+		r, _ := utf8.DecodeRune(out)
+		return r
+	}
+	return utf8.RuneError
+}
+
+type johabDecoder struct{ transform.NopResetter }
+
+func (johabDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := rune(src[nSrc]), 1
+		if r >= 0x80 {
+			if nSrc+1 >= len(src) {
+				if !atEOF {
+					return nDst, nSrc, transform.ErrShortSrc
+				}
+				r = utf8.RuneError
+			} else {
+				r, size = decodeJohab(src[nSrc], src[nSrc+1]), 2
+			}
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
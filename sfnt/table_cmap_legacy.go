@@ -0,0 +1,253 @@
+package sfnt
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// legacyEncoding returns the encoding of the character codes
+// for the given platform, or nil for Unicode (or unsupported) platforms.
+func legacyEncoding(pid, psid uint16) encoding.Encoding {
+	switch pid {
+	case pidMacintosh:
+		switch psid {
+		case psidMacintoshRoman:
+			return charmap.Macintosh
+		case psidMacintoshJapanese:
+			return japanese.ShiftJIS
+		case psidMacintoshTradChinese:
+			return traditionalchinese.Big5
+		case psidMacintoshKorean:
+			return korean.EUCKR
+		case psidMacintoshSimpChinese:
+			return simplifiedchinese.GBK
+		}
+	case pidWindows:
+		switch psid {
+		case psidWindowsShiftJIS:
+			return japanese.ShiftJIS
+		case psidWindowsPRC:
+			return simplifiedchinese.GBK
+		case psidWindowsBig5:
+			return traditionalchinese.Big5
+		case psidWindowsWansung:
+			return korean.EUCKR
+		case psidWindowsJohab:
+			return johab
+		}
+	}
+	return nil
+}
+
+// decodeLegacyCode converts a one or two bytes character code to Unicode.
+// It returns 0 if the code is not valid in the encoding.
+// If enc is nil, the code is returned as is.
+func decodeLegacyCode(enc encoding.Encoding, code uint16) rune {
+	if enc == nil {
+		return rune(code)
+	}
+	b := []byte{byte(code)}
+	if code > 0xFF {
+		b = []byte{byte(code >> 8), byte(code)}
+	}
+	out, err := enc.NewDecoder().Bytes(b)
+	if err != nil {
+		return 0
+	}
+	r, size := utf8.DecodeRune(out)
+	if r == utf8.RuneError || size != len(out) {
+		return 0
+	}
+	return r
+}
+
+// decodeLegacyCmap converts the character codes of the table,
+// expressed in a legacy encoding, to Unicode.
+func decodeLegacyCmap(m cmapSubtable, enc encoding.Encoding) cmap0 {
+	out := cmap0{}
+	for code, glyph := range m.Compile() {
+		if code > 0xFFFF {
+			continue
+		}
+		if r := decodeLegacyCode(enc, uint16(code)); r != 0 {
+			out[r] = glyph
+		}
+	}
+	return out
+}
+
+// parseCmapFormat2 parses the high-byte mapping through table, used by
+// the legacy CJK encodings, whose codes use one or two bytes.
+func parseCmapFormat2(input []byte, offset uint32, enc encoding.Encoding) (cmapSubtable, error) {
+	const headerSize, subHeaderSize = 6 + 2*256, 8
+	if uint64(offset)+headerSize > uint64(len(input)) {
+		return nil, errInvalidCmapTable
+	}
+	buf := input[offset:]
+
+	// subHeaderKeys are the indexes of the subheaders, times 8
+	var keys [256]int
+	maxKey := 0
+	for i := range keys {
+		keys[i] = int(be.Uint16(buf[6+2*i:])) / subHeaderSize
+		if keys[i] > maxKey {
+			maxKey = keys[i]
+		}
+	}
+	if len(buf) < headerSize+subHeaderSize*(maxKey+1) {
+		return nil, errInvalidCmapTable
+	}
+
+	out := cmap0{}
+	for high, key := range keys {
+		subHeader := buf[headerSize+subHeaderSize*key:]
+		firstCode, entryCount := int(be.Uint16(subHeader)), int(be.Uint16(subHeader[2:]))
+		idDelta := be.Uint16(subHeader[4:])
+		// idRangeOffset is relative to its own position
+		glyphsStart := headerSize + subHeaderSize*key + 6 + int(be.Uint16(subHeader[6:]))
+
+		lows := [2]int{firstCode, firstCode + entryCount}
+		if key == 0 { // one byte code: the high byte is the code
+			if high < lows[0] || high >= lows[1] {
+				continue
+			}
+			lows = [2]int{high, high + 1}
+		}
+		for low := lows[0]; low < lows[1]; low++ {
+			position := glyphsStart + 2*(low-firstCode)
+			if len(buf) < position+2 {
+				return nil, errInvalidCmapTable
+			}
+			glyph := be.Uint16(buf[position:])
+			if glyph == 0 {
+				continue
+			}
+			code := uint16(low)
+			if key != 0 {
+				code |= uint16(high) << 8
+			}
+			if r := decodeLegacyCode(enc, code); r != 0 {
+				out[r] = GlyphIndex(glyph + idDelta)
+			}
+		}
+	}
+	return out, nil
+}
+
+// surrogateCode converts the 32-bit character codes of format 8, made of
+// two UTF-16 surrogates, to Unicode.
+func surrogateCode(code uint32) uint32 {
+	if code <= 0xFFFF {
+		return code
+	}
+	if r := utf16.DecodeRune(rune(code>>16), rune(code&0xFFFF)); r != utf8.RuneError {
+		return uint32(r)
+	}
+	return code
+}
+
+// parseCmapFormat8 parses the mixed 16-bit and 32-bit coverage table,
+// whose 32-bit codes are UTF-16 surrogate pairs.
+func parseCmapFormat8(input []byte, offset uint32) (cmapSubtable, error) {
+	const headerSize, groupSize = 12 + 8192 + 4, 12
+	if uint64(offset)+headerSize > uint64(len(input)) {
+		return nil, errInvalidCmapTable
+	}
+	buf := input[offset:]
+	numGroups := be.Uint32(buf[headerSize-4:])
+	if numGroups > maxCmapSegments {
+		return nil, errUnsupportedNumberOfCmapSegments
+	}
+	if len(buf) < headerSize+groupSize*int(numGroups) {
+		return nil, errInvalidCmapTable
+	}
+
+	entries := make(cmap12, numGroups)
+	for i := range entries {
+		group := buf[headerSize+groupSize*i:]
+		entries[i] = cmapEntry32{
+			start: surrogateCode(be.Uint32(group)),
+			end:   surrogateCode(be.Uint32(group[4:])),
+			delta: be.Uint32(group[8:]),
+		}
+		if entries[i].start > entries[i].end {
+			return nil, errInvalidCmapTable
+		}
+	}
+	return entries, nil
+}
+
+// parseCmapFormat10 parses the trimmed array of 32-bit codes.
+func parseCmapFormat10(input []byte, offset uint32) (cmapSubtable, error) {
+	const headerSize = 20
+	if uint64(offset)+headerSize > uint64(len(input)) {
+		return nil, errInvalidCmapTable
+	}
+	buf := input[offset:]
+	startCode := be.Uint32(buf[12:])
+	numChars := be.Uint32(buf[16:])
+	if startCode > unicode10FFFF || numChars > unicode10FFFF {
+		return nil, errInvalidCmapTable
+	}
+	if uint64(len(buf)) < headerSize+2*uint64(numChars) {
+		return nil, errInvalidCmapTable
+	}
+
+	entries := make([]uint16, numChars)
+	for i := range entries {
+		entries[i] = be.Uint16(buf[headerSize+2*i:])
+	}
+	return cmap6{firstCode: rune(startCode), entries: entries}, nil
+}
+
+// maximum Unicode code point
+const unicode10FFFF = 0x10FFFF
+
+// cmap13 maps ranges of characters to a single glyph:
+// the delta of the entries is the glyph.
+type cmap13 []cmapEntry32
+
+func (s cmap13) Compile() map[rune]GlyphIndex {
+	chars := map[rune]GlyphIndex{}
+	for _, cm := range s {
+		for c := cm.start; c <= cm.end && c <= unicode10FFFF; c++ {
+			chars[rune(c)] = GlyphIndex(cm.delta)
+		}
+	}
+	return chars
+}
+
+func (s cmap13) Lookup(r rune) GlyphIndex {
+	c := uint32(r)
+	// binary search
+	for i, j := 0, len(s); i < j; {
+		h := i + (j-i)/2
+		entry := s[h]
+		if c < entry.start {
+			j = h
+		} else if entry.end < c {
+			i = h + 1
+		} else {
+			return GlyphIndex(entry.delta)
+		}
+	}
+	return 0
+}
+
+// parseCmapFormat13 parses the many-to-one range mappings,
+// used by last-resort fonts.
+func parseCmapFormat13(input []byte, offset uint32) (cmapSubtable, error) {
+	// the layout is the same as format 12
+	entries, err := parseCmapFormat12(input, offset)
+	if err != nil {
+		return nil, err
+	}
+	return cmap13(entries.(cmap12)), nil
+}
@@ -3,6 +3,7 @@ package sfnt
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -42,3 +43,202 @@ func TestCmap(t *testing.T) {
 
 	}
 }
+
+func TestCmapVariations(t *testing.T) {
+	format4 := []byte{
+		0, 4, 0, 32, 0, 0, // format, length, language
+		0, 4, 0, 0, 0, 0, 0, 0, // segCountX2, searchRange, entrySelector, rangeShift
+		0, 0x42, 0xFF, 0xFF, // endCode
+		0, 0, // reservedPad
+		0, 0x41, 0xFF, 0xFF, // startCode
+		0xFF, 0xC0, 0, 1, // idDelta: A -> 1
+		0, 0, 0, 0, // idRangeOffset
+	}
+	format14 := []byte{
+		0, 14, 0, 0, 0, 49, // format, length
+		0, 0, 0, 2, // numVarSelectorRecords
+		0x0E, 0x01, 0x00, 0, 0, 0, 32, 0, 0, 0, 0, // U+E0100: default UVS
+		0x00, 0xFE, 0x00, 0, 0, 0, 0, 0, 0, 0, 40, // U+FE00: non default UVS
+		0, 0, 0, 1, 0, 0, 0x41, 1, // A and B use their default glyph
+		0, 0, 0, 1, 0, 0, 0x41, 0, 5, // A is mapped to glyph 5
+	}
+	header := []byte{
+		0, 0, 0, 2, // version, numTables
+		0, 3, 0, 1, 0, 0, 0, 20, // Windows Unicode BMP
+		0, 0, 0, 5, 0, 0, 0, 52, // Unicode Variation Sequences
+	}
+	buf := append(append(header, format4...), format14...)
+
+	cmap, err := parseTableCmap(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gi := cmap.Lookup('B'); gi != 2 {
+		t.Errorf("expected glyph 2, got %d", gi)
+	}
+	for _, test := range []struct {
+		r, selector rune
+		glyph       GlyphIndex
+		ok          bool
+	}{
+		{'A', 0xFE00, 5, true},
+		{'B', 0xFE00, 0, false},
+		{'A', 0xE0100, 1, true},
+		{'B', 0xE0100, 2, true},
+		{'C', 0xE0100, 0, false},
+		{'A', 0xFE01, 0, false},
+	} {
+		glyph, ok := cmap.VariationGlyph(test.r, test.selector)
+		if glyph != test.glyph || ok != test.ok {
+			t.Errorf("sequence %U %U: expected (%d, %v), got (%d, %v)", test.r, test.selector, test.glyph, test.ok, glyph, ok)
+		}
+	}
+}
+
+func TestCmapLegacyFormats(t *testing.T) {
+	// wraps a subtable in a cmap table with one encoding record
+	cmapWith := func(pid, psid uint16, subtable []byte) []byte {
+		return append([]byte{
+			0, 0, 0, 1, // version, numTables
+			byte(pid >> 8), byte(pid), byte(psid >> 8), byte(psid), 0, 0, 0, 12,
+		}, subtable...)
+	}
+
+	format2 := make([]byte, 6+512+16+4)
+	format2[1] = 2
+	format2[6+2*0x81+1] = 8 // 0x81 high byte uses subheader 1
+	copy(format2[6+512:], []byte{
+		0, 0x41, 0, 1, 0, 0, 0, 10, // subheader 0: A
+		0, 0x40, 0, 1, 0, 1, 0, 4, // subheader 1: 0x8140, delta 1
+		0, 3, 0, 6, // glyphs
+	})
+
+	for _, test := range []struct {
+		cmap     []byte
+		expected map[rune]GlyphIndex
+	}{
+		{
+			cmapWith(pidMacintosh, psidMacintoshRoman, []byte{
+				0, 6, 0, 16, 0, 0, // format, length, language
+				0, 0x8A, 0, 3, // firstCode, entryCount
+				0, 1, 0, 2, 0, 3, // glyphs
+			}),
+			map[rune]GlyphIndex{'ä': 1, 'ã': 2, 'å': 3},
+		},
+		{
+			cmapWith(pidWindows, psidWindowsShiftJIS, format2),
+			map[rune]GlyphIndex{'A': 3, '　': 7},
+		},
+		{
+			cmapWith(pidUnicode, psidUnicode2FullRepertoire, []byte{
+				0, 10, 0, 0, 0, 0, 0, 26, 0, 0, 0, 0, // format, length, language
+				0, 1, 0xF3, 0, 0, 0, 0, 3, // startCharCode, numChars
+				0, 4, 0, 0, 0, 5, // glyphs
+			}),
+			map[rune]GlyphIndex{0x1F300: 4, 0x1F301: 0, 0x1F302: 5},
+		},
+		{
+			cmapWith(pidUnicode, psidUnicodeFullRepertoire13, []byte{
+				0, 13, 0, 0, 0, 0, 0, 40, 0, 0, 0, 0, 0, 0, 0, 2, // format, length, language, numGroups
+				0, 0, 0, 0, 0, 0, 0, 0x7F, 0, 0, 0, 1, // ASCII -> 1
+				0, 0, 0x4E, 0, 0, 0, 0x9F, 0xFF, 0, 0, 0, 2, // CJK ideographs -> 2
+			}),
+			map[rune]GlyphIndex{'a': 1, 'Z': 1, '中': 2, 'é': 0},
+		},
+		{
+			cmapWith(pidWindows, psidWindowsJohab, []byte{
+				0, 6, 0, 14, 0, 0, // format, length, language
+				0x88, 0x61, 0, 2, // firstCode, entryCount
+				0, 5, 0, 6, // glyphs
+			}),
+			map[rune]GlyphIndex{'가': 5, '각': 6},
+		},
+	} {
+		cmap, err := parseTableCmap(test.cmap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r, expected := range test.expected {
+			if gi := cmap.Lookup(r); gi != expected {
+				t.Errorf("rune %U: expected glyph %d, got %d", r, expected, gi)
+			}
+		}
+	}
+}
+
+func TestCmapMacLanguage(t *testing.T) {
+	buf := []byte{
+		0, 0, 0, 2, // version, numTables
+		0, 1, 0, 0, 0, 0, 0, 20, // Macintosh Roman, German
+		0, 1, 0, 0, 0, 0, 0, 32, // Macintosh Roman
+		0, 6, 0, 12, 0, 3, // format, length, language: German (2) + 1
+		0, 0x41, 0, 1, 0, 2, // firstCode, entryCount, glyphs
+		0, 6, 0, 12, 0, 0, // format, length, language
+		0, 0x41, 0, 1, 0, 1, // firstCode, entryCount, glyphs
+	}
+	font := New(TypeTrueType)
+	font.AddTable(TagCmap, NewTable(TagCmap, buf))
+
+	subtables, err := font.CmapSubtables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []CmapSubtable{
+		{Platform: PlatformMac, Encoding: PlatformEncodingMacRoman, Format: 6, Language: 3},
+		{Platform: PlatformMac, Encoding: PlatformEncodingMacRoman, Format: 6},
+	}
+	if !reflect.DeepEqual(subtables, expected) {
+		t.Errorf("expected subtables %v, got %v", expected, subtables)
+	}
+	if lang, ok := subtables[0].MacLanguage(); !ok || lang != 2 {
+		t.Errorf("expected German, got %d %v", lang, ok)
+	}
+	if _, ok := subtables[1].MacLanguage(); ok {
+		t.Error("unexpected language specific subtable")
+	}
+
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gi := cmap.Lookup('A'); gi != 1 {
+		t.Errorf("expected the language independent glyph 1, got %d", gi)
+	}
+	for language, expected := range map[PlatformLanguageID]GlyphIndex{2: 2, PlatformLanguageMacEnglish: 1} {
+		cmap, err := font.CmapTableForMacLanguage(language)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gi := cmap.Lookup('A'); gi != expected {
+			t.Errorf("language %d: expected glyph %d, got %d", language, expected, gi)
+		}
+	}
+
+	// the language specific subtable is used as a last resort
+	single := append([]byte{0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 12}, buf[20:32]...)
+	cmap, err = parseTableCmap(single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gi := cmap.Lookup('A'); gi != 2 {
+		t.Errorf("expected glyph 2, got %d", gi)
+	}
+}
+
+func TestDecodeJohab(t *testing.T) {
+	for code, expected := range map[uint16]rune{
+		0x41:   'A',
+		0x8861: '가',
+		0xD3BD: '힣', // last syllable
+		0x8841: 'ㄱ',
+		0x8461: 'ㅏ',
+		0xD931: '　', // symbols
+		0xE031: '伽', // Hanja
+		0x8401: 0,   // invalid medial
+		0xDAA1: 0,   // excluded jamo range
+	} {
+		if got := decodeLegacyCode(johab, code); got != expected {
+			t.Errorf("code %x: expected %q, got %q", code, expected, got)
+		}
+	}
+}
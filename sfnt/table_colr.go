@@ -0,0 +1,185 @@
+package sfnt
+
+import (
+	"errors"
+	"image/color"
+	"sort"
+)
+
+var (
+	errInvalidCOLRTable = errors.New("invalid COLR table")
+	errInvalidCPALTable = errors.New("invalid CPAL table")
+)
+
+// ForegroundPaletteIndex is the palette index of the layers
+// which use the text foreground color.
+const ForegroundPaletteIndex = 0xFFFF
+
+// TableCOLR is the color table, which defines the color glyphs
+// as stacks of layers, colored with the palettes of the 'CPAL' table.
+// The version 1 also defines color glyphs as graphs of paints
+// (gradients, transformations and compositions), see Paint.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/colr
+type TableCOLR struct {
+	baseTable
+
+	bytes []byte
+
+	Version uint16
+
+	baseGlyphs []colrBaseGlyph // sorted by glyph
+	layers     []ColorLayer
+
+	// LayerList stores the layers referenced by the
+	// PaintColrLayers of the version 1 paint graphs.
+	LayerList []Paint
+
+	basePaints []colrBasePaint // sorted by glyph
+	clips      []colrClip
+}
+
+// ColorLayer is a layer of a color glyph: the outline of
+// Glyph, filled with a color of the palette.
+type ColorLayer struct {
+	Glyph GlyphIndex
+	// PaletteIndex is the index of the color in the palette,
+	// or ForegroundPaletteIndex.
+	PaletteIndex uint16
+}
+
+type colrBaseGlyph struct {
+	glyph                GlyphIndex
+	firstLayer, numLayer int
+}
+
+// Bytes returns the bytes for this table. The TableCOLR is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableCOLR) Bytes() []byte {
+	return t.bytes
+}
+
+// Layers returns the layers of the color glyph, from bottom to top,
+// or nil if the glyph has no (version 0) color layers.
+func (t *TableCOLR) Layers(gi GlyphIndex) []ColorLayer {
+	i := sort.Search(len(t.baseGlyphs), func(i int) bool { return t.baseGlyphs[i].glyph >= gi })
+	if i == len(t.baseGlyphs) || t.baseGlyphs[i].glyph != gi {
+		return nil
+	}
+	bg := t.baseGlyphs[i]
+	return t.layers[bg.firstLayer : bg.firstLayer+bg.numLayer]
+}
+
+func parseTableCOLR(tag Tag, buf []byte) (Table, error) {
+	const headerSize, baseGlyphSize, layerSize = 14, 6, 4
+	if len(buf) < headerSize {
+		return nil, errInvalidCOLRTable
+	}
+	numBaseGlyphs := int(be.Uint16(buf[2:]))
+	baseGlyphsOffset := int(be.Uint32(buf[4:]))
+	layersOffset := int(be.Uint32(buf[8:]))
+	numLayers := int(be.Uint16(buf[12:]))
+	if (numBaseGlyphs != 0 && len(buf) < baseGlyphsOffset+baseGlyphSize*numBaseGlyphs) ||
+		(numLayers != 0 && len(buf) < layersOffset+layerSize*numLayers) {
+		return nil, errInvalidCOLRTable
+	}
+
+	out := &TableCOLR{
+		baseTable:  baseTable(tag),
+		bytes:      buf,
+		Version:    be.Uint16(buf),
+		baseGlyphs: make([]colrBaseGlyph, numBaseGlyphs),
+		layers:     make([]ColorLayer, numLayers),
+	}
+	for i := range out.layers {
+		record := buf[layersOffset+layerSize*i:]
+		out.layers[i] = ColorLayer{Glyph: GlyphIndex(be.Uint16(record)), PaletteIndex: be.Uint16(record[2:])}
+	}
+	for i := range out.baseGlyphs {
+		record := buf[baseGlyphsOffset+baseGlyphSize*i:]
+		bg := colrBaseGlyph{
+			glyph:      GlyphIndex(be.Uint16(record)),
+			firstLayer: int(be.Uint16(record[2:])),
+			numLayer:   int(be.Uint16(record[4:])),
+		}
+		if bg.firstLayer+bg.numLayer > numLayers {
+			return nil, errInvalidCOLRTable
+		}
+		out.baseGlyphs[i] = bg
+	}
+	// the records should be sorted, but don't trust the font
+	sort.SliceStable(out.baseGlyphs, func(i, j int) bool { return out.baseGlyphs[i].glyph < out.baseGlyphs[j].glyph })
+
+	if out.Version >= 1 {
+		if err := out.parseCOLRv1(buf); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// TableCPAL is the color palette table, used by the color glyphs of the 'COLR' table.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/cpal
+type TableCPAL struct {
+	baseTable
+
+	bytes []byte
+
+	// Palettes stores the colors of each palette, which
+	// all have the same number of entries.
+	// The first palette is the default one.
+	Palettes [][]color.NRGBA
+}
+
+// Bytes returns the bytes for this table. The TableCPAL is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableCPAL) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableCPAL(tag Tag, buf []byte) (Table, error) {
+	const headerSize, colorSize = 12, 4
+	if len(buf) < headerSize {
+		return nil, errInvalidCPALTable
+	}
+	numEntries := int(be.Uint16(buf[2:]))
+	numPalettes := int(be.Uint16(buf[4:]))
+	numColors := int(be.Uint16(buf[6:]))
+	colorsOffset := int(be.Uint32(buf[8:]))
+	if len(buf) < headerSize+2*numPalettes || len(buf) < colorsOffset+colorSize*numColors {
+		return nil, errInvalidCPALTable
+	}
+
+	out := &TableCPAL{baseTable: baseTable(tag), bytes: buf, Palettes: make([][]color.NRGBA, numPalettes)}
+	for i := range out.Palettes {
+		first := int(be.Uint16(buf[headerSize+2*i:]))
+		if first+numEntries > numColors {
+			return nil, errInvalidCPALTable
+		}
+		palette := make([]color.NRGBA, numEntries)
+		for j := range palette {
+			// colors are stored as BGRA
+			c := buf[colorsOffset+colorSize*(first+j):]
+			palette[j] = color.NRGBA{R: c[2], G: c[1], B: c[0], A: c[3]}
+		}
+		out.Palettes[i] = palette
+	}
+	return out, nil
+}
+
+// COLRTable returns the color table.
+func (font *Font) COLRTable() (*TableCOLR, error) {
+	t, err := font.Table(TagCOLR)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableCOLR), nil
+}
+
+// CPALTable returns the color palette table.
+func (font *Font) CPALTable() (*TableCPAL, error) {
+	t, err := font.Table(TagCPAL)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableCPAL), nil
+}
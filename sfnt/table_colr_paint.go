@@ -0,0 +1,515 @@
+package sfnt
+
+import "sort"
+
+// NoVariationIndex is the VarIndexBase of the non variable paints.
+const NoVariationIndex = 0xFFFFFFFF
+
+// maximum nesting of the paint graph, protecting
+// against cycles in malicious fonts
+const maxPaintDepth = 64
+
+// extend modes of the color lines
+const (
+	ExtendPad     = 0
+	ExtendRepeat  = 1
+	ExtendReflect = 2
+)
+
+// Paint is a node of the paint graph of a COLR version 1 glyph.
+// It is one of PaintColrLayers, PaintSolid, PaintLinearGradient,
+// PaintRadialGradient, PaintSweepGradient, PaintGlyph, PaintColrGlyph,
+// PaintTransform, PaintTranslate, PaintScale, PaintRotate, PaintSkew
+// and PaintComposite.
+//
+// The variable formats are returned with the same types, with a VarIndexBase
+// different from NoVariationIndex: it is the index of the variation deltas
+// of the first varied field, the other fields using the following indexes.
+type Paint interface {
+	isPaint()
+}
+
+// PaintColrLayers paints the layers LayerList[FirstLayer:FirstLayer+NumLayers]
+// of the COLR table, from bottom to top.
+type PaintColrLayers struct {
+	FirstLayer, NumLayers int
+}
+
+// PaintSolid fills with a color of the palette.
+type PaintSolid struct {
+	// PaletteIndex is the index of the color in the palette,
+	// or ForegroundPaletteIndex.
+	PaletteIndex uint16
+	Alpha        float32
+	VarIndexBase uint32
+}
+
+// ColorStop is a color of a gradient.
+type ColorStop struct {
+	Offset       float32
+	PaletteIndex uint16
+	Alpha        float32
+	VarIndexBase uint32
+}
+
+// ColorLine is the list of colors of a gradient.
+type ColorLine struct {
+	// Extend is ExtendPad, ExtendRepeat or ExtendReflect.
+	Extend uint8
+	Stops  []ColorStop
+}
+
+// PaintLinearGradient fills with a linear gradient from the point
+// (X0, Y0) to (X1, Y1), whose direction is rotated by the point (X2, Y2).
+type PaintLinearGradient struct {
+	ColorLine
+	X0, Y0, X1, Y1, X2, Y2 int16
+	VarIndexBase           uint32
+}
+
+// PaintRadialGradient fills with a gradient between two circles.
+type PaintRadialGradient struct {
+	ColorLine
+	X0, Y0       int16
+	Radius0      uint16
+	X1, Y1       int16
+	Radius1      uint16
+	VarIndexBase uint32
+}
+
+// PaintSweepGradient fills with a gradient around a center.
+type PaintSweepGradient struct {
+	ColorLine
+	CenterX, CenterY int16
+	// StartAngle and EndAngle are expressed in degrees, counter-clockwise.
+	StartAngle, EndAngle float32
+	VarIndexBase         uint32
+}
+
+// PaintGlyph clips Paint by the outline of Glyph.
+type PaintGlyph struct {
+	Paint Paint
+	Glyph GlyphIndex
+}
+
+// PaintColrGlyph paints the color glyph Glyph, defined in the
+// base glyph list of the COLR table.
+type PaintColrGlyph struct {
+	Glyph GlyphIndex
+}
+
+// Affine is an affine transformation, mapping (x, y)
+// to (XX*x + XY*y + DX, YX*x + YY*y + DY).
+type Affine struct {
+	XX, YX, XY, YY, DX, DY float32
+}
+
+// PaintTransform applies a transformation to Paint.
+type PaintTransform struct {
+	Paint        Paint
+	Transform    Affine
+	VarIndexBase uint32
+}
+
+// PaintTranslate applies a translation to Paint.
+type PaintTranslate struct {
+	Paint        Paint
+	DX, DY       int16
+	VarIndexBase uint32
+}
+
+// PaintScale applies a scale to Paint, around the center (CenterX, CenterY).
+// The formats using the origin as center, and the uniform scales are also
+// returned as PaintScale: Format is needed to map the variation deltas.
+type PaintScale struct {
+	Paint            Paint
+	ScaleX, ScaleY   float32
+	CenterX, CenterY int16
+	Format           uint8
+	VarIndexBase     uint32
+}
+
+// PaintRotate applies a rotation to Paint, around the center (CenterX, CenterY).
+// The formats using the origin as center are also returned as PaintRotate.
+type PaintRotate struct {
+	Paint Paint
+	// Angle is expressed in degrees, counter-clockwise.
+	Angle            float32
+	CenterX, CenterY int16
+	Format           uint8
+	VarIndexBase     uint32
+}
+
+// PaintSkew applies a skew to Paint, around the center (CenterX, CenterY).
+// The formats using the origin as center are also returned as PaintSkew.
+type PaintSkew struct {
+	Paint Paint
+	// XSkewAngle and YSkewAngle are expressed in degrees.
+	XSkewAngle, YSkewAngle float32
+	CenterX, CenterY       int16
+	Format                 uint8
+	VarIndexBase           uint32
+}
+
+// PaintComposite composes Source over Backdrop, using one of
+// the composite modes of the specification (such as 3 for "source over").
+type PaintComposite struct {
+	Source, Backdrop Paint
+	Mode             uint8
+}
+
+func (PaintColrLayers) isPaint()     {}
+func (PaintSolid) isPaint()          {}
+func (PaintLinearGradient) isPaint() {}
+func (PaintRadialGradient) isPaint() {}
+func (PaintSweepGradient) isPaint()  {}
+func (PaintGlyph) isPaint()          {}
+func (PaintColrGlyph) isPaint()      {}
+func (PaintTransform) isPaint()      {}
+func (PaintTranslate) isPaint()      {}
+func (PaintScale) isPaint()          {}
+func (PaintRotate) isPaint()         {}
+func (PaintSkew) isPaint()           {}
+func (PaintComposite) isPaint()      {}
+
+// ClipBox bounds the drawing of a color glyph.
+type ClipBox struct {
+	XMin, YMin, XMax, YMax int16
+	VarIndexBase           uint32
+}
+
+type colrBasePaint struct {
+	glyph GlyphIndex
+	paint Paint
+}
+
+type colrClip struct {
+	start, end GlyphIndex
+	box        ClipBox
+}
+
+// Paint returns the root of the paint graph of the color glyph,
+// or nil if the glyph has no (version 1) paint.
+func (t *TableCOLR) Paint(gi GlyphIndex) Paint {
+	i := sort.Search(len(t.basePaints), func(i int) bool { return t.basePaints[i].glyph >= gi })
+	if i == len(t.basePaints) || t.basePaints[i].glyph != gi {
+		return nil
+	}
+	return t.basePaints[i].paint
+}
+
+// ClipBox returns the clip box of the color glyph, if any.
+func (t *TableCOLR) ClipBox(gi GlyphIndex) (ClipBox, bool) {
+	for _, clip := range t.clips {
+		if clip.start <= gi && gi <= clip.end {
+			return clip.box, true
+		}
+	}
+	return ClipBox{}, false
+}
+
+// paintParser decodes the paints of a COLR table, sharing the
+// paints referenced several times.
+type paintParser struct {
+	buf    []byte
+	paints map[int]Paint // by offset in buf
+}
+
+// parseCOLRv1 parses the base glyph list, the layer list
+// and the clip list of the table.
+func (t *TableCOLR) parseCOLRv1(buf []byte) error {
+	const headerSize = 34
+	if len(buf) < headerSize {
+		return errInvalidCOLRTable
+	}
+	baseGlyphsOffset := int(be.Uint32(buf[14:]))
+	layersOffset := int(be.Uint32(buf[18:]))
+	clipsOffset := int(be.Uint32(buf[22:]))
+
+	p := paintParser{buf: buf, paints: map[int]Paint{}}
+
+	if layersOffset != 0 {
+		if len(buf) < layersOffset+4 {
+			return errInvalidCOLRTable
+		}
+		count := int(be.Uint32(buf[layersOffset:]))
+		if len(buf) < layersOffset+4+4*count {
+			return errInvalidCOLRTable
+		}
+		t.LayerList = make([]Paint, count)
+		for i := range t.LayerList {
+			offset := layersOffset + int(be.Uint32(buf[layersOffset+4+4*i:]))
+			paint, err := p.parsePaint(offset, 0)
+			if err != nil {
+				return err
+			}
+			t.LayerList[i] = paint
+		}
+	}
+
+	if baseGlyphsOffset != 0 {
+		const recordSize = 6
+		if len(buf) < baseGlyphsOffset+4 {
+			return errInvalidCOLRTable
+		}
+		count := int(be.Uint32(buf[baseGlyphsOffset:]))
+		if len(buf) < baseGlyphsOffset+4+recordSize*count {
+			return errInvalidCOLRTable
+		}
+		t.basePaints = make([]colrBasePaint, count)
+		for i := range t.basePaints {
+			record := buf[baseGlyphsOffset+4+recordSize*i:]
+			paint, err := p.parsePaint(baseGlyphsOffset+int(be.Uint32(record[2:])), 0)
+			if err != nil {
+				return err
+			}
+			t.basePaints[i] = colrBasePaint{glyph: GlyphIndex(be.Uint16(record)), paint: paint}
+		}
+		sort.SliceStable(t.basePaints, func(i, j int) bool { return t.basePaints[i].glyph < t.basePaints[j].glyph })
+	}
+
+	// check the layers references
+	for _, paint := range p.paints {
+		if layers, ok := paint.(PaintColrLayers); ok && layers.FirstLayer+layers.NumLayers > len(t.LayerList) {
+			return errInvalidCOLRTable
+		}
+	}
+
+	if clipsOffset != 0 {
+		clips, err := parseClipList(buf, clipsOffset)
+		if err != nil {
+			return err
+		}
+		t.clips = clips
+	}
+	return nil
+}
+
+func parseClipList(buf []byte, offset int) ([]colrClip, error) {
+	const headerSize, recordSize = 5, 7
+	if len(buf) < offset+headerSize {
+		return nil, errInvalidCOLRTable
+	}
+	list := buf[offset:]
+	count := int(be.Uint32(list[1:]))
+	if len(list) < headerSize+recordSize*count {
+		return nil, errInvalidCOLRTable
+	}
+	out := make([]colrClip, count)
+	for i := range out {
+		record := list[headerSize+recordSize*i:]
+		boxOffset := int(uint24(record[4:]))
+		if len(list) < boxOffset+9 {
+			return nil, errInvalidCOLRTable
+		}
+		box := list[boxOffset:]
+		out[i] = colrClip{
+			start: GlyphIndex(be.Uint16(record)),
+			end:   GlyphIndex(be.Uint16(record[2:])),
+			box: ClipBox{
+				XMin:         int16(be.Uint16(box[1:])),
+				YMin:         int16(be.Uint16(box[3:])),
+				XMax:         int16(be.Uint16(box[5:])),
+				YMax:         int16(be.Uint16(box[7:])),
+				VarIndexBase: NoVariationIndex,
+			},
+		}
+		if box[0] == 2 { // variable
+			if len(box) < 13 {
+				return nil, errInvalidCOLRTable
+			}
+			out[i].box.VarIndexBase = be.Uint32(box[9:])
+		}
+	}
+	return out, nil
+}
+
+// sizes of the paint tables, for each format
+var paintSizes = [...]int{
+	1: 6, 2: 5, 3: 9, 4: 16, 5: 20, 6: 16, 7: 20, 8: 12, 9: 16,
+	10: 6, 11: 3, 12: 7, 13: 7, 14: 8, 15: 12, 16: 8, 17: 12, 18: 12, 19: 16,
+	20: 6, 21: 10, 22: 10, 23: 14, 24: 6, 25: 10, 26: 10, 27: 14,
+	28: 8, 29: 12, 30: 12, 31: 16, 32: 8,
+}
+
+// parsePaint parses the paint at the given offset of the table.
+func (p *paintParser) parsePaint(offset, depth int) (Paint, error) {
+	if paint, ok := p.paints[offset]; ok {
+		return paint, nil
+	}
+	if depth > maxPaintDepth || len(p.buf) < offset+1 {
+		return nil, errInvalidCOLRTable
+	}
+	b := p.buf[offset:]
+	format := int(b[0])
+	if format == 0 || format >= len(paintSizes) || len(b) < paintSizes[format] {
+		return nil, errInvalidCOLRTable
+	}
+	isVariable := format <= 31 && format >= 3 && format%2 == 1
+
+	// returns the sub-paint stored at the given position in b
+	child := func(position int) (Paint, error) {
+		return p.parsePaint(offset+int(uint24(b[position:])), depth+1)
+	}
+	varIndex := func(position int) uint32 {
+		if isVariable {
+			return be.Uint32(b[position:])
+		}
+		return NoVariationIndex
+	}
+	i16 := func(position int) int16 { return int16(be.Uint16(b[position:])) }
+	f2dot14 := func(position int) float32 { return f2dot14ToFloat(be.Uint16(b[position:])) }
+
+	var (
+		out Paint
+		err error
+	)
+	switch format {
+	case 1:
+		out = PaintColrLayers{NumLayers: int(b[1]), FirstLayer: int(be.Uint32(b[2:]))}
+	case 2, 3:
+		out = PaintSolid{PaletteIndex: be.Uint16(b[1:]), Alpha: f2dot14(3), VarIndexBase: varIndex(5)}
+	case 4, 5:
+		var line ColorLine
+		line, err = p.parseColorLine(offset+int(uint24(b[1:])), isVariable)
+		out = PaintLinearGradient{
+			ColorLine: line,
+			X0:        i16(4), Y0: i16(6), X1: i16(8), Y1: i16(10), X2: i16(12), Y2: i16(14),
+			VarIndexBase: varIndex(16),
+		}
+	case 6, 7:
+		var line ColorLine
+		line, err = p.parseColorLine(offset+int(uint24(b[1:])), isVariable)
+		out = PaintRadialGradient{
+			ColorLine: line,
+			X0:        i16(4), Y0: i16(6), Radius0: be.Uint16(b[8:]),
+			X1: i16(10), Y1: i16(12), Radius1: be.Uint16(b[14:]),
+			VarIndexBase: varIndex(16),
+		}
+	case 8, 9:
+		var line ColorLine
+		line, err = p.parseColorLine(offset+int(uint24(b[1:])), isVariable)
+		out = PaintSweepGradient{
+			ColorLine: line,
+			CenterX:   i16(4), CenterY: i16(6),
+			StartAngle: 180 * f2dot14(8), EndAngle: 180 * f2dot14(10),
+			VarIndexBase: varIndex(12),
+		}
+	case 10:
+		var paint Paint
+		paint, err = child(1)
+		out = PaintGlyph{Paint: paint, Glyph: GlyphIndex(be.Uint16(b[4:]))}
+	case 11:
+		out = PaintColrGlyph{Glyph: GlyphIndex(be.Uint16(b[1:]))}
+	case 12, 13:
+		var paint Paint
+		paint, err = child(1)
+		transformOffset := int(uint24(b[4:]))
+		transformSize := 24
+		if isVariable {
+			transformSize += 4
+		}
+		if len(b) < transformOffset+transformSize {
+			return nil, errInvalidCOLRTable
+		}
+		t := b[transformOffset:]
+		fixed := func(position int) float32 { return fixedToFloat(be.Uint32(t[position:])) }
+		transform := PaintTransform{
+			Paint:        paint,
+			Transform:    Affine{XX: fixed(0), YX: fixed(4), XY: fixed(8), YY: fixed(12), DX: fixed(16), DY: fixed(20)},
+			VarIndexBase: NoVariationIndex,
+		}
+		if isVariable {
+			transform.VarIndexBase = be.Uint32(t[24:])
+		}
+		out = transform
+	case 14, 15:
+		var paint Paint
+		paint, err = child(1)
+		out = PaintTranslate{Paint: paint, DX: i16(4), DY: i16(6), VarIndexBase: varIndex(8)}
+	case 16, 17, 18, 19, 20, 21, 22, 23:
+		var paint Paint
+		paint, err = child(1)
+		scale := PaintScale{Paint: paint, Format: uint8(format)}
+		position := 4
+		if format <= 19 {
+			scale.ScaleX, scale.ScaleY = f2dot14(4), f2dot14(6)
+			position += 4
+		} else { // uniform
+			scale.ScaleX = f2dot14(4)
+			scale.ScaleY = scale.ScaleX
+			position += 2
+		}
+		if format >= 18 && format <= 19 || format >= 22 { // around center
+			scale.CenterX, scale.CenterY = i16(position), i16(position+2)
+			position += 4
+		}
+		scale.VarIndexBase = varIndex(position)
+		out = scale
+	case 24, 25, 26, 27:
+		var paint Paint
+		paint, err = child(1)
+		rotate := PaintRotate{Paint: paint, Angle: 180 * f2dot14(4), Format: uint8(format)}
+		position := 6
+		if format >= 26 {
+			rotate.CenterX, rotate.CenterY = i16(6), i16(8)
+			position += 4
+		}
+		rotate.VarIndexBase = varIndex(position)
+		out = rotate
+	case 28, 29, 30, 31:
+		var paint Paint
+		paint, err = child(1)
+		skew := PaintSkew{Paint: paint, XSkewAngle: 180 * f2dot14(4), YSkewAngle: 180 * f2dot14(6), Format: uint8(format)}
+		position := 8
+		if format >= 30 {
+			skew.CenterX, skew.CenterY = i16(8), i16(10)
+			position += 4
+		}
+		skew.VarIndexBase = varIndex(position)
+		out = skew
+	case 32:
+		var source, backdrop Paint
+		source, err = child(1)
+		if err != nil {
+			return nil, err
+		}
+		backdrop, err = child(5)
+		out = PaintComposite{Source: source, Backdrop: backdrop, Mode: b[4]}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.paints[offset] = out
+	return out, nil
+}
+
+func (p *paintParser) parseColorLine(offset int, isVariable bool) (ColorLine, error) {
+	stopSize := 6
+	if isVariable {
+		stopSize += 4
+	}
+	if len(p.buf) < offset+3 {
+		return ColorLine{}, errInvalidCOLRTable
+	}
+	b := p.buf[offset:]
+	count := int(be.Uint16(b[1:]))
+	if len(b) < 3+stopSize*count {
+		return ColorLine{}, errInvalidCOLRTable
+	}
+	out := ColorLine{Extend: b[0], Stops: make([]ColorStop, count)}
+	for i := range out.Stops {
+		stop := b[3+stopSize*i:]
+		out.Stops[i] = ColorStop{
+			Offset:       f2dot14ToFloat(be.Uint16(stop)),
+			PaletteIndex: be.Uint16(stop[2:]),
+			Alpha:        f2dot14ToFloat(be.Uint16(stop[4:])),
+			VarIndexBase: NoVariationIndex,
+		}
+		if isVariable {
+			out.Stops[i].VarIndexBase = be.Uint32(stop[6:])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,145 @@
+package sfnt
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestCOLR(t *testing.T) {
+	colr := []byte{
+		0, 0, 0, 2, // version, numBaseGlyphRecords
+		0, 0, 0, 14, 0, 0, 0, 26, // baseGlyphRecordsOffset, layerRecordsOffset
+		0, 3, // numLayerRecords
+		0, 5, 0, 1, 0, 2, // glyph 5: layers 1 and 2
+		0, 2, 0, 0, 0, 1, // glyph 2: layer 0 (not sorted)
+		0, 10, 0, 1,
+		0, 11, 0, 0,
+		0, 12, 0xFF, 0xFF,
+	}
+	cpal := []byte{
+		0, 0, 0, 2, 0, 2, 0, 3, // version, numPaletteEntries, numPalettes, numColorRecords
+		0, 0, 0, 16, // colorRecordsArrayOffset
+		0, 0, 0, 1, // colorRecordIndices
+		0xFF, 0, 0, 0xFF, // blue
+		0, 0, 0xFF, 0xFF, // red
+		0, 0xFF, 0, 0x80, // green
+	}
+
+	font := New(TypeTrueType)
+	for tag, content := range map[Tag][]byte{TagCOLR: colr, TagCPAL: cpal} {
+		table, err := ParseTable(tag, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font.AddTable(tag, table)
+	}
+
+	table, err := font.COLRTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gi, expected := range map[GlyphIndex][]ColorLayer{
+		2: {{10, 1}},
+		5: {{11, 0}, {12, ForegroundPaletteIndex}},
+		3: nil,
+	} {
+		if got := table.Layers(gi); !reflect.DeepEqual(got, expected) {
+			t.Errorf("glyph %d: expected %v, got %v", gi, expected, got)
+		}
+	}
+
+	palettes, err := font.CPALTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]color.NRGBA{
+		{{0, 0, 0xFF, 0xFF}, {0xFF, 0, 0, 0xFF}},
+		{{0xFF, 0, 0, 0xFF}, {0, 0xFF, 0, 0x80}},
+	}
+	if !reflect.DeepEqual(palettes.Palettes, expected) {
+		t.Errorf("expected %v, got %v", expected, palettes.Palettes)
+	}
+}
+
+func TestCOLRv1(t *testing.T) {
+	colr := []byte{
+		0, 1, 0, 0, // version, numBaseGlyphRecords
+		0, 0, 0, 0, 0, 0, 0, 0, // baseGlyphRecordsOffset, layerRecordsOffset
+		0, 0, // numLayerRecords
+		0, 0, 0, 34, 0, 0, 0, 77, 0, 0, 0, 137, // baseGlyphListOffset, layerListOffset, clipListOffset
+		0, 0, 0, 0, 0, 0, 0, 0, // varIndexMapOffset, itemVariationStoreOffset
+		// BaseGlyphList, at 34
+		0, 0, 0, 2,
+		0, 7, 0, 0, 0, 22,
+		0, 3, 0, 0, 0, 16,
+		1, 2, 0, 0, 0, 0, // PaintColrLayers, at 50
+		10, 0, 0, 6, 0, 20, // PaintGlyph, at 56
+		26, 0, 0, 10, 0x20, 0, 0, 10, 0, 20, // PaintRotateAroundCenter, at 62
+		2, 0, 1, 0x40, 0, // PaintSolid, at 72
+		// LayerList, at 77
+		0, 0, 0, 2,
+		0, 0, 0, 12, 0, 0, 0, 43,
+		4, 0, 0, 16, 0, 1, 0, 2, 0, 3, 0, 4, 0, 5, 0, 6, // PaintLinearGradient, at 89
+		1, 0, 2, 0, 0, 0, 0, 0x40, 0, 0x40, 0, 0, 2, 0x20, 0, // ColorLine, at 105
+		32, 0, 0, 8, 3, 0, 0, 8, // PaintComposite, at 120
+		3, 0xFF, 0xFF, 0x40, 0, 0, 0, 0, 5, // PaintVarSolid, at 128
+		// ClipList, at 137
+		1, 0, 0, 0, 1,
+		0, 3, 0, 7, 0, 0, 12,
+		1, 0xFF, 0xF6, 0xFF, 0xEC, 0, 100, 0, 200, // ClipBox, at 149
+	}
+
+	table, err := parseTableCOLR(TagCOLR, colr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colrTable := table.(*TableCOLR)
+
+	solid := PaintSolid{PaletteIndex: 1, Alpha: 1, VarIndexBase: NoVariationIndex}
+	for gi, expected := range map[GlyphIndex]Paint{
+		7: PaintGlyph{
+			Paint: PaintRotate{Paint: solid, Angle: 90, CenterX: 10, CenterY: 20, Format: 26, VarIndexBase: NoVariationIndex},
+			Glyph: 20,
+		},
+		3: PaintColrLayers{FirstLayer: 0, NumLayers: 2},
+		4: nil,
+	} {
+		if got := colrTable.Paint(gi); !reflect.DeepEqual(got, expected) {
+			t.Errorf("glyph %d: expected %v, got %v", gi, expected, got)
+		}
+	}
+
+	varSolid := PaintSolid{PaletteIndex: ForegroundPaletteIndex, Alpha: 1, VarIndexBase: 5}
+	expectedLayers := []Paint{
+		PaintLinearGradient{
+			ColorLine: ColorLine{Extend: ExtendRepeat, Stops: []ColorStop{
+				{Offset: 0, PaletteIndex: 0, Alpha: 1, VarIndexBase: NoVariationIndex},
+				{Offset: 1, PaletteIndex: 2, Alpha: 0.5, VarIndexBase: NoVariationIndex},
+			}},
+			X0: 1, Y0: 2, X1: 3, Y1: 4, X2: 5, Y2: 6,
+			VarIndexBase: NoVariationIndex,
+		},
+		PaintComposite{Source: varSolid, Backdrop: varSolid, Mode: 3},
+	}
+	if !reflect.DeepEqual(colrTable.LayerList, expectedLayers) {
+		t.Errorf("expected %v, got %v", expectedLayers, colrTable.LayerList)
+	}
+
+	box, ok := colrTable.ClipBox(5)
+	if expected := (ClipBox{-10, -20, 100, 200, NoVariationIndex}); !ok || box != expected {
+		t.Errorf("expected clip box %v, got %v", expected, box)
+	}
+	if _, ok := colrTable.ClipBox(8); ok {
+		t.Error("unexpected clip box")
+	}
+
+	// a paint referencing itself
+	cycle := append(colr[:34:34], 0, 0, 0, 1, 0, 1, 0, 0, 0, 10, 14, 0, 0, 0, 0, 0, 0, 0)
+	for i := 18; i < 26; i++ { // no layers nor clips
+		cycle[i] = 0
+	}
+	if _, err := parseTableCOLR(TagCOLR, cycle); err != errInvalidCOLRTable {
+		t.Errorf("expected error for cyclic paints, got %v", err)
+	}
+}
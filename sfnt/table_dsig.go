@@ -0,0 +1,293 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+var (
+	errInvalidDSIGTable = errors.New("invalid DSIG table")
+	errInvalidSignedOTF = errors.New("invalid table directory in signed font")
+	errUnsignedFormat   = errors.New("only the signatures of OpenType files may be verified")
+)
+
+// ErrNoSignature is returned by VerifySignatures if the 'DSIG' table
+// of the font has no signature.
+var ErrNoSignature = errors.New("the DSIG table has no signature")
+
+// ErrCannotResign is returned by Sign if the existing 'DSIG' table
+// has the DSIGCannotResign flag.
+var ErrCannotResign = errors.New("the DSIG table may not be re-signed")
+
+// DSIGCannotResign is the DSIG flag indicating that the font
+// may not be re-signed.
+const DSIGCannotResign = 1
+
+// TableDSIG is the digital signature table.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/dsig
+type TableDSIG struct {
+	baseTable
+
+	Flags uint16
+	// Signatures are the PKCS#7 packets (format 1 signature blocks).
+	Signatures [][]byte
+}
+
+type dsigHeader struct {
+	Version       uint32
+	NumSignatures uint16
+	Flags         uint16
+}
+
+type dsigSignatureRecord struct {
+	Format uint32
+	Length uint32
+	Offset uint32
+}
+
+type dsigSignatureBlock1 struct {
+	Reserved1       uint16
+	Reserved2       uint16
+	SignatureLength uint32
+}
+
+// Bytes returns the representation of this table to be stored in a font.
+func (t *TableDSIG) Bytes() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, dsigHeader{
+		Version:       1,
+		NumSignatures: uint16(len(t.Signatures)),
+		Flags:         t.Flags,
+	})
+
+	offset := binary.Size(dsigHeader{}) + len(t.Signatures)*binary.Size(dsigSignatureRecord{})
+	for _, signature := range t.Signatures {
+		length := binary.Size(dsigSignatureBlock1{}) + len(signature)
+		binary.Write(&buf, binary.BigEndian, dsigSignatureRecord{
+			Format: 1,
+			Length: uint32(length),
+			Offset: uint32(offset),
+		})
+		offset += length
+	}
+
+	for _, signature := range t.Signatures {
+		binary.Write(&buf, binary.BigEndian, dsigSignatureBlock1{SignatureLength: uint32(len(signature))})
+		buf.Write(signature)
+	}
+
+	return buf.Bytes()
+}
+
+func parseTableDSIG(tag Tag, buf []byte) (Table, error) {
+	r := bytes.NewReader(buf)
+	var header dsigHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, errInvalidDSIGTable
+	}
+	records := make([]dsigSignatureRecord, header.NumSignatures)
+	if err := binary.Read(r, binary.BigEndian, records); err != nil {
+		return nil, errInvalidDSIGTable
+	}
+
+	out := &TableDSIG{baseTable: baseTable(tag), Flags: header.Flags}
+	for _, record := range records {
+		if record.Format != 1 { // no other format is defined
+			continue
+		}
+		const blockHeaderSize = 8
+		if int64(len(buf)) < int64(record.Offset)+blockHeaderSize {
+			return nil, errInvalidDSIGTable
+		}
+		block := buf[record.Offset:]
+		length := int64(be.Uint32(block[4:]))
+		if int64(len(block)) < blockHeaderSize+length {
+			return nil, errInvalidDSIGTable
+		}
+		out.Signatures = append(out.Signatures, block[blockHeaderSize:blockHeaderSize+length])
+	}
+	return out, nil
+}
+
+// DSIGTable returns the digital signature table.
+func (font *Font) DSIGTable() (*TableDSIG, error) {
+	t, err := font.Table(TagDSIG)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableDSIG), nil
+}
+
+// Signer computes a PKCS#7 signature.
+type Signer interface {
+	// Sign returns the DER encoded PKCS#7 SignedData packet
+	// signing the given content.
+	Sign(content []byte) ([]byte, error)
+}
+
+// Sign computes a new signature for the font and stores it
+// in the 'DSIG' table, replacing any existing one.
+// The signed content is the font file written by WriteTo, as
+// defined by signedContent, so that the signature is valid for
+// the file written after this call, as long as the font is not modified.
+// It returns ErrCannotResign if the flags of the existing 'DSIG' table
+// forbid re-signing. The font is left unchanged if an error occurs.
+func (font *Font) Sign(signer Signer) error {
+	if font.HasTable(TagDSIG) {
+		dsig, err := font.DSIGTable()
+		if err != nil {
+			return err
+		}
+		if dsig.Flags&DSIGCannotResign != 0 {
+			return ErrCannotResign
+		}
+	}
+
+	// the signed content does not include the 'DSIG' table:
+	// lay out a copy of the font without it
+	unsigned := *font
+	unsigned.tables = make(map[Tag]*tableSection, len(font.tables))
+	for tag, section := range font.tables {
+		if tag != TagDSIG {
+			unsigned.tables[tag] = section
+		}
+	}
+
+	layout, err := unsigned.layoutOTF(WriteOptions{})
+	if err != nil {
+		return err
+	}
+	var file bytes.Buffer
+	if _, err := layout.writeTo(&file); err != nil {
+		return err
+	}
+	content, err := signedContent(file.Bytes())
+	if err != nil {
+		return err
+	}
+
+	signature, err := signer.Sign(content)
+	if err != nil {
+		return err
+	}
+
+	font.AddTable(TagDSIG, &TableDSIG{
+		baseTable:  baseTable(TagDSIG),
+		Signatures: [][]byte{signature},
+	})
+	return nil
+}
+
+// Verifier checks a PKCS#7 signature.
+type Verifier interface {
+	// Verify returns an error if the DER encoded PKCS#7 SignedData
+	// packet is not a valid signature of the given content.
+	Verify(content, signature []byte) error
+}
+
+// VerifySignatures checks that the signatures of the 'DSIG' table
+// match the file the font has been parsed from, which must be an
+// OpenType file: the modifications of the font are not taken into account.
+// The signed content is defined by signedContent.
+// It returns ErrMissingTable if the font has no 'DSIG' table,
+// ErrNoSignature if the table is empty, and the first verification
+// error otherwise.
+func (font *Font) VerifySignatures(verifier Verifier) error {
+	dsig, err := font.DSIGTable()
+	if err != nil {
+		return err
+	}
+	if len(dsig.Signatures) == 0 {
+		return ErrNoSignature
+	}
+	if font.header == nil { // WOFF, WOFF2 and collections
+		return errUnsignedFormat
+	}
+
+	file, err := ioutil.ReadAll(io.NewSectionReader(font.file, 0, math.MaxInt64))
+	if err != nil {
+		return err
+	}
+	content, err := signedContent(file)
+	if err != nil {
+		return err
+	}
+
+	for _, signature := range dsig.Signatures {
+		if err := verifier.Verify(content, signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signedContent returns the content of the OpenType file covered by
+// its signatures, that is the file without the 'DSIG' table:
+// its directory entry and its data are removed, and the header and the
+// offsets of the other tables are adjusted accordingly.
+// Since the checkSumAdjustment field of the 'head' table depends on
+// the 'DSIG' table, it is set to 0.
+func signedContent(file []byte) ([]byte, error) {
+	if len(file) < otfHeaderLength {
+		return nil, errInvalidSignedOTF
+	}
+	numTables := int(be.Uint16(file[4:]))
+	directoryEnd := otfHeaderLength + directoryEntryLength*numTables
+	if len(file) < directoryEnd {
+		return nil, errInvalidSignedOTF
+	}
+	entries := make([][]byte, 0, numTables)
+	dsigStart, dsigEnd := len(file), len(file) // the removed data
+	for i := 0; i < numTables; i++ {
+		entry := file[otfHeaderLength+directoryEntryLength*i : otfHeaderLength+directoryEntryLength*(i+1)]
+		if NewTag(entry) != TagDSIG {
+			entries = append(entries, entry)
+			continue
+		}
+		offset, length := int(be.Uint32(entry[8:])), int(be.Uint32(entry[12:]))
+		if dsigStart != len(file) || offset < directoryEnd || len(file)-offset < length {
+			return nil, errInvalidSignedOTF
+		}
+		dsigStart, dsigEnd = offset, offset+length+padding(length)
+		if dsigEnd > len(file) {
+			dsigEnd = len(file)
+		}
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(file))
+	binary.Write(&out, binary.BigEndian, newOTFHeader(NewTag(file), uint16(len(entries))))
+	headOffset := -1
+	for _, entry := range entries {
+		offset := int(be.Uint32(entry[8:]))
+		if offset < directoryEnd || dsigStart <= offset && offset < dsigEnd {
+			return nil, errInvalidSignedOTF
+		}
+		// the data of the 'DSIG' table is removed...
+		if offset >= dsigEnd {
+			offset -= dsigEnd - dsigStart
+		}
+		// ... as well as its directory entry
+		offset -= directoryEntryLength * (numTables - len(entries))
+		if NewTag(entry) == TagHead {
+			headOffset = offset
+		}
+		out.Write(entry[:8])
+		binary.Write(&out, binary.BigEndian, uint32(offset))
+		out.Write(entry[12:])
+	}
+	out.Write(file[directoryEnd:dsigStart])
+	out.Write(file[dsigEnd:])
+
+	// the checkSumAdjustment field is at offset 8 of the 'head' table
+	content := out.Bytes()
+	if headOffset == -1 || len(content) < headOffset+12 {
+		return nil, errInvalidSignedOTF
+	}
+	be.PutUint32(content[headOffset+8:], 0)
+	return content, nil
+}
@@ -0,0 +1,196 @@
+package sfnt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type hashSigner struct{}
+
+func (hashSigner) Sign(content []byte) ([]byte, error) {
+	sum := sha256.Sum256(content)
+	return sum[:], nil
+}
+
+func TestSign(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := font.Sign(hashSigner{}); err != nil {
+		t.Fatal(err)
+	}
+	if !font.HasTable(TagDSIG) {
+		t.Fatal("missing DSIG table")
+	}
+
+	table, err := font.Table(TagDSIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := table.Bytes()
+	if len(b) != 8+12+8+sha256.Size {
+		t.Fatalf("unexpected DSIG length %d", len(b))
+	}
+	if version, count := be.Uint32(b), be.Uint16(b[4:]); version != 1 || count != 1 {
+		t.Errorf("unexpected DSIG header %d %d", version, count)
+	}
+
+	// the signature must not depend on the previous one
+	expected := b[len(b)-sha256.Size:]
+	if err := font.Sign(hashSigner{}); err != nil {
+		t.Fatal(err)
+	}
+	table, _ = font.Table(TagDSIG)
+	b = table.Bytes()
+	if !bytes.Equal(expected, b[len(b)-sha256.Size:]) {
+		t.Error("signature is not reproducible")
+	}
+
+	// the signature covers the written file, without its 'DSIG' table
+	var file bytes.Buffer
+	if _, err := font.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	content, err := signedContent(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(content); !bytes.Equal(expected, sum[:]) {
+		t.Error("signature does not match the written file")
+	}
+}
+
+var errBadSignature = errors.New("bad signature")
+
+type hashVerifier struct{}
+
+func (hashVerifier) Verify(content, signature []byte) error {
+	sum := sha256.Sum256(content)
+	if !bytes.Equal(sum[:], signature) {
+		return errBadSignature
+	}
+	return nil
+}
+
+// writeSignedFont signs the font and writes it to a file,
+// returning the font parsed from this file.
+func writeSignedFont(t *testing.T, font *Font, path string) *Font {
+	t.Helper()
+	if err := font.Sign(hashSigner{}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return parseSignedFile(t, path, buf.Bytes())
+}
+
+func parseSignedFile(t *testing.T, path string, content []byte) *Font {
+	t.Helper()
+	if err := ioutil.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+func TestVerifySignatures(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	if err := font.VerifySignatures(hashVerifier{}); err != ErrMissingTable {
+		t.Errorf("expected ErrMissingTable, got %v", err)
+	}
+	font.AddTable(TagDSIG, &TableDSIG{baseTable: baseTable(TagDSIG)})
+	if err := font.VerifySignatures(hashVerifier{}); err != ErrNoSignature {
+		t.Errorf("expected ErrNoSignature, got %v", err)
+	}
+
+	dir := t.TempDir()
+	signed := writeSignedFont(t, font, filepath.Join(dir, "signed.ttf"))
+	dsig, err := signed.DSIGTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := font.DSIGTable()
+	if !reflect.DeepEqual(dsig.Signatures, expected.Signatures) {
+		t.Errorf("expected %v, got %v", expected.Signatures, dsig.Signatures)
+	}
+	if err := signed.VerifySignatures(hashVerifier{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the signatures are checked against the file, not the modified font
+	signed.AddTable(TagGasp, NewTable(TagGasp, []byte{0, 1, 0, 0}))
+	if err := signed.VerifySignatures(hashVerifier{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// modifying the file invalidates the signatures
+	layout, err := font.layoutOTF(WriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := layout.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	file[layout.entries[TagGlyf].Offset+20] ^= 1
+	corrupted := parseSignedFile(t, filepath.Join(dir, "corrupted.ttf"), file)
+	if err := corrupted.VerifySignatures(hashVerifier{}); err != errBadSignature {
+		t.Errorf("expected a bad signature, got %v", err)
+	}
+
+	if _, err := parseTableDSIG(TagDSIG, dsig.Bytes()[:20]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
+
+type failingSigner struct{}
+
+func (failingSigner) Sign(content []byte) ([]byte, error) { return nil, errBadSignature }
+
+func TestSignErrors(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+
+	// a failing signer leaves the existing signature
+	original := &TableDSIG{baseTable: baseTable(TagDSIG), Signatures: [][]byte{{1, 2, 3}}}
+	font.AddTable(TagDSIG, original)
+	if err := font.Sign(failingSigner{}); err != errBadSignature {
+		t.Fatalf("expected a signing error, got %v", err)
+	}
+	if dsig, err := font.DSIGTable(); err != nil || dsig != original {
+		t.Errorf("the DSIG table has been modified: %v %v", dsig, err)
+	}
+
+	// the flagged fonts may not be re-signed
+	flagged := &TableDSIG{baseTable: baseTable(TagDSIG), Flags: DSIGCannotResign, Signatures: [][]byte{{1, 2, 3}}}
+	font.AddTable(TagDSIG, flagged)
+	if err := font.Sign(hashSigner{}); err != ErrCannotResign {
+		t.Fatalf("expected ErrCannotResign, got %v", err)
+	}
+	if dsig, err := font.DSIGTable(); err != nil || dsig != flagged {
+		t.Errorf("the DSIG table has been modified: %v %v", dsig, err)
+	}
+}
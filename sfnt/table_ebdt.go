@@ -0,0 +1,430 @@
+package sfnt
+
+import "errors"
+
+var (
+	errInvalidEBLCTable = errors.New("invalid EBLC table")
+	errInvalidEBDTTable = errors.New("invalid EBDT table")
+	errInvalidEBSCTable = errors.New("invalid EBSC table")
+
+	// ErrMissingBitmap is returned by BitmapGlyph when the
+	// strike has no bitmap for the glyph.
+	ErrMissingBitmap = errors.New("missing bitmap for glyph")
+)
+
+// SbitLineMetrics are the line metrics of a bitmap strike, in pixels.
+type SbitLineMetrics struct {
+	Ascender, Descender   int8
+	WidthMax              uint8
+	CaretSlopeNumerator   int8
+	CaretSlopeDenominator int8
+	CaretOffset           int8
+	MinOriginSB           int8
+	MinAdvanceSB          int8
+	MaxBeforeBL           int8
+	MinAfterBL            int8
+}
+
+func parseSbitLineMetrics(b []byte) SbitLineMetrics {
+	return SbitLineMetrics{
+		Ascender:              int8(b[0]),
+		Descender:             int8(b[1]),
+		WidthMax:              b[2],
+		CaretSlopeNumerator:   int8(b[3]),
+		CaretSlopeDenominator: int8(b[4]),
+		CaretOffset:           int8(b[5]),
+		MinOriginSB:           int8(b[6]),
+		MinAdvanceSB:          int8(b[7]),
+		MaxBeforeBL:           int8(b[8]),
+		MinAfterBL:            int8(b[9]),
+	}
+}
+
+// TableEBLC is the embedded bitmap location table, which
+// describes the bitmap strikes stored in the 'EBDT' table.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/eblc
+type TableEBLC struct {
+	baseTable
+
+	bytes []byte
+
+	Strikes []BitmapStrike
+}
+
+// BitmapStrike describes the bitmaps of a range of glyphs, for one size.
+type BitmapStrike struct {
+	Hori, Vert           SbitLineMetrics
+	StartGlyph, EndGlyph GlyphIndex
+	PPEMX, PPEMY         uint8
+	BitDepth             uint8 // 1, 2, 4 or 8 bits per pixel
+	Flags                int8  // 1 for horizontal metrics, 2 for vertical metrics
+
+	subtables []bitmapIndexSubtable
+}
+
+// bitmapIndexSubtable locates the bitmaps of the glyphs first to last.
+type bitmapIndexSubtable struct {
+	first, last     GlyphIndex
+	indexFormat     uint16
+	imageFormat     uint16
+	imageDataOffset int    // in the EBDT table
+	data            []byte // starting after the subtable header
+}
+
+// Bytes returns the bytes for this table. The TableEBLC is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableEBLC) Bytes() []byte {
+	return t.bytes
+}
+
+// Strike returns the index of the strike designed for the given size,
+// or false if there is none.
+func (t *TableEBLC) Strike(ppemX, ppemY uint8) (int, bool) {
+	for i, strike := range t.Strikes {
+		if strike.PPEMX == ppemX && strike.PPEMY == ppemY {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func parseTableEBLC(tag Tag, buf []byte) (Table, error) {
+	const headerSize, sizeRecordSize, arrayRecordSize, subtableHeaderSize = 8, 48, 8, 8
+	if len(buf) < headerSize {
+		return nil, errInvalidEBLCTable
+	}
+	numSizes := int(be.Uint32(buf[4:]))
+	if len(buf) < headerSize+sizeRecordSize*numSizes {
+		return nil, errInvalidEBLCTable
+	}
+	out := &TableEBLC{baseTable: baseTable(tag), bytes: buf, Strikes: make([]BitmapStrike, numSizes)}
+	for i := range out.Strikes {
+		record := buf[headerSize+sizeRecordSize*i:]
+		strike := BitmapStrike{
+			Hori:       parseSbitLineMetrics(record[16:]),
+			Vert:       parseSbitLineMetrics(record[28:]),
+			StartGlyph: GlyphIndex(be.Uint16(record[40:])),
+			EndGlyph:   GlyphIndex(be.Uint16(record[42:])),
+			PPEMX:      record[44],
+			PPEMY:      record[45],
+			BitDepth:   record[46],
+			Flags:      int8(record[47]),
+		}
+
+		arrayOffset := int(be.Uint32(record))
+		numSubtables := int(be.Uint32(record[8:]))
+		if len(buf) < arrayOffset+arrayRecordSize*numSubtables {
+			return nil, errInvalidEBLCTable
+		}
+		strike.subtables = make([]bitmapIndexSubtable, numSubtables)
+		for j := range strike.subtables {
+			arrayRecord := buf[arrayOffset+arrayRecordSize*j:]
+			offset := arrayOffset + int(be.Uint32(arrayRecord[4:]))
+			if len(buf) < offset+subtableHeaderSize {
+				return nil, errInvalidEBLCTable
+			}
+			subtable := bitmapIndexSubtable{
+				first:           GlyphIndex(be.Uint16(arrayRecord)),
+				last:            GlyphIndex(be.Uint16(arrayRecord[2:])),
+				indexFormat:     be.Uint16(buf[offset:]),
+				imageFormat:     be.Uint16(buf[offset+2:]),
+				imageDataOffset: int(be.Uint32(buf[offset+4:])),
+				data:            buf[offset+subtableHeaderSize:],
+			}
+			if subtable.first > subtable.last {
+				return nil, errInvalidEBLCTable
+			}
+			strike.subtables[j] = subtable
+		}
+		out.Strikes[i] = strike
+	}
+	return out, nil
+}
+
+// BitmapMetrics are the metrics of a bitmap glyph, in pixels.
+// The vertical metrics are zero for the glyphs stored
+// with only horizontal metrics.
+type BitmapMetrics struct {
+	Height, Width              uint8
+	HoriBearingX, HoriBearingY int8
+	HoriAdvance                uint8
+	VertBearingX, VertBearingY int8
+	VertAdvance                uint8
+}
+
+func parseBigGlyphMetrics(b []byte) BitmapMetrics {
+	return BitmapMetrics{
+		Height: b[0], Width: b[1],
+		HoriBearingX: int8(b[2]), HoriBearingY: int8(b[3]), HoriAdvance: b[4],
+		VertBearingX: int8(b[5]), VertBearingY: int8(b[6]), VertAdvance: b[7],
+	}
+}
+
+func parseSmallGlyphMetrics(b []byte) BitmapMetrics {
+	return BitmapMetrics{
+		Height: b[0], Width: b[1],
+		HoriBearingX: int8(b[2]), HoriBearingY: int8(b[3]), HoriAdvance: b[4],
+	}
+}
+
+// BitmapComponent is a component of a composite bitmap glyph.
+type BitmapComponent struct {
+	Glyph            GlyphIndex
+	XOffset, YOffset int8 // position of the component, in pixels
+}
+
+// BitmapGlyph is the bitmap of a glyph in a strike.
+type BitmapGlyph struct {
+	Metrics BitmapMetrics
+	// Data stores the pixels, row by row, from the top, using the
+	// bit depth of the strike. The rows are padded to a byte boundary,
+	// unless BitAligned is true.
+	Data       []byte
+	BitAligned bool
+	// Components is not empty for the composite glyphs, which have no Data.
+	Components []BitmapComponent
+}
+
+// location returns the location of the glyph data in the EBDT table,
+// and the metrics defined in the EBLC table, if any.
+func (s *bitmapIndexSubtable) location(gi GlyphIndex) (start, end int, metrics *BitmapMetrics, err error) {
+	index := int(gi - s.first)
+	switch s.indexFormat {
+	case 1, 3: // variable metrics, with 32 or 16 bits offsets
+		size := 4
+		if s.indexFormat == 3 {
+			size = 2
+		}
+		if len(s.data) < size*(index+2) {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		if size == 4 {
+			start, end = int(be.Uint32(s.data[4*index:])), int(be.Uint32(s.data[4*index+4:]))
+		} else {
+			start, end = int(be.Uint16(s.data[2*index:])), int(be.Uint16(s.data[2*index+2:]))
+		}
+	case 2: // constant metrics
+		if len(s.data) < 12 {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		imageSize := int(be.Uint32(s.data))
+		m := parseBigGlyphMetrics(s.data[4:])
+		start, end, metrics = imageSize*index, imageSize*(index+1), &m
+	case 4: // sparse glyphs, variable metrics
+		if len(s.data) < 4 {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		numGlyphs := int(be.Uint32(s.data))
+		if len(s.data) < 4+4*(numGlyphs+1) {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		found := false
+		for i := 0; i < numGlyphs; i++ {
+			pair := s.data[4+4*i:]
+			if GlyphIndex(be.Uint16(pair)) == gi {
+				start, end, found = int(be.Uint16(pair[2:])), int(be.Uint16(pair[6:])), true
+				break
+			}
+		}
+		if !found {
+			return 0, 0, nil, ErrMissingBitmap
+		}
+	case 5: // sparse glyphs, constant metrics
+		if len(s.data) < 16 {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		imageSize := int(be.Uint32(s.data))
+		m := parseBigGlyphMetrics(s.data[4:])
+		numGlyphs := int(be.Uint32(s.data[12:]))
+		if len(s.data) < 16+2*numGlyphs {
+			return 0, 0, nil, errInvalidEBLCTable
+		}
+		index = -1
+		for i := 0; i < numGlyphs; i++ {
+			if GlyphIndex(be.Uint16(s.data[16+2*i:])) == gi {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return 0, 0, nil, ErrMissingBitmap
+		}
+		start, end, metrics = imageSize*index, imageSize*(index+1), &m
+	default:
+		return 0, 0, nil, errInvalidEBLCTable
+	}
+	if start > end {
+		return 0, 0, nil, errInvalidEBLCTable
+	}
+	return s.imageDataOffset + start, s.imageDataOffset + end, metrics, nil
+}
+
+// parseBitmapGlyph decodes the glyph data stored in the EBDT table.
+func parseBitmapGlyph(data []byte, imageFormat uint16, metrics *BitmapMetrics) (BitmapGlyph, error) {
+	var out BitmapGlyph
+	switch imageFormat {
+	case 1, 2, 8: // small metrics
+		if len(data) < 5 {
+			return out, errInvalidEBDTTable
+		}
+		out.Metrics, data = parseSmallGlyphMetrics(data), data[5:]
+		if imageFormat == 8 { // padding
+			if len(data) < 1 {
+				return out, errInvalidEBDTTable
+			}
+			data = data[1:]
+		}
+	case 6, 7, 9: // big metrics
+		if len(data) < 8 {
+			return out, errInvalidEBDTTable
+		}
+		out.Metrics, data = parseBigGlyphMetrics(data), data[8:]
+	case 5: // metrics in the EBLC table
+		if metrics == nil {
+			return out, errInvalidEBDTTable
+		}
+		out.Metrics = *metrics
+	default:
+		return out, errInvalidEBDTTable
+	}
+
+	switch imageFormat {
+	case 8, 9: // composite
+		if len(data) < 2 {
+			return out, errInvalidEBDTTable
+		}
+		count := int(be.Uint16(data))
+		if len(data) < 2+4*count {
+			return out, errInvalidEBDTTable
+		}
+		out.Components = make([]BitmapComponent, count)
+		for i := range out.Components {
+			component := data[2+4*i:]
+			out.Components[i] = BitmapComponent{
+				Glyph:   GlyphIndex(be.Uint16(component)),
+				XOffset: int8(component[2]),
+				YOffset: int8(component[3]),
+			}
+		}
+	default:
+		out.Data = data
+		out.BitAligned = imageFormat == 2 || imageFormat == 5 || imageFormat == 7
+	}
+	return out, nil
+}
+
+// BitmapGlyph returns the bitmap of the glyph in the given strike
+// of the 'EBLC' table (see TableEBLC.Strike), read from the 'EBDT' table.
+// ErrMissingBitmap is returned if the strike has no bitmap for the glyph.
+func (font *Font) BitmapGlyph(strike int, gi GlyphIndex) (BitmapGlyph, error) {
+	eblc, err := font.EBLCTable()
+	if err != nil {
+		return BitmapGlyph{}, err
+	}
+	if strike < 0 || strike >= len(eblc.Strikes) {
+		return BitmapGlyph{}, ErrMissingBitmap
+	}
+	s, found := font.tables[TagEBDT]
+	if !found {
+		return BitmapGlyph{}, ErrMissingTable
+	}
+	ebdt, err := font.findTableBuffer(s)
+	if err != nil {
+		return BitmapGlyph{}, err
+	}
+
+	for _, subtable := range eblc.Strikes[strike].subtables {
+		if gi < subtable.first || gi > subtable.last {
+			continue
+		}
+		start, end, metrics, err := subtable.location(gi)
+		if err != nil {
+			return BitmapGlyph{}, err
+		}
+		if start == end {
+			return BitmapGlyph{}, ErrMissingBitmap
+		}
+		if len(ebdt) < end {
+			return BitmapGlyph{}, errInvalidEBDTTable
+		}
+		return parseBitmapGlyph(ebdt[start:end], subtable.imageFormat, metrics)
+	}
+	return BitmapGlyph{}, ErrMissingBitmap
+}
+
+// EBLCTable returns the embedded bitmap location table.
+func (font *Font) EBLCTable() (*TableEBLC, error) {
+	t, err := font.Table(TagEBLC)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableEBLC), nil
+}
+
+// TableEBSC is the embedded bitmap scaling table, which lists the sizes
+// for which the bitmaps of another strike should be scaled.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/ebsc
+type TableEBSC struct {
+	baseTable
+
+	bytes []byte
+
+	Scales []BitmapScale
+}
+
+// BitmapScale indicates that the size PPEMX, PPEMY should use
+// the strike SubstitutePPEMX, SubstitutePPEMY, scaled.
+type BitmapScale struct {
+	Hori, Vert                       SbitLineMetrics
+	PPEMX, PPEMY                     uint8
+	SubstitutePPEMX, SubstitutePPEMY uint8
+}
+
+// Bytes returns the bytes for this table. The TableEBSC is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableEBSC) Bytes() []byte {
+	return t.bytes
+}
+
+// Scale returns the scaling record for the given size, if any.
+func (t *TableEBSC) Scale(ppemX, ppemY uint8) (BitmapScale, bool) {
+	for _, scale := range t.Scales {
+		if scale.PPEMX == ppemX && scale.PPEMY == ppemY {
+			return scale, true
+		}
+	}
+	return BitmapScale{}, false
+}
+
+func parseTableEBSC(tag Tag, buf []byte) (Table, error) {
+	const headerSize, recordSize = 8, 28
+	if len(buf) < headerSize {
+		return nil, errInvalidEBSCTable
+	}
+	numSizes := int(be.Uint32(buf[4:]))
+	if len(buf) < headerSize+recordSize*numSizes {
+		return nil, errInvalidEBSCTable
+	}
+	out := &TableEBSC{baseTable: baseTable(tag), bytes: buf, Scales: make([]BitmapScale, numSizes)}
+	for i := range out.Scales {
+		record := buf[headerSize+recordSize*i:]
+		out.Scales[i] = BitmapScale{
+			Hori:            parseSbitLineMetrics(record),
+			Vert:            parseSbitLineMetrics(record[12:]),
+			PPEMX:           record[24],
+			PPEMY:           record[25],
+			SubstitutePPEMX: record[26],
+			SubstitutePPEMY: record[27],
+		}
+	}
+	return out, nil
+}
+
+// EBSCTable returns the embedded bitmap scaling table.
+func (font *Font) EBSCTable() (*TableEBSC, error) {
+	t, err := font.Table(TagEBSC)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableEBSC), nil
+}
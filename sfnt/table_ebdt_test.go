@@ -0,0 +1,91 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEmbeddedBitmaps(t *testing.T) {
+	eblc := []byte{
+		0, 2, 0, 0, 0, 0, 0, 1, // version, numSizes
+		// BitmapSize
+		0, 0, 0, 56, 0, 0, 0, 76, 0, 0, 0, 3, 0, 0, 0, 0, // indexSubTableArrayOffset, indexTablesSize, numberOfIndexSubTables, colorRef
+		8, 0xFE, 8, 1, 0, 0, 0, 0, 0, 0, 0, 0, // hori
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // vert
+		0, 1, 0, 10, 10, 12, 1, 1, // startGlyphIndex, endGlyphIndex, ppemX, ppemY, bitDepth, flags
+		// IndexSubTableArray, at 56
+		0, 1, 0, 2, 0, 0, 0, 24,
+		0, 5, 0, 6, 0, 0, 0, 44,
+		0, 10, 0, 10, 0, 0, 0, 64,
+		0, 1, 0, 1, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 7, // format 1, at 80
+		0, 2, 0, 5, 0, 0, 0, 11, 0, 0, 0, 2, 2, 8, 0, 2, 9, 0, 0, 0, // format 2, at 100
+		0, 3, 0, 8, 0, 0, 0, 15, 0, 0, 0, 16, // format 3, at 120
+	}
+	ebdt := []byte{
+		0, 2, 0, 0, // version
+		2, 8, 1, 2, 9, 0xFF, 0x81, // glyph 1, image format 1
+		0xAA, 0x55, 0x0F, 0xF0, // glyphs 5 and 6, image format 5
+		2, 8, 0, 2, 9, 0, 0, 2, 0, 5, 0, 0, 0, 6, 1, 0, // glyph 10, image format 8
+	}
+	ebsc := []byte{
+		0, 2, 0, 0, 0, 0, 0, 1, // version, numSizes
+		9, 0xFE, 9, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		11, 13, 10, 12,
+	}
+
+	font := New(TypeTrueType)
+	for tag, content := range map[Tag][]byte{TagEBLC: eblc, TagEBSC: ebsc} {
+		table, err := ParseTable(tag, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font.AddTable(tag, table)
+	}
+	font.AddTable(TagEBDT, NewTable(TagEBDT, ebdt))
+
+	table, err := font.EBLCTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	strike, ok := table.Strike(10, 12)
+	if !ok {
+		t.Fatal("missing strike")
+	}
+	if s := table.Strikes[strike]; s.Hori.Ascender != 8 || s.Hori.Descender != -2 || s.BitDepth != 1 {
+		t.Errorf("unexpected strike %v", s)
+	}
+	if _, ok := table.Strike(11, 13); ok {
+		t.Error("unexpected strike")
+	}
+
+	constantMetrics := BitmapMetrics{Height: 2, Width: 8, HoriBearingY: 2, HoriAdvance: 9}
+	for gi, expected := range map[GlyphIndex]BitmapGlyph{
+		1:  {Metrics: BitmapMetrics{Height: 2, Width: 8, HoriBearingX: 1, HoriBearingY: 2, HoriAdvance: 9}, Data: []byte{0xFF, 0x81}},
+		5:  {Metrics: constantMetrics, Data: []byte{0xAA, 0x55}, BitAligned: true},
+		6:  {Metrics: constantMetrics, Data: []byte{0x0F, 0xF0}, BitAligned: true},
+		10: {Metrics: constantMetrics, Components: []BitmapComponent{{5, 0, 0}, {6, 1, 0}}},
+	} {
+		glyph, err := font.BitmapGlyph(strike, gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(glyph, expected) {
+			t.Errorf("glyph %d: expected %v, got %v", gi, expected, glyph)
+		}
+	}
+	for _, gi := range []GlyphIndex{0, 2, 7, 11} {
+		if _, err := font.BitmapGlyph(strike, gi); err != ErrMissingBitmap {
+			t.Errorf("glyph %d: expected ErrMissingBitmap, got %v", gi, err)
+		}
+	}
+
+	scales, err := font.EBSCTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scale, ok := scales.Scale(11, 13)
+	if !ok || scale.SubstitutePPEMX != 10 || scale.SubstitutePPEMY != 12 || scale.Hori.Ascender != 9 {
+		t.Errorf("unexpected scale %v", scale)
+	}
+}
@@ -0,0 +1,152 @@
+package sfnt
+
+import "errors"
+
+var errInvalidAATFeatTable = errors.New("invalid feat table")
+
+const (
+	// AATFeatureExclusive is the flag of the AAT features
+	// whose selectors are mutually exclusive.
+	AATFeatureExclusive = 0x8000
+	// aatFeatureDefaultIndex indicates that the low byte
+	// of the flags is the index of the default selector.
+	aatFeatureDefaultIndex = 0x4000
+)
+
+// TableAATFeat is the AAT feature name table, which lists the
+// features supported by the font and their selectors.
+// It is not to be confused with the Graphite 'Feat' table.
+// See https://developer.apple.com/fonts/TrueType-Reference-Manual/RM06/Chap6feat.html
+type TableAATFeat struct {
+	baseTable
+
+	bytes []byte
+
+	Features []AATFeature
+}
+
+// AATFeature is a feature type of an AAT font, such as
+// ligatures (1) or letter case (3).
+type AATFeature struct {
+	Type      uint16
+	Flags     uint16
+	Name      NameID
+	Selectors []AATSelector
+
+	// Names are the localized names of the feature, taken from the
+	// 'name' table. It is only set by Font.AATFeatures.
+	Names []*NameEntry
+}
+
+// AATSelector is a setting of an AAT feature.
+type AATSelector struct {
+	Value uint16
+	Name  NameID
+
+	// Names are the localized names of the selector, taken from the
+	// 'name' table. It is only set by Font.AATFeatures.
+	Names []*NameEntry
+}
+
+// Exclusive returns true if only one selector of the feature
+// may be enabled at a time. Otherwise, the selectors are
+// usually pairs of on (even) and off (odd) values.
+func (f AATFeature) Exclusive() bool { return f.Flags&AATFeatureExclusive != 0 }
+
+// Default returns the index of the default selector: the first
+// one, unless the flags specify another one.
+// It is -1 if the feature has no selector.
+func (f AATFeature) Default() int {
+	if len(f.Selectors) == 0 {
+		return -1
+	}
+	if f.Flags&aatFeatureDefaultIndex != 0 {
+		if index := int(f.Flags & 0xFF); index < len(f.Selectors) {
+			return index
+		}
+	}
+	return 0
+}
+
+// Bytes returns the bytes for this table. The TableAATFeat is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableAATFeat) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableAATFeat(tag Tag, buf []byte) (Table, error) {
+	const headerSize, recordSize, selectorSize = 12, 12, 4
+	if len(buf) < headerSize {
+		return nil, errInvalidAATFeatTable
+	}
+	numFeatures := int(be.Uint16(buf[4:]))
+	if len(buf) < headerSize+recordSize*numFeatures {
+		return nil, errInvalidAATFeatTable
+	}
+
+	features := make([]AATFeature, numFeatures)
+	for i := range features {
+		record := buf[headerSize+recordSize*i:]
+		numSelectors, offset := int(be.Uint16(record[2:])), int(be.Uint32(record[4:]))
+		features[i] = AATFeature{
+			Type:  be.Uint16(record),
+			Flags: be.Uint16(record[8:]),
+			Name:  NameID(be.Uint16(record[10:])),
+		}
+		if len(buf) < offset+selectorSize*numSelectors {
+			return nil, errInvalidAATFeatTable
+		}
+		selectors := make([]AATSelector, numSelectors)
+		for j := range selectors {
+			selector := buf[offset+selectorSize*j:]
+			selectors[j] = AATSelector{Value: be.Uint16(selector), Name: NameID(be.Uint16(selector[2:]))}
+		}
+		features[i].Selectors = selectors
+	}
+
+	return &TableAATFeat{baseTable: baseTable(tag), bytes: buf, Features: features}, nil
+}
+
+// AATFeatTable returns the AAT feature name table identified with the 'feat' tag.
+func (font *Font) AATFeatTable() (*TableAATFeat, error) {
+	t, err := font.Table(TagAATFeat)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableAATFeat), nil
+}
+
+// AATFeatures returns the features of the 'feat' table, with the
+// localized names of the features and selectors, if the font
+// has a 'name' table.
+func (font *Font) AATFeatures() ([]AATFeature, error) {
+	feat, err := font.AATFeatTable()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]AATFeature, len(feat.Features))
+	for i, feature := range feat.Features {
+		feature.Selectors = append([]AATSelector(nil), feature.Selectors...)
+		out[i] = feature
+	}
+	if !font.HasTable(TagName) {
+		return out, nil
+	}
+
+	names, err := font.NameTable()
+	if err != nil {
+		return nil, err
+	}
+	entries := map[NameID][]*NameEntry{}
+	for _, entry := range names.List() {
+		entries[entry.NameID] = append(entries[entry.NameID], entry)
+	}
+	for i := range out {
+		out[i].Names = entries[out[i].Name]
+		for j := range out[i].Selectors {
+			out[i].Selectors[j].Names = entries[out[i].Selectors[j].Name]
+		}
+	}
+	return out, nil
+}
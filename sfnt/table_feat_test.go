@@ -0,0 +1,60 @@
+package sfnt
+
+import "testing"
+
+func TestAATFeat(t *testing.T) {
+	feat := []byte{
+		0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, // version, featureNameCount, reserved
+		0, 1, 0, 2, 0, 0, 0, 36, 0, 0, 1, 0, // ligatures: non exclusive
+		0, 3, 0, 2, 0, 0, 0, 44, 0xC0, 1, 1, 3, // letter case: exclusive, default 1
+		0, 2, 1, 1, 0, 3, 1, 2, // common ligatures on and off
+		0, 0, 1, 4, 0, 3, 1, 5, // upper and lower case
+	}
+	table, err := ParseTable(TagAATFeat, feat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := New(TypeTrueType)
+	font.AddTable(TagAATFeat, table)
+	names := NewTableName()
+	for id, value := range map[NameID]string{256: "Ligatures", 257: "Common Ligatures On", 260: "Upper Case"} {
+		if err := names.AddMicrosoftEnglishEntry(id, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	font.AddTable(TagName, names)
+
+	features, err := font.AATFeatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(features))
+	}
+	ligatures, letterCase := features[0], features[1]
+	if ligatures.Type != 1 || ligatures.Exclusive() || ligatures.Default() != 0 {
+		t.Errorf("unexpected ligature feature %v", ligatures)
+	}
+	if letterCase.Type != 3 || !letterCase.Exclusive() || letterCase.Default() != 1 {
+		t.Errorf("unexpected letter case feature %v", letterCase)
+	}
+	if len(ligatures.Names) != 1 || ligatures.Names[0].String() != "Ligatures" {
+		t.Errorf("unexpected feature names %v", ligatures.Names)
+	}
+	if sel := ligatures.Selectors[0]; sel.Value != 2 || len(sel.Names) != 1 || sel.Names[0].String() != "Common Ligatures On" {
+		t.Errorf("unexpected selector %v", sel)
+	}
+	if sel := letterCase.Selectors[1]; sel.Value != 3 || sel.Name != 261 || sel.Names != nil {
+		t.Errorf("unexpected selector %v", sel)
+	}
+
+	// the parsed table is not modified
+	parsed, _ := font.AATFeatTable()
+	if parsed.Features[0].Names != nil || parsed.Features[0].Selectors[0].Names != nil {
+		t.Error("unexpected names in the table")
+	}
+
+	if _, err := ParseTable(TagAATFeat, feat[:40]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
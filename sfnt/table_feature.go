@@ -3,9 +3,16 @@ package sfnt
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"sync"
+)
+
+var (
+	scriptDefault = MustNamedTag("DFLT")
+	scriptLatin   = MustNamedTag("latn")
 )
 
 // TableLayout represents the common layout table used by GPOS and GSUB.
@@ -34,6 +41,12 @@ func (t *TableLayout) Bytes() []byte {
 	return t.bytes
 }
 
+// HasFeatureVariations returns true if the table has a FeatureVariations table,
+// which is only possible for version 1.1 tables, found in variable fonts.
+func (t *TableLayout) HasFeatureVariations() bool {
+	return t.header.FeatureVariationsOffset != 0
+}
+
 // Script represents a single script (i.e "latn" (Latin), "cyrl" (Cyrillic), etc).
 type Script struct {
 	Tag             Tag        // Tag for this script.
@@ -59,7 +72,8 @@ func (l *LangSys) String() string {
 
 // Feature represents a glyph substitution or glyph positioning features.
 type Feature struct {
-	Tag Tag // Tag for this feature
+	Tag     Tag       // Tag for this feature
+	Lookups []*Lookup // Lookups used by this feature, in the order of the LookupList.
 }
 
 // Script returns the name for this feature.
@@ -90,6 +104,85 @@ type Lookup struct {
 	subtableOffsets []uint16 // Array of offsets to lookup subtables, from beginning of Lookup table
 	data            []byte   // input data of the lookup table
 	// markFilteringSet uint16 // Index (base 0) into GDEF mark glyph sets structure. This field is only present if bit useMarkFilteringSet of lookup flags is set.
+
+	// the lookups may be shared by the fonts of a Collection,
+	// used concurrently
+	mu        sync.Mutex
+	subtables []*lookupSubtable // lazily decoded, see parsedSubtables
+}
+
+// lookupSubtable caches the decoded content of a lookup subtable,
+// so that repeated queries don't decode the same data again.
+type lookupSubtable struct {
+	format uint16
+	data   []byte // starting at the subtable
+
+	mu       sync.Mutex  // protects coverage and parsed
+	coverage coverage    // lazily parsed, see lookupSubtable.fetchCoverage
+	parsed   interface{} // type specific content, see cached and cache
+}
+
+// cached returns the type specific content of the subtable,
+// or nil if it has not been decoded yet.
+func (st *lookupSubtable) cached() interface{} {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.parsed
+}
+
+// cache stores the type specific content of the subtable, and returns
+// the stored value, which is the one of a concurrent caller if
+// it was first.
+func (st *lookupSubtable) cache(parsed interface{}) interface{} {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.parsed == nil {
+		st.parsed = parsed
+	}
+	return st.parsed
+}
+
+var errInvalidLookupSubtable = errors.New("invalid lookup subtable")
+
+// parsedSubtables returns the subtables of the lookup, decoding
+// their header on first use.
+func (l *Lookup) parsedSubtables() ([]*lookupSubtable, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.subtables != nil {
+		return l.subtables, nil
+	}
+
+	subtables := make([]*lookupSubtable, len(l.subtableOffsets))
+	for i, offset := range l.subtableOffsets {
+		if len(l.data) < 4+int(offset) {
+			return nil, errInvalidLookupSubtable
+		}
+		b := l.data[offset:]
+		subtables[i] = &lookupSubtable{format: be.Uint16(b), data: b}
+	}
+	l.subtables = subtables
+	return subtables, nil
+}
+
+// fetchCoverage returns the coverage of a subtable, whose offset
+// is found at the given position, starting from the beginning of the subtable.
+// The result is cached.
+func (st *lookupSubtable) fetchCoverage(offsetPosition int) (coverage, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.coverage != nil {
+		return st.coverage, nil
+	}
+	if len(st.data) < offsetPosition+2 {
+		return nil, errInvalidLookupSubtable
+	}
+	cov, err := fetchCoverage(st.data, int(be.Uint16(st.data[offsetPosition:])))
+	if err != nil {
+		return nil, err
+	}
+	st.coverage = cov
+	return cov, nil
 }
 
 // versionHeader is the beginning of on-disk format of the GPOS/GSUB version header.
@@ -142,6 +235,69 @@ type langSysTable struct {
 	// featureIndices[featureIndexCount] uint16 // Array of indices into the FeatureList, in arbitrary order
 }
 
+// ChooseLangSys returns the language system to use for the given script
+// and language, following the fallback chain of the specification: the
+// language system of the language, then the default language system of
+// the script. If the script is not found, or has no suitable language
+// system, the same chain is applied to the default script (DFLT), then to
+// the Latin script, as done by most shaping engines.
+// The zero Tag may be used as language to select the default language system.
+// It returns nil if no language system is found.
+func (t TableLayout) ChooseLangSys(script, language Tag) *LangSys {
+	for _, candidate := range [...]Tag{script, scriptDefault, scriptLatin} {
+		for _, s := range t.Scripts {
+			if s.Tag != candidate {
+				continue
+			}
+			for _, l := range s.Languages {
+				if l.Tag == language {
+					return l
+				}
+			}
+			if s.DefaultLanguage != nil {
+				return s.DefaultLanguage
+			}
+		}
+	}
+	return nil
+}
+
+// featureLookups returns the lookups of the given features, for the given
+// script and language (see ChooseLangSys), in the order of the LookupList.
+func (t TableLayout) featureLookups(script, language Tag, features ...Tag) []*Lookup {
+	langSys := t.ChooseLangSys(script, language)
+	if langSys == nil {
+		return nil
+	}
+
+	// several features may share the same lookups
+	used := map[*Lookup]bool{}
+	for _, feature := range langSys.Features {
+		if !containsTag(features, feature.Tag) {
+			continue
+		}
+		for _, lookup := range feature.Lookups {
+			used[lookup] = true
+		}
+	}
+	var out []*Lookup
+	for _, lookup := range t.Lookups {
+		if used[lookup] {
+			out = append(out, lookup)
+		}
+	}
+	return out
+}
+
+func containsTag(tags []Tag, tag Tag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // parseLangSys parses a single Language System table. b expected to be the beginning of Script table.
 // See https://www.microsoft.com/typography/otspec/chapter2.htm#langSysTbl
 func (t *TableLayout) parseLangSys(b []byte, record langSysRecord) (*LangSys, error) {
@@ -275,10 +431,31 @@ func (t *TableLayout) parseFeature(b []byte, record featureRecord) (*Feature, er
 		return nil, fmt.Errorf("reading featureTable: %s", err)
 	}
 
-	// TODO Read feature.FeatureParams and feature.LookupIndexCount
+	// TODO Read feature.FeatureParams
+
+	lookupIndices := make([]uint16, feature.LookupIndexCount)
+	if err := binary.Read(r, binary.BigEndian, &lookupIndices); err != nil {
+		return nil, fmt.Errorf("reading featureTable lookupListIndices[%d]: %s", feature.LookupIndexCount, err)
+	}
+	// the indices are in arbitrary order
+	used := make([]bool, len(t.Lookups))
+	for _, index := range lookupIndices {
+		// as shaping engines do, the invalid indices are skipped,
+		// so that the other lookups of the feature are still used
+		if int(index) < len(t.Lookups) {
+			used[index] = true
+		}
+	}
+	var lookups []*Lookup
+	for i, lookup := range t.Lookups {
+		if used[i] {
+			lookups = append(lookups, lookup)
+		}
+	}
 
 	return &Feature{
-		Tag: record.Tag,
+		Tag:     record.Tag,
+		Lookups: lookups,
 	}, nil
 }
 
@@ -416,6 +593,10 @@ func parseTableLayout(tag Tag, buf []byte) (Table, error) {
 		panic("unsupported minor version")
 	}
 
+	if int(t.header.FeatureVariationsOffset) >= len(t.bytes) {
+		return nil, fmt.Errorf("invalid FeatureVariations offset %d", t.header.FeatureVariationsOffset)
+	}
+
 	if err := t.parseLookupList(); err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package sfnt
 
 import (
+	"os"
 	"testing"
 )
 
@@ -28,3 +29,144 @@ func TestFeatureString(t *testing.T) {
 		}
 	}
 }
+
+func TestLookupSubtablesCache(t *testing.T) {
+	f, err := os.Open("testdata/Castoro-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpos, err := font.GposTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gpos.parseKern(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lookup := range gpos.Lookups {
+		if lookup.Type != 2 {
+			continue
+		}
+		subtables, err := lookup.parsedSubtables()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(subtables) != len(lookup.subtableOffsets) {
+			t.Fatalf("expected %d subtables, got %d", len(lookup.subtableOffsets), len(subtables))
+		}
+		for _, st := range subtables {
+			if st.coverage == nil || st.parsed == nil {
+				t.Errorf("subtable not cached")
+			}
+		}
+		again, _ := lookup.parsedSubtables()
+		if &again[0] != &subtables[0] {
+			t.Errorf("subtables decoded twice")
+		}
+	}
+}
+
+func TestLayoutVersion11(t *testing.T) {
+	header := []byte{
+		0, 1, 0, 1, // version 1.1
+		0, 14, // ScriptList
+		0, 16, // FeatureList
+		0, 18, // LookupList
+		0, 0, 0, 20, // FeatureVariations
+		0, 0, // empty ScriptList
+		0, 0, // empty FeatureList
+		0, 0, // empty LookupList
+		0, 1, 0, 0, 0, 0, 0, 0, // FeatureVariations header, without records
+	}
+
+	table, err := parseTableLayout(TagGsub, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := table.(*TableLayout)
+	if !layout.HasFeatureVariations() {
+		t.Error("expected feature variations")
+	}
+	if len(layout.Scripts) != 0 || len(layout.Features) != 0 || len(layout.Lookups) != 0 {
+		t.Error("expected empty layout")
+	}
+
+	// same table, with version 1.0
+	header[3] = 0
+	table, err = parseTableLayout(TagGsub, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.(*TableLayout).HasFeatureVariations() {
+		t.Error("unexpected feature variations")
+	}
+
+	// invalid offset
+	header[3] = 1
+	header[13] = 200
+	if _, err = parseTableLayout(TagGsub, header); err == nil {
+		t.Error("expected error for invalid offset")
+	}
+}
+
+func TestFeatureInvalidLookupIndex(t *testing.T) {
+	table, err := parseTableLayout(TagGpos, []byte{
+		0, 1, 0, 0, // version 1.0
+		0, 10, // ScriptList
+		0, 12, // FeatureList
+		0, 28, // LookupList
+		0, 0, // empty ScriptList
+		0, 1, 'k', 'e', 'r', 'n', 0, 8, // FeatureList with one record
+		0, 0, 0, 2, 0, 0, 0, 5, // Feature with lookups 0 and 5
+		0, 1, 0, 4, // LookupList with one lookup
+		0, 2, 0, 0, 0, 0, // Lookup without subtables
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the dangling index is skipped
+	features := table.(*TableLayout).Features
+	if len(features) != 1 || len(features[0].Lookups) != 1 {
+		t.Errorf("expected one feature with one lookup, got %v", features)
+	}
+}
+
+func TestChooseLangSys(t *testing.T) {
+	var (
+		dflt    = &LangSys{}
+		cyrl    = &LangSys{}
+		srb     = &LangSys{Tag: MustNamedTag("SRB ")}
+		arabURD = &LangSys{Tag: MustNamedTag("URD ")}
+	)
+	layout := TableLayout{Scripts: []*Script{
+		{Tag: scriptDefault, DefaultLanguage: dflt},
+		{Tag: MustNamedTag("cyrl"), DefaultLanguage: cyrl, Languages: []*LangSys{srb}},
+		{Tag: MustNamedTag("arab"), Languages: []*LangSys{arabURD}},
+	}}
+	for _, test := range []struct {
+		script, language string
+		expected         *LangSys
+	}{
+		{"cyrl", "SRB ", srb},
+		{"cyrl", "MKD ", cyrl},
+		{"arab", "URD ", arabURD},
+		{"arab", "FAR ", dflt}, // no default language system
+		{"grek", "ELL ", dflt},
+	} {
+		if got := layout.ChooseLangSys(MustNamedTag(test.script), MustNamedTag(test.language)); got != test.expected {
+			t.Errorf("%s %s: expected %v, got %v", test.script, test.language, test.expected, got)
+		}
+	}
+	if got := layout.ChooseLangSys(MustNamedTag("cyrl"), Tag{}); got != cyrl {
+		t.Errorf("expected the default language system, got %v", got)
+	}
+	if got := (TableLayout{}).ChooseLangSys(scriptDefault, Tag{}); got != nil {
+		t.Errorf("expected no language system, got %v", got)
+	}
+}
@@ -0,0 +1,158 @@
+package sfnt
+
+import (
+	"errors"
+)
+
+var errInvalidFvarTable = errors.New("invalid fvar table")
+
+// TableFvar is the font variations table, defining the
+// axes of a variable font and its named instances.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/fvar
+type TableFvar struct {
+	baseTable
+
+	bytes []byte
+
+	Axes      []VariationAxis
+	Instances []NamedInstance
+}
+
+// VariationAxis is an axis of variation, like the weight (wght) or the width (wdth).
+// The values are expressed in user space.
+type VariationAxis struct {
+	Tag     Tag
+	Min     float32
+	Default float32
+	Max     float32
+	Flags   uint16
+	NameID  NameID // name of the axis, in the 'name' table
+}
+
+// NamedInstance is a predefined set of coordinates, like "Bold" or "Condensed".
+type NamedInstance struct {
+	SubfamilyNameID NameID
+	// PostScriptNameID is 0xFFFF if the instance has no PostScript name.
+	PostScriptNameID NameID
+	Coordinates      []float32 // one for each axis, in user space
+
+	// Names are the localized names of the instance, taken from the
+	// 'name' table. It is only set by Font.NamedInstances.
+	Names []*NameEntry
+}
+
+// Bytes returns the bytes for this table. The TableFvar is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableFvar) Bytes() []byte {
+	return t.bytes
+}
+
+func fixedToFloat(u uint32) float32 {
+	return float32(int32(u)) / (1 << 16)
+}
+
+func parseTableFvar(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 16
+	if len(buf) < headerSize {
+		return nil, errInvalidFvarTable
+	}
+	axesOffset := int(be.Uint16(buf[4:]))
+	axisCount := int(be.Uint16(buf[8:]))
+	axisSize := int(be.Uint16(buf[10:]))
+	instanceCount := int(be.Uint16(buf[12:]))
+	instanceSize := int(be.Uint16(buf[14:]))
+
+	const axisRecordSize = 20
+	if axisSize < axisRecordSize || instanceSize < 4+4*axisCount {
+		return nil, errInvalidFvarTable
+	}
+	instancesOffset := axesOffset + axisCount*axisSize
+	if len(buf) < instancesOffset+instanceCount*instanceSize {
+		return nil, errInvalidFvarTable
+	}
+
+	out := &TableFvar{
+		baseTable: baseTable(tag),
+		bytes:     buf,
+		Axes:      make([]VariationAxis, axisCount),
+		Instances: make([]NamedInstance, instanceCount),
+	}
+	for i := range out.Axes {
+		b := buf[axesOffset+i*axisSize:]
+		out.Axes[i] = VariationAxis{
+			Tag:     NewTag(b),
+			Min:     fixedToFloat(be.Uint32(b[4:])),
+			Default: fixedToFloat(be.Uint32(b[8:])),
+			Max:     fixedToFloat(be.Uint32(b[12:])),
+			Flags:   be.Uint16(b[16:]),
+			NameID:  NameID(be.Uint16(b[18:])),
+		}
+	}
+	hasPostScriptName := instanceSize >= 6+4*axisCount
+	for i := range out.Instances {
+		b := buf[instancesOffset+i*instanceSize:]
+		instance := NamedInstance{
+			SubfamilyNameID:  NameID(be.Uint16(b)),
+			PostScriptNameID: 0xFFFF,
+			Coordinates:      make([]float32, axisCount),
+		}
+		for j := range instance.Coordinates {
+			instance.Coordinates[j] = fixedToFloat(be.Uint32(b[4+4*j:]))
+		}
+		if hasPostScriptName {
+			instance.PostScriptNameID = NameID(be.Uint16(b[4+4*axisCount:]))
+		}
+		out.Instances[i] = instance
+	}
+
+	return out, nil
+}
+
+// FvarTable returns the font variations table.
+func (font *Font) FvarTable() (*TableFvar, error) {
+	t, err := font.Table(TagFvar)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableFvar), nil
+}
+
+// VariationAxes returns the axes of a variable font.
+// It returns ErrMissingTable if the font is not a variable font.
+func (font *Font) VariationAxes() ([]VariationAxis, error) {
+	fvar, err := font.FvarTable()
+	if err != nil {
+		return nil, err
+	}
+	return fvar.Axes, nil
+}
+
+// NamedInstances returns the named instances of a variable font,
+// with their localized names, if the font has a 'name' table.
+// It returns ErrMissingTable if the font is not a variable font.
+func (font *Font) NamedInstances() ([]NamedInstance, error) {
+	fvar, err := font.FvarTable()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]NamedInstance, len(fvar.Instances))
+	copy(out, fvar.Instances)
+	if !font.HasTable(TagName) {
+		return out, nil
+	}
+
+	names, err := font.NameTable()
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Names = nil
+		for _, entry := range names.List() {
+			if entry.NameID == out[i].SubfamilyNameID {
+				out[i].Names = append(out[i].Names, entry)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fvarTable returns a fvar table with a weight and a width axis,
+// and two instances
+func fvarTable() []byte {
+	b := []byte{
+		0, 1, 0, 0, // version
+		0, 16, // axesArrayOffset
+		0, 2, // reserved
+		0, 2, // axisCount
+		0, 20, // axisSize
+		0, 2, // instanceCount
+		0, 14, // instanceSize
+		// wght: 100, 400, 900
+		'w', 'g', 'h', 't', 0, 100, 0, 0, 1, 144, 0, 0, 3, 132, 0, 0, 0, 0, 1, 0,
+		// wdth: 75, 100, 100
+		'w', 'd', 't', 'h', 0, 75, 0, 0, 0, 100, 0, 0, 0, 100, 0, 0, 0, 0, 1, 1,
+		// Bold: 700, 100
+		1, 2, 0, 0, 2, 188, 0, 0, 0, 100, 0, 0, 1, 3,
+		// Condensed: 400, 87.5
+		1, 4, 0, 0, 1, 144, 0, 0, 0, 87, 128, 0, 0xFF, 0xFF,
+	}
+	return b
+}
+
+func TestFvar(t *testing.T) {
+	table, err := parseTableFvar(TagFvar, fvarTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagFvar, table)
+	names := NewTableName()
+	names.AddMicrosoftEnglishEntry(NameID(0x102), "Bold")
+	names.AddUnicodeEntry(NameID(0x102), "Bold")
+	names.AddMicrosoftEnglishEntry(NameID(0x104), "Condensed")
+	font.AddTable(TagName, names)
+
+	axes, err := font.VariationAxes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(axes) != 2 {
+		t.Fatalf("expected 2 axes, got %d", len(axes))
+	}
+	exp := VariationAxis{Tag: MustNamedTag("wght"), Min: 100, Default: 400, Max: 900, NameID: 0x100}
+	if axes[0] != exp {
+		t.Errorf("expected %v, got %v", exp, axes[0])
+	}
+
+	instances, err := font.NamedInstances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("expected 2 instances, got %d", len(instances))
+	}
+	if c := instances[1].Coordinates; c[0] != 400 || c[1] != 87.5 {
+		t.Errorf("unexpected coordinates %v", c)
+	}
+	if instances[0].PostScriptNameID != 0x103 || instances[1].PostScriptNameID != 0xFFFF {
+		t.Errorf("unexpected PostScript names")
+	}
+	if len(instances[0].Names) != 2 || instances[0].Names[0].String() != "Bold" {
+		t.Errorf("unexpected names %v", instances[0].Names)
+	}
+
+	if _, err := parseTableFvar(TagFvar, fvarTable()[:40]); err == nil {
+		t.Error("expected error on truncated table")
+	}
+}
+
+func TestNormalizeCoordinates(t *testing.T) {
+	fvar, err := parseTableFvar(TagFvar, fvarTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	avar, err := parseTableAvar(TagAvar, []byte{
+		0, 1, 0, 0, // version
+		0, 0, // reserved
+		0, 2, // axisCount
+		// wght: 0.5 is mapped to 0.75
+		0, 4, 0xC0, 0, 0xC0, 0, 0, 0, 0, 0, 0x20, 0, 0x30, 0, 0x40, 0, 0x40, 0,
+		// wdth: identity
+		0, 3, 0xC0, 0, 0xC0, 0, 0, 0, 0, 0, 0x40, 0, 0x40, 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagFvar, fvar)
+	for _, test := range []struct {
+		user, normalized []float32
+	}{
+		{nil, []float32{0, 0}},
+		{[]float32{250, 87.5}, []float32{-0.5, -0.5}},
+		{[]float32{650, 100}, []float32{0.5, 0}},
+		{[]float32{1000, 50}, []float32{1, -1}},
+	} {
+		got, err := font.NormalizeCoordinates(test.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.normalized) {
+			t.Errorf("%v: expected %v, got %v", test.user, test.normalized, got)
+		}
+	}
+
+	font.AddTable(TagAvar, avar)
+	for _, test := range []struct {
+		user, normalized []float32
+	}{
+		{[]float32{250, 87.5}, []float32{-0.5, -0.5}},
+		{[]float32{650, 100}, []float32{0.75, 0}},
+		{[]float32{525}, []float32{0.375, 0}},
+		{[]float32{900}, []float32{1, 0}},
+	} {
+		got, err := font.NormalizeCoordinates(test.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.normalized) {
+			t.Errorf("%v: expected %v, got %v", test.user, test.normalized, got)
+		}
+	}
+}
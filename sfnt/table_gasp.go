@@ -0,0 +1,102 @@
+package sfnt
+
+import "errors"
+
+var errInvalidGaspTable = errors.New("invalid gasp table")
+
+// GaspBehavior are the flags of the gasp table, describing
+// the preferred rasterization for a range of sizes.
+type GaspBehavior uint16
+
+const (
+	// GaspGridFit enables the hinting.
+	GaspGridFit GaspBehavior = 1 << iota
+	// GaspDoGray enables the grayscale rendering.
+	GaspDoGray
+	// GaspSymmetricGridFit enables the hinting for ClearType (version 1).
+	GaspSymmetricGridFit
+	// GaspSymmetricSmoothing enables the smoothing along the
+	// multiple axes with ClearType (version 1).
+	GaspSymmetricSmoothing
+)
+
+// TableGasp is the grid-fitting and scan-conversion procedure table.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/gasp
+type TableGasp struct {
+	baseTable
+
+	bytes []byte
+
+	Version uint16
+	// Ranges are sorted by increasing MaxPPEM.
+	Ranges []GaspRange
+}
+
+// GaspRange gives the behavior for the sizes up to MaxPPEM
+// (included), and greater than the previous range.
+type GaspRange struct {
+	MaxPPEM  uint16
+	Behavior GaspBehavior
+}
+
+// Bytes returns the bytes for this table. The TableGasp is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableGasp) Bytes() []byte {
+	return t.bytes
+}
+
+// Behavior returns the flags for the given size, in pixels per em.
+// The sizes after the last range (which should not happen, since
+// the last MaxPPEM is normally 0xFFFF) use the last behavior.
+// It returns 0 if the table has no range.
+func (t *TableGasp) Behavior(ppem uint16) GaspBehavior {
+	for _, r := range t.Ranges {
+		if ppem <= r.MaxPPEM {
+			return r.Behavior
+		}
+	}
+	if len(t.Ranges) == 0 {
+		return 0
+	}
+	return t.Ranges[len(t.Ranges)-1].Behavior
+}
+
+func parseTableGasp(tag Tag, buf []byte) (Table, error) {
+	const headerSize, rangeSize = 4, 4
+	if len(buf) < headerSize {
+		return nil, errInvalidGaspTable
+	}
+	numRanges := int(be.Uint16(buf[2:]))
+	if len(buf) < headerSize+rangeSize*numRanges {
+		return nil, errInvalidGaspTable
+	}
+	ranges := make([]GaspRange, numRanges)
+	for i := range ranges {
+		r := buf[headerSize+rangeSize*i:]
+		ranges[i] = GaspRange{MaxPPEM: be.Uint16(r), Behavior: GaspBehavior(be.Uint16(r[2:]))}
+		if i > 0 && ranges[i].MaxPPEM <= ranges[i-1].MaxPPEM {
+			return nil, errInvalidGaspTable
+		}
+	}
+	return &TableGasp{baseTable: baseTable(tag), bytes: buf, Version: be.Uint16(buf), Ranges: ranges}, nil
+}
+
+// GaspTable returns the grid-fitting and scan-conversion procedure table.
+func (font *Font) GaspTable() (*TableGasp, error) {
+	t, err := font.Table(TagGasp)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableGasp), nil
+}
+
+// GaspBehavior returns the rasterization flags for the given size, in pixels
+// per em. It returns ErrMissingTable if the font has no gasp table, in which
+// case the rasterizer should use its defaults.
+func (font *Font) GaspBehavior(ppem uint16) (GaspBehavior, error) {
+	gasp, err := font.GaspTable()
+	if err != nil {
+		return 0, err
+	}
+	return gasp.Behavior(ppem), nil
+}
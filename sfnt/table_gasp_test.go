@@ -0,0 +1,33 @@
+package sfnt
+
+import "testing"
+
+func TestGasp(t *testing.T) {
+	font := loadTestFont(t, "FreeSerif.ttf")
+	for ppem, expected := range map[uint16]GaspBehavior{
+		0:      GaspDoGray,
+		9:      GaspDoGray,
+		10:     GaspGridFit,
+		21:     GaspGridFit,
+		22:     GaspGridFit | GaspDoGray,
+		0xFFFF: GaspGridFit | GaspDoGray,
+	} {
+		got, err := font.GaspBehavior(ppem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("ppem %d: expected %d, got %d", ppem, expected, got)
+		}
+	}
+
+	font = loadTestFont(t, "Roboto-BoldItalic.ttf")
+	if _, err := font.GaspBehavior(12); err != ErrMissingTable {
+		t.Errorf("expected ErrMissingTable, got %v", err)
+	}
+
+	// the ranges must be sorted
+	if _, err := ParseTable(TagGasp, []byte{0, 1, 0, 2, 0, 20, 0, 1, 0, 10, 0, 2}); err == nil {
+		t.Error("expected error for unsorted ranges")
+	}
+}
@@ -0,0 +1,313 @@
+package sfnt
+
+import (
+	"errors"
+)
+
+var (
+	errInvalidGlyfTable = errors.New("invalid glyf table")
+	errInvalidLocaTable = errors.New("invalid loca table")
+)
+
+// simple glyph flags
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/glyf#simple-glyph-description
+const (
+	flagOnCurve       = 0x01
+	flagXShort        = 0x02
+	flagYShort        = 0x04
+	flagRepeat        = 0x08
+	flagXSameOrPlus   = 0x10
+	flagYSameOrPlus   = 0x20
+	glyphHeaderSize   = 10 // numberOfContours and bounding box
+	maxComponentLevel = 8  // protects against cyclic composite glyphs
+)
+
+// composite glyph flags
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/glyf#composite-glyph-description
+const (
+	argsAreWords       = 0x0001
+	argsAreXYValues    = 0x0002
+	weHaveAScale       = 0x0008
+	moreComponents     = 0x0020
+	weHaveAnXAndYScale = 0x0040
+	weHaveATwoByTwo    = 0x0080
+)
+
+// GlyphPoint is a point of a TrueType outline, expressed in font units.
+type GlyphPoint struct {
+	X, Y    float32
+	OnCurve bool
+}
+
+// GlyphOutline is the outline of a TrueType glyph: a list
+// of quadratic contours.
+type GlyphOutline struct {
+	Points []GlyphPoint
+	// EndPoints stores the index in Points of the
+	// last point of each contour.
+	EndPoints []int
+}
+
+// glyphComponent is a reference to another glyph in a composite glyph.
+type glyphComponent struct {
+	glyph GlyphIndex
+	flags uint16
+	// offset, if argsAreXYValues is set, or the indices of the
+	// parent and child points to match
+	arg1, arg2 int
+	transform  [4]float32 // xx, xy, yx, yy
+}
+
+// glyphData is a parsed entry of the glyf table.
+type glyphData struct {
+	outline    GlyphOutline     // for simple glyphs
+	components []glyphComponent // for composite glyphs
+}
+
+// numPoints returns the number of points (excluding the phantom points)
+// which may be varied: the points of a simple glyph, or the
+// components of a composite glyph.
+func (g glyphData) numPoints() int {
+	if g.components != nil {
+		return len(g.components)
+	}
+	return len(g.outline.Points)
+}
+
+// glyphBuffer returns the raw content of a glyph.
+func (font *Font) glyphBuffer(gi GlyphIndex) ([]byte, error) {
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	loca, err := font.Table(TagLoca)
+	if err != nil {
+		return nil, err
+	}
+	glyf, err := font.Table(TagGlyf)
+	if err != nil {
+		return nil, err
+	}
+	locaBytes, glyfBytes := loca.Bytes(), glyf.Bytes()
+
+	var start, end int
+	if head.IndexToLocFormat == 0 {
+		if len(locaBytes) < 2*int(gi)+4 {
+			return nil, errInvalidLocaTable
+		}
+		start, end = 2*int(be.Uint16(locaBytes[2*int(gi):])), 2*int(be.Uint16(locaBytes[2*int(gi)+2:]))
+	} else {
+		if len(locaBytes) < 4*int(gi)+8 {
+			return nil, errInvalidLocaTable
+		}
+		start, end = int(be.Uint32(locaBytes[4*int(gi):])), int(be.Uint32(locaBytes[4*int(gi)+4:]))
+	}
+	if start > end || end > len(glyfBytes) {
+		return nil, errInvalidLocaTable
+	}
+	return glyfBytes[start:end], nil
+}
+
+func parseGlyphData(buf []byte) (glyphData, error) {
+	if len(buf) == 0 { // empty glyph
+		return glyphData{}, nil
+	}
+	if len(buf) < glyphHeaderSize {
+		return glyphData{}, errInvalidGlyfTable
+	}
+	numberOfContours := int16(be.Uint16(buf))
+	if numberOfContours >= 0 {
+		outline, err := parseSimpleGlyph(buf[glyphHeaderSize:], int(numberOfContours))
+		return glyphData{outline: outline}, err
+	}
+	components, err := parseCompositeGlyph(buf[glyphHeaderSize:])
+	return glyphData{components: components}, err
+}
+
+func parseSimpleGlyph(buf []byte, numberOfContours int) (GlyphOutline, error) {
+	if len(buf) < 2*numberOfContours+2 {
+		return GlyphOutline{}, errInvalidGlyfTable
+	}
+	out := GlyphOutline{EndPoints: make([]int, numberOfContours)}
+	numPoints := 0
+	for i := range out.EndPoints {
+		out.EndPoints[i] = int(be.Uint16(buf[2*i:]))
+		if out.EndPoints[i] < numPoints-1 {
+			return GlyphOutline{}, errInvalidGlyfTable
+		}
+		numPoints = out.EndPoints[i] + 1
+	}
+	instructionLength := int(be.Uint16(buf[2*numberOfContours:]))
+	offset := 2*numberOfContours + 2 + instructionLength
+	if len(buf) < offset {
+		return GlyphOutline{}, errInvalidGlyfTable
+	}
+
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if len(buf) <= offset {
+			return GlyphOutline{}, errInvalidGlyfTable
+		}
+		flag := buf[offset]
+		offset++
+		flags = append(flags, flag)
+		if flag&flagRepeat != 0 {
+			if len(buf) <= offset {
+				return GlyphOutline{}, errInvalidGlyfTable
+			}
+			for count := buf[offset]; count > 0 && len(flags) < numPoints; count-- {
+				flags = append(flags, flag)
+			}
+			offset++
+		}
+	}
+
+	out.Points = make([]GlyphPoint, numPoints)
+	// read the x coordinates, then the y coordinates
+	for _, axis := range [2]struct{ short, sameOrPlus byte }{
+		{flagXShort, flagXSameOrPlus},
+		{flagYShort, flagYSameOrPlus},
+	} {
+		var value int16
+		for i, flag := range flags {
+			switch {
+			case flag&axis.short != 0:
+				if len(buf) < offset+1 {
+					return GlyphOutline{}, errInvalidGlyfTable
+				}
+				if flag&axis.sameOrPlus != 0 {
+					value += int16(buf[offset])
+				} else {
+					value -= int16(buf[offset])
+				}
+				offset++
+			case flag&axis.sameOrPlus == 0:
+				if len(buf) < offset+2 {
+					return GlyphOutline{}, errInvalidGlyfTable
+				}
+				value += int16(be.Uint16(buf[offset:]))
+				offset += 2
+			}
+			if axis.short == flagXShort {
+				out.Points[i].X = float32(value)
+			} else {
+				out.Points[i].Y = float32(value)
+			}
+			out.Points[i].OnCurve = flag&flagOnCurve != 0
+		}
+	}
+	return out, nil
+}
+
+func parseCompositeGlyph(buf []byte) ([]glyphComponent, error) {
+	var out []glyphComponent
+	for offset := 0; ; {
+		if len(buf) < offset+4 {
+			return nil, errInvalidGlyfTable
+		}
+		component := glyphComponent{
+			flags:     be.Uint16(buf[offset:]),
+			glyph:     GlyphIndex(be.Uint16(buf[offset+2:])),
+			transform: [4]float32{1, 0, 0, 1},
+		}
+		offset += 4
+
+		if component.flags&argsAreWords != 0 {
+			if len(buf) < offset+4 {
+				return nil, errInvalidGlyfTable
+			}
+			if component.flags&argsAreXYValues != 0 {
+				component.arg1, component.arg2 = int(int16(be.Uint16(buf[offset:]))), int(int16(be.Uint16(buf[offset+2:])))
+			} else {
+				component.arg1, component.arg2 = int(be.Uint16(buf[offset:])), int(be.Uint16(buf[offset+2:]))
+			}
+			offset += 4
+		} else {
+			if len(buf) < offset+2 {
+				return nil, errInvalidGlyfTable
+			}
+			if component.flags&argsAreXYValues != 0 {
+				component.arg1, component.arg2 = int(int8(buf[offset])), int(int8(buf[offset+1]))
+			} else {
+				component.arg1, component.arg2 = int(buf[offset]), int(buf[offset+1])
+			}
+			offset += 2
+		}
+
+		var scales []float32
+		switch {
+		case component.flags&weHaveAScale != 0:
+			scales = make([]float32, 1)
+		case component.flags&weHaveAnXAndYScale != 0:
+			scales = make([]float32, 2)
+		case component.flags&weHaveATwoByTwo != 0:
+			scales = make([]float32, 4)
+		}
+		if len(buf) < offset+2*len(scales) {
+			return nil, errInvalidGlyfTable
+		}
+		for i := range scales {
+			scales[i] = f2dot14ToFloat(be.Uint16(buf[offset:]))
+			offset += 2
+		}
+		switch len(scales) {
+		case 1:
+			component.transform[0], component.transform[3] = scales[0], scales[0]
+		case 2:
+			component.transform[0], component.transform[3] = scales[0], scales[1]
+		case 4:
+			copy(component.transform[:], scales)
+		}
+
+		out = append(out, component)
+		if component.flags&moreComponents == 0 {
+			return out, nil
+		}
+	}
+}
+
+// f2dot14ToFloat converts a 2.14 fixed number.
+func f2dot14ToFloat(u uint16) float32 {
+	return float32(int16(u)) / (1 << 14)
+}
+
+// GlyphOutline returns the outline of the given glyph, for fonts
+// with TrueType outlines. The components of composite glyphs are resolved.
+// For variable fonts, the outline of the default instance is returned.
+func (font *Font) GlyphOutline(gi GlyphIndex) (GlyphOutline, error) {
+	return font.VariedGlyphOutline(gi, nil)
+}
+
+// appendComponent adds the outline of a component, transformed
+// and moved according to the (possibly varied) offset [dx, dy].
+func (out *GlyphOutline) appendComponent(component glyphComponent, dx, dy float32, outline GlyphOutline) error {
+	m := component.transform
+	transformed := make([]GlyphPoint, len(outline.Points))
+	for i, p := range outline.Points {
+		transformed[i] = GlyphPoint{
+			X:       m[0]*p.X + m[2]*p.Y,
+			Y:       m[1]*p.X + m[3]*p.Y,
+			OnCurve: p.OnCurve,
+		}
+	}
+
+	if component.flags&argsAreXYValues == 0 {
+		// align the point arg2 of the component on the point arg1 of the parent
+		if component.arg1 >= len(out.Points) || component.arg2 >= len(transformed) {
+			return errInvalidGlyfTable
+		}
+		parent, child := out.Points[component.arg1], transformed[component.arg2]
+		dx, dy = parent.X-child.X, parent.Y-child.Y
+	}
+
+	start := len(out.Points)
+	for _, p := range transformed {
+		p.X += dx
+		p.Y += dy
+		out.Points = append(out.Points, p)
+	}
+	for _, end := range outline.EndPoints {
+		out.EndPoints = append(out.EndPoints, start+end)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package sfnt
+
+const weHaveInstructions = 0x0100 // composite glyph flag
+
+// glyphProgram locates the instructions in the data of a glyph.
+type glyphProgram struct {
+	// start and end are the offsets of the instructions, after their length.
+	// For composite glyphs without instructions, they are both
+	// the end of the components.
+	start, end int
+	// lastFlags is the offset of the flags of the last component,
+	// or -1 for simple glyphs.
+	lastFlags int
+}
+
+// findGlyphProgram returns the location of the instructions
+// of the non empty glyph.
+func findGlyphProgram(buf []byte) (glyphProgram, error) {
+	if len(buf) < glyphHeaderSize {
+		return glyphProgram{}, errInvalidGlyfTable
+	}
+	numberOfContours := int16(be.Uint16(buf))
+	if numberOfContours >= 0 {
+		lengthOffset := glyphHeaderSize + 2*int(numberOfContours)
+		if len(buf) < lengthOffset+2 {
+			return glyphProgram{}, errInvalidGlyfTable
+		}
+		start := lengthOffset + 2
+		end := start + int(be.Uint16(buf[lengthOffset:]))
+		if len(buf) < end {
+			return glyphProgram{}, errInvalidGlyfTable
+		}
+		return glyphProgram{start: start, end: end, lastFlags: -1}, nil
+	}
+
+	// skip the components
+	out := glyphProgram{}
+	offset := glyphHeaderSize
+	for {
+		if len(buf) < offset+4 {
+			return glyphProgram{}, errInvalidGlyfTable
+		}
+		out.lastFlags = offset
+		flags := be.Uint16(buf[offset:])
+		offset += 4
+		if flags&argsAreWords != 0 {
+			offset += 4
+		} else {
+			offset += 2
+		}
+		switch {
+		case flags&weHaveAScale != 0:
+			offset += 2
+		case flags&weHaveAnXAndYScale != 0:
+			offset += 4
+		case flags&weHaveATwoByTwo != 0:
+			offset += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	out.start, out.end = offset, offset
+	if be.Uint16(buf[out.lastFlags:])&weHaveInstructions != 0 {
+		if len(buf) < offset+2 {
+			return glyphProgram{}, errInvalidGlyfTable
+		}
+		out.start = offset + 2
+		out.end = out.start + int(be.Uint16(buf[offset:]))
+	}
+	if len(buf) < out.end {
+		return glyphProgram{}, errInvalidGlyfTable
+	}
+	return out, nil
+}
+
+// GlyphInstructions returns the TrueType instructions (the glyph program)
+// of the given glyph, or nil if it has none.
+func (font *Font) GlyphInstructions(gi GlyphIndex) ([]byte, error) {
+	buf, err := font.glyphBuffer(gi)
+	if err != nil || len(buf) == 0 {
+		return nil, err
+	}
+	program, err := findGlyphProgram(buf)
+	if err != nil || program.start == program.end {
+		return nil, err
+	}
+	return buf[program.start:program.end], nil
+}
+
+// setGlyphInstructions returns a copy of the glyph data using the given
+// instructions. The empty glyphs can't have instructions, and are returned
+// unchanged.
+func setGlyphInstructions(buf, instructions []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return buf, nil
+	}
+	program, err := findGlyphProgram(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(buf)+len(instructions)+2)
+	if program.lastFlags == -1 {
+		out = append(out, buf[:program.start-2]...)
+		out = append(out, byte(len(instructions)>>8), byte(len(instructions)))
+		out = append(out, instructions...)
+		// the flags and coordinates
+		return append(out, buf[program.end:]...), nil
+	}
+
+	// the composite glyphs store the instructions after the components,
+	// if the flags of the last component say so
+	componentsEnd := program.start
+	if be.Uint16(buf[program.lastFlags:])&weHaveInstructions != 0 {
+		componentsEnd -= 2
+	}
+	out = append(out, buf[:componentsEnd]...)
+	flags := be.Uint16(out[program.lastFlags:]) &^ weHaveInstructions
+	if len(instructions) == 0 {
+		be.PutUint16(out[program.lastFlags:], flags)
+		return out, nil
+	}
+	be.PutUint16(out[program.lastFlags:], flags|weHaveInstructions)
+	out = append(out, byte(len(instructions)>>8), byte(len(instructions)))
+	return append(out, instructions...), nil
+}
+
+// UpdateGlyphInstructions replaces the instructions of the glyphs, for fonts
+// with TrueType outlines: fn is called for each glyph having an outline,
+// with its current instructions (nil if it has none), and returns the new
+// ones (nil to strip them).
+// The 'glyf', 'loca', 'head' and 'maxp' tables are updated accordingly (see UpdateMaxp).
+func (font *Font) UpdateGlyphInstructions(fn func(gi GlyphIndex, instructions []byte) []byte) error {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return err
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		return err
+	}
+
+	var glyf []byte
+	offsets := make([]int, int(numGlyphs)+1)
+	for i := range offsets[1:] {
+		gi := GlyphIndex(i)
+		buf, err := font.glyphBuffer(gi)
+		if err != nil {
+			return err
+		}
+		if len(buf) != 0 {
+			program, err := findGlyphProgram(buf)
+			if err != nil {
+				return err
+			}
+			var current []byte
+			if program.start != program.end {
+				current = buf[program.start:program.end]
+			}
+			instructions := fn(gi, current)
+			if len(instructions) > 0xFFFF {
+				return errInvalidGlyfTable
+			}
+			if buf, err = setGlyphInstructions(buf, instructions); err != nil {
+				return err
+			}
+		}
+
+		glyf = append(glyf, buf...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		offsets[i+1] = len(glyf)
+	}
+
+	newHead := *head
+	var loca []byte
+	if len(glyf) <= 2*0xFFFF {
+		loca = make([]byte, 2*len(offsets))
+		for i, offset := range offsets {
+			be.PutUint16(loca[2*i:], uint16(offset/2))
+		}
+		newHead.IndexToLocFormat = 0
+	} else {
+		loca = make([]byte, 4*len(offsets))
+		for i, offset := range offsets {
+			be.PutUint32(loca[4*i:], uint32(offset))
+		}
+		newHead.IndexToLocFormat = 1
+	}
+	font.AddTable(TagGlyf, NewTable(TagGlyf, glyf))
+	font.AddTable(TagLoca, NewTable(TagLoca, loca))
+	font.AddTable(TagHead, &newHead)
+
+	return font.UpdateMaxp()
+}
+
+// StripInstructions removes the TrueType hinting of the font: the
+// instructions of the glyphs, and the 'fpgm', 'prep' and 'cvt ' tables.
+func (font *Font) StripInstructions() error {
+	// removed first, so that they are not included in the 'maxp' table
+	for _, tag := range [...]Tag{TagFpgm, TagPrep, TagCvt} {
+		font.RemoveTable(tag)
+	}
+	return font.UpdateGlyphInstructions(func(GlyphIndex, []byte) []byte { return nil })
+}
@@ -0,0 +1,90 @@
+package sfnt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGlyphInstructions(t *testing.T) {
+	font := loadTestFont(t, "Castoro-Regular.ttf")
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outlines := make([]GlyphOutline, numGlyphs)
+	instructions := make([][]byte, numGlyphs)
+	for i := range outlines {
+		if outlines[i], err = font.GlyphOutline(GlyphIndex(i)); err != nil {
+			t.Fatal(err)
+		}
+		if instructions[i], err = font.GlyphInstructions(GlyphIndex(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// strip the instructions of the composite glyphs, and add
+	// instructions to the others
+	program := []byte{0xB0, 1} // PUSHB[0] 1
+	expected := make([][]byte, numGlyphs)
+	err = font.UpdateGlyphInstructions(func(gi GlyphIndex, current []byte) []byte {
+		if !bytes.Equal(current, instructions[gi]) {
+			t.Errorf("glyph %d: unexpected current instructions", gi)
+		}
+		buf, _ := font.glyphBuffer(gi)
+		if int16(be.Uint16(buf)) < 0 && current != nil {
+			return nil
+		}
+		if current == nil {
+			expected[gi] = program
+			return program
+		}
+		expected[gi] = current
+		return current
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := font.WriteOTF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range outlines {
+		gi := GlyphIndex(i)
+		outline, err := font.GlyphOutline(gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(outline, outlines[i]) {
+			t.Errorf("glyph %d: outline modified", i)
+		}
+		got, err := font.GlyphInstructions(gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expected[i]) {
+			t.Errorf("glyph %d: expected instructions %v, got %v", i, expected[i], got)
+		}
+	}
+
+	if err := font.StripInstructions(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range outlines {
+		if got, _ := font.GlyphInstructions(GlyphIndex(i)); got != nil {
+			t.Errorf("glyph %d: unexpected instructions %v", i, got)
+		}
+	}
+	if font.HasTable(TagFpgm) || font.HasTable(TagPrep) || font.HasTable(TagCvt) {
+		t.Error("unexpected hinting tables")
+	}
+	maxp, _ := font.Table(TagMaxp)
+	if size := be.Uint16(maxp.Bytes()[maxpMaxSizeOfInstructionsOffset:]); size != 0 {
+		t.Errorf("expected no instructions in maxp, got %d", size)
+	}
+}
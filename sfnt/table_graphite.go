@@ -0,0 +1,226 @@
+package sfnt
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errInvalidFeatTable = errors.New("invalid Feat table")
+	errInvalidSillTable = errors.New("invalid Sill table")
+)
+
+// GraphiteFeatureHidden is the flag of the Graphite
+// features which should not be shown to the users.
+const GraphiteFeatureHidden = 0x8000
+
+// graphiteFeatureDefaultIndex indicates that the low byte
+// of the flags is the index of the default setting (version 3).
+const graphiteFeatureDefaultIndex = 0x0800
+
+// TableFeat is the Graphite features table, which lists the
+// features supported by the font and their settings.
+// See https://github.com/silnrsi/graphite/blob/master/doc/GTF.txt
+type TableFeat struct {
+	baseTable
+
+	bytes []byte
+
+	Features []GraphiteFeature
+}
+
+// GraphiteFeature is a feature of a Graphite font.
+type GraphiteFeature struct {
+	// ID identifies the feature; it is often made
+	// of four ASCII characters, as a Tag.
+	ID       uint32
+	Flags    uint16
+	Label    NameID
+	Settings []GraphiteSetting
+}
+
+// GraphiteSetting is a value of a Graphite feature.
+type GraphiteSetting struct {
+	Value int16
+	Label NameID
+}
+
+// Default returns the default value of the feature: the first setting,
+// unless the flags specify another one. It is 0 if the
+// feature has no setting.
+func (f GraphiteFeature) Default() int16 {
+	if len(f.Settings) == 0 {
+		return 0
+	}
+	if f.Flags&graphiteFeatureDefaultIndex != 0 {
+		if index := int(f.Flags & 0xFF); index < len(f.Settings) {
+			return f.Settings[index].Value
+		}
+	}
+	return f.Settings[0].Value
+}
+
+// Bytes returns the bytes for this table. The TableFeat is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableFeat) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableFeat(tag Tag, buf []byte) (Table, error) {
+	const headerSize, settingSize = 12, 4
+	if len(buf) < headerSize {
+		return nil, errInvalidFeatTable
+	}
+	version := be.Uint32(buf)
+	numFeatures := int(be.Uint16(buf[4:]))
+	// the IDs are 32 bits long from version 2
+	recordSize := 12
+	if version >= 0x00020000 {
+		recordSize = 16
+	}
+	if len(buf) < headerSize+recordSize*numFeatures {
+		return nil, errInvalidFeatTable
+	}
+
+	features := make([]GraphiteFeature, numFeatures)
+	for i := range features {
+		record := buf[headerSize+recordSize*i:]
+		if recordSize == 16 {
+			features[i].ID = be.Uint32(record)
+			record = record[4:]
+		} else {
+			features[i].ID = uint32(be.Uint16(record))
+			record = record[2:]
+		}
+		numSettings := int(be.Uint16(record))
+		if recordSize == 16 {
+			record = record[2:] // reserved
+		}
+		offset := int(be.Uint32(record[2:]))
+		features[i].Flags = be.Uint16(record[6:])
+		features[i].Label = NameID(be.Uint16(record[8:]))
+
+		if len(buf) < offset+settingSize*numSettings {
+			return nil, errInvalidFeatTable
+		}
+		settings := make([]GraphiteSetting, numSettings)
+		for j := range settings {
+			setting := buf[offset+settingSize*j:]
+			settings[j] = GraphiteSetting{Value: int16(be.Uint16(setting)), Label: NameID(be.Uint16(setting[2:]))}
+		}
+		features[i].Settings = settings
+	}
+
+	return &TableFeat{baseTable: baseTable(tag), bytes: buf, Features: features}, nil
+}
+
+// TableSill is the Graphite language table, which maps
+// languages to the default values of the features.
+// See https://github.com/silnrsi/graphite/blob/master/doc/GTF.txt
+type TableSill struct {
+	baseTable
+
+	bytes []byte
+
+	Languages []GraphiteLanguage
+}
+
+// GraphiteLanguage stores the feature values for a language.
+type GraphiteLanguage struct {
+	// Code is the language code, such as "en" or "zh-Hant",
+	// without its padding.
+	Code string
+	// Settings maps the features IDs to their value,
+	// overriding the defaults of the 'Feat' table.
+	Settings map[uint32]int16
+}
+
+// Bytes returns the bytes for this table. The TableSill is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableSill) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableSill(tag Tag, buf []byte) (Table, error) {
+	const headerSize, entrySize, settingSize = 12, 8, 8
+	if len(buf) < headerSize {
+		return nil, errInvalidSillTable
+	}
+	numLanguages := int(be.Uint16(buf[4:]))
+	// the last entry is a sentinel, which is not returned
+	if len(buf) < headerSize+entrySize*(numLanguages+1) {
+		return nil, errInvalidSillTable
+	}
+
+	languages := make([]GraphiteLanguage, numLanguages)
+	for i := range languages {
+		entry := buf[headerSize+entrySize*i:]
+		numSettings, offset := int(be.Uint16(entry[4:])), int(be.Uint16(entry[6:]))
+		if len(buf) < offset+settingSize*numSettings {
+			return nil, errInvalidSillTable
+		}
+		settings := make(map[uint32]int16, numSettings)
+		for j := 0; j < numSettings; j++ {
+			setting := buf[offset+settingSize*j:]
+			settings[be.Uint32(setting)] = int16(be.Uint16(setting[4:]))
+		}
+		languages[i] = GraphiteLanguage{
+			Code:     strings.TrimRight(string(entry[:4]), "\x00 "),
+			Settings: settings,
+		}
+	}
+
+	return &TableSill{baseTable: baseTable(tag), bytes: buf, Languages: languages}, nil
+}
+
+// FeatTable returns the Graphite features table identified with the 'Feat' tag.
+func (font *Font) FeatTable() (*TableFeat, error) {
+	t, err := font.Table(TagFeat)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableFeat), nil
+}
+
+// SillTable returns the Graphite language table identified with the 'Sill' tag.
+func (font *Font) SillTable() (*TableSill, error) {
+	t, err := font.Table(TagSill)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableSill), nil
+}
+
+// GraphiteFeatureDefaults returns the initial values of the Graphite features
+// for the given language code (such as "en" or "sr"), mapped by feature ID.
+// The defaults of the 'Feat' table are overridden by the values of the
+// language found in the 'Sill' table, if any. The code is matched
+// case insensitively, and an empty code returns the defaults of the 'Feat' table.
+func (font *Font) GraphiteFeatureDefaults(language string) (map[uint32]int16, error) {
+	feat, err := font.FeatTable()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uint32]int16, len(feat.Features))
+	for _, feature := range feat.Features {
+		out[feature.ID] = feature.Default()
+	}
+
+	if language == "" || !font.HasTable(TagSill) {
+		return out, nil
+	}
+	sill, err := font.SillTable()
+	if err != nil {
+		return nil, err
+	}
+	for _, lang := range sill.Languages {
+		if !strings.EqualFold(lang.Code, language) {
+			continue
+		}
+		for id, value := range lang.Settings {
+			out[id] = value
+		}
+		break
+	}
+	return out, nil
+}
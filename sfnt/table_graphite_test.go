@@ -0,0 +1,60 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphiteFeatureDefaults(t *testing.T) {
+	feat := []byte{
+		0, 2, 0, 0, // version
+		0, 2, 0, 0, 0, 0, 0, 0, // numFeat, reserved
+		's', 'm', 'c', 'p', 0, 2, 0, 0, 0, 0, 0, 44, 0, 0, 1, 0, // smcp: settings 0, 1
+		0, 0, 0, 7, 0, 3, 0, 0, 0, 0, 0, 52, 0x08, 0x02, 1, 1, // 7: settings 1, 2, 3, the third is the default
+		0, 0, 1, 2, 0, 1, 1, 3, // smcp settings
+		0, 1, 1, 4, 0, 2, 1, 5, 0, 3, 1, 6, // 7 settings
+	}
+	sill := []byte{
+		0, 1, 0, 0, // version
+		0, 2, 0, 0, 0, 0, 0, 0, // numLangs, searchRange, entrySelector, rangeShift
+		's', 'r', 0, 0, 0, 1, 0, 36, // sr
+		'e', 'n', 0, 0, 0, 0, 0, 44, // en
+		0, 0, 0, 0, 0, 0, 0, 44, // sentinel
+		0, 0, 0, 7, 0, 1, 0, 0, // 7 = 1
+	}
+
+	font := New(TypeTrueType)
+	for tag, content := range map[Tag][]byte{TagFeat: feat, TagSill: sill} {
+		table, err := ParseTable(tag, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font.AddTable(tag, table)
+	}
+
+	parsed, err := font.FeatTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := parsed.Features[0]; f.ID != 0x736D6370 || f.Label != 256 || len(f.Settings) != 2 || f.Settings[1].Label != 259 {
+		t.Errorf("unexpected feature %v", f)
+	}
+
+	for _, test := range []struct {
+		language string
+		expected map[uint32]int16
+	}{
+		{"", map[uint32]int16{0x736D6370: 0, 7: 3}},
+		{"en", map[uint32]int16{0x736D6370: 0, 7: 3}},
+		{"SR", map[uint32]int16{0x736D6370: 0, 7: 1}},
+		{"fr", map[uint32]int16{0x736D6370: 0, 7: 3}},
+	} {
+		got, err := font.GraphiteFeatureDefaults(test.language)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("language %q: expected %v, got %v", test.language, test.expected, got)
+		}
+	}
+}
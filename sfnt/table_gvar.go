@@ -0,0 +1,524 @@
+package sfnt
+
+import (
+	"errors"
+)
+
+var errInvalidGvarTable = errors.New("invalid gvar table")
+
+// tuple variation flags
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/otvarcommonformats
+const (
+	sharedPointNumbers   = 0x8000
+	tupleCountMask       = 0x0FFF
+	embeddedPeakTuple    = 0x8000
+	intermediateRegion   = 0x4000
+	privatePointNumbers  = 0x2000
+	tupleIndexMask       = 0x0FFF
+	pointsAreWords       = 0x80
+	pointRunCountMask    = 0x7F
+	deltasAreZero        = 0x80
+	deltasAreWords       = 0x40
+	deltaRunCountMask    = 0x3F
+	numPhantomPoints     = 4
+	gvarHeaderSize       = 20
+	tupleVariationHeader = 4 // variationDataSize and tupleIndex
+)
+
+// TableGvar is the glyph variations table, storing the variations
+// of the TrueType outlines of a variable font.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/gvar
+type TableGvar struct {
+	baseTable
+
+	bytes []byte
+
+	axisCount    int
+	sharedTuples [][]float32 // normalized coordinates
+	// variations stores the raw glyph variation data of each glyph
+	variations [][]byte
+}
+
+// Bytes returns the bytes for this table. The TableGvar is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableGvar) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableGvar(tag Tag, buf []byte) (Table, error) {
+	if len(buf) < gvarHeaderSize {
+		return nil, errInvalidGvarTable
+	}
+	axisCount := int(be.Uint16(buf[4:]))
+	sharedTupleCount := int(be.Uint16(buf[6:]))
+	sharedTuplesOffset := int(be.Uint32(buf[8:]))
+	glyphCount := int(be.Uint16(buf[12:]))
+	longOffsets := be.Uint16(buf[14:])&1 != 0
+	dataOffset := int(be.Uint32(buf[16:]))
+
+	if len(buf) < sharedTuplesOffset+2*axisCount*sharedTupleCount {
+		return nil, errInvalidGvarTable
+	}
+	out := &TableGvar{
+		baseTable:    baseTable(tag),
+		bytes:        buf,
+		axisCount:    axisCount,
+		sharedTuples: make([][]float32, sharedTupleCount),
+		variations:   make([][]byte, glyphCount),
+	}
+	for i := range out.sharedTuples {
+		out.sharedTuples[i] = parseTuple(buf[sharedTuplesOffset+2*axisCount*i:], axisCount)
+	}
+
+	offsets := make([]int, glyphCount+1)
+	if longOffsets {
+		if len(buf) < gvarHeaderSize+4*len(offsets) {
+			return nil, errInvalidGvarTable
+		}
+		for i := range offsets {
+			offsets[i] = int(be.Uint32(buf[gvarHeaderSize+4*i:]))
+		}
+	} else {
+		if len(buf) < gvarHeaderSize+2*len(offsets) {
+			return nil, errInvalidGvarTable
+		}
+		for i := range offsets {
+			offsets[i] = 2 * int(be.Uint16(buf[gvarHeaderSize+2*i:]))
+		}
+	}
+	for i := range out.variations {
+		start, end := dataOffset+offsets[i], dataOffset+offsets[i+1]
+		if start > end || end > len(buf) {
+			return nil, errInvalidGvarTable
+		}
+		out.variations[i] = buf[start:end]
+	}
+
+	return out, nil
+}
+
+func parseTuple(buf []byte, axisCount int) []float32 {
+	out := make([]float32, axisCount)
+	for i := range out {
+		out[i] = f2dot14ToFloat(be.Uint16(buf[2*i:]))
+	}
+	return out
+}
+
+// tupleVariation is the variation of the points of a glyph
+// for one region of the design space.
+type tupleVariation struct {
+	peak, start, end []float32 // start and end are nil if the region is not intermediate
+	// points are the indices of the varied points,
+	// or nil if all the points are varied
+	points []int
+	deltaX []int16
+	deltaY []int16
+}
+
+// scalar returns the contribution of the variation at the given
+// normalized coordinates.
+func (t tupleVariation) scalar(coords []float32) float32 {
+	out := float32(1)
+	for i, peak := range t.peak {
+		if peak == 0 {
+			continue
+		}
+		var coord float32
+		if i < len(coords) {
+			coord = coords[i]
+		}
+		if coord == peak {
+			continue
+		}
+		if coord == 0 {
+			return 0
+		}
+
+		start, end := peak, peak
+		if t.start != nil {
+			start, end = t.start[i], t.end[i]
+			if start > peak || peak > end || (start < 0 && end > 0) {
+				continue // invalid region, which is ignored
+			}
+		} else if peak < 0 {
+			end = 0
+		} else {
+			start = 0
+		}
+
+		if coord < start || coord > end {
+			return 0
+		}
+		if coord < peak {
+			out *= (coord - start) / (peak - start)
+		} else {
+			out *= (end - coord) / (end - peak)
+		}
+	}
+	return out
+}
+
+// parseGlyphVariations parses the variation data of a glyph with the given
+// number of points (including the phantom points).
+func (t *TableGvar) parseGlyphVariations(buf []byte, numPoints int) ([]tupleVariation, error) {
+	if len(buf) == 0 { // no variations
+		return nil, nil
+	}
+	if len(buf) < 4 {
+		return nil, errInvalidGvarTable
+	}
+	tupleCount := int(be.Uint16(buf) & tupleCountMask)
+	hasSharedPoints := be.Uint16(buf)&sharedPointNumbers != 0
+	dataOffset := int(be.Uint16(buf[2:]))
+	if len(buf) < dataOffset {
+		return nil, errInvalidGvarTable
+	}
+
+	data := buf[dataOffset:]
+	var sharedPoints []int
+	if hasSharedPoints {
+		var (
+			n   int
+			err error
+		)
+		sharedPoints, n, err = parsePointNumbers(data, numPoints)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+	}
+
+	out := make([]tupleVariation, tupleCount)
+	header := buf[4:]
+	for i := range out {
+		if len(header) < tupleVariationHeader {
+			return nil, errInvalidGvarTable
+		}
+		dataSize := int(be.Uint16(header))
+		tupleIndex := be.Uint16(header[2:])
+		header = header[tupleVariationHeader:]
+
+		tuple := &out[i]
+		if tupleIndex&embeddedPeakTuple != 0 {
+			if len(header) < 2*t.axisCount {
+				return nil, errInvalidGvarTable
+			}
+			tuple.peak = parseTuple(header, t.axisCount)
+			header = header[2*t.axisCount:]
+		} else {
+			index := int(tupleIndex & tupleIndexMask)
+			if index >= len(t.sharedTuples) {
+				return nil, errInvalidGvarTable
+			}
+			tuple.peak = t.sharedTuples[index]
+		}
+		if tupleIndex&intermediateRegion != 0 {
+			if len(header) < 4*t.axisCount {
+				return nil, errInvalidGvarTable
+			}
+			tuple.start = parseTuple(header, t.axisCount)
+			tuple.end = parseTuple(header[2*t.axisCount:], t.axisCount)
+			header = header[4*t.axisCount:]
+		}
+
+		if len(data) < dataSize {
+			return nil, errInvalidGvarTable
+		}
+		tupleData := data[:dataSize]
+		data = data[dataSize:]
+
+		tuple.points = sharedPoints
+		if tupleIndex&privatePointNumbers != 0 {
+			points, n, err := parsePointNumbers(tupleData, numPoints)
+			if err != nil {
+				return nil, err
+			}
+			tuple.points = points
+			tupleData = tupleData[n:]
+		}
+
+		count := numPoints
+		if tuple.points != nil {
+			count = len(tuple.points)
+		}
+		deltas, err := parseDeltas(tupleData, 2*count)
+		if err != nil {
+			return nil, err
+		}
+		tuple.deltaX, tuple.deltaY = deltas[:count], deltas[count:]
+	}
+	return out, nil
+}
+
+// parsePointNumbers parses packed point numbers, returning nil
+// if all the points are referenced, and the number of bytes read.
+func parsePointNumbers(buf []byte, numPoints int) ([]int, int, error) {
+	if len(buf) < 1 {
+		return nil, 0, errInvalidGvarTable
+	}
+	count, n := int(buf[0]), 1
+	if count&pointsAreWords != 0 {
+		if len(buf) < 2 {
+			return nil, 0, errInvalidGvarTable
+		}
+		count, n = int(be.Uint16(buf)&0x7FFF), 2
+	}
+	if count == 0 {
+		return nil, n, nil
+	}
+
+	out := make([]int, 0, count)
+	point := 0
+	for len(out) < count {
+		if len(buf) < n+1 {
+			return nil, 0, errInvalidGvarTable
+		}
+		control := buf[n]
+		n++
+		runCount := int(control&pointRunCountMask) + 1
+		for j := 0; j < runCount && len(out) < count; j++ {
+			if control&pointsAreWords != 0 {
+				if len(buf) < n+2 {
+					return nil, 0, errInvalidGvarTable
+				}
+				point += int(be.Uint16(buf[n:]))
+				n += 2
+			} else {
+				if len(buf) < n+1 {
+					return nil, 0, errInvalidGvarTable
+				}
+				point += int(buf[n])
+				n++
+			}
+			if point >= numPoints {
+				return nil, 0, errInvalidGvarTable
+			}
+			out = append(out, point)
+		}
+	}
+	return out, n, nil
+}
+
+// parseDeltas parses count packed deltas.
+func parseDeltas(buf []byte, count int) ([]int16, error) {
+	out := make([]int16, 0, count)
+	for len(out) < count {
+		if len(buf) < 1 {
+			return nil, errInvalidGvarTable
+		}
+		control := buf[0]
+		buf = buf[1:]
+		runCount := int(control&deltaRunCountMask) + 1
+		switch {
+		case control&deltasAreZero != 0:
+			for j := 0; j < runCount; j++ {
+				out = append(out, 0)
+			}
+		case control&deltasAreWords != 0:
+			if len(buf) < 2*runCount {
+				return nil, errInvalidGvarTable
+			}
+			for j := 0; j < runCount; j++ {
+				out = append(out, int16(be.Uint16(buf[2*j:])))
+			}
+			buf = buf[2*runCount:]
+		default:
+			if len(buf) < runCount {
+				return nil, errInvalidGvarTable
+			}
+			for j := 0; j < runCount; j++ {
+				out = append(out, int16(int8(buf[j])))
+			}
+			buf = buf[runCount:]
+		}
+	}
+	if len(out) > count {
+		return nil, errInvalidGvarTable
+	}
+	return out, nil
+}
+
+// glyphDeltas returns the deltas to apply to the points of the glyph (including
+// the phantom points), at the given normalized coordinates.
+// endPoints are the contours used to infer the deltas of untouched points.
+func (t *TableGvar) glyphDeltas(gi GlyphIndex, points []GlyphPoint, endPoints []int, coords []float32) ([]GlyphPoint, error) {
+	if int(gi) >= len(t.variations) {
+		return nil, errInvalidGvarTable
+	}
+	tuples, err := t.parseGlyphVariations(t.variations[gi], len(points))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]GlyphPoint, len(points))
+	for _, tuple := range tuples {
+		scalar := tuple.scalar(coords)
+		if scalar == 0 {
+			continue
+		}
+
+		if tuple.points == nil { // all points
+			for i := range out {
+				out[i].X += scalar * float32(tuple.deltaX[i])
+				out[i].Y += scalar * float32(tuple.deltaY[i])
+			}
+			continue
+		}
+
+		deltas := make([]GlyphPoint, len(points))
+		touched := make([]bool, len(points))
+		for j, point := range tuple.points {
+			deltas[point].X += float32(tuple.deltaX[j])
+			deltas[point].Y += float32(tuple.deltaY[j])
+			touched[point] = true
+		}
+		inferDeltas(points, endPoints, deltas, touched)
+		for i, delta := range deltas {
+			out[i].X += scalar * delta.X
+			out[i].Y += scalar * delta.Y
+		}
+	}
+	return out, nil
+}
+
+// inferDeltas interpolates the deltas of the untouched points
+// of each contour, from the nearest touched points (IUP).
+func inferDeltas(points []GlyphPoint, endPoints []int, deltas []GlyphPoint, touched []bool) {
+	start := 0
+	for _, end := range endPoints {
+		if end >= len(points) {
+			return
+		}
+		var touchedPoints []int
+		for i := start; i <= end; i++ {
+			if touched[i] {
+				touchedPoints = append(touchedPoints, i)
+			}
+		}
+		if len(touchedPoints) == 0 { // no deltas for this contour
+			start = end + 1
+			continue
+		}
+
+		for j, prev := range touchedPoints {
+			next := touchedPoints[(j+1)%len(touchedPoints)]
+			// the untouched points between prev and next, cyclically
+			for i := prev + 1; ; i++ {
+				if i > end {
+					i = start
+				}
+				if i == next {
+					break
+				}
+				deltas[i].X = interpolateDelta(points[i].X, points[prev].X, points[next].X, deltas[prev].X, deltas[next].X)
+				deltas[i].Y = interpolateDelta(points[i].Y, points[prev].Y, points[next].Y, deltas[prev].Y, deltas[next].Y)
+			}
+		}
+		start = end + 1
+	}
+}
+
+func interpolateDelta(v, v1, v2, d1, d2 float32) float32 {
+	if v1 > v2 {
+		v1, v2, d1, d2 = v2, v1, d2, d1
+	}
+	switch {
+	case v1 == v2:
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	case v <= v1:
+		return d1
+	case v >= v2:
+		return d2
+	default:
+		return d1 + (v-v1)*(d2-d1)/(v2-v1)
+	}
+}
+
+// GvarTable returns the glyph variations table.
+func (font *Font) GvarTable() (*TableGvar, error) {
+	t, err := font.Table(TagGvar)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableGvar), nil
+}
+
+// VariedGlyphOutline returns the outline of the given glyph, for
+// a variable font with TrueType outlines, at the given normalized coordinates
+// (one for each axis, in the range [-1, 1]).
+// If coords is empty or the font has no 'gvar' table, the outline
+// of the default instance is returned.
+func (font *Font) VariedGlyphOutline(gi GlyphIndex, coords []float32) (GlyphOutline, error) {
+	var gvar *TableGvar
+	if len(coords) != 0 && font.HasTable(TagGvar) {
+		var err error
+		gvar, err = font.GvarTable()
+		if err != nil {
+			return GlyphOutline{}, err
+		}
+	}
+	return font.variedGlyphOutline(gi, gvar, coords, 0)
+}
+
+func (font *Font) variedGlyphOutline(gi GlyphIndex, gvar *TableGvar, coords []float32, level int) (GlyphOutline, error) {
+	if level > maxComponentLevel {
+		return GlyphOutline{}, errInvalidGlyfTable
+	}
+	buf, err := font.glyphBuffer(gi)
+	if err != nil {
+		return GlyphOutline{}, err
+	}
+	glyph, err := parseGlyphData(buf)
+	if err != nil {
+		return GlyphOutline{}, err
+	}
+
+	// the points varied by gvar: the outline points or the
+	// component offsets, followed by the phantom points
+	points := make([]GlyphPoint, glyph.numPoints()+numPhantomPoints)
+	endPoints := glyph.outline.EndPoints
+	if glyph.components == nil {
+		copy(points, glyph.outline.Points)
+	} else {
+		endPoints = nil // the offsets are not contours
+		for i, component := range glyph.components {
+			if component.flags&argsAreXYValues != 0 {
+				points[i] = GlyphPoint{X: float32(component.arg1), Y: float32(component.arg2)}
+			}
+		}
+	}
+
+	if gvar != nil {
+		deltas, err := gvar.glyphDeltas(gi, points, endPoints, coords)
+		if err != nil {
+			return GlyphOutline{}, err
+		}
+		for i, delta := range deltas {
+			points[i].X += delta.X
+			points[i].Y += delta.Y
+		}
+	}
+
+	if glyph.components == nil {
+		return GlyphOutline{
+			Points:    points[:len(glyph.outline.Points)],
+			EndPoints: glyph.outline.EndPoints,
+		}, nil
+	}
+
+	var out GlyphOutline
+	for i, component := range glyph.components {
+		outline, err := font.variedGlyphOutline(component.glyph, gvar, coords, level+1)
+		if err != nil {
+			return GlyphOutline{}, err
+		}
+		if err := out.appendComponent(component, points[i].X, points[i].Y, outline); err != nil {
+			return GlyphOutline{}, err
+		}
+	}
+	return out, nil
+}
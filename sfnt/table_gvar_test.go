@@ -0,0 +1,145 @@
+package sfnt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// variableGlyphsFont returns a font with one axis, a square (glyph 1)
+// and a composite glyph (glyph 2) using the square.
+func variableGlyphsFont(t *testing.T) *Font {
+	square := []byte{
+		0, 1, // numberOfContours
+		0, 0, 0, 0, 0, 100, 0, 100, // bounding box
+		0, 3, // endPtsOfContours
+		0, 0, // instructionLength
+		1, 1, 1, 1, // flags: on curve
+		0, 0, 0, 100, 0, 0, 0xFF, 0x9C, // x: 0, 100, 100, 0
+		0, 0, 0, 0, 0, 100, 0, 0, // y: 0, 0, 100, 100
+	}
+	composite := []byte{
+		0xFF, 0xFF, // numberOfContours
+		0, 0, 0, 0, 0, 0, 0, 0, // bounding box
+		0, argsAreWords | argsAreXYValues,
+		0, 1, // glyph
+		0, 10, 0, 20, // offset
+	}
+	glyf := append(append([]byte(nil), square...), composite...)
+	loca := []byte{0, 0, 0, 0, 0, byte(len(square) / 2), 0, byte(len(glyf) / 2)}
+
+	gvar := []byte{
+		0, 1, 0, 0, // version
+		0, 1, // axisCount
+		0, 1, // sharedTupleCount
+		0, 0, 0, 28, // sharedTuplesOffset
+		0, 3, // glyphCount
+		0, 0, // flags
+		0, 0, 0, 30, // glyphVariationDataArrayOffset
+		0, 0, 0, 0, 0, 9, 0, 15, // offsets
+		0x40, 0, // shared tuple: 1
+		// square: points 1 and 2 are moved
+		0, 1, 0, 8, // tupleVariationCount, dataOffset
+		0, 10, privatePointNumbers >> 8, 0, // variationDataSize, tupleIndex
+		2, 1, 1, 1, // points
+		1, 50, 50, // x deltas
+		1, 0, 50, // y deltas
+		// composite: the component is moved
+		0, 1, 0, 8, // tupleVariationCount, dataOffset
+		0, 4, 0, 0, // variationDataSize, tupleIndex
+		0, 10, 0x83, // x deltas
+		0x84, // y deltas
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagHead, &TableHead{baseTable: baseTable(TagHead)})
+	font.AddTable(TagLoca, NewTable(TagLoca, loca))
+	font.AddTable(TagGlyf, NewTable(TagGlyf, glyf))
+	table, err := parseTableGvar(TagGvar, gvar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagGvar, table)
+	return font
+}
+
+func outlinePoints(points ...float32) []GlyphPoint {
+	out := make([]GlyphPoint, len(points)/2)
+	for i := range out {
+		out[i] = GlyphPoint{X: points[2*i], Y: points[2*i+1], OnCurve: true}
+	}
+	return out
+}
+
+func TestVariedGlyphOutline(t *testing.T) {
+	font := variableGlyphsFont(t)
+
+	for _, test := range []struct {
+		glyph  GlyphIndex
+		coords []float32
+		points []GlyphPoint
+	}{
+		{1, nil, outlinePoints(0, 0, 100, 0, 100, 100, 0, 100)},
+		{1, []float32{-0.5}, outlinePoints(0, 0, 100, 0, 100, 100, 0, 100)},
+		// untouched points are interpolated
+		{1, []float32{1}, outlinePoints(50, 0, 150, 0, 150, 150, 50, 150)},
+		{1, []float32{0.5}, outlinePoints(25, 0, 125, 0, 125, 125, 25, 125)},
+		{2, nil, outlinePoints(10, 20, 110, 20, 110, 120, 10, 120)},
+		{2, []float32{1}, outlinePoints(70, 20, 170, 20, 170, 170, 70, 170)},
+	} {
+		outline, err := font.VariedGlyphOutline(test.glyph, test.coords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(outline.Points, test.points) {
+			t.Errorf("glyph %d at %v: expected %v, got %v", test.glyph, test.coords, test.points, outline.Points)
+		}
+		if !reflect.DeepEqual(outline.EndPoints, []int{3}) {
+			t.Errorf("unexpected contours %v", outline.EndPoints)
+		}
+	}
+}
+
+func TestTupleScalar(t *testing.T) {
+	for _, test := range []struct {
+		tuple  tupleVariation
+		coords []float32
+		scalar float32
+	}{
+		{tupleVariation{peak: []float32{1, 0}}, []float32{0.5, 1}, 0.5},
+		{tupleVariation{peak: []float32{-1}}, []float32{0.5}, 0},
+		{tupleVariation{peak: []float32{0.5}, start: []float32{0.25}, end: []float32{1}}, []float32{0.25}, 0},
+		{tupleVariation{peak: []float32{0.5}, start: []float32{0.25}, end: []float32{1}}, []float32{0.75}, 0.5},
+		{tupleVariation{peak: []float32{0.5}, start: []float32{0.25}, end: []float32{1}}, []float32{0.375}, 0.5},
+	} {
+		if got := test.tuple.scalar(test.coords); got != test.scalar {
+			t.Errorf("tuple %v at %v: expected %v, got %v", test.tuple, test.coords, test.scalar, got)
+		}
+	}
+}
+
+func TestGlyphOutline(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gi := GlyphIndex(0); gi < GlyphIndex(numGlyphs); gi++ {
+		outline, err := font.GlyphOutline(gi)
+		if err != nil {
+			t.Fatalf("glyph %d: %s", gi, err)
+		}
+		if n := len(outline.EndPoints); n != 0 && outline.EndPoints[n-1] != len(outline.Points)-1 {
+			t.Errorf("glyph %d: invalid contours", gi)
+		}
+	}
+}
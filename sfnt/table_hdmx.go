@@ -0,0 +1,100 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+)
+
+var errInvalidHdmxTable = errors.New("invalid hdmx table")
+
+// TableHdmx is the horizontal device metrics table, which stores
+// the advances of the glyphs, in pixels, for some sizes, as
+// computed by the hinting instructions.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/hdmx
+type TableHdmx struct {
+	baseTable
+
+	bytes []byte
+
+	// Records are sorted by increasing PixelSize.
+	Records []HdmxRecord
+}
+
+// HdmxRecord stores the advances for one size.
+type HdmxRecord struct {
+	PixelSize uint8 // pixels per em
+	MaxWidth  uint8
+	// Widths are indexed by glyph. The slice may be longer than
+	// the number of glyphs, because of the padding of the table.
+	Widths []uint8
+}
+
+// Bytes returns the bytes for this table. The TableHdmx is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableHdmx) Bytes() []byte {
+	return t.bytes
+}
+
+// Widths returns the advances for the given size, in pixels
+// per em, or false if the table has no record for this size.
+func (t *TableHdmx) Widths(ppem uint8) ([]uint8, bool) {
+	i := sort.Search(len(t.Records), func(i int) bool { return t.Records[i].PixelSize >= ppem })
+	if i < len(t.Records) && t.Records[i].PixelSize == ppem {
+		return t.Records[i].Widths, true
+	}
+	return nil, false
+}
+
+func parseTableHdmx(tag Tag, buf []byte) (Table, error) {
+	const headerSize, recordHeaderSize = 8, 2
+	if len(buf) < headerSize {
+		return nil, errInvalidHdmxTable
+	}
+	numRecords := int(int16(be.Uint16(buf[2:])))
+	recordSize := int(int32(be.Uint32(buf[4:])))
+	if numRecords < 0 || recordSize < recordHeaderSize || len(buf) < headerSize+numRecords*recordSize {
+		return nil, errInvalidHdmxTable
+	}
+
+	records := make([]HdmxRecord, numRecords)
+	for i := range records {
+		record := buf[headerSize+recordSize*i : headerSize+recordSize*(i+1)]
+		records[i] = HdmxRecord{PixelSize: record[0], MaxWidth: record[1], Widths: record[recordHeaderSize:]}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].PixelSize < records[j].PixelSize })
+
+	return &TableHdmx{baseTable: baseTable(tag), bytes: buf, Records: records}, nil
+}
+
+// HdmxTable returns the horizontal device metrics table.
+func (font *Font) HdmxTable() (*TableHdmx, error) {
+	t, err := font.Table(TagHdmx)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableHdmx), nil
+}
+
+// DeviceAdvances returns the advances of the glyphs, in pixels, stored
+// for the given size (in pixels per em) by the 'hdmx' table, so that the
+// layout may match the hinted rendering without running the instructions.
+// It returns false if the size is not stored, in which case the
+// advances should be computed from the 'hmtx' table.
+func (font *Font) DeviceAdvances(ppem uint8) ([]uint8, bool, error) {
+	hdmx, err := font.HdmxTable()
+	if err != nil {
+		return nil, false, err
+	}
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, false, err
+	}
+	widths, ok := hdmx.Widths(ppem)
+	if !ok {
+		return nil, false, nil
+	}
+	if len(widths) < int(numGlyphs) {
+		return nil, false, errInvalidHdmxTable
+	}
+	return widths[:numGlyphs], true, nil
+}
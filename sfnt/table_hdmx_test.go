@@ -0,0 +1,37 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHdmx(t *testing.T) {
+	hdmx := []byte{
+		0, 0, 0, 2, 0, 0, 0, 8, // version, numRecords, sizeDeviceRecord
+		14, 9, 7, 8, 9, 0, 0, 0, // 14 ppem, 3 glyphs and padding
+		12, 8, 6, 7, 8, 0, 0, 0, // 12 ppem
+	}
+	maxp := []byte{0, 0, 0x50, 0, 0, 3} // version 0.5, 3 glyphs
+
+	font := New(TypeTrueType)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, maxp))
+	table, err := ParseTable(TagHdmx, hdmx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagHdmx, table)
+
+	for ppem, expected := range map[uint8][]uint8{12: {6, 7, 8}, 14: {7, 8, 9}, 13: nil} {
+		widths, ok, err := font.DeviceAdvances(ppem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != (expected != nil) || !reflect.DeepEqual(widths, expected) {
+			t.Errorf("ppem %d: expected %v, got %v (%v)", ppem, expected, widths, ok)
+		}
+	}
+
+	if _, err := ParseTable(TagHdmx, hdmx[:20]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
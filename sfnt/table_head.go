@@ -3,8 +3,11 @@ package sfnt
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 )
 
+var errInvalidHeadTable = errors.New("invalid head table")
+
 // TableHead contains critical information about the rest of the font.
 // https://developer.apple.com/fonts/TrueType-Reference-Manual/RM06/Chap6head.html
 type TableHead struct {
@@ -49,7 +52,7 @@ func parseTableHead(tag Tag, buf []byte) (Table, error) {
 // Bytes returns the byte representation of this header.
 func (table *TableHead) Bytes() []byte {
 	var buffer bytes.Buffer
-	if err := binary.Write(&buffer, binary.BigEndian, table); err != nil {
+	if err := binary.Write(&buffer, binary.BigEndian, table.tableHeadFields); err != nil {
 		panic(err) // should never happen
 	}
 	return buffer.Bytes()
@@ -69,3 +72,56 @@ func (table *TableHead) SetExpectedChecksum(checksum uint32) {
 func (table *TableHead) ClearExpectedChecksum() {
 	table.CheckSumAdjustment = 0
 }
+
+// bits of the head flags
+const (
+	headBaselineAtZero           = 1 << 0
+	headLSBAtZero                = 1 << 1
+	headInstructionsDependOnSize = 1 << 2
+	headIntegerPPEM              = 1 << 3
+	headInstructionsAlterAdvance = 1 << 4
+	headLossless                 = 1 << 11
+	headConverted                = 1 << 12
+	headClearTypeOptimized       = 1 << 13
+	headLastResort               = 1 << 14
+)
+
+// BaselineAtZero returns true if the baseline of the font is at y = 0.
+func (table *TableHead) BaselineAtZero() bool { return table.Flags&headBaselineAtZero != 0 }
+
+// LSBAtZero returns true if the left side bearing point is at x = 0
+// (only relevant for TrueType rasterizers).
+func (table *TableHead) LSBAtZero() bool { return table.Flags&headLSBAtZero != 0 }
+
+// InstructionsDependOnSize returns true if the hinting
+// instructions may depend on the point size.
+func (table *TableHead) InstructionsDependOnSize() bool {
+	return table.Flags&headInstructionsDependOnSize != 0
+}
+
+// IntegerPPEM returns true if the scaler should use integer ppem values
+// (the ppem is rounded for all internal computations).
+func (table *TableHead) IntegerPPEM() bool { return table.Flags&headIntegerPPEM != 0 }
+
+// InstructionsAlterAdvance returns true if the hinting instructions
+// may alter the advance widths, which then do not scale linearly.
+func (table *TableHead) InstructionsAlterAdvance() bool {
+	return table.Flags&headInstructionsAlterAdvance != 0
+}
+
+// Lossless returns true if the font data has been subjected to
+// an optimizing transformation or compression, preserving the
+// original functionality (as with WOFF2).
+func (table *TableHead) Lossless() bool { return table.Flags&headLossless != 0 }
+
+// Converted returns true if the font has been converted,
+// producing compatible metrics.
+func (table *TableHead) Converted() bool { return table.Flags&headConverted != 0 }
+
+// ClearTypeOptimized returns true if the font is optimized for ClearType.
+func (table *TableHead) ClearTypeOptimized() bool { return table.Flags&headClearTypeOptimized != 0 }
+
+// LastResort returns true if the font is a last resort font, whose glyphs
+// do not represent the characters they are mapped to, but a generic
+// symbol of their Unicode range.
+func (table *TableHead) LastResort() bool { return table.Flags&headLastResort != 0 }
@@ -0,0 +1,16 @@
+package sfnt
+
+import "testing"
+
+func TestHeadFlags(t *testing.T) {
+	head := TableHead{}
+	head.Flags = 0x4013 // baseline, lsb, instructions may alter advance, last resort
+
+	if !head.BaselineAtZero() || !head.LSBAtZero() || !head.InstructionsAlterAdvance() || !head.LastResort() {
+		t.Errorf("missing flags for %x", head.Flags)
+	}
+	if head.InstructionsDependOnSize() || head.IntegerPPEM() || head.Lossless() ||
+		head.Converted() || head.ClearTypeOptimized() {
+		t.Errorf("unexpected flags for %x", head.Flags)
+	}
+}
@@ -27,7 +27,7 @@ func parseHtmxTable(input []byte, numberOfHMetrics, numGlyphs uint16) ([]int, er
 	widths := make([]int, numberOfHMetrics)
 	for i := range widths {
 		// we ignore the Glyph left side bearing
-		widths[i] = int(be.Uint16(input[2*i : 2*i+2]))
+		widths[i] = int(be.Uint16(input[4*i : 4*i+2]))
 	}
 	if numberOfHMetrics < numGlyphs { // pad
 		widths = append(widths, make([]int, numGlyphs-numberOfHMetrics)...)
@@ -38,3 +38,161 @@ func parseHtmxTable(input []byte, numberOfHMetrics, numGlyphs uint16) ([]int, er
 	}
 	return widths, nil
 }
+
+// HMetric is the horizontal metric of a glyph.
+type HMetric struct {
+	Advance uint16
+	LSB     int16 // left side bearing
+}
+
+// parseHmtxMetrics returns the metrics of each glyph: the glyphs after
+// the numberOfHMetrics first ones share the last advance, and have their
+// own left side bearing.
+func parseHmtxMetrics(input []byte, numberOfHMetrics, numGlyphs int) ([]HMetric, error) {
+	if numberOfHMetrics <= 0 || numberOfHMetrics > numGlyphs ||
+		len(input) < 4*numberOfHMetrics+2*(numGlyphs-numberOfHMetrics) {
+		return nil, errInvalidHtmxTable
+	}
+
+	out := make([]HMetric, numGlyphs)
+	for i := range out {
+		if i < numberOfHMetrics {
+			out[i] = HMetric{Advance: be.Uint16(input[4*i:]), LSB: int16(be.Uint16(input[4*i+2:]))}
+		} else {
+			out[i] = HMetric{
+				Advance: out[numberOfHMetrics-1].Advance,
+				LSB:     int16(be.Uint16(input[4*numberOfHMetrics+2*(i-numberOfHMetrics):])),
+			}
+		}
+	}
+	return out, nil
+}
+
+// HmtxMetrics returns the advance and the left side bearing of each
+// glyph (array of size numGlyphs), as stored in the 'hmtx' table.
+// See HtmxTable for a simpler access to the advances.
+func (font *Font) HmtxMetrics() ([]HMetric, error) {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return nil, err
+	}
+	section, found := font.tables[TagHmtx]
+	if !found {
+		return nil, ErrMissingTable
+	}
+	buf, err := font.findTableBuffer(section)
+	if err != nil {
+		return nil, err
+	}
+	return parseHmtxMetrics(buf, int(uint16(hhea.NumOfLongHorMetrics)), int(numGlyphs))
+}
+
+// IsMonospace returns true if all the glyphs with a non zero advance
+// (zero width glyphs are usually combining marks) share the same advance.
+// When the metrics are not available, or all the advances are zero,
+// it falls back on the isFixedPitch field of the 'post' table and
+// the PANOSE classification of the 'OS/2' table.
+func (font *Font) IsMonospace() bool {
+	if widths, err := font.HtmxTable(); err == nil {
+		if advance := uniformAdvance(widths); advance != 0 {
+			return advance != -1
+		}
+	}
+
+	if post, err := font.PostTable(); err == nil && post.IsFixedPitch {
+		return true
+	}
+	if os2, err := font.OS2Table(); err == nil && os2.isPanoseMonospace() {
+		return true
+	}
+	return false
+}
+
+// uniformAdvance returns the common advance of the non zero
+// widths, 0 if there is none, or -1 if the advances differ.
+func uniformAdvance(widths []int) int {
+	advance := 0
+	for _, w := range widths {
+		if w == 0 {
+			continue
+		}
+		if advance != 0 && w != advance {
+			return -1
+		}
+		advance = w
+	}
+	return advance
+}
+
+const (
+	panoseFamilyLatinText     = 2
+	panoseProportionMonospace = 9
+	os2PanoseOffset           = 32
+)
+
+func (t *TableOS2) isPanoseMonospace() bool {
+	return t.Panose[0] == panoseFamilyLatinText && t.Panose[3] == panoseProportionMonospace
+}
+
+// monospaceTables returns the content of the 'hmtx', 'hhea', 'post' and
+// 'OS/2' tables to write so that every glyph with a non zero advance has
+// the given advance, and the font is marked as monospaced.
+// The missing 'post' and 'OS/2' tables are not added.
+func (font *Font) monospaceTables(advance uint16) (map[Tag][]byte, error) {
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := font.HmtxMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	// all the glyphs are written with a full metric record
+	hmtx := make([]byte, 4*len(metrics))
+	for gi, metric := range metrics {
+		if metric.Advance != 0 {
+			metric.Advance = advance
+		}
+		be.PutUint16(hmtx[4*gi:], metric.Advance)
+		be.PutUint16(hmtx[4*gi+2:], uint16(metric.LSB))
+	}
+	newHhea := *hhea
+	newHhea.NumOfLongHorMetrics = int16(len(metrics))
+	newHhea.AdvanceWidthMax = advance
+	out := map[Tag][]byte{TagHmtx: hmtx, TagHhea: newHhea.Bytes()}
+
+	if section, found := font.tables[TagPost]; found {
+		buf, err := font.findTableBuffer(section)
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) < 16 {
+			return nil, errInvalidPostTable
+		}
+		post := append([]byte(nil), buf...)
+		be.PutUint32(post[12:], 1) // isFixedPitch
+		out[TagPost] = post
+	}
+
+	if font.HasTable(TagOS2) {
+		// the character indexes may be updated
+		buf, err := font.tableBytes(TagOS2)
+		if err != nil {
+			return nil, err
+		}
+		os2 := append([]byte(nil), buf...)
+		if len(os2) >= os2PanoseOffset+10 {
+			be.PutUint16(os2[2:], advance) // xAvgCharWidth
+			if os2[os2PanoseOffset] == panoseFamilyLatinText {
+				os2[os2PanoseOffset+3] = panoseProportionMonospace
+			}
+		}
+		out[TagOS2] = os2
+	}
+	return out, nil
+}
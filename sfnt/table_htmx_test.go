@@ -1,8 +1,11 @@
 package sfnt
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -35,3 +38,162 @@ func TestHtmx(t *testing.T) {
 		f.Close()
 	}
 }
+
+func TestMonospace(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.IsMonospace() {
+		t.Fatal("unexpected monospace font")
+	}
+
+	var buf bytes.Buffer
+	if _, err := font.WriteWithOptions(&buf, WriteOptions{MonospaceAdvance: 1200}); err != nil {
+		t.Fatal(err)
+	}
+	// the font itself is not modified
+	if font.IsMonospace() {
+		t.Error("unexpected monospace font")
+	}
+
+	written, err := StrictParse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !written.IsMonospace() {
+		t.Fatal("expected monospace font")
+	}
+	widths, err := written.HtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uniformAdvance(widths) != 1200 {
+		t.Errorf("unexpected advances")
+	}
+	post, err := written.PostTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.IsFixedPitch {
+		t.Error("expected fixed pitch")
+	}
+	os2, err := written.OS2Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := be.Uint16(os2.Bytes()[2:]); width != 1200 { // xAvgCharWidth
+		t.Errorf("unexpected average width %d", width)
+	}
+
+	// an invalid 'post' table
+	font.AddTable(TagPost, NewTable(TagPost, make([]byte, 8)))
+	if _, err := font.WriteWithOptions(ioutil.Discard, WriteOptions{MonospaceAdvance: 1200}); err == nil {
+		t.Fatal("expected an error for an invalid post table")
+	}
+}
+
+func TestIsMonospaceZeroAdvances(t *testing.T) {
+	font := New(TypeTrueType)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, []byte{0, 0, 0x50, 0, 0, 2}))
+	font.AddTable(TagHhea, &TableHhea{baseTable: baseTable(TagHhea), tableHheaFields: tableHheaFields{NumOfLongHorMetrics: 2}})
+	font.AddTable(TagHmtx, NewTable(TagHmtx, make([]byte, 8)))
+	if font.IsMonospace() {
+		t.Error("unexpected monospace font without advances")
+	}
+
+	// the 'post' table is used instead
+	post := make([]byte, 32)
+	post[1] = 3 // version 3.0
+	post[15] = 1
+	font.AddTable(TagPost, NewTable(TagPost, post))
+	if !font.IsMonospace() {
+		t.Error("expected monospace font")
+	}
+}
+
+func TestHmtxMetrics(t *testing.T) {
+	// 2 long metrics and 2 left side bearings
+	hmtx := []byte{0, 100, 0, 5, 0, 200, 0xFF, 0xF6, 0, 7, 0, 8}
+	metrics, err := parseHmtxMetrics(hmtx, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []HMetric{{100, 5}, {200, -10}, {200, 7}, {200, 8}}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("expected %v, got %v", expected, metrics)
+	}
+	if _, err := parseHmtxMetrics(hmtx, 2, 5); err == nil {
+		t.Error("expected error for truncated table")
+	}
+	if _, err := parseHmtxMetrics(hmtx, -492, 4); err == nil {
+		t.Error("expected error for a negative number of metrics")
+	}
+
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err = font.HmtxMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range widths {
+		if int(metrics[i].Advance) != w {
+			t.Fatalf("glyph %d: expected advance %d, got %d", i, w, metrics[i].Advance)
+		}
+	}
+
+	// numberOfHMetrics is unsigned: 0xFE14 is more than the number of glyphs
+	hhea, err := font.HheaTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hhea.NumOfLongHorMetrics = -492
+	if _, err := font.HmtxMetrics(); err == nil {
+		t.Error("expected an error for an invalid numberOfHMetrics")
+	}
+}
+
+func TestHtmxAdvances(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the long metrics are (advance, lsb) pairs
+	expected := []int{918, 0, 0, 505, 505, 505, 540, 637}
+	for gi, advance := range expected {
+		if widths[gi] != advance {
+			t.Errorf("glyph %d: expected advance %d, got %d", gi, advance, widths[gi])
+		}
+	}
+	// 3358 long metrics for 3359 glyphs: the last advance is repeated
+	if len(widths) != 3359 || widths[3358] != widths[3357] {
+		t.Errorf("unexpected padding of %d advances", len(widths))
+	}
+}
@@ -0,0 +1,137 @@
+package sfnt
+
+import "errors"
+
+var errInvalidHvarTable = errors.New("invalid HVAR table")
+
+// TableHvar is the horizontal metrics variations table, which stores
+// the variations of the advance widths of a variable font.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/hvar
+type TableHvar struct {
+	baseTable
+
+	bytes []byte
+
+	store          itemVariationStore
+	advanceMapping deltaSetMapping // may be nil
+}
+
+// Bytes returns the bytes for this table. The TableHvar is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableHvar) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableHvar(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 20
+	if len(buf) < headerSize {
+		return nil, errInvalidHvarTable
+	}
+	storeOffset := int(be.Uint32(buf[4:]))
+	advanceMappingOffset := int(be.Uint32(buf[8:]))
+
+	out := &TableHvar{baseTable: baseTable(tag), bytes: buf}
+	var err error
+	out.store, err = parseItemVariationStore(buf, storeOffset)
+	if err != nil {
+		return nil, err
+	}
+	if advanceMappingOffset != 0 {
+		out.advanceMapping, err = parseDeltaSetMapping(buf, advanceMappingOffset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// AdvanceDelta returns the variation of the advance width of the glyph,
+// at the given normalized coordinates.
+func (t *TableHvar) AdvanceDelta(gi GlyphIndex, coords []float32) float32 {
+	outer, inner := t.advanceMapping.index(int(gi))
+	return t.store.delta(outer, inner, coords)
+}
+
+// HvarTable returns the horizontal metrics variations table.
+func (font *Font) HvarTable() (*TableHvar, error) {
+	t, err := font.Table(TagHvar)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableHvar), nil
+}
+
+// hAdvances stores the tables used to compute the
+// advances of the glyphs of a variable font.
+type hAdvances struct {
+	widths []int
+	hvar   *TableHvar // nil if the font has no 'HVAR' table
+	gvar   *TableGvar // nil if the font has no 'HVAR' nor 'gvar' table
+}
+
+// loadHAdvances returns the tables used by GlyphHAdvance,
+// parsed once until the font is modified.
+func (font *Font) loadHAdvances() (*hAdvances, error) {
+	if font.derived.hAdvances != nil {
+		return font.derived.hAdvances, nil
+	}
+	widths, err := font.HtmxTable()
+	if err != nil {
+		return nil, err
+	}
+	out := &hAdvances{widths: widths}
+	switch {
+	case font.HasTable(TagHvar):
+		out.hvar, err = font.HvarTable()
+	case font.HasTable(TagGvar):
+		out.gvar, err = font.GvarTable()
+	}
+	if err != nil {
+		return nil, err
+	}
+	font.derived.hAdvances = out
+	return out, nil
+}
+
+// GlyphHAdvance returns the advance width of the glyph, for a variable font,
+// at the given normalized coordinates (see NormalizeCoordinates).
+// The variations are read from the 'HVAR' table or, if it is missing, from
+// the phantom points of the 'gvar' table.
+// If coords is empty, the advance of the default instance is returned.
+// The tables are only parsed on the first call, so that the advances
+// of all the glyphs may be queried efficiently.
+func (font *Font) GlyphHAdvance(gi GlyphIndex, coords []float32) (float32, error) {
+	tables, err := font.loadHAdvances()
+	if err != nil {
+		return 0, err
+	}
+	if int(gi) >= len(tables.widths) {
+		return 0, errInvalidHtmxTable
+	}
+	advance := float32(tables.widths[gi])
+	if len(coords) == 0 {
+		return advance, nil
+	}
+
+	switch {
+	case tables.hvar != nil:
+		advance += tables.hvar.AdvanceDelta(gi, coords)
+	case tables.gvar != nil:
+		buf, err := font.glyphBuffer(gi)
+		if err != nil {
+			return 0, err
+		}
+		glyph, err := parseGlyphData(buf)
+		if err != nil {
+			return 0, err
+		}
+		// only the deltas of the phantom points matter
+		n := glyph.numPoints()
+		deltas, err := tables.gvar.glyphDeltas(gi, make([]GlyphPoint, n+numPhantomPoints), nil, coords)
+		if err != nil {
+			return 0, err
+		}
+		advance += deltas[n+1].X - deltas[n].X
+	}
+	return advance, nil
+}
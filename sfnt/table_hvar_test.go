@@ -0,0 +1,73 @@
+package sfnt
+
+import (
+	"testing"
+)
+
+func TestGlyphHAdvance(t *testing.T) {
+	hvar, err := parseTableHvar(TagHvar, []byte{
+		0, 1, 0, 0, // version
+		0, 0, 0, 20, // itemVariationStoreOffset
+		0, 0, 0, 0, // advanceWidthMappingOffset
+		0, 0, 0, 0, // lsbMappingOffset
+		0, 0, 0, 0, // rsbMappingOffset
+		// item variation store
+		0, 1, // format
+		0, 0, 0, 12, // variationRegionListOffset
+		0, 1, // itemVariationDataCount
+		0, 0, 0, 22, // itemVariationDataOffsets
+		// region list: one axis, one region [0, 1, 1]
+		0, 1, 0, 1, 0, 0, 0x40, 0, 0x40, 0,
+		// item variation data: two items, one region
+		0, 2, 0, 0, 0, 1, 0, 0, 10, 0xEC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, []byte{0, 0, 0x50, 0, 0, 2}))
+	font.AddTable(TagHhea, &TableHhea{baseTable: baseTable(TagHhea), tableHheaFields: tableHheaFields{NumOfLongHorMetrics: 2}})
+	font.AddTable(TagHmtx, NewTable(TagHmtx, []byte{0x01, 0xF4, 0, 0, 0x02, 0x58, 0, 0}))
+	font.AddTable(TagHvar, hvar)
+
+	for _, test := range []struct {
+		glyph   GlyphIndex
+		coords  []float32
+		advance float32
+	}{
+		{0, nil, 500},
+		{1, nil, 600},
+		{0, []float32{-1}, 500},
+		{0, []float32{1}, 510},
+		{0, []float32{0.5}, 505},
+		{1, []float32{0.5}, 590},
+	} {
+		advance, err := font.GlyphHAdvance(test.glyph, test.coords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if advance != test.advance {
+			t.Errorf("glyph %d at %v: expected %v, got %v", test.glyph, test.coords, test.advance, advance)
+		}
+	}
+
+	// the parsed tables are reset when the font is modified
+	font.AddTable(TagHmtx, NewTable(TagHmtx, []byte{0x02, 0x00, 0, 0, 0x02, 0x58, 0, 0}))
+	if advance, err := font.GlyphHAdvance(0, []float32{1}); err != nil || advance != 522 {
+		t.Errorf("expected 522, got %v %v", advance, err)
+	}
+}
+
+func TestDeltaSetMapping(t *testing.T) {
+	// format 0, 2 bytes entries with 4 bits inner indices
+	mapping, err := parseDeltaSetMapping([]byte{0, 0x13, 0, 2, 0, 0x12, 0, 0x25}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item, exp := range [][2]uint16{{1, 2}, {2, 5}, {2, 5}} {
+		if outer, inner := mapping.index(item); outer != exp[0] || inner != exp[1] {
+			t.Errorf("item %d: expected %v, got %d %d", item, exp, outer, inner)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package sfnt
 
 import (
 	"errors"
+	"sort"
 )
 
 var (
@@ -16,8 +17,20 @@ type Kerns interface {
 	// The value is expressed in glyph units and
 	// is negative when glyphs should be closer.
 	KernPair(left, right GlyphIndex) (int16, bool)
+	// KernPairAt returns the kern value of the pair, as KernPair does,
+	// and its correction at the given size (ppem), in pixels, as stored
+	// in the device tables of the 'GPOS' table. As done by the renderers,
+	// the hinted kerning is the value scaled to the size and rounded,
+	// plus the correction.
+	KernPairAt(left, right GlyphIndex, ppem uint16) (value, delta int16, ok bool)
 	// Size returns the number of kerning pairs
 	Size() int
+	// Each calls fn for each kerning pair, once, with the value
+	// returned by KernPair. The order of the pairs is not specified,
+	// and the pairs with a zero value may be skipped.
+	// The class based 'GPOS' kerning does not visit the second glyphs
+	// of the default class, which can't be enumerated.
+	Each(fn func(left, right GlyphIndex, value int16))
 }
 
 // key is left << 16 + right
@@ -28,11 +41,44 @@ func (s simpleKerns) KernPair(left, right GlyphIndex) (int16, bool) {
 	return out, has
 }
 
+func (s simpleKerns) KernPairAt(left, right GlyphIndex, _ uint16) (int16, int16, bool) {
+	out, has := s.KernPair(left, right)
+	return out, 0, has
+}
+
 func (s simpleKerns) Size() int { return len(s) }
 
-// assume non overlapping kerns, otherwise the return value is undefined
+// NewKerns returns the kerning values for the given pairs,
+// mapping (left, right) glyphs to a value in glyph units.
+// Combined with MergeKerns, it may be used to add or override
+// the kerning of a font.
+func NewKerns(pairs map[[2]GlyphIndex]int16) Kerns {
+	out := make(simpleKerns, len(pairs))
+	for pair, value := range pairs {
+		out[uint32(pair[0])<<16|uint32(pair[1])] = value
+	}
+	return out
+}
+
+func (s simpleKerns) Each(fn func(left, right GlyphIndex, value int16)) {
+	for key, value := range s {
+		fn(GlyphIndex(key>>16), GlyphIndex(key), value)
+	}
+}
+
+// the first Kerns defining a pair has priority
 type kernUnions []Kerns
 
+// MergeKerns returns the union of the given kerning values.
+// When a pair is defined more than once, the first value is used,
+// so that, for instance, MergeKerns(NewKerns(exceptions), fontKerns)
+// overrides some pairs of the font.
+// The returned Size is the sum of the sizes of the Kerns, and
+// thus counts overlapping pairs more than once.
+func MergeKerns(kerns ...Kerns) Kerns {
+	return kernUnions(kerns)
+}
+
 func (ks kernUnions) KernPair(left, right GlyphIndex) (int16, bool) {
 	for _, k := range ks {
 		out, has := k.KernPair(left, right)
@@ -43,6 +89,28 @@ func (ks kernUnions) KernPair(left, right GlyphIndex) (int16, bool) {
 	return 0, false
 }
 
+func (ks kernUnions) KernPairAt(left, right GlyphIndex, ppem uint16) (int16, int16, bool) {
+	for _, k := range ks {
+		value, delta, has := k.KernPairAt(left, right, ppem)
+		if has {
+			return value, delta, true
+		}
+	}
+	return 0, 0, false
+}
+
+// Each skips the pairs already defined by a previous Kerns.
+func (ks kernUnions) Each(fn func(left, right GlyphIndex, value int16)) {
+	for i, k := range ks {
+		previous := ks[:i]
+		k.Each(func(left, right GlyphIndex, value int16) {
+			if _, has := previous.KernPair(left, right); !has {
+				fn(left, right, value)
+			}
+		})
+	}
+}
+
 func (ks kernUnions) Size() int {
 	out := 0
 	for _, k := range ks {
@@ -51,53 +119,214 @@ func (ks kernUnions) Size() int {
 	return out
 }
 
+// kern subtable coverage flags, for the Microsoft and Apple headers
+const (
+	kernHorizontal       = 0x01
+	kernMinimum          = 0x02
+	kernCrossStream      = 0x04
+	kernOverride         = 0x08
+	kernAppleVertical    = 0x80
+	kernAppleCrossStream = 0x40
+	kernAppleVariation   = 0x20
+)
+
+// kernSubtable is a subtable of a 'kern' table, whose header
+// has been resolved.
+type kernSubtable struct {
+	format      uint8
+	horizontal  bool // false for vertical kerning
+	crossStream bool
+	override    bool // if false, the values are added to the previous ones
+	ignored     bool // minimum values, or variation subtables
+
+	headerSize int    // 6 for Microsoft, 8 for Apple subtables
+	data       []byte // the whole subtable, including the header
+}
+
+// KernDirection selects the subtables of a 'kern' table.
+type KernDirection uint8
+
+const (
+	// HorizontalKern adjusts the advances of horizontal text.
+	HorizontalKern KernDirection = iota
+	// VerticalKern adjusts the advances of vertical text.
+	VerticalKern
+	// HorizontalCrossStream shifts the glyphs of horizontal text
+	// perpendicularly to the line, that is vertically.
+	HorizontalCrossStream
+	// VerticalCrossStream shifts the glyphs of vertical text
+	// perpendicularly to the line, that is horizontally.
+	VerticalCrossStream
+)
+
+func (d KernDirection) horizontal() bool {
+	return d == HorizontalKern || d == HorizontalCrossStream
+}
+
+func (d KernDirection) crossStream() bool {
+	return d == HorizontalCrossStream || d == VerticalCrossStream
+}
+
+// parseKernTable returns the horizontal kerning of the table.
 func parseKernTable(input []byte) (simpleKerns, error) {
+	return parseKernTableDirection(input, HorizontalKern)
+}
+
+// parseKernTableDirection parses the Microsoft (version 0) and Apple
+// (version 1.0) 'kern' tables, and merges the values of the subtables
+// matching the direction, of format 0 (pairs), 1 (state table),
+// 2 (class array) and 3 (compact class array). The other subtables are skipped.
+func parseKernTableDirection(input []byte, direction KernDirection) (simpleKerns, error) {
+	subtables, err := parseKernSubtables(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := simpleKerns{}
+	skipped := 0
+	for _, st := range subtables {
+		if st.ignored || st.horizontal != direction.horizontal() || st.crossStream != direction.crossStream() {
+			skipped++
+			continue
+		}
+		if err := st.parseKerning(out); err != nil {
+			return nil, err
+		}
+	}
+	if len(out) == 0 && skipped != 0 {
+		return nil, errUnsupportedKernTable
+	}
+	return out, nil
+}
+
+func parseKernSubtables(input []byte) ([]kernSubtable, error) {
 	const headerSize = 4
 	if len(input) < headerSize {
 		return nil, errInvalidKernTable
 	}
-	version := be.Uint16(input[:2])
-	numTables := be.Uint16(input[2:4])
 
-	// like golang/x, we dont support apple version
-	if version != 0 {
+	var (
+		numTables int
+		apple     bool
+	)
+	switch version := be.Uint16(input); version {
+	case 0:
+		numTables = int(be.Uint16(input[2:]))
+		input = input[4:]
+	case 1:
+		// Apple header: 32-bit version (1.0) and nTables
+		if len(input) < 8 || be.Uint16(input[2:]) != 0 {
+			return nil, errInvalidKernTable
+		}
+		numTables = int(be.Uint32(input[4:]))
+		input = input[8:]
+		apple = true
+	default:
 		return nil, errUnsupportedKernTable
 	}
 
-	input = input[4:]
-	out := simpleKerns{}
-	for i := uint16(0); i < numTables; i++ {
-		nbRead, err := parseKernSubtable(input, out)
+	var out []kernSubtable
+	for i := 0; i < numTables; i++ {
+		var (
+			st  kernSubtable
+			err error
+		)
+		if apple {
+			st, err = parseAppleKernSubtable(input)
+		} else {
+			st, err = parseMicrosoftKernSubtable(input)
+		}
 		if err != nil {
 			return nil, err
 		}
-		input = input[nbRead:]
+		out = append(out, st)
+		input = input[len(st.data):]
 	}
 	return out, nil
 }
 
-// returns the number of bytes read
-func parseKernSubtable(input []byte, out simpleKerns) (int, error) {
-	const subtableHeaderSize = 6
-	if len(input) < subtableHeaderSize {
-		return 0, errInvalidKernTable
+func parseMicrosoftKernSubtable(input []byte) (kernSubtable, error) {
+	// version, length, format, coverage
+	const headerSize = 6
+	if len(input) < headerSize {
+		return kernSubtable{}, errInvalidKernTable
 	}
-	// skip version and length
+	length := int(be.Uint16(input[2:]))
 	format, coverage := input[4], input[5]
-	if coverage != 0x01 {
-		// We only support horizontal kerning.
-		return 0, errUnsupportedKernTable
+	st := kernSubtable{
+		format:      format,
+		horizontal:  coverage&kernHorizontal != 0,
+		crossStream: coverage&kernCrossStream != 0,
+		override:    coverage&kernOverride != 0,
+		ignored:     coverage&kernMinimum != 0,
+		headerSize:  headerSize,
+	}
+	if format == 0 {
+		// the 16-bit length overflows for big subtables:
+		// rely on the number of pairs instead
+		size, err := kernFormat0Size(input[headerSize:])
+		if err != nil {
+			return kernSubtable{}, err
+		}
+		length = headerSize + size
 	}
-	if format != 0 {
-		// following other implementation
-		return 0, errUnsupportedKernTable
+	if length < headerSize || len(input) < length {
+		return kernSubtable{}, errInvalidKernTable
 	}
+	st.data = input[:length]
+	return st, nil
+}
 
-	read, err := parseKernFormat0(input[6:], out)
-	return subtableHeaderSize + read, err
+func parseAppleKernSubtable(input []byte) (kernSubtable, error) {
+	// length, coverage, format, tupleIndex
+	const headerSize = 8
+	if len(input) < headerSize {
+		return kernSubtable{}, errInvalidKernTable
+	}
+	length := int(be.Uint32(input))
+	coverage, format := input[4], input[5]
+	if length < headerSize || len(input) < length {
+		return kernSubtable{}, errInvalidKernTable
+	}
+	return kernSubtable{
+		format:      format,
+		horizontal:  coverage&kernAppleVertical == 0,
+		crossStream: coverage&kernAppleCrossStream != 0,
+		override:    false, // the values of the subtables are added
+		ignored:     coverage&kernAppleVariation != 0,
+		headerSize:  headerSize,
+		data:        input[:length],
+	}, nil
+}
+
+// parseKerning adds the pairs of the subtable to out.
+// The unsupported formats are ignored.
+func (st kernSubtable) parseKerning(out simpleKerns) error {
+	add := func(left, right GlyphIndex, value int16) {
+		key := uint32(left)<<16 | uint32(right)
+		if st.override {
+			out[key] = value
+		} else {
+			out[key] += value
+		}
+	}
+	body := st.data[st.headerSize:]
+	switch st.format {
+	case 0:
+		return parseKernFormat0(body, add)
+	case 1:
+		return parseKernFormat1(body, add)
+	case 2:
+		return parseKernFormat2(st.data, st.headerSize, add)
+	case 3:
+		return parseKernFormat3(body, add)
+	}
+	return nil
 }
 
-func parseKernFormat0(input []byte, out simpleKerns) (int, error) {
+// kernFormat0Size returns the size of a format 0 subtable,
+// without its header.
+func kernFormat0Size(input []byte) (int, error) {
 	const headerSize, entrySize = 8, 6
 	if len(input) < headerSize {
 		return 0, errInvalidKernTable
@@ -110,14 +339,311 @@ func parseKernFormat0(input []byte, out simpleKerns) (int, error) {
 	if len(input) < subtableProperSize {
 		return 0, errInvalidKernTable
 	}
+	return subtableProperSize, nil
+}
 
-	// we opt for a brute force approach:
-	// we could instead store a slice of {left, right, value} to reduce
-	// memory usage
-	for i := 0; i < int(numPairs); i++ {
-		left := GlyphIndex(be.Uint16(input[entrySize*i:]))
-		right := GlyphIndex(be.Uint16(input[entrySize*i+2:]))
-		out[uint32(left)<<16|uint32(right)] = int16(be.Uint16(input[entrySize*i+4:]))
+func parseKernFormat0(input []byte, add func(left, right GlyphIndex, value int16)) error {
+	const headerSize, entrySize = 8, 6
+	if _, err := kernFormat0Size(input); err != nil {
+		return err
 	}
-	return subtableProperSize, nil
+	numPairs := int(be.Uint16(input))
+
+	entries := input[headerSize:]
+	for i := 0; i < numPairs; i++ {
+		left := GlyphIndex(be.Uint16(entries[entrySize*i:]))
+		right := GlyphIndex(be.Uint16(entries[entrySize*i+2:]))
+		add(left, right, int16(be.Uint16(entries[entrySize*i+4:])))
+	}
+	return nil
+}
+
+// AAT state table flags, for kern format 1
+const (
+	kernPush          = 0x8000
+	kernDontAdvance   = 0x4000
+	kernValueOffset   = 0x3FFF
+	kernStackSize     = 8
+	kernFirstClass    = 4 // the classes 0 to 3 are predefined
+	kernResetCross    = 0x8001
+	kernMaxStateSteps = 16
+)
+
+// parseKernFormat1 resolves the kerning of the glyph pairs, by running
+// the state machine on each pair of classes, at the start of the text.
+// The contextual kerning is thus not supported.
+func parseKernFormat1(input []byte, add func(left, right GlyphIndex, value int16)) error {
+	// state table header: nClasses, classTableOffset,
+	// stateArrayOffset, entryTableOffset, valueOffset
+	const headerSize = 10
+	if len(input) < headerSize {
+		return errInvalidKernTable
+	}
+	nClasses := int(be.Uint16(input))
+	classTable := int(be.Uint16(input[2:]))
+	stateArray := int(be.Uint16(input[4:]))
+	entryTable := int(be.Uint16(input[6:]))
+	if len(input) < classTable+4 || len(input) < stateArray+nClasses {
+		return errInvalidKernTable
+	}
+	firstGlyph := GlyphIndex(be.Uint16(input[classTable:]))
+	nGlyphs := int(be.Uint16(input[classTable+2:]))
+	if len(input) < classTable+4+nGlyphs {
+		return errInvalidKernTable
+	}
+	classes := input[classTable+4 : classTable+4+nGlyphs]
+
+	// run returns the kerning value applied to the first glyph of the sequence
+	run := func(sequence [3]int) (int16, error) {
+		var (
+			stack     []int
+			kern      int16
+			row       = stateArray
+			pos, step int
+		)
+		for pos < len(sequence) {
+			if step++; step > kernMaxStateSteps {
+				break // infinite loop
+			}
+			class := sequence[pos]
+			if len(input) < row+class+1 {
+				return 0, errInvalidKernTable
+			}
+			entry := entryTable + 4*int(input[row+class])
+			if len(input) < entry+4 {
+				return 0, errInvalidKernTable
+			}
+			newState, flags := int(be.Uint16(input[entry:])), be.Uint16(input[entry+2:])
+			if flags&kernPush != 0 && len(stack) < kernStackSize {
+				stack = append(stack, pos)
+			}
+			if valueOffset := int(flags & kernValueOffset); valueOffset != 0 {
+				for ; len(stack) != 0; valueOffset += 2 {
+					if len(input) < valueOffset+2 {
+						return 0, errInvalidKernTable
+					}
+					value := be.Uint16(input[valueOffset:])
+					glyph := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					if glyph == 0 && value != kernResetCross {
+						kern += int16(value &^ 1)
+					}
+					if value&1 != 0 { // end of list
+						break
+					}
+				}
+			}
+			row = newState
+			if flags&kernDontAdvance == 0 {
+				pos++
+			}
+		}
+		return kern, nil
+	}
+
+	glyphsByClass := make([][]GlyphIndex, nClasses)
+	for i, class := range classes {
+		if int(class) < nClasses {
+			glyphsByClass[class] = append(glyphsByClass[class], firstGlyph+GlyphIndex(i))
+		}
+	}
+	for c1 := kernFirstClass; c1 < nClasses; c1++ {
+		for c2 := kernFirstClass; c2 < nClasses; c2++ {
+			if len(glyphsByClass[c1]) == 0 || len(glyphsByClass[c2]) == 0 {
+				continue
+			}
+			value, err := run([3]int{c1, c2, 0}) // 0 is the end of text class
+			if err != nil {
+				return err
+			}
+			if value == 0 {
+				continue
+			}
+			for _, left := range glyphsByClass[c1] {
+				for _, right := range glyphsByClass[c2] {
+					add(left, right, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseKernFormat2 parses a class array subtable, whose offsets are
+// relative to the start of the subtable, including its header.
+func parseKernFormat2(input []byte, headerSize int, add func(left, right GlyphIndex, value int16)) error {
+	// rowWidth, leftClassTable, rightClassTable, array
+	if len(input) < headerSize+8 {
+		return errInvalidKernTable
+	}
+	leftTable := int(be.Uint16(input[headerSize+2:]))
+	rightTable := int(be.Uint16(input[headerSize+4:]))
+	array := int(be.Uint16(input[headerSize+6:]))
+
+	// the class values are offsets: the left ones include the
+	// offset of the array and are multiples of rowWidth,
+	// the right ones are multiples of 2
+	classGlyphs := func(offset int) (map[int][]GlyphIndex, error) {
+		if len(input) < offset+4 {
+			return nil, errInvalidKernTable
+		}
+		firstGlyph := GlyphIndex(be.Uint16(input[offset:]))
+		nGlyphs := int(be.Uint16(input[offset+2:]))
+		if len(input) < offset+4+2*nGlyphs {
+			return nil, errInvalidKernTable
+		}
+		out := map[int][]GlyphIndex{}
+		for i := 0; i < nGlyphs; i++ {
+			class := int(be.Uint16(input[offset+4+2*i:]))
+			out[class] = append(out[class], firstGlyph+GlyphIndex(i))
+		}
+		return out, nil
+	}
+	lefts, err := classGlyphs(leftTable)
+	if err != nil {
+		return err
+	}
+	rights, err := classGlyphs(rightTable)
+	if err != nil {
+		return err
+	}
+
+	for leftClass, leftGlyphs := range lefts {
+		for rightClass, rightGlyphs := range rights {
+			offset := leftClass + rightClass
+			if leftClass < array || len(input) < offset+2 {
+				continue // no kerning for this pair
+			}
+			value := int16(be.Uint16(input[offset:]))
+			if value == 0 {
+				continue
+			}
+			for _, left := range leftGlyphs {
+				for _, right := range rightGlyphs {
+					add(left, right, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func parseKernFormat3(input []byte, add func(left, right GlyphIndex, value int16)) error {
+	// glyphCount, kernValueCount, leftClassCount, rightClassCount, flags
+	const headerSize = 6
+	if len(input) < headerSize {
+		return errInvalidKernTable
+	}
+	glyphCount := int(be.Uint16(input))
+	kernValueCount := int(input[2])
+	leftClassCount, rightClassCount := int(input[3]), int(input[4])
+	size := headerSize + 2*kernValueCount + 2*glyphCount + leftClassCount*rightClassCount
+	if len(input) < size {
+		return errInvalidKernTable
+	}
+	values := input[headerSize:]
+	leftClasses := values[2*kernValueCount:]
+	rightClasses := leftClasses[glyphCount:]
+	kernIndexes := rightClasses[glyphCount:]
+
+	glyphsByClass := func(classes []byte, count int) [][]GlyphIndex {
+		out := make([][]GlyphIndex, count)
+		for gi, class := range classes[:glyphCount] {
+			if int(class) < count {
+				out[class] = append(out[class], GlyphIndex(gi))
+			}
+		}
+		return out
+	}
+	lefts := glyphsByClass(leftClasses, leftClassCount)
+	rights := glyphsByClass(rightClasses, rightClassCount)
+	for leftClass, leftGlyphs := range lefts {
+		for rightClass, rightGlyphs := range rights {
+			index := int(kernIndexes[leftClass*rightClassCount+rightClass])
+			if index >= kernValueCount {
+				return errInvalidKernTable
+			}
+			value := int16(be.Uint16(values[2*index:]))
+			if value == 0 {
+				continue
+			}
+			for _, left := range leftGlyphs {
+				for _, right := range rightGlyphs {
+					add(left, right, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// the subtable length is stored on 16 bits, which limits
+// the number of pairs to less than the 65535 allowed by nPairs
+const maxKernPairsPerSubtable = (0xFFFF - 14) / 6
+
+// NewTableKern returns a legacy 'kern' table storing the given kerning values,
+// for targets which don't support GPOS kerning.
+// The pairs are stored in format 0 subtables, splitting them if
+// there are too many pairs for one subtable.
+func NewTableKern(kerns Kerns) (Table, error) {
+	pairs, keys := resolveKernPairs(kerns)
+
+	numTables := (len(keys) + maxKernPairsPerSubtable - 1) / maxKernPairsPerSubtable
+	out := make([]byte, 4, 4+numTables*14+len(keys)*6)
+	be.PutUint16(out[2:], uint16(numTables))
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > maxKernPairsPerSubtable {
+			chunk = chunk[:maxKernPairsPerSubtable]
+		}
+		keys = keys[len(chunk):]
+		out = appendKernFormat0(out, chunk, pairs)
+	}
+
+	return &unparsedTable{baseTable(TagKern), out}, nil
+}
+
+// resolveKernPairs returns the non zero pairs of kerns,
+// and their sorted keys.
+func resolveKernPairs(kerns Kerns) (simpleKerns, []uint32) {
+	pairs := simpleKerns{}
+	kerns.Each(func(left, right GlyphIndex, value int16) {
+		if value != 0 {
+			pairs[uint32(left)<<16|uint32(right)] = value
+		}
+	})
+	keys := make([]uint32, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return pairs, keys
+}
+
+// appendKernFormat0 appends a horizontal format 0 subtable
+// with the given sorted pairs.
+func appendKernFormat0(out []byte, keys []uint32, pairs simpleKerns) []byte {
+	const headerSize, entrySize = 14, 6
+	var header [headerSize]byte
+	be.PutUint16(header[2:], uint16(headerSize+entrySize*len(keys))) // length
+	header[5] = 0x01                                                 // coverage: horizontal, format 0
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(keys) {
+		entrySelector++
+	}
+	searchRange := entrySize * (1 << entrySelector)
+	be.PutUint16(header[6:], uint16(len(keys)))
+	be.PutUint16(header[8:], uint16(searchRange))
+	be.PutUint16(header[10:], uint16(entrySelector))
+	be.PutUint16(header[12:], uint16(entrySize*len(keys)-searchRange))
+	out = append(out, header[:]...)
+
+	var entry [entrySize]byte
+	for _, key := range keys {
+		be.PutUint32(entry[:], key)
+		be.PutUint16(entry[4:], uint16(pairs[key]))
+		out = append(out, entry[:]...)
+	}
+	return out
 }
@@ -3,6 +3,7 @@ package sfnt
 import (
 	"errors"
 	"fmt"
+	"math/bits"
 	"sort"
 )
 
@@ -11,48 +12,465 @@ var (
 	errUnsupportedClassDefFormat = errors.New("unsupported class definition format")
 )
 
+var featureKern = MustNamedTag("kern")
+
+const gposPair = 2 // pair adjustment lookup type
+
+// parseKern returns the kerning of all the pair adjustment lookups.
 func (t TableLayout) parseKern() (Kerns, error) {
-	var kerns kernUnions
-
-	for _, lookup := range t.Lookups {
-		if lookup.Type == 2 {
-			for _, subtableOffset := range lookup.subtableOffsets {
-				b := lookup.data
-				if len(b) < 4+int(subtableOffset) {
-					return nil, errInvalidGPOSKern
-				}
-				b = b[subtableOffset:]
-				format, coverageOffset := be.Uint16(b), be.Uint16(b[2:])
+	return t.parseLookupsKern(t.Lookups)
+}
 
-				coverage, err := fetchCoverage(b, int(coverageOffset))
-				if err != nil {
-					return nil, err
-				}
+// parseScriptKern returns the kerning of the pair adjustment
+// lookups of the feature for the given script and language.
+func (t TableLayout) parseScriptKern(script, language, feature Tag) (Kerns, error) {
+	return t.parseLookupsKern(t.featureLookups(script, language, feature))
+}
+
+func (t TableLayout) parseLookupsKern(lookups []*Lookup) (Kerns, error) {
+	kerns, stats := parseKernStats(lookups)
+
+	if len(kerns) == 0 {
+		// no kerning information
+		if len(stats.Errors) != 0 {
+			return nil, fmt.Errorf("invalid GPOS kerning: %d lookup or subtable(s) skipped: %w",
+				len(stats.Errors), stats.Errors[0])
+		}
+		if stats.IgnoredSubtables != 0 {
+			return nil, fmt.Errorf("missing GPOS kerning information: %d unsupported pair adjustment subtable(s) ignored",
+				stats.IgnoredSubtables)
+		}
+		return nil, errors.New("missing GPOS kerning information")
+	}
+
+	return kerns, nil
+}
+
+// KernStats describes the pair adjustment subtables of a 'GPOS' table
+// ignored when building the kerning, since only the subtables adjusting
+// the horizontal space between the glyphs are supported.
+type KernStats struct {
+	Subtables        int // number of pair adjustment subtables
+	IgnoredSubtables int
+	// IgnoredPairs is the number of glyph pairs (format 1) or
+	// class pairs (format 2) of the ignored subtables.
+	IgnoredPairs int
+	// IgnoredFormats counts the ignored subtables by
+	// [valueFormat1, valueFormat2]. The subtables with an
+	// unknown format are not included.
+	IgnoredFormats map[[2]uint16]int
+	// Errors are the errors of the invalid lookups or pair adjustment
+	// subtables, which are skipped: as shaping engines do, the kerning
+	// of the valid subtables is still returned.
+	Errors []error
+}
+
+// ValueFormat flags, describing the fields of a ValueRecord
+const (
+	valueXPlacement = 1 << iota
+	valueYPlacement
+	valueXAdvance
+	valueYAdvance
+	valueXPlaDevice
+	valueYPlaDevice
+	valueXAdvDevice
+	valueYAdvDevice
+
+	valueDevices = valueXPlaDevice | valueYPlaDevice | valueXAdvDevice | valueYAdvDevice
+)
+
+// kernConvention describes how the kerning of a pair adjustment
+// subtable is expressed, as guessed from its value formats.
+type kernConvention uint8
+
+const (
+	// the advance of the first glyph (left to right text)
+	kernLTR kernConvention = iota
+	// the placement of the first glyph, without advance,
+	// or the advance of the second glyph (right to left text)
+	kernRTL
+)
+
+// kernValueFormats returns the convention of the pair adjustment
+// subtables supported as kerning, or false for unsupported value formats.
+// The supported subtables only adjust the horizontal advances and
+// placements, their device tables being used by KernPairAt:
+//   - X_ADVANCE for the first glyph, the usual convention, the
+//     placement of the first glyph and the advance of the second
+//     one being ignored,
+//   - otherwise, X_PLACEMENT for the first glyph, or X_ADVANCE for the
+//     second glyph, as used by the right to left fonts.
+func kernValueFormats(formats [2]uint16) (kernConvention, bool) {
+	const horizontal = valueXPlacement | valueXAdvance
+	format1, format2 := formats[0]&^valueDevices, formats[1]&^valueDevices
+	switch {
+	case format1&^horizontal != 0 || format2&^horizontal != 0:
+		return 0, false
+	case format1&valueXAdvance != 0:
+		// the placement of the second glyph changes the space
+		// between the glyphs, which is not supported
+		return kernLTR, format2&valueXPlacement == 0
+	case format1&valueXPlacement != 0 || format2&valueXAdvance != 0:
+		return kernRTL, true
+	default:
+		return 0, false
+	}
+}
+
+func isKernValueFormats(formats [2]uint16) bool {
+	_, ok := kernValueFormats(formats)
+	return ok
+}
+
+// value returns the change of the space between the two glyphs.
+// For right to left text, the second glyph is at the left of the first,
+// so that its advance and the placement of the first glyph widen the space.
+func (kc kernConvention) value(adjustment PairAdjustment) int16 {
+	if kc == kernRTL {
+		return adjustment[0].XPlacement + adjustment[1].XAdvance - adjustment[1].XPlacement
+	}
+	return adjustment[0].XAdvance
+}
+
+// delta returns the correction of value at the given size, in pixels.
+func (kc kernConvention) delta(adjustment PairAdjustment, ppem uint16) int16 {
+	placement1, advance1 := adjustment[0].Devices.x()
+	placement2, advance2 := adjustment[1].Devices.x()
+	if kc == kernRTL {
+		return placement1.Delta(ppem) + advance2.Delta(ppem) - placement2.Delta(ppem)
+	}
+	return advance1.Delta(ppem)
+}
+
+// variation returns the variation of value at the given
+// normalized coordinates, in glyph units.
+func (kc kernConvention) variation(adjustment PairAdjustment, store *itemVariationStore, coords []float32) float32 {
+	placement1, advance1 := adjustment[0].Devices.x()
+	placement2, advance2 := adjustment[1].Devices.x()
+	if kc == kernRTL {
+		return placement1.variation(store, coords) + advance2.variation(store, coords) -
+			placement2.variation(store, coords)
+	}
+	return advance1.variation(store, coords)
+}
+
+// ValueRecord is the positioning adjustment of a glyph, expressed
+// in glyph units, as stored in the 'GPOS' table.
+type ValueRecord struct {
+	XPlacement, YPlacement int16
+	XAdvance, YAdvance     int16
+	// Devices is nil if the record has no device table.
+	Devices *ValueDevices
+}
+
+// ValueDevices are the device tables of a ValueRecord, correcting
+// its values at some sizes, or the VariationIndex tables of variable
+// fonts. The fields are nil for the missing tables.
+type ValueDevices struct {
+	XPlacement, YPlacement *DeviceTable
+	XAdvance, YAdvance     *DeviceTable
+}
+
+// x returns the tables of the horizontal values, which are nil if vd is nil.
+func (vd *ValueDevices) x() (placement, advance *DeviceTable) {
+	if vd == nil {
+		return nil, nil
+	}
+	return vd.XPlacement, vd.XAdvance
+}
+
+// PairAdjustment is the adjustment of the first and
+// of the second glyph of a pair.
+type PairAdjustment [2]ValueRecord
+
+// PairAdjustments stores the pair adjustments of a 'GPOS' table.
+type PairAdjustments interface {
+	// PairAdjustment returns the adjustment of the given pair, if any.
+	PairAdjustment(left, right GlyphIndex) (PairAdjustment, bool)
+	// Size returns the number of pairs.
+	Size() int
+}
+
+// pairPos is a parsed pair adjustment subtable, whose Kerns
+// view depends on its value formats (see kernValueFormats).
+type pairPos interface {
+	Kerns
+	PairAdjustments
+	valueConvention() kernConvention
+	// eachAdjustment calls fn for each pair, including the
+	// pairs whose kerning value is 0, as Each does not.
+	eachAdjustment(fn func(left, right GlyphIndex, adjustment PairAdjustment))
+}
+
+// valueRecordSize returns the size of a ValueRecord, in bytes.
+func valueRecordSize(format uint16) int { return 2 * bits.OnesCount16(format) }
+
+// valueRecordParser reads the ValueRecords of a pair adjustment subtable,
+// sharing the device tables referenced by several records.
+type valueRecordParser struct {
+	subtable []byte // the device offsets are relative to the subtable
+	devices  map[uint16]*DeviceTable
+}
+
+// parse reads a ValueRecord, whose length is checked by the caller.
+// As shaping engines do, the invalid device tables are ignored.
+func (vp *valueRecordParser) parse(format uint16, buf []byte) ValueRecord {
+	var (
+		out    ValueRecord
+		fields = [4]*int16{&out.XPlacement, &out.YPlacement, &out.XAdvance, &out.YAdvance}
+	)
+	for i, field := range fields {
+		if format&(1<<i) != 0 {
+			*field = int16(be.Uint16(buf))
+			buf = buf[2:]
+		}
+	}
+	if format&valueDevices == 0 {
+		return out
+	}
 
-				switch format {
-				case 1: // Adjustments for Glyph Pairs
-					kern, err := parsePairPosFormat1(b, coverage)
+	var (
+		devices ValueDevices
+		found   bool
+		tables  = [4]**DeviceTable{&devices.XPlacement, &devices.YPlacement, &devices.XAdvance, &devices.YAdvance}
+	)
+	for i, table := range tables {
+		if format&(valueXPlaDevice<<i) == 0 {
+			continue
+		}
+		offset := be.Uint16(buf)
+		buf = buf[2:]
+		if offset == 0 {
+			continue
+		}
+		device, ok := vp.devices[offset]
+		if !ok {
+			if int(offset) < len(vp.subtable) {
+				device, _ = parseDeviceTable(vp.subtable[offset:])
+			}
+			if vp.devices == nil {
+				vp.devices = make(map[uint16]*DeviceTable)
+			}
+			vp.devices[offset] = device
+		}
+		if device != nil {
+			*table, found = device, true
+		}
+	}
+	if found {
+		out.Devices = &devices
+	}
+	return out
+}
+
+func (stats *KernStats) ignore(st *lookupSubtable) {
+	stats.IgnoredSubtables++
+	formats, ok := st.pairPosValueFormats()
+	if !ok {
+		return
+	}
+	if stats.IgnoredFormats == nil {
+		stats.IgnoredFormats = make(map[[2]uint16]int)
+	}
+	stats.IgnoredFormats[formats]++
+	stats.IgnoredPairs += st.pairPosCount()
+}
+
+// parseKernStats returns the supported kerning subtables of
+// the lookups, and statistics about the ignored ones.
+// The invalid lookups and subtables are skipped and reported in
+// the statistics.
+func parseKernStats(lookups []*Lookup) (kernUnions, KernStats) {
+	subtables, stats := parsePairPosLookups(lookups, isKernValueFormats)
+	kerns := make(kernUnions, len(subtables))
+	for i, subtable := range subtables {
+		kerns[i] = subtable
+	}
+	return kerns, stats
+}
+
+// parsePairPosLookups returns the pair adjustment subtables of the lookups
+// whose value formats are accepted, and statistics about the ignored ones.
+func parsePairPosLookups(lookups []*Lookup, accept func(formats [2]uint16) bool) ([]pairPos, KernStats) {
+	var (
+		out   []pairPos
+		stats KernStats
+	)
+
+	for i, lookup := range lookups {
+		if lookup.Type == gposPair || lookup.Type == gposExtension {
+			subtables, err := lookup.parsedSubtables()
+			if err != nil {
+				stats.Errors = append(stats.Errors, fmt.Errorf("lookup %d: %w", i, err))
+				continue
+			}
+			for j, subtable := range subtables {
+				if lookup.Type == gposExtension {
+					// large fonts wrap their subtables to use 32-bit offsets
+					var lookupType uint16
+					lookupType, subtable, err = subtable.extensionSubtable(gposExtension)
 					if err != nil {
-						return nil, err
+						stats.Errors = append(stats.Errors, fmt.Errorf("lookup %d, subtable %d: %w", i, j, err))
+						continue
 					}
-					kerns = append(kerns, kern)
-				case 2: // Class Pair Adjustment
-					kern, err := parsePairPosFormat2(b, coverage)
-					if err != nil {
-						return nil, err
+					if lookupType != gposPair {
+						continue
 					}
-					kerns = append(kerns, kern)
+				}
+				stats.Subtables++
+				if formats, ok := subtable.pairPosValueFormats(); !ok || !accept(formats) {
+					stats.ignore(subtable)
+					continue
+				}
+				pp, err := subtable.parsePairPos()
+				if err != nil {
+					stats.Errors = append(stats.Errors, fmt.Errorf("lookup %d, subtable %d: %w", i, j, err))
+					continue
+				}
+				if pp != nil {
+					out = append(out, pp)
 				}
 			}
 		}
 	}
 
-	if len(kerns) == 0 {
-		// no kerning information
-		return nil, errors.New("missing GPOS kerning information")
+	return out, stats
+}
+
+// pairPosValueFormats returns the value formats of a pair adjustment subtable,
+// or false if its format is unknown.
+func (st *lookupSubtable) pairPosValueFormats() ([2]uint16, bool) {
+	if (st.format != 1 && st.format != 2) || len(st.data) < 8 {
+		return [2]uint16{}, false
 	}
+	return [2]uint16{be.Uint16(st.data[4:]), be.Uint16(st.data[6:])}, true
+}
 
-	return kerns, nil
+// pairPosCount returns the number of glyph pairs (format 1)
+// or class pairs (format 2) of a pair adjustment subtable.
+func (st *lookupSubtable) pairPosCount() int {
+	switch st.format {
+	case 1:
+		if len(st.data) < 10 {
+			return 0
+		}
+		nPairSets := int(be.Uint16(st.data[8:]))
+		if len(st.data) < 10+2*nPairSets {
+			return 0
+		}
+		out := 0
+		for i := 0; i < nPairSets; i++ {
+			offset := int(be.Uint16(st.data[10+2*i:]))
+			if len(st.data) >= offset+2 {
+				out += int(be.Uint16(st.data[offset:]))
+			}
+		}
+		return out
+	case 2:
+		if len(st.data) < 16 {
+			return 0
+		}
+		return int(be.Uint16(st.data[12:])) * int(be.Uint16(st.data[14:]))
+	}
+	return 0
+}
+
+// GposKernStats returns statistics about the pair adjustment subtables of
+// the 'GPOS' table which are ignored by KernTable, because their format is
+// not supported or because they are invalid. It explains why a font may kern in other applications
+// but have no (or partial) kerning through this package.
+func (font *Font) GposKernStats() (KernStats, error) {
+	gpos, err := font.GposTable()
+	if err != nil {
+		return KernStats{}, err
+	}
+	_, stats := parseKernStats(gpos.Lookups)
+	return stats, nil
+}
+
+// GposFeatureKerning returns the kerning of the pair adjustment lookups
+// reachable from the given feature, for the given script and language
+// (see TableLayout.ChooseLangSys), instead of all the lookups of the
+// 'GPOS' table as KernTable does. This avoids mixing the kerning defined
+// for different scripts, such as Latin and Cyrillic.
+// The zero Tag selects the 'kern' feature, the default language system
+// of the script, or the default script (DFLT, then latn).
+func (font *Font) GposFeatureKerning(script, language, feature Tag) (Kerns, error) {
+	gpos, err := font.GposTable()
+	if err != nil {
+		return nil, err
+	}
+	if feature == (Tag{}) {
+		feature = featureKern
+	}
+	return gpos.parseScriptKern(script, language, feature)
+}
+
+// pairPosUnions merges pair adjustment subtables:
+// the first one defining a pair has priority.
+type pairPosUnions []pairPos
+
+func (ps pairPosUnions) PairAdjustment(left, right GlyphIndex) (PairAdjustment, bool) {
+	for _, pp := range ps {
+		if out, has := pp.PairAdjustment(left, right); has {
+			return out, true
+		}
+	}
+	return PairAdjustment{}, false
+}
+
+func (ps pairPosUnions) Size() int {
+	out := 0
+	for _, pp := range ps {
+		out += pp.Size()
+	}
+	return out
+}
+
+// GposPairAdjustments returns the adjustments of the pair adjustment
+// lookups of the 'GPOS' table, whatever their value formats, as
+// opposed to KernTable, which only supports the horizontal adjustments.
+// As for KernTable, the invalid subtables are skipped, and the returned
+// Size counts overlapping pairs more than once.
+func (font *Font) GposPairAdjustments() (PairAdjustments, error) {
+	gpos, err := font.GposTable()
+	if err != nil {
+		return nil, err
+	}
+	subtables, stats := parsePairPosLookups(gpos.Lookups, func([2]uint16) bool { return true })
+	if len(subtables) == 0 && len(stats.Errors) != 0 {
+		return nil, fmt.Errorf("invalid GPOS pair adjustments: %d lookup or subtable(s) skipped: %w",
+			len(stats.Errors), stats.Errors[0])
+	}
+	return pairPosUnions(subtables), nil
+}
+
+// parsePairPos decodes a Pair Adjustment Positioning subtable,
+// caching the result. It returns nil for unsupported formats.
+func (st *lookupSubtable) parsePairPos() (pairPos, error) {
+	if parsed := st.cached(); parsed != nil {
+		return parsed.(pairPos), nil
+	}
+
+	coverage, err := st.fetchCoverage(2)
+	if err != nil {
+		return nil, err
+	}
+
+	var pp pairPos
+	switch st.format {
+	case 1: // Adjustments for Glyph Pairs
+		pp, err = parsePairPosFormat1(st.data, coverage)
+	case 2: // Class Pair Adjustment
+		pp, err = parsePairPosFormat2(st.data, coverage)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st.cache(pp).(pairPos), nil
 }
 
 type coverage interface {
@@ -61,6 +479,9 @@ type coverage interface {
 	// Note: this method is injective: two distincts, covered glyphs are mapped
 	// to distincts tables
 	tableIndex(GlyphIndex) (int, bool)
+
+	// glyphSet returns the glyphs covered.
+	glyphSet() *GlyphSet
 }
 
 // if l[i] = gi then gi has coverage index of i
@@ -93,6 +514,8 @@ func (cl coverageList) tableIndex(gi GlyphIndex) (int, bool) {
 	return 0, false
 }
 
+func (cl coverageList) glyphSet() *GlyphSet { return NewGlyphSet(cl...) }
+
 // func (cl coverageList) maxIndex() int { return len(cl) - 1 }
 
 func fetchCoverageList(buf []byte) (coverageList, error) {
@@ -154,6 +577,14 @@ func (cr coverageRanges) tableIndex(gi GlyphIndex) (int, bool) {
 	return 0, false
 }
 
+func (cr coverageRanges) glyphSet() *GlyphSet {
+	var out GlyphSet
+	for _, rang := range cr {
+		out.AddRange(rang.start, rang.end)
+	}
+	return &out
+}
+
 // func (cr coverageRanges) maxIndex() int {
 // 	lastRange := cr[len(cr)-1]
 // 	return lastRange.startCoverage + int(lastRange.end-lastRange.start)
@@ -179,7 +610,6 @@ func fetchCoverageRange(buf []byte) (coverageRanges, error) {
 	return out, nil
 }
 
-// offset int
 func parsePairPosFormat1(buf []byte, coverage coverage) (pairPosKern, error) {
 	// PairPos Format 1: posFormat, coverageOffset, valueFormat1,
 	// valueFormat2, pairSetCount, []pairSetOffsets
@@ -188,44 +618,10 @@ func parsePairPosFormat1(buf []byte, coverage coverage) (pairPosKern, error) {
 		return pairPosKern{}, errInvalidGPOSKern
 	}
 	valueFormat1, valueFormat2, nPairs := be.Uint16(buf[4:]), be.Uint16(buf[6:]), int(be.Uint16(buf[8:]))
-
-	// check valueFormat1 and valueFormat2 flags
-	if valueFormat1 != 0x04 || valueFormat2 != 0x00 {
-		// we only support kerning with X_ADVANCE for first glyph
-		return pairPosKern{}, nil
-	}
-
-	// PairPos table contains an array of offsets to PairSet
-	// tables, which contains an array of PairValueRecords.
-	// Calculate length of complete PairPos table by jumping to
-	// last PairSet.
-	// We need to iterate all offsets to find the last pair as
-	// offsets are not sorted and can be repeated.
 	if len(buf) < headerSize+nPairs*2 {
 		return pairPosKern{}, errInvalidGPOSKern
 	}
-	var lastPairSetOffset int
-	for n := 0; n < nPairs; n++ {
-		pairOffset := int(be.Uint16(buf[headerSize+n*2:]))
-		if pairOffset > lastPairSetOffset {
-			lastPairSetOffset = pairOffset
-		}
-	}
-
-	if len(buf) < lastPairSetOffset+2 {
-		return pairPosKern{}, errInvalidGPOSKern
-	}
-
-	pairValueCount := int(be.Uint16(buf[lastPairSetOffset:]))
-	// Each PairSet contains the secondGlyph (u16) and one or more value records (all u16).
-	// We only support lookup tables with one value record (X_ADVANCE, see valueFormat1/2 above).
-	lastPairSetLength := 2 + pairValueCount*4
-
-	length := lastPairSetOffset + lastPairSetLength
-	if len(buf) < length {
-		return pairPosKern{}, errInvalidGPOSKern
-	}
-	return fetchPairPosGlyph(coverage, nPairs, buf)
+	return fetchPairPosGlyph(coverage, nPairs, valueFormat1, valueFormat2, buf)
 }
 
 type pairKern struct {
@@ -237,29 +633,70 @@ type pairKern struct {
 type pairPosKern struct {
 	cov  coverage
 	list [][]pairKern
+	// records stores the adjustments of the pairs, in the order of list,
+	// only when the kerning values are not enough (see needsValueRecords)
+	records    [][]PairAdjustment
+	convention kernConvention
 }
 
-func (pp pairPosKern) KernPair(a, b GlyphIndex) (int16, bool) {
-	idx, found := pp.cov.tableIndex(a)
+// find returns the position of the pair in list.
+func (pp pairPosKern) find(a, b GlyphIndex) (idx, pos int, found bool) {
+	idx, found = pp.cov.tableIndex(a)
 	if !found {
-		return 0, false
+		return 0, 0, false
 	}
 	if idx >= len(pp.list) { // coverage might be corrupted
-		return 0, false
+		return 0, 0, false
 	}
 
 	list := pp.list[idx]
-	for _, secondGlyphIndex := range list {
+	for pos, secondGlyphIndex := range list {
 		if secondGlyphIndex.right == b {
-			return secondGlyphIndex.kern, true
+			return idx, pos, true
 		}
 		if secondGlyphIndex.right > b { // list is sorted
-			return 0, false
+			return 0, 0, false
 		}
 	}
-	return 0, false
+	return 0, 0, false
+}
+
+func (pp pairPosKern) adjustment(idx, pos int) PairAdjustment {
+	if pp.records == nil {
+		return PairAdjustment{{XAdvance: pp.list[idx][pos].kern}}
+	}
+	return pp.records[idx][pos]
+}
+
+func (pp pairPosKern) PairAdjustment(a, b GlyphIndex) (PairAdjustment, bool) {
+	idx, pos, ok := pp.find(a, b)
+	if !ok {
+		return PairAdjustment{}, false
+	}
+	return pp.adjustment(idx, pos), true
 }
 
+func (pp pairPosKern) KernPair(a, b GlyphIndex) (int16, bool) {
+	idx, pos, ok := pp.find(a, b)
+	if !ok {
+		return 0, false
+	}
+	return pp.list[idx][pos].kern, true
+}
+
+func (pp pairPosKern) KernPairAt(a, b GlyphIndex, ppem uint16) (int16, int16, bool) {
+	idx, pos, ok := pp.find(a, b)
+	if !ok {
+		return 0, 0, false
+	}
+	if pp.records == nil { // no device tables
+		return pp.list[idx][pos].kern, 0, true
+	}
+	return pp.list[idx][pos].kern, pp.convention.delta(pp.records[idx][pos], ppem), true
+}
+
+func (pp pairPosKern) valueConvention() kernConvention { return pp.convention }
+
 func (pp pairPosKern) Size() int {
 	out := 0
 	for _, l := range pp.list {
@@ -268,10 +705,54 @@ func (pp pairPosKern) Size() int {
 	return out
 }
 
-func fetchPairPosGlyph(coverage coverage, num int, glyphs []byte) (pairPosKern, error) {
-	// glyphs length is checked before calling this function
+func (pp pairPosKern) Each(fn func(left, right GlyphIndex, value int16)) {
+	pp.eachPair(func(left GlyphIndex, idx, pos int) {
+		pair := pp.list[idx][pos]
+		fn(left, pair.right, pair.kern)
+	})
+}
+
+func (pp pairPosKern) eachAdjustment(fn func(left, right GlyphIndex, adjustment PairAdjustment)) {
+	pp.eachPair(func(left GlyphIndex, idx, pos int) {
+		fn(left, pp.list[idx][pos].right, pp.adjustment(idx, pos))
+	})
+}
+
+// eachPair calls fn with the position in list of each pair.
+func (pp pairPosKern) eachPair(fn func(left GlyphIndex, idx, pos int)) {
+	if pp.cov == nil {
+		return
+	}
+	pp.cov.glyphSet().ForEach(func(left GlyphIndex) {
+		idx, _ := pp.cov.tableIndex(left)
+		if idx >= len(pp.list) {
+			return
+		}
+		for pos := range pp.list[idx] {
+			fn(left, idx, pos)
+		}
+	})
+}
+
+// needsValueRecords returns true if the ValueRecords of a pair adjustment
+// subtable must be stored: the usual kerning subtables, only adjusting the
+// advance of the first glyph, are fully described by their kerning values.
+func needsValueRecords(valueFormat1, valueFormat2 uint16) bool {
+	return valueFormat1 != valueXAdvance || valueFormat2 != 0
+}
+
+func fetchPairPosGlyph(coverage coverage, num int, valueFormat1, valueFormat2 uint16, glyphs []byte) (pairPosKern, error) {
+	// the offsets length is checked before calling this function
 
+	size1 := valueRecordSize(valueFormat1)
+	recordSize := 2 + size1 + valueRecordSize(valueFormat2)
+	convention, _ := kernValueFormats([2]uint16{valueFormat1, valueFormat2})
+	vp := valueRecordParser{subtable: glyphs}
 	lists := make([][]pairKern, num)
+	var records [][]PairAdjustment
+	if needsValueRecords(valueFormat1, valueFormat2) {
+		records = make([][]PairAdjustment, num)
+	}
 	for idx := range lists {
 		offset := int(be.Uint16(glyphs[10+idx*2:]))
 		if offset+1 >= len(glyphs) {
@@ -279,20 +760,32 @@ func fetchPairPosGlyph(coverage coverage, num int, glyphs []byte) (pairPosKern,
 		}
 
 		count := int(be.Uint16(glyphs[offset:]))
-		if len(glyphs) < offset+2+4*count {
+		if len(glyphs) < offset+2+recordSize*count {
 			return pairPosKern{}, errInvalidGPOSKern
 		}
 
 		list := make([]pairKern, count)
+		if records == nil {
+			for i := range list {
+				record := glyphs[offset+2+i*recordSize:]
+				list[i] = pairKern{right: GlyphIndex(be.Uint16(record)), kern: int16(be.Uint16(record[2:]))}
+			}
+			lists[idx] = list
+			continue
+		}
+
+		adjustments := make([]PairAdjustment, count)
 		for i := range list {
-			list[i] = pairKern{
-				right: GlyphIndex(be.Uint16(glyphs[offset+2+i*4:])),
-				kern:  int16(be.Uint16(glyphs[offset+2+i*4+2:])),
+			record := glyphs[offset+2+i*recordSize:]
+			adjustments[i] = PairAdjustment{
+				vp.parse(valueFormat1, record[2:]),
+				vp.parse(valueFormat2, record[2+size1:]),
 			}
+			list[i] = pairKern{right: GlyphIndex(be.Uint16(record)), kern: convention.value(adjustments[i])}
 		}
-		lists[idx] = list
+		lists[idx], records[idx] = list, adjustments
 	}
-	return pairPosKern{cov: coverage, list: lists}, nil
+	return pairPosKern{cov: coverage, list: lists, records: records, convention: convention}, nil
 }
 
 type classKerns struct {
@@ -300,9 +793,15 @@ type classKerns struct {
 	class1, class2 class
 	numClass2      int
 	kerns          []int16 // size numClass1 * numClass2
+	// records stores the adjustments of the class pairs, in the order
+	// of kerns, only when the kerning values are not enough
+	// (see needsValueRecords)
+	records    []PairAdjustment
+	convention kernConvention
 }
 
-func (c classKerns) KernPair(left, right GlyphIndex) (int16, bool) {
+// index returns the index in kerns of the pair.
+func (c classKerns) index(left, right GlyphIndex) (int, bool) {
 	// check coverage to avoid selection of default class 0
 	_, found := c.coverage.tableIndex(left)
 	if !found {
@@ -310,11 +809,138 @@ func (c classKerns) KernPair(left, right GlyphIndex) (int16, bool) {
 	}
 	idxa := c.class1.glyphClassID(left)
 	idxb := c.class2.glyphClassID(right)
-	return c.kerns[idxb+idxa*c.numClass2], true
+	if index := idxb + idxa*c.numClass2; idxb < c.numClass2 && index < len(c.kerns) {
+		return index, true
+	}
+	return 0, false
+}
+
+func (c classKerns) adjustment(index int) PairAdjustment {
+	if c.records == nil {
+		return PairAdjustment{{XAdvance: c.kerns[index]}}
+	}
+	return c.records[index]
+}
+
+func (c classKerns) PairAdjustment(left, right GlyphIndex) (PairAdjustment, bool) {
+	index, ok := c.index(left, right)
+	if !ok {
+		return PairAdjustment{}, false
+	}
+	return c.adjustment(index), true
 }
 
+func (c classKerns) KernPair(left, right GlyphIndex) (int16, bool) {
+	index, ok := c.index(left, right)
+	if !ok {
+		return 0, false
+	}
+	return c.kerns[index], true
+}
+
+func (c classKerns) KernPairAt(left, right GlyphIndex, ppem uint16) (int16, int16, bool) {
+	index, ok := c.index(left, right)
+	if !ok {
+		return 0, 0, false
+	}
+	if c.records == nil { // no device tables
+		return c.kerns[index], 0, true
+	}
+	return c.kerns[index], c.convention.delta(c.records[index], ppem), true
+}
+
+func (c classKerns) valueConvention() kernConvention { return c.convention }
+
 func (c classKerns) Size() int { return c.class1.size() * c.class2.size() }
 
+// glyphClasses caches the class information of a glyph,
+// as used by classKerns.KernedAdvances
+type glyphClasses struct {
+	covered        bool
+	class1, class2 int
+}
+
+// KernedAdvances returns the kerning adjustments for a run of glyphs:
+// out[i] is the kern value between run[i] and run[i+1], and the last
+// entry is always 0.
+// The classes of each glyph are resolved only once per run, which is faster
+// than calling KernPair for each pair when measuring long strings.
+func (c classKerns) KernedAdvances(run []GlyphIndex) []int16 {
+	out := make([]int16, len(run))
+	if len(run) < 2 {
+		return out
+	}
+
+	cache := make(map[GlyphIndex]glyphClasses, len(run))
+	classes := make([]glyphClasses, len(run))
+	for i, gi := range run {
+		cl, ok := cache[gi]
+		if !ok {
+			_, cl.covered = c.coverage.tableIndex(gi)
+			cl.class1 = c.class1.glyphClassID(gi)
+			cl.class2 = c.class2.glyphClassID(gi)
+			cache[gi] = cl
+		}
+		classes[i] = cl
+	}
+
+	for i := 0; i < len(run)-1; i++ {
+		left, right := classes[i], classes[i+1]
+		if !left.covered {
+			continue
+		}
+		if index := right.class2 + left.class1*c.numClass2; right.class2 < c.numClass2 && index < len(c.kerns) {
+			out[i] = c.kerns[index]
+		}
+	}
+	return out
+}
+
+// Each does not visit the glyphs of the default class 0 as second glyph,
+// since they can't be enumerated.
+func (c classKerns) Each(fn func(left, right GlyphIndex, value int16)) {
+	c.eachPair(func(left, right GlyphIndex, index int) {
+		if value := c.kerns[index]; value != 0 {
+			fn(left, right, value)
+		}
+	})
+}
+
+// eachAdjustment has the same limitation as Each.
+func (c classKerns) eachAdjustment(fn func(left, right GlyphIndex, adjustment PairAdjustment)) {
+	c.eachPair(func(left, right GlyphIndex, index int) {
+		fn(left, right, c.adjustment(index))
+	})
+}
+
+// eachPair calls fn with the index in kerns of each pair,
+// and has the same limitation as Each.
+func (c classKerns) eachPair(fn func(left, right GlyphIndex, index int)) {
+	if c.coverage == nil {
+		return
+	}
+	// group the second glyphs by class
+	var rights [][]GlyphIndex
+	c.class2.forEach(func(gi GlyphIndex, class int) {
+		for class >= len(rights) {
+			rights = append(rights, nil)
+		}
+		rights[class] = append(rights[class], gi)
+	})
+	c.coverage.glyphSet().ForEach(func(left GlyphIndex) {
+		class1 := c.class1.glyphClassID(left)
+		for class2 := 1; class2 < len(rights) && class2 < c.numClass2; class2++ {
+			index := class2 + class1*c.numClass2
+			if index >= len(c.kerns) {
+				continue
+			}
+			for _, right := range rights[class2] {
+				fn(left, right, index)
+			}
+		}
+	})
+}
+
 func parsePairPosFormat2(buf []byte, coverage coverage) (classKerns, error) {
 	// PairPos Format 2:
 	// posFormat, coverageOffset, valueFormat1, valueFormat2,
@@ -326,12 +952,6 @@ func parsePairPosFormat2(buf []byte, coverage coverage) (classKerns, error) {
 	}
 
 	valueFormat1, valueFormat2 := be.Uint16(buf[4:]), be.Uint16(buf[6:])
-	// check valueFormat1 and valueFormat2 flags
-	if valueFormat1 != 0x04 || valueFormat2 != 0x00 {
-		// we only support kerning with X_ADVANCE for first glyph
-		return classKerns{}, nil
-	}
-
 	cdef1Offset := int(be.Uint16(buf[8:]))
 	cdef2Offset := int(be.Uint16(buf[10:]))
 	numClass1 := int(be.Uint16(buf[12:]))
@@ -347,10 +967,13 @@ func parsePairPosFormat2(buf []byte, coverage coverage) (classKerns, error) {
 	}
 
 	return fetchPairPosClass(
-		buf[headerSize:],
+		buf,
+		headerSize,
 		coverage,
 		numClass1,
 		numClass2,
+		valueFormat1,
+		valueFormat2,
 		cdef1,
 		cdef2,
 	)
@@ -377,6 +1000,9 @@ type class interface {
 	// (default class) for glyphs not covered by this lookup.
 	glyphClassID(GlyphIndex) int
 	size() int // return the number of glyh
+	// forEach calls fn for each glyph explicitly
+	// assigned to a class.
+	forEach(fn func(gi GlyphIndex, class int))
 }
 
 type classFormat1 struct {
@@ -393,6 +1019,12 @@ func (c classFormat1) glyphClassID(gi GlyphIndex) int {
 
 func (c classFormat1) size() int { return len(c.targetClassIDs) }
 
+func (c classFormat1) forEach(fn func(gi GlyphIndex, class int)) {
+	for i, class := range c.targetClassIDs {
+		fn(c.startGlyph+GlyphIndex(i), class)
+	}
+}
+
 // ClassDefFormat 1: classFormat, startGlyphID, glyphCount, []classValueArray
 func fetchClassLookupFormat1(buf []byte) (classFormat1, error) {
 	const headerSize = 6 // including classFormat
@@ -460,6 +1092,14 @@ func (c class2) size() int {
 	return out
 }
 
+func (c class2) forEach(fn func(gi GlyphIndex, class int)) {
+	for _, rang := range c {
+		for gi := int(rang.start); gi <= int(rang.end); gi++ {
+			fn(GlyphIndex(gi), rang.targetClassID)
+		}
+	}
+}
+
 // ClassDefFormat 2: classFormat, classRangeCount, []classRangeRecords
 func fetchClassLookupFormat2(buf []byte) (class2, error) {
 	const headerSize = 4 // including classFormat
@@ -481,24 +1121,42 @@ func fetchClassLookupFormat2(buf []byte) (class2, error) {
 	return out, nil
 }
 
-func fetchPairPosClass(buf []byte, cov coverage, num1, num2 int, cdef1, cdef2 class) (classKerns, error) {
-	if len(buf) < num1*num2*2 {
+// fetchPairPosClass reads the class records of the
+// subtable buf, starting at offset.
+func fetchPairPosClass(buf []byte, offset int, cov coverage, num1, num2 int, valueFormat1, valueFormat2 uint16, cdef1, cdef2 class) (classKerns, error) {
+	size1 := valueRecordSize(valueFormat1)
+	recordSize := size1 + valueRecordSize(valueFormat2)
+	if len(buf) < offset+num1*num2*recordSize {
 		return classKerns{}, errInvalidGPOSKern
 	}
 
+	convention, _ := kernValueFormats([2]uint16{valueFormat1, valueFormat2})
 	kerns := make([]int16, num1*num2)
-	for i := 0; i < num1; i++ {
-		for j := 0; j < num2; j++ {
-			index := j + i*num2
-			kerns[index] = int16(be.Uint16(buf[index*2:]))
+	var records []PairAdjustment
+	if needsValueRecords(valueFormat1, valueFormat2) {
+		vp := valueRecordParser{subtable: buf}
+		records = make([]PairAdjustment, len(kerns))
+		for index := range records {
+			record := buf[offset+index*recordSize:]
+			records[index] = PairAdjustment{
+				vp.parse(valueFormat1, record),
+				vp.parse(valueFormat2, record[size1:]),
+			}
+			kerns[index] = convention.value(records[index])
+		}
+	} else {
+		for index := range kerns {
+			kerns[index] = int16(be.Uint16(buf[offset+index*recordSize:]))
 		}
 	}
 
 	return classKerns{
-		coverage:  cov,
-		class1:    cdef1,
-		class2:    cdef2,
-		kerns:     kerns,
-		numClass2: num2,
+		coverage:   cov,
+		class1:     cdef1,
+		class2:     cdef2,
+		kerns:      kerns,
+		records:    records,
+		numClass2:  num2,
+		convention: convention,
 	}, nil
 }
@@ -1,8 +1,10 @@
 package sfnt
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -46,3 +48,768 @@ func TestKern(t *testing.T) {
 		f.Close()
 	}
 }
+
+func TestKernedAdvances(t *testing.T) {
+	f, err := os.Open("testdata/Castoro-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpos, err := font.GposTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kerns, err := gpos.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := make([]GlyphIndex, 500)
+	for i := range run {
+		run[i] = GlyphIndex(i % 250)
+	}
+	for _, k := range kerns.(kernUnions) {
+		ck, ok := k.(classKerns)
+		if !ok {
+			continue
+		}
+		advances := ck.KernedAdvances(run)
+		if len(advances) != len(run) {
+			t.Fatalf("expected %d advances, got %d", len(run), len(advances))
+		}
+		for i := 0; i < len(run)-1; i++ {
+			exp, _ := ck.KernPair(run[i], run[i+1])
+			if advances[i] != exp {
+				t.Errorf("pair (%d, %d): expected %d, got %d", run[i], run[i+1], exp, advances[i])
+			}
+		}
+	}
+
+	// glyph 4 has an invalid class 2 in the second class definition
+	pairPos := []byte{
+		0, 2, // posFormat
+		0, 24, // coverageOffset
+		0, 4, 0, 0, // valueFormat1: X_ADVANCE, valueFormat2
+		0, 32, 0, 42, // classDef1Offset, classDef2Offset
+		0, 2, 0, 2, // class1Count, class2Count
+		0, 0, 0xFF, 0xF6, // class 0: 0, -10
+		0xFF, 0xEC, 0xFF, 0xE2, // class 1: -20, -30
+		0, 1, 0, 1, 0, 1, 0, 2, // coverage format 1: glyphs 1 and 2
+		0, 1, 0, 1, 0, 2, 0, 0, 0, 1, // classDef1 format 1: glyphs 1 and 2
+		0, 1, 0, 3, 0, 2, 0, 1, 0, 2, // classDef2 format 1: glyphs 3 and 4
+	}
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtableOffsets: []uint16{0}, data: pairPos},
+	}}
+	kerns, err = layout.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ck := kerns.(kernUnions)[0].(classKerns)
+	run = []GlyphIndex{1, 3, 2, 4, 1, 4}
+	expected := []int16{-10, 0, 0, 0, 0, 0}
+	if got := ck.KernedAdvances(run); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if _, ok := ck.KernPair(1, 4); ok {
+		t.Error("unexpected kerning for an invalid class")
+	}
+}
+
+func TestNewTableKern(t *testing.T) {
+	for _, file := range []string{
+		"testdata/Castoro-Regular.ttf",
+		"testdata/FreeSerif.ttf",
+	} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		font, err := Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kerns, err := font.KernTable(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		table, err := NewTableKern(kerns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := parseKernTable(table.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if numTables := be.Uint16(table.Bytes()[2:]); len(parsed) > maxKernPairsPerSubtable && numTables < 2 {
+			t.Errorf("expected several subtables for %d pairs", len(parsed))
+		}
+		for key, value := range parsed {
+			exp, _ := kerns.KernPair(GlyphIndex(key>>16), GlyphIndex(key))
+			if exp != value {
+				t.Errorf("%s: pair %d: expected %d, got %d", file, key, exp, value)
+			}
+		}
+		fmt.Println("	exported kerns:", len(parsed))
+
+		f.Close()
+	}
+}
+
+func TestNewKerns(t *testing.T) {
+	font := loadTestFont(t, "Castoro-Regular.ttf")
+	fontKerns, err := font.KernTable(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// find an existing pair
+	var existing [2]GlyphIndex
+	fontKerns.Each(func(left, right GlyphIndex, value int16) {
+		if value != 0 {
+			existing = [2]GlyphIndex{left, right}
+		}
+	})
+
+	exceptions := NewKerns(map[[2]GlyphIndex]int16{
+		existing: 1234,
+		{1, 2}:   -50,
+	})
+	if exceptions.Size() != 2 {
+		t.Errorf("expected 2 pairs, got %d", exceptions.Size())
+	}
+	kerns := MergeKerns(exceptions, fontKerns)
+	for _, pair := range [][2]GlyphIndex{existing, {1, 2}} {
+		exp, _ := exceptions.KernPair(pair[0], pair[1])
+		if got, ok := kerns.KernPair(pair[0], pair[1]); !ok || got != exp {
+			t.Errorf("pair %v: expected %d, got %d", pair, exp, got)
+		}
+	}
+
+	table, err := NewTableKern(kerns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseKernTable(table.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := parsed.KernPair(existing[0], existing[1]); got != 1234 {
+		t.Errorf("expected overridden value 1234, got %d", got)
+	}
+}
+
+func TestKernsEach(t *testing.T) {
+	font := loadTestFont(t, "Castoro-Regular.ttf")
+	fontKerns, err := font.KernTable(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var existing [2]GlyphIndex
+	fontKerns.Each(func(left, right GlyphIndex, value int16) {
+		if value != 0 {
+			existing = [2]GlyphIndex{left, right}
+		}
+	})
+
+	kerns := MergeKerns(NewKerns(map[[2]GlyphIndex]int16{existing: 1234}), fontKerns)
+	visited := map[[2]GlyphIndex]bool{}
+	kerns.Each(func(left, right GlyphIndex, value int16) {
+		pair := [2]GlyphIndex{left, right}
+		if visited[pair] {
+			t.Fatalf("pair %v visited twice", pair)
+		}
+		visited[pair] = true
+		if expected, _ := kerns.KernPair(left, right); value != expected {
+			t.Errorf("pair %v: expected %d, got %d", pair, expected, value)
+		}
+	})
+	if !visited[existing] {
+		t.Errorf("pair %v not visited", existing)
+	}
+}
+
+func TestGposKernStats(t *testing.T) {
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 0, // coverageOffset (not used)
+		0, 0x0C, 0, 0, // valueFormat1: X_ADVANCE | Y_ADVANCE, valueFormat2
+		0, 1, // pairSetCount
+		0, 12, // pairSetOffsets
+		0, 2, // pairValueCount
+		0, 1, 0xFF, 0xF6, 0xFF, 0xF6,
+		0, 2, 0xFF, 0xF6, 0xFF, 0xF6,
+	}
+	layout := TableLayout{Lookups: []*Lookup{{
+		Type: 2,
+		subtables: []*lookupSubtable{
+			{format: 1, data: pairPos},
+			{format: 3, data: []byte{0, 3}}, // unknown format
+		},
+	}}}
+
+	_, stats := parseKernStats(layout.Lookups)
+	expected := KernStats{
+		Subtables:        2,
+		IgnoredSubtables: 2,
+		IgnoredPairs:     2,
+		IgnoredFormats:   map[[2]uint16]int{{0x0C, 0}: 1},
+	}
+	if fmt.Sprint(stats) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, stats)
+	}
+	if _, err := layout.parseKern(); err == nil {
+		t.Error("expected an error for unsupported kerning")
+	}
+
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err = font.GposKernStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Subtables != 2 || stats.IgnoredSubtables != 0 {
+		t.Errorf("unexpected stats %v", stats)
+	}
+}
+
+func TestGposKernPartial(t *testing.T) {
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 4, 0, 0, // valueFormat1: X_ADVANCE, valueFormat2
+		0, 1, // pairSetCount
+		0, 20, // pairSetOffsets
+		0, 0, // padding
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, // pairValueCount
+		0, 2, 0xFF, 0xF6, // glyph 2: -10
+	}
+	broken := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 4, 0, 0, // valueFormat1: X_ADVANCE, valueFormat2
+		0, 3, // pairSetCount, out of bounds
+	}
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtables: []*lookupSubtable{{format: 1, data: broken}}},
+		{Type: 2, subtableOffsets: []uint16{100}}, // invalid offset
+		{Type: 2, subtables: []*lookupSubtable{{format: 1, data: pairPos}}},
+	}}
+
+	kerns, err := layout.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, ok := kerns.KernPair(1, 2); !ok || k != -10 {
+		t.Errorf("expected kerning -10, got %d %v", k, ok)
+	}
+	_, stats := parseKernStats(layout.Lookups)
+	if len(stats.Errors) != 2 || stats.Subtables != 2 {
+		t.Errorf("unexpected stats %v", stats)
+	}
+
+	// without any valid subtable, the error is returned
+	layout.Lookups = layout.Lookups[:2]
+	if _, err := layout.parseKern(); !errors.Is(err, errInvalidGPOSKern) {
+		t.Errorf("expected an invalid kerning error, got %v", err)
+	}
+}
+
+func TestKernTableForScript(t *testing.T) {
+	f, err := os.Open("testdata/FreeSerif.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpos, err := font.GposTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the kern feature uses lookup 15 for latn and DFLT,
+	// 16 for cyrl and 10 for grek
+	for _, test := range []struct {
+		script string
+		lookup int
+	}{
+		{"latn", 15},
+		{"DFLT", 15},
+		{"xxxx", 15}, // not in the font
+		{"cyrl", 16},
+		{"grek", 10},
+	} {
+		kerns, err := font.KernTableForScript(MustNamedTag(test.script), Tag{}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := gpos.parseLookupsKern(gpos.Lookups[test.lookup : test.lookup+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kerns.Size() != expected.Size() {
+			t.Errorf("script %s: expected %d pairs, got %d", test.script, expected.Size(), kerns.Size())
+		}
+	}
+
+	latin, _ := font.KernTableForScript(MustNamedTag("latn"), Tag{}, false)
+	cyrillic, _ := font.KernTableForScript(MustNamedTag("cyrl"), MustNamedTag("SRB "), false)
+	if latin.Size() == cyrillic.Size() {
+		t.Error("expected different kerning for latn and cyrl")
+	}
+
+	// the zero Tags select the 'kern' feature of the default script
+	kerns, err := font.GposFeatureKerning(Tag{}, Tag{}, Tag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kerns.Size() != latin.Size() {
+		t.Errorf("expected %d pairs, got %d", latin.Size(), kerns.Size())
+	}
+	if _, err := font.GposFeatureKerning(MustNamedTag("latn"), Tag{}, MustNamedTag("liga")); err == nil {
+		t.Error("expected an error for a feature without pair adjustments")
+	}
+}
+
+// appleKernSubtable wraps body with an Apple subtable header.
+func appleKernSubtable(coverage, format byte, body []byte) []byte {
+	length := 8 + len(body)
+	header := []byte{byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length), coverage, format, 0, 0}
+	return append(header, body...)
+}
+
+func TestKernAppleFormats(t *testing.T) {
+	format1 := []byte{
+		0, 6, 0, 10, 0, 16, 0, 28, 0, 40, // header
+		0, 10, 0, 2, 4, 5, // class table: glyph 10 in class 4, 11 in class 5
+		0, 0, 0, 0, 1, 0, // state 0
+		0, 0, 0, 0, 1, 2, // state 1, after a glyph of class 4
+		0, 16, 0, 0, // entry 0: state 0
+		0, 22, 0x80, 0, // entry 1: push, state 1
+		0, 16, 0, 40, // entry 2: apply the values at 40, state 0
+		0xFF, 0xCF, // -50, end of list
+	}
+	format2 := []byte{
+		0, 4, 0, 16, 0, 24, 0, 32, // rowWidth, left, right and array offsets
+		0, 10, 0, 2, 0, 32, 0, 36, // left classes, for glyphs 10 and 11
+		0, 20, 0, 2, 0, 0, 0, 2, // right classes, for glyphs 20 and 21
+		0, 0, 0xFF, 0xF6, // 0, -10
+		0xFF, 0xEC, 0xFF, 0xE2, // -20, -30
+	}
+	format3 := []byte{
+		0, 3, 2, 2, 2, 0, // glyphCount, kernValueCount, leftClassCount, rightClassCount, flags
+		0, 0, 0xFF, 0xD8, // kern values: 0, -40
+		0, 1, 0, // left classes
+		0, 0, 1, // right classes
+		0, 0, 0, 1, // kern indexes
+	}
+	vertical := []byte{0, 1, 0, 6, 0, 0, 0, 0, 0, 1, 0, 2, 0, 99}
+
+	table := []byte{0, 1, 0, 0, 0, 0, 0, 4}
+	table = append(table, appleKernSubtable(0, 1, format1)...)
+	table = append(table, appleKernSubtable(0, 2, format2)...)
+	table = append(table, appleKernSubtable(0, 3, format3)...)
+	table = append(table, appleKernSubtable(kernAppleVertical, 0, vertical)...)
+
+	kerns, err := parseKernTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := simpleKerns{
+		10<<16 | 11: -50,
+		10<<16 | 21: -10, 11<<16 | 20: -20, 11<<16 | 21: -30,
+		1<<16 | 2: -40,
+	}
+	if !reflect.DeepEqual(kerns, expected) {
+		t.Errorf("expected %v, got %v", expected, kerns)
+	}
+
+	onlyVertical := append([]byte{0, 1, 0, 0, 0, 0, 0, 1}, appleKernSubtable(kernAppleVertical, 0, vertical)...)
+	if _, err := parseKernTable(onlyVertical); err != errUnsupportedKernTable {
+		t.Errorf("expected errUnsupportedKernTable, got %v", err)
+	}
+}
+
+func TestKernOverride(t *testing.T) {
+	pairs := simpleKerns{1<<16 | 2: 10}
+	table := []byte{0, 0, 0, 2}
+	table = appendKernFormat0(table, []uint32{1<<16 | 2}, pairs)
+	table = appendKernFormat0(table, []uint32{1<<16 | 2}, pairs)
+	kerns, err := parseKernTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := kerns.KernPair(1, 2); value != 20 {
+		t.Errorf("expected the values to be added, got %d", value)
+	}
+
+	table[4+20+5] |= kernOverride // coverage of the second subtable
+	kerns, err = parseKernTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := kerns.KernPair(1, 2); value != 10 {
+		t.Errorf("expected the value to be overridden, got %d", value)
+	}
+}
+
+func TestKernDirection(t *testing.T) {
+	pair := []byte{0, 1, 0, 6, 0, 0, 0, 0, 0, 1, 0, 2} // format 0 with one pair (1, 2)
+	table := []byte{0, 1, 0, 0, 0, 0, 0, 3}
+	table = append(table, appleKernSubtable(0, 0, append(pair, 0, 10))...)
+	table = append(table, appleKernSubtable(kernAppleVertical, 0, append(pair, 0, 20))...)
+	table = append(table, appleKernSubtable(kernAppleCrossStream, 0, append(pair, 0, 30))...)
+
+	for direction, expected := range map[KernDirection]int16{
+		HorizontalKern:        10,
+		VerticalKern:          20,
+		HorizontalCrossStream: 30,
+	} {
+		kerns, err := parseKernTableDirection(table, direction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, ok := kerns.KernPair(1, 2); !ok || value != expected || kerns.Size() != 1 {
+			t.Errorf("direction %d: expected %d, got %d", direction, expected, value)
+		}
+	}
+	if _, err := parseKernTableDirection(table, VerticalCrossStream); err != errUnsupportedKernTable {
+		t.Errorf("expected errUnsupportedKernTable, got %v", err)
+	}
+
+	font := loadTestFont(t, "FreeSerif.ttf")
+	if _, err := font.KernTableDirection(HorizontalKern); err != nil {
+		t.Error(err)
+	}
+	if _, err := font.KernTableDirection(VerticalKern); err == nil {
+		t.Error("expected an error for a font without vertical kerning")
+	}
+}
+
+func TestGposPairAdjustments(t *testing.T) {
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 0x45, 0, 0x02, // valueFormat1: X_PLACEMENT | X_ADVANCE | X_ADV_DEVICE, valueFormat2: Y_PLACEMENT
+		0, 1, // pairSetCount
+		0, 20, // pairSetOffsets
+		0, 0, // padding
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, // pairValueCount
+		0, 2, 0, 5, 0xFF, 0xF6, 0, 0, 0, 7, // glyph 2: 5, -10, no device, 7
+	}
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtables: []*lookupSubtable{{format: 1, data: pairPos}}},
+	}}
+	subtables, stats := parsePairPosLookups(layout.Lookups, func([2]uint16) bool { return true })
+	if len(subtables) != 1 || len(stats.Errors) != 0 {
+		t.Fatalf("unexpected subtables %v and stats %v", subtables, stats)
+	}
+	expected := PairAdjustment{{XPlacement: 5, XAdvance: -10}, {YPlacement: 7}}
+	if got, ok := subtables[0].PairAdjustment(1, 2); !ok || got != expected {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if _, ok := subtables[0].PairAdjustment(1, 3); ok {
+		t.Error("unexpected adjustment for pair (1, 3)")
+	}
+	if pp := subtables[0].(pairPosKern); pp.records == nil {
+		t.Error("the value records should be stored")
+	}
+	// the placement is not supported as kerning
+	if _, err := layout.parseKern(); err == nil {
+		t.Error("expected an error for unsupported kerning")
+	}
+
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	adjustments, err := font.GposPairAdjustments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kerns, err := font.KernTable(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adjustments.Size() != kerns.Size() {
+		t.Errorf("expected %d pairs, got %d", kerns.Size(), adjustments.Size())
+	}
+	kerns.Each(func(left, right GlyphIndex, _ int16) {
+		value, _ := kerns.KernPair(left, right)
+		if got, _ := adjustments.PairAdjustment(left, right); got[0].XAdvance != value {
+			t.Errorf("pair (%d, %d): expected %d, got %v", left, right, value, got)
+		}
+	})
+}
+
+func TestGposKernRTL(t *testing.T) {
+	pairPos := func(valueFormat1, valueFormat2 byte, values ...byte) []byte {
+		out := []byte{
+			0, 1, // posFormat
+			0, 14, // coverageOffset
+			0, valueFormat1, 0, valueFormat2,
+			0, 1, // pairSetCount
+			0, 20, // pairSetOffsets
+			0, 0, // padding
+			0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+			0, 1, // pairValueCount
+			0, 2, // second glyph
+		}
+		return append(out, values...)
+	}
+	for _, test := range []struct {
+		subtable []byte
+		expected int16
+	}{
+		{pairPos(0x05, 0, 0xFF, 0xF6, 0xFF, 0xF6), -10},    // X_PLACEMENT and X_ADVANCE on the first glyph
+		{pairPos(0x05, 0, 0, 0, 0xFF, 0xCE), -50},          // X_ADVANCE only used by the first glyph
+		{pairPos(0x04, 0x04, 0xFF, 0xEC, 0, 7), -20},       // X_ADVANCE on both glyphs
+		{pairPos(0x01, 0, 0xFF, 0xF6), -10},                // X_PLACEMENT on the first glyph
+		{pairPos(0, 0x04, 0xFF, 0xEC), -20},                // X_ADVANCE on the second glyph
+		{pairPos(0x04, 0x01, 0xFF, 0xEC, 0, 5), 0},         // X_PLACEMENT on the second glyph: unsupported
+		{pairPos(0x44, 0, 0xFF, 0xE2, 0, 0), -30},          // X_ADVANCE with a device table
+		{pairPos(0x01, 0x05, 0, 5, 0, 2, 0xFF, 0xE2), -27}, // the placements and the advance of the second glyph
+	} {
+		layout := TableLayout{Lookups: []*Lookup{
+			{Type: 2, subtables: []*lookupSubtable{{format: 1, data: test.subtable}}},
+		}}
+		kerns, err := layout.parseKern()
+		if test.expected == 0 {
+			if err == nil {
+				t.Errorf("expected an error for unsupported formats, got %v", kerns)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := kerns.KernPair(1, 2); !ok || got != test.expected {
+			t.Errorf("expected %d, got %d", test.expected, got)
+		}
+		pairs := 0
+		kerns.Each(func(left, right GlyphIndex, value int16) {
+			if left != 1 || right != 2 || value != test.expected {
+				t.Errorf("unexpected pair (%d, %d): %d", left, right, value)
+			}
+			pairs++
+		})
+		if pairs != 1 {
+			t.Errorf("expected 1 pair, got %d", pairs)
+		}
+	}
+}
+
+func TestGposKernDevice(t *testing.T) {
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 0x44, 0, 0, // valueFormat1: X_ADVANCE | X_ADV_DEVICE, valueFormat2
+		0, 1, // pairSetCount
+		0, 20, // pairSetOffsets
+		0, 0, // padding
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, // pairValueCount
+		0, 2, 0xFF, 0xE2, 0, 28, // glyph 2: -30, device table
+		0, 10, 0, 13, 0, 1, 0x72, 0, // deltas 1, -1, 0, -2 for sizes 10 to 13
+	}
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtables: []*lookupSubtable{{format: 1, data: pairPos}}},
+	}}
+	kerns, err := layout.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		ppem  uint16
+		delta int16
+	}{
+		{9, 0},
+		{10, 1},
+		{11, -1},
+		{13, -2},
+		{14, 0},
+	} {
+		value, delta, ok := kerns.KernPairAt(1, 2, test.ppem)
+		if !ok || value != -30 || delta != test.delta {
+			t.Errorf("ppem %d: expected -30 and %d, got %d and %d", test.ppem, test.delta, value, delta)
+		}
+	}
+	if _, _, ok := kerns.KernPairAt(1, 3, 10); ok {
+		t.Error("unexpected kerning for pair (1, 3)")
+	}
+
+	merged := MergeKerns(NewKerns(map[[2]GlyphIndex]int16{{1, 3}: 5}), kerns)
+	if value, delta, ok := merged.KernPairAt(1, 2, 10); !ok || value != -30 || delta != 1 {
+		t.Errorf("expected -30 and 1, got %d and %d", value, delta)
+	}
+}
+
+func TestGposKernExtension(t *testing.T) {
+	extension := func(lookupType byte, subtable []byte) []byte {
+		out := []byte{
+			0, 1, // posFormat
+			0, lookupType, // extensionLookupType
+			0, 0, 0, 8, // extensionOffset
+		}
+		return append(out, subtable...)
+	}
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 4, 0, 0, // valueFormat1: X_ADVANCE, valueFormat2
+		0, 1, // pairSetCount
+		0, 20, // pairSetOffsets
+		0, 0, // padding
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, // pairValueCount
+		0, 2, 0xFF, 0xF6, // glyph 2: -10
+	}
+	single := []byte{0, 1, 0, 6, 0, 4, 0, 1, 0, 1, 0, 1} // single adjustment, ignored
+
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: gposExtension, subtables: []*lookupSubtable{
+			{format: 1, data: extension(1, single)},
+			{format: 1, data: extension(2, pairPos)},
+		}},
+	}}
+	kerns, stats := parseKernStats(layout.Lookups)
+	if len(stats.Errors) != 0 || stats.Subtables != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	if got, ok := kerns.KernPair(1, 2); !ok || got != -10 {
+		t.Errorf("expected -10, got %d", got)
+	}
+
+	layout.Lookups = append(layout.Lookups, &Lookup{Type: gposExtension, subtables: []*lookupSubtable{
+		{format: 1, data: extension(2, nil)[:6]}, // truncated
+	}})
+	if _, stats := parseKernStats(layout.Lookups); len(stats.Errors) != 1 {
+		t.Errorf("expected an error for an invalid extension, got %v", stats)
+	}
+}
+
+func TestGposKernVariations(t *testing.T) {
+	pairPos := []byte{
+		0, 1, // posFormat
+		0, 14, // coverageOffset
+		0, 0x44, 0, 0, // valueFormat1: X_ADVANCE | X_ADV_DEVICE, valueFormat2
+		0, 1, // pairSetCount
+		0, 20, // pairSetOffsets
+		0, 0, // padding
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, // pairValueCount
+		0, 2, 0xFF, 0xE2, 0, 28, // glyph 2: -30, VariationIndex table
+		0, 0, 0, 1, 0x80, 0, // outer 0, inner 1
+	}
+	gdef := []byte{
+		0, 1, 0, 3, // version
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // class definitions, attachment points and mark sets
+		0, 0, 0, 18, // itemVarStoreOffset
+		// item variation store
+		0, 1, // format
+		0, 0, 0, 12, // variationRegionListOffset
+		0, 1, // itemVariationDataCount
+		0, 0, 0, 22, // itemVariationDataOffsets
+		// region list: one axis, one region [0, 1, 1]
+		0, 1, 0, 1, 0, 0, 0x40, 0, 0x40, 0,
+		// item variation data: two items, one region
+		0, 2, 0, 0, 0, 1, 0, 0, 10, 0xEC,
+	}
+	font := New(TypeTrueType)
+	font.AddTable(TagGDEF, NewTable(TagGDEF, gdef))
+	store, err := font.gdefVariationStore()
+	if err != nil || store == nil {
+		t.Fatal(store, err)
+	}
+
+	layout := TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtables: []*lookupSubtable{{format: 1, data: pairPos}}},
+	}}
+	kerns, err := layout.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		coords   []float32
+		expected int16
+	}{
+		{nil, -30},
+		{[]float32{-1}, -30},
+		{[]float32{0.5}, -40},
+		{[]float32{1}, -50},
+	} {
+		varied := varyKerns(kerns.(kernUnions), store, test.coords)
+		if got, ok := varied.KernPair(1, 2); !ok || got != test.expected {
+			t.Errorf("at %v: expected %d, got %d", test.coords, test.expected, got)
+		}
+		varied.Each(func(left, right GlyphIndex, value int16) {
+			if value != test.expected {
+				t.Errorf("at %v: expected %d, got %d", test.coords, test.expected, value)
+			}
+		})
+	}
+	if got, _ := kerns.KernPair(1, 2); got != -30 {
+		t.Errorf("expected -30 for the default instance, got %d", got)
+	}
+
+	// a class pair only kerned by its variations
+	classPairPos := []byte{
+		0, 2, // posFormat
+		0, 24, // coverageOffset
+		0, 0x44, 0, 0, // valueFormat1: X_ADVANCE | X_ADV_DEVICE, valueFormat2
+		0, 30, 0, 38, // classDef1Offset, classDef2Offset
+		0, 1, 0, 2, // class1Count, class2Count
+		0, 0, 0, 0, // class 0: no kerning
+		0, 0, 0, 46, // class 1: 0, VariationIndex table
+		0, 1, 0, 1, 0, 1, // coverage format 1: glyph 1
+		0, 1, 0, 1, 0, 1, 0, 0, // classDef1 format 1: glyph 1
+		0, 1, 0, 2, 0, 1, 0, 1, // classDef2 format 1: glyph 2
+		0, 0, 0, 1, 0x80, 0, // outer 0, inner 1
+	}
+	layout = TableLayout{Lookups: []*Lookup{
+		{Type: 2, subtables: []*lookupSubtable{{format: 2, data: classPairPos}}},
+	}}
+	kerns, err = layout.parseKern()
+	if err != nil {
+		t.Fatal(err)
+	}
+	varied := varyKerns(kerns.(kernUnions), store, []float32{1})
+	if got, ok := varied.KernPair(1, 2); !ok || got != -20 {
+		t.Errorf("expected -20, got %d", got)
+	}
+	var pairs [][3]int
+	varied.Each(func(left, right GlyphIndex, value int16) {
+		pairs = append(pairs, [3]int{int(left), int(right), int(value)})
+	})
+	if expected := [][3]int{{1, 2, -20}}; !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("expected pairs %v, got %v", expected, pairs)
+	}
+	varyKerns(kerns.(kernUnions), store, nil).Each(func(left, right GlyphIndex, value int16) {
+		t.Errorf("unexpected pair (%d, %d): %d for the default instance", left, right, value)
+	})
+
+	// the older versions have no variation store
+	gdef[3] = 2
+	font.AddTable(TagGDEF, NewTable(TagGDEF, gdef))
+	if store, err := font.gdefVariationStore(); store != nil || err != nil {
+		t.Errorf("unexpected store %v and error %v", store, err)
+	}
+	if _, err := font.VariedKernTable([]float32{1}); err == nil {
+		t.Error("expected an error for a font without GPOS table")
+	}
+}
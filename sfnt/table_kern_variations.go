@@ -0,0 +1,113 @@
+package sfnt
+
+import (
+	"errors"
+	"math"
+)
+
+var errInvalidGDEFTable = errors.New("invalid GDEF table")
+
+// VariedKernTable returns the kerning of the 'GPOS' table, as KernTable
+// does, at the given normalized coordinates (see NormalizeCoordinates)
+// of a variable font: the values referencing a VariationIndex table are
+// adjusted by the deltas of the item variation store of the 'GDEF' table,
+// and rounded to glyph units.
+// If coords is empty, or if the font has no variation store, the kerning
+// of the default instance is returned.
+func (font *Font) VariedKernTable(coords []float32) (Kerns, error) {
+	kerns, err := font.gposKerning()
+	if err != nil {
+		return nil, err
+	}
+	store, err := font.gdefVariationStore()
+	if err != nil {
+		return nil, err
+	}
+	if store == nil || len(coords) == 0 {
+		return kerns, nil
+	}
+	return varyKerns(kerns.(kernUnions), store, coords), nil
+}
+
+// gdefVariationStore returns the item variation store of the 'GDEF'
+// table (version 1.3), which stores the deltas referenced by the
+// VariationIndex tables, or nil if the font has none.
+func (font *Font) gdefVariationStore() (*itemVariationStore, error) {
+	section, found := font.tables[TagGDEF]
+	if !found {
+		return nil, nil
+	}
+	buf, err := font.findTableBuffer(section)
+	if err != nil {
+		return nil, err
+	}
+	// majorVersion, minorVersion, glyphClassDefOffset, attachListOffset,
+	// ligCaretListOffset, markAttachClassDefOffset, markGlyphSetsDefOffset,
+	// itemVarStoreOffset
+	const headerSize = 18
+	if len(buf) < 4 {
+		return nil, errInvalidGDEFTable
+	}
+	if major, minor := be.Uint16(buf), be.Uint16(buf[2:]); major != 1 || minor < 3 {
+		return nil, nil
+	}
+	if len(buf) < headerSize {
+		return nil, errInvalidGDEFTable
+	}
+	offset := be.Uint32(buf[14:])
+	if offset == 0 {
+		return nil, nil
+	}
+	if uint64(offset) >= uint64(len(buf)) {
+		return nil, errInvalidGDEFTable
+	}
+	store, err := parseItemVariationStore(buf, int(offset))
+	if err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// varyKerns returns the kerning of the subtables of kerns
+// at the given coordinates.
+func varyKerns(kerns kernUnions, store *itemVariationStore, coords []float32) Kerns {
+	out := make(kernUnions, len(kerns))
+	for i, k := range kerns {
+		out[i] = variedPairPos{pairPos: k.(pairPos), store: store, coords: coords}
+	}
+	return out
+}
+
+// variedPairPos is the kerning of a pair adjustment
+// subtable at some normalized coordinates.
+type variedPairPos struct {
+	pairPos
+	store  *itemVariationStore
+	coords []float32
+}
+
+func (vp variedPairPos) value(adjustment PairAdjustment) int16 {
+	kc := vp.valueConvention()
+	variation := kc.variation(adjustment, vp.store, vp.coords)
+	return kc.value(adjustment) + int16(math.Round(float64(variation)))
+}
+
+func (vp variedPairPos) KernPair(left, right GlyphIndex) (int16, bool) {
+	adjustment, ok := vp.PairAdjustment(left, right)
+	return vp.value(adjustment), ok
+}
+
+func (vp variedPairPos) KernPairAt(left, right GlyphIndex, ppem uint16) (int16, int16, bool) {
+	adjustment, ok := vp.PairAdjustment(left, right)
+	return vp.value(adjustment), vp.valueConvention().delta(adjustment, ppem), ok
+}
+
+// Each also visits the pairs only kerned by their variations,
+// skipped by the Each method of the class subtables.
+func (vp variedPairPos) Each(fn func(left, right GlyphIndex, value int16)) {
+	vp.pairPos.eachAdjustment(func(left, right GlyphIndex, adjustment PairAdjustment) {
+		if value := vp.value(adjustment); value != 0 {
+			fn(left, right, value)
+		}
+	})
+}
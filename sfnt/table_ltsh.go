@@ -0,0 +1,56 @@
+package sfnt
+
+import "errors"
+
+var errInvalidLTSHTable = errors.New("invalid LTSH table")
+
+// TableLTSH is the linear threshold table, which gives for each glyph
+// the size from which its advance scales linearly, that is, is no
+// more altered by the hinting instructions.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/ltsh
+type TableLTSH struct {
+	baseTable
+
+	bytes []byte
+
+	// YPels are the thresholds, in pixels per em, indexed by glyph.
+	// A value of 1 means that the glyph always scales linearly.
+	YPels []uint8
+}
+
+// Bytes returns the bytes for this table. The TableLTSH is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableLTSH) Bytes() []byte {
+	return t.bytes
+}
+
+// Linear returns true if the advance of the glyph scales
+// linearly at the given size, in pixels per em. The glyphs
+// not covered by the table are assumed to be hinted at all sizes.
+func (t *TableLTSH) Linear(gi GlyphIndex, ppem uint16) bool {
+	if int(gi) >= len(t.YPels) {
+		return false
+	}
+	return ppem >= uint16(t.YPels[gi])
+}
+
+func parseTableLTSH(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 4
+	if len(buf) < headerSize {
+		return nil, errInvalidLTSHTable
+	}
+	numGlyphs := int(be.Uint16(buf[2:]))
+	if len(buf) < headerSize+numGlyphs {
+		return nil, errInvalidLTSHTable
+	}
+	return &TableLTSH{baseTable: baseTable(tag), bytes: buf, YPels: buf[headerSize : headerSize+numGlyphs]}, nil
+}
+
+// LTSHTable returns the linear threshold table.
+func (font *Font) LTSHTable() (*TableLTSH, error) {
+	t, err := font.Table(TagLTSH)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableLTSH), nil
+}
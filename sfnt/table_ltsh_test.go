@@ -0,0 +1,62 @@
+package sfnt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLTSH(t *testing.T) {
+	table, err := ParseTable(TagLTSH, []byte{0, 0, 0, 3, 1, 12, 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ltsh := table.(*TableLTSH)
+	if !reflect.DeepEqual(ltsh.YPels, []uint8{1, 12, 50}) {
+		t.Errorf("unexpected thresholds %v", ltsh.YPels)
+	}
+	for _, test := range []struct {
+		gi       GlyphIndex
+		ppem     uint16
+		expected bool
+	}{
+		{0, 8, true},
+		{1, 11, false},
+		{1, 12, true},
+		{2, 300, true},
+		{3, 300, false},
+	} {
+		if got := ltsh.Linear(test.gi, test.ppem); got != test.expected {
+			t.Errorf("glyph %d at %d ppem: expected %v", test.gi, test.ppem, test.expected)
+		}
+	}
+
+	if _, err := ParseTable(TagLTSH, []byte{0, 0, 0, 3, 1}); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
+
+func TestPCLT(t *testing.T) {
+	buf := make([]byte, 54)
+	copy(buf, []byte{0, 1, 0, 0, 0x80, 0, 0, 1, 0x02, 0x26, 0x04, 0x1A}) // version, fontNumber, pitch, xHeight
+	copy(buf[20:], "Roboto  ")
+	buf[50] = 0xFE // strokeWeight: -2
+	table, err := ParseTable(TagPCLT, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pclt := table.(*TablePCLT)
+	if pclt.Version != 0x10000 || pclt.Pitch != 550 || pclt.XHeight != 1050 || pclt.StrokeWeight != -2 {
+		t.Errorf("unexpected fields %+v", pclt.tablePCLTFields)
+	}
+	if name := pclt.TypefaceName(); name != "Roboto" {
+		t.Errorf("unexpected typeface %q", name)
+	}
+	if !bytes.Equal(pclt.Bytes(), buf) {
+		t.Error("unexpected bytes")
+	}
+
+	if _, err := ParseTable(TagPCLT, buf[:50]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
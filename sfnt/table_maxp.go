@@ -0,0 +1,173 @@
+package sfnt
+
+// offsets in the 'maxp' table (version 1.0)
+const (
+	maxpNumGlyphsOffset             = 4
+	maxpMaxPointsOffset             = 6
+	maxpMaxContoursOffset           = 8
+	maxpMaxCompositePointsOffset    = 10
+	maxpMaxCompositeContoursOffset  = 12
+	maxpMaxSizeOfInstructionsOffset = 26
+	maxpMaxComponentElementsOffset  = 28
+	maxpMaxComponentDepthOffset     = 30
+	maxpVersion1Size                = 32
+)
+
+// glyphProfile is the size of a glyph, with its components resolved.
+type glyphProfile struct {
+	points, contours int
+	components       int // number of direct components
+	depth            int // 0 for simple glyphs
+}
+
+// maxpProfile computes the glyph profiles, memoized in profiles.
+type maxpProfile struct {
+	font     *Font
+	profiles map[GlyphIndex]glyphProfile
+}
+
+func (mp maxpProfile) profile(gi GlyphIndex, level int) (glyphProfile, error) {
+	if profile, ok := mp.profiles[gi]; ok {
+		return profile, nil
+	}
+	if level > maxComponentLevel {
+		return glyphProfile{}, errInvalidGlyfTable
+	}
+	buf, err := mp.font.glyphBuffer(gi)
+	if err != nil {
+		return glyphProfile{}, err
+	}
+	data, err := parseGlyphData(buf)
+	if err != nil {
+		return glyphProfile{}, err
+	}
+	out := glyphProfile{
+		points:     len(data.outline.Points),
+		contours:   len(data.outline.EndPoints),
+		components: len(data.components),
+	}
+	for _, component := range data.components {
+		child, err := mp.profile(component.glyph, level+1)
+		if err != nil {
+			return glyphProfile{}, err
+		}
+		out.points += child.points
+		out.contours += child.contours
+		if child.depth+1 > out.depth {
+			out.depth = child.depth + 1
+		}
+	}
+	mp.profiles[gi] = out
+	return out, nil
+}
+
+// UpdateMaxp recomputes the 'maxp' table of a font with TrueType outlines,
+// by scanning the 'glyf' table. It should be called after editing or merging
+// glyphs, so that the rasterizers allocate the right amount of memory.
+// The number of glyphs is deduced from the 'loca' table. The maxima of the
+// points, contours, components and instructions sizes are updated, but the
+// other values related to the hinting programs (such as the stack size) are kept.
+func (font *Font) UpdateMaxp() error {
+	head, err := font.HeadTable()
+	if err != nil {
+		return err
+	}
+	loca, err := font.Table(TagLoca)
+	if err != nil {
+		return err
+	}
+	maxp, err := font.Table(TagMaxp)
+	if err != nil {
+		return err
+	}
+	newMaxp := append([]byte(nil), maxp.Bytes()...)
+	if len(newMaxp) < maxpVersion1Size {
+		return errInvalidMaxpTable
+	}
+
+	entrySize := 2
+	if head.IndexToLocFormat != 0 {
+		entrySize = 4
+	}
+	numGlyphs := len(loca.Bytes())/entrySize - 1
+	if numGlyphs < 0 || numGlyphs > 0xFFFF {
+		return errInvalidLocaTable
+	}
+
+	var (
+		maxPoints, maxContours                   int
+		maxCompositePoints, maxCompositeContours int
+		maxComponents, maxDepth, maxInstructions int
+	)
+	mp := maxpProfile{font: font, profiles: map[GlyphIndex]glyphProfile{}}
+	for i := 0; i < numGlyphs; i++ {
+		gi := GlyphIndex(i)
+		profile, err := mp.profile(gi, 0)
+		if err != nil {
+			return err
+		}
+		buf, err := font.glyphBuffer(gi)
+		if err != nil {
+			return err
+		}
+		if len(buf) == 0 {
+			continue
+		}
+		program, err := findGlyphProgram(buf)
+		if err != nil {
+			return err
+		}
+		if program.end-program.start > maxInstructions {
+			maxInstructions = program.end - program.start
+		}
+
+		if program.lastFlags == -1 { // simple glyph
+			if profile.points > maxPoints {
+				maxPoints = profile.points
+			}
+			if profile.contours > maxContours {
+				maxContours = profile.contours
+			}
+			continue
+		}
+		if profile.points > maxCompositePoints {
+			maxCompositePoints = profile.points
+		}
+		if profile.contours > maxCompositeContours {
+			maxCompositeContours = profile.contours
+		}
+		if profile.components > maxComponents {
+			maxComponents = profile.components
+		}
+		if profile.depth > maxDepth {
+			maxDepth = profile.depth
+		}
+	}
+
+	// as done by ttfautohint, the programs of the font are included,
+	// since some rasterizers use this size for all the instructions
+	for _, tag := range [...]Tag{TagFpgm, TagPrep} {
+		if table, err := font.Table(tag); err == nil && len(table.Bytes()) > maxInstructions {
+			maxInstructions = len(table.Bytes())
+		}
+	}
+
+	for _, field := range [...]struct{ offset, value int }{
+		{maxpNumGlyphsOffset, numGlyphs},
+		{maxpMaxPointsOffset, maxPoints},
+		{maxpMaxContoursOffset, maxContours},
+		{maxpMaxCompositePointsOffset, maxCompositePoints},
+		{maxpMaxCompositeContoursOffset, maxCompositeContours},
+		{maxpMaxSizeOfInstructionsOffset, maxInstructions},
+		{maxpMaxComponentElementsOffset, maxComponents},
+		{maxpMaxComponentDepthOffset, maxDepth},
+	} {
+		value := field.value
+		if value > 0xFFFF {
+			value = 0xFFFF
+		}
+		be.PutUint16(newMaxp[field.offset:], uint16(value))
+	}
+	font.AddTable(TagMaxp, NewTable(TagMaxp, newMaxp))
+	return nil
+}
@@ -0,0 +1,47 @@
+package sfnt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUpdateMaxp(t *testing.T) {
+	for file, maxInstructions := range map[string]uint16{
+		"Roboto-BoldItalic.ttf": 0,
+		"Castoro-Regular.ttf":   3596, // the size of fpgm
+		"FreeSerif.ttf":         613,  // the size of fpgm, not included by the font
+	} {
+		font := loadTestFont(t, file)
+		maxp, err := font.Table(TagMaxp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := append([]byte(nil), maxp.Bytes()...)
+		be.PutUint16(expected[maxpMaxSizeOfInstructionsOffset:], maxInstructions)
+		if err := font.UpdateMaxp(); err != nil {
+			t.Fatal(err)
+		}
+		updated, _ := font.Table(TagMaxp)
+		if !bytes.Equal(expected, updated.Bytes()) {
+			t.Errorf("%s: expected %v, got %v", file, expected, updated.Bytes())
+		}
+	}
+
+	// the stale composite values are fixed
+	font := loadTestFont(t, "AnjaliOldLipi-Regular.ttf")
+	if err := font.UpdateMaxp(); err != nil {
+		t.Fatal(err)
+	}
+	maxp, _ := font.Table(TagMaxp)
+	b := maxp.Bytes()
+	if elements, depth := be.Uint16(b[maxpMaxComponentElementsOffset:]), be.Uint16(b[maxpMaxComponentDepthOffset:]); elements != 0 || depth != 0 {
+		t.Errorf("expected no composite glyph, got %d elements and depth %d", elements, depth)
+	}
+	if points := be.Uint16(b[maxpMaxPointsOffset:]); points != 43 {
+		t.Errorf("expected 43 points, got %d", points)
+	}
+
+	if err := loadTestFont(t, "Raleway-v4020-Regular.otf").UpdateMaxp(); err != ErrMissingTable {
+		t.Errorf("expected ErrMissingTable for CFF outlines, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package sfnt
+
+import (
+	"errors"
+	"strings"
+)
+
+var errInvalidMetaTable = errors.New("invalid meta table")
+
+var (
+	// MetaDesignLanguages is the tag of the metadata listing the
+	// languages the font is designed for.
+	MetaDesignLanguages = MustNamedTag("dlng")
+	// MetaSupportedLanguages is the tag of the metadata listing the
+	// languages the font is able to render.
+	MetaSupportedLanguages = MustNamedTag("slng")
+)
+
+// TableMeta is the metadata table, which stores data
+// identified by tags, such as the languages of the font.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/meta
+type TableMeta struct {
+	baseTable
+
+	bytes []byte
+
+	Data map[Tag][]byte
+}
+
+// Bytes returns the bytes for this table. The TableMeta is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableMeta) Bytes() []byte {
+	return t.bytes
+}
+
+// DesignLanguages returns the ScriptLangTags (such as "Latn" or
+// "zh-Hant") of the 'dlng' entry, or nil if there is none.
+func (t *TableMeta) DesignLanguages() []string {
+	return parseScriptLangTags(t.Data[MetaDesignLanguages])
+}
+
+// SupportedLanguages returns the ScriptLangTags of the 'slng'
+// entry, or nil if there is none.
+func (t *TableMeta) SupportedLanguages() []string {
+	return parseScriptLangTags(t.Data[MetaSupportedLanguages])
+}
+
+// parseScriptLangTags splits the comma separated list, ignoring
+// the spaces and the empty items.
+func parseScriptLangTags(data []byte) []string {
+	var out []string
+	for _, tag := range strings.Split(string(data), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func parseTableMeta(tag Tag, buf []byte) (Table, error) {
+	const headerSize, mapSize = 16, 12
+	if len(buf) < headerSize {
+		return nil, errInvalidMetaTable
+	}
+	numMaps := int(be.Uint32(buf[12:]))
+	if len(buf) < headerSize+mapSize*numMaps {
+		return nil, errInvalidMetaTable
+	}
+
+	data := make(map[Tag][]byte, numMaps)
+	for i := 0; i < numMaps; i++ {
+		record := buf[headerSize+mapSize*i:]
+		offset, length := int(be.Uint32(record[4:])), int(be.Uint32(record[8:]))
+		if len(buf) < offset+length {
+			return nil, errInvalidMetaTable
+		}
+		data[Tag{be.Uint32(record)}] = buf[offset : offset+length]
+	}
+
+	return &TableMeta{baseTable: baseTable(tag), bytes: buf, Data: data}, nil
+}
+
+// MetaTable returns the metadata table identified with the 'meta' tag.
+func (font *Font) MetaTable() (*TableMeta, error) {
+	t, err := font.Table(TagMeta)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableMeta), nil
+}
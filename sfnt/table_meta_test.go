@@ -0,0 +1,42 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMeta(t *testing.T) {
+	buf := []byte{
+		0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, // version, flags, reserved, dataMapsCount
+		'd', 'l', 'n', 'g', 0, 0, 0, 40, 0, 0, 0, 9, // design languages
+		'a', 'p', 'p', 'l', 0, 0, 0, 49, 0, 0, 0, 3, // private data
+	}
+	buf = append(buf, "Latn,Cyrlabc"...)
+	table, err := ParseTable(TagMeta, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := New(TypeTrueType)
+	font.AddTable(TagMeta, table)
+	meta, err := font.MetaTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := meta.DesignLanguages(); !reflect.DeepEqual(got, []string{"Latn", "Cyrl"}) {
+		t.Errorf("unexpected design languages %v", got)
+	}
+	if got := meta.SupportedLanguages(); got != nil {
+		t.Errorf("unexpected supported languages %v", got)
+	}
+	if got := string(meta.Data[MustNamedTag("appl")]); got != "abc" {
+		t.Errorf("unexpected data %q", got)
+	}
+
+	if got := parseScriptLangTags([]byte("en-Latn, zh-Hant ,,Hira")); !reflect.DeepEqual(got, []string{"en-Latn", "zh-Hant", "Hira"}) {
+		t.Errorf("unexpected tags %v", got)
+	}
+
+	if _, err := ParseTable(TagMeta, buf[:45]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
@@ -0,0 +1,164 @@
+package sfnt
+
+import "errors"
+
+var errInvalidMvarTable = errors.New("invalid MVAR table")
+
+// value tags of the MVAR table
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/mvar#value-tags
+var (
+	mvarTypoAscender    = MustNamedTag("hasc")
+	mvarTypoDescender   = MustNamedTag("hdsc")
+	mvarTypoLineGap     = MustNamedTag("hlgp")
+	mvarWinAscent       = MustNamedTag("hcla")
+	mvarWinDescent      = MustNamedTag("hcld")
+	mvarUnderlineOffset = MustNamedTag("undo")
+	mvarUnderlineSize   = MustNamedTag("unds")
+	mvarStrikeoutOffset = MustNamedTag("stro")
+	mvarStrikeoutSize   = MustNamedTag("strs")
+	mvarXHeight         = MustNamedTag("xhgt")
+	mvarCapHeight       = MustNamedTag("cpht")
+)
+
+const mvarValueRecordMinSize = 8
+
+// TableMvar is the metrics variations table, which stores the
+// variations of the global metrics of a variable font.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/mvar
+type TableMvar struct {
+	baseTable
+
+	bytes []byte
+
+	store   itemVariationStore
+	records map[Tag][2]uint16 // outer and inner indices
+}
+
+// Bytes returns the bytes for this table. The TableMvar is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableMvar) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableMvar(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 12
+	if len(buf) < headerSize {
+		return nil, errInvalidMvarTable
+	}
+	recordSize := int(be.Uint16(buf[6:]))
+	recordCount := int(be.Uint16(buf[8:]))
+	storeOffset := int(be.Uint16(buf[10:]))
+	if recordSize < mvarValueRecordMinSize || len(buf) < headerSize+recordSize*recordCount {
+		return nil, errInvalidMvarTable
+	}
+
+	out := &TableMvar{
+		baseTable: baseTable(tag),
+		bytes:     buf,
+		records:   make(map[Tag][2]uint16, recordCount),
+	}
+	for i := 0; i < recordCount; i++ {
+		record := buf[headerSize+recordSize*i:]
+		out.records[NewTag(record)] = [2]uint16{be.Uint16(record[4:]), be.Uint16(record[6:])}
+	}
+	if storeOffset == 0 { // no variations
+		return out, nil
+	}
+
+	var err error
+	out.store, err = parseItemVariationStore(buf, storeOffset)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delta returns the variation of the metric identified by the value tag
+// (such as 'hasc' for the typographic ascender of the 'OS/2' table), at
+// the given normalized coordinates.
+func (t *TableMvar) Delta(valueTag Tag, coords []float32) float32 {
+	indexes, ok := t.records[valueTag]
+	if !ok {
+		return 0
+	}
+	return t.store.delta(indexes[0], indexes[1], coords)
+}
+
+// MvarTable returns the metrics variations table.
+func (font *Font) MvarTable() (*TableMvar, error) {
+	t, err := font.Table(TagMvar)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableMvar), nil
+}
+
+// VariedMetrics are the global metrics of a variable font instance,
+// expressed in font units.
+type VariedMetrics struct {
+	TypoAscender, TypoDescender, TypoLineGap float32 // from the 'OS/2' table
+	WinAscent, WinDescent                    float32 // from the 'OS/2' table
+
+	UnderlinePosition, UnderlineThickness float32 // from the 'post' table
+	StrikeoutPosition, StrikeoutSize      float32 // from the 'OS/2' table
+
+	XHeight, CapHeight float32 // from the 'OS/2' table
+}
+
+// VariedMetrics returns the global metrics of the font at the given normalized
+// coordinates (see NormalizeCoordinates), applying the variations
+// of the 'MVAR' table, if any.
+// The metrics of the missing tables are left to zero.
+func (font *Font) VariedMetrics(coords []float32) (VariedMetrics, error) {
+	var out VariedMetrics
+	if font.HasTable(TagOS2) {
+		os2, err := font.OS2Table()
+		if err != nil {
+			return out, err
+		}
+		out.TypoAscender = float32(os2.STypoAscender)
+		out.TypoDescender = float32(os2.STypoDescender)
+		out.TypoLineGap = float32(os2.STypoLineGap)
+		out.WinAscent = float32(os2.UsWinAscent)
+		out.WinDescent = float32(os2.UsWinDescent)
+		out.StrikeoutPosition = float32(os2.YStrikeoutPosition)
+		out.StrikeoutSize = float32(os2.YStrikeoutSize)
+		out.XHeight = float32(os2.SxHeigh)
+		out.CapHeight = float32(os2.SCapHeight)
+	}
+	if font.HasTable(TagPost) {
+		post, err := font.PostTable()
+		if err != nil {
+			return out, err
+		}
+		out.UnderlinePosition = float32(post.UnderlinePosition)
+		out.UnderlineThickness = float32(post.UnderlineThickness)
+	}
+
+	if len(coords) == 0 || !font.HasTable(TagMvar) {
+		return out, nil
+	}
+	mvar, err := font.MvarTable()
+	if err != nil {
+		return out, err
+	}
+	for _, metric := range [...]struct {
+		tag   Tag
+		value *float32
+	}{
+		{mvarTypoAscender, &out.TypoAscender},
+		{mvarTypoDescender, &out.TypoDescender},
+		{mvarTypoLineGap, &out.TypoLineGap},
+		{mvarWinAscent, &out.WinAscent},
+		{mvarWinDescent, &out.WinDescent},
+		{mvarUnderlineOffset, &out.UnderlinePosition},
+		{mvarUnderlineSize, &out.UnderlineThickness},
+		{mvarStrikeoutOffset, &out.StrikeoutPosition},
+		{mvarStrikeoutSize, &out.StrikeoutSize},
+		{mvarXHeight, &out.XHeight},
+		{mvarCapHeight, &out.CapHeight},
+	} {
+		*metric.value += mvar.Delta(metric.tag, coords)
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package sfnt
+
+import (
+	"testing"
+)
+
+func TestVariedMetrics(t *testing.T) {
+	mvar, err := parseTableMvar(TagMvar, []byte{
+		0, 1, 0, 0, // version
+		0, 0, // reserved
+		0, 8, // valueRecordSize
+		0, 2, // valueRecordCount
+		0, 28, // itemVariationStoreOffset
+		'h', 'a', 's', 'c', 0, 0, 0, 0,
+		'u', 'n', 'd', 'o', 0, 0, 0, 1,
+		// item variation store
+		0, 1, // format
+		0, 0, 0, 12, // variationRegionListOffset
+		0, 1, // itemVariationDataCount
+		0, 0, 0, 22, // itemVariationDataOffsets
+		// region list: one axis, one region [0, 1, 1]
+		0, 1, 0, 1, 0, 0, 0x40, 0, 0x40, 0,
+		// item variation data: two items, one region
+		0, 2, 0, 0, 0, 1, 0, 0, 10, 0xEC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font := New(TypeTrueType)
+	os2 := make([]byte, 96)
+	be.PutUint16(os2[68:], 800)   // sTypoAscender
+	os2[70], os2[71] = 0xFF, 0x38 // sTypoDescender: -200
+	os2Table, err := parseTableOS2(TagOS2, os2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagOS2, os2Table)
+	font.AddTable(TagMvar, mvar)
+
+	for _, test := range []struct {
+		coords                      []float32
+		ascender, underlinePosition float32
+	}{
+		{nil, 800, 0},
+		{[]float32{-0.5}, 800, 0},
+		{[]float32{0.5}, 805, -10},
+		{[]float32{1}, 810, -20},
+	} {
+		metrics, err := font.VariedMetrics(test.coords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metrics.TypoAscender != test.ascender || metrics.UnderlinePosition != test.underlinePosition {
+			t.Errorf("at %v: unexpected metrics %v", test.coords, metrics)
+		}
+		if metrics.TypoDescender != -200 {
+			t.Errorf("unexpected descender %v", metrics.TypoDescender)
+		}
+	}
+}
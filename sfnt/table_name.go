@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"sort"
 	"strconv"
 
 	"golang.org/x/text/encoding/charmap"
@@ -330,6 +331,28 @@ func (table *TableName) Add(entry *NameEntry) {
 	table.entries = append(table.entries, entry)
 }
 
+// sorted returns a copy of the table with its records sorted by
+// platform, encoding, language and name ID, as recommended by the
+// specification. The order of the duplicated records is preserved.
+func (table *TableName) sorted() *TableName {
+	entries := make([]*NameEntry, len(table.entries))
+	copy(entries, table.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.PlatformID != ej.PlatformID {
+			return ei.PlatformID < ej.PlatformID
+		}
+		if ei.EncodingID != ej.EncodingID {
+			return ei.EncodingID < ej.EncodingID
+		}
+		if ei.LanguageID != ej.LanguageID {
+			return ei.LanguageID < ej.LanguageID
+		}
+		return ei.NameID < ej.NameID
+	})
+	return &TableName{baseTable: table.baseTable, entries: entries}
+}
+
 // Bytes returns the representation of this table to be stored in a font.
 func (table *TableName) Bytes() []byte {
 	if len(table.bytes) > 0 {
@@ -369,3 +392,21 @@ func (table *TableName) Bytes() []byte {
 func (table *TableName) List() []*NameEntry {
 	return table.entries
 }
+
+// Lookup returns the entry with the given name ID, preferring the
+// English Microsoft entries, or nil if there is none.
+func (table *TableName) Lookup(nameID NameID) *NameEntry {
+	var out *NameEntry
+	for _, entry := range table.entries {
+		if entry.NameID != nameID {
+			continue
+		}
+		if entry.PlatformID == PlatformMicrosoft && entry.LanguageID == PlatformLanguageMicrosoftEnglish {
+			return entry
+		}
+		if out == nil {
+			out = entry
+		}
+	}
+	return out
+}
@@ -3,9 +3,12 @@ package sfnt
 import (
 	"bytes"
 	"encoding/binary"
-	"io"
+	"errors"
+	"fmt"
 )
 
+var errInvalidOS2Table = errors.New("invalid OS/2 table")
+
 type tableOS2Fields struct {
 	Version             uint16
 	XAvgCharWidth       uint16
@@ -52,17 +55,14 @@ type TableOS2 struct {
 }
 
 func parseTableOS2(tag Tag, buf []byte) (Table, error) {
-	r := bytes.NewBuffer(buf)
+	// Different versions of the table are different lengths:
+	// the fields missing in older versions are left to zero.
+	full := make([]byte, binary.Size(tableOS2Fields{}))
+	copy(full, buf)
 
 	var table tableOS2Fields
-	if err := binary.Read(r, binary.BigEndian, &table); err != nil {
-		// Different versions of the table are different lengths, as such
-		// we may not already read every field.
-		if err != io.ErrUnexpectedEOF {
-			return nil, err
-		}
-
-		// TODO Check the len(buf) is expected for this version
+	if err := binary.Read(bytes.NewReader(full), binary.BigEndian, &table); err != nil {
+		return nil, err
 	}
 
 	return &TableOS2{
@@ -75,3 +75,235 @@ func parseTableOS2(tag Tag, buf []byte) (Table, error) {
 func (t *TableOS2) Bytes() []byte {
 	return t.bytes
 }
+
+// offsets of the character indexes in the 'OS/2' table
+const (
+	os2FirstCharOffset   = 64
+	os2LastCharOffset    = 66
+	os2DefaultCharOffset = 90
+	os2BreakCharOffset   = 92
+)
+
+// CharIndexes are the fields of the 'OS/2' table describing
+// the characters supported by the font.
+type CharIndexes struct {
+	First, Last uint16 // usFirstCharIndex and usLastCharIndex
+	// Default and Break are the usDefaultChar and usBreakChar
+	// fields, which are only defined from version 2.
+	Default, Break uint16
+}
+
+// CharIndexes returns the character indexes of the table.
+func (t *TableOS2) CharIndexes() CharIndexes {
+	return CharIndexes{
+		First:   t.FsFirstCharIndex,
+		Last:    t.FsLastCharIndex,
+		Default: t.UsDefaultChar,
+		Break:   t.UsBreakChar,
+	}
+}
+
+// hasDefaultChar returns true if the table defines
+// usDefaultChar and usBreakChar.
+func (t *TableOS2) hasDefaultChar() bool {
+	return t.Version >= 2 && len(t.bytes) >= os2BreakCharOffset+2
+}
+
+// setCharIndexes returns a copy of the table, with the given indexes.
+func (t *TableOS2) setCharIndexes(indexes CharIndexes) (*TableOS2, error) {
+	buf := append([]byte(nil), t.bytes...)
+	if len(buf) < os2LastCharOffset+2 {
+		return nil, errInvalidOS2Table
+	}
+	be.PutUint16(buf[os2FirstCharOffset:], indexes.First)
+	be.PutUint16(buf[os2LastCharOffset:], indexes.Last)
+	if t.hasDefaultChar() {
+		be.PutUint16(buf[os2DefaultCharOffset:], indexes.Default)
+		be.PutUint16(buf[os2BreakCharOffset:], indexes.Break)
+	}
+	table, err := parseTableOS2(Tag(t.baseTable), buf)
+	if err != nil {
+		return nil, err
+	}
+	return table.(*TableOS2), nil
+}
+
+// ComputeCharIndexes returns the character indexes matching the 'cmap' table.
+// The current default and break characters are kept if they
+// are mapped by the 'cmap' table, otherwise they are set to 0 (.notdef) and
+// the space character.
+func (font *Font) ComputeCharIndexes() (CharIndexes, error) {
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return CharIndexes{}, err
+	}
+	os2, err := font.OS2Table()
+	if err != nil {
+		return CharIndexes{}, err
+	}
+
+	out := CharIndexes{First: 0xFFFF, Default: 0, Break: ' '}
+	for r, gi := range cmap.Compile() {
+		if gi == 0 {
+			continue
+		}
+		// values above the BMP are clamped
+		if r > 0xFFFF {
+			r = 0xFFFF
+		}
+		if uint16(r) < out.First {
+			out.First = uint16(r)
+		}
+		if uint16(r) > out.Last {
+			out.Last = uint16(r)
+		}
+	}
+	if out.Last == 0 { // empty cmap
+		out.First = 0
+	}
+	if cmap.Lookup(rune(os2.UsDefaultChar)) != 0 {
+		out.Default = os2.UsDefaultChar
+	}
+	if cmap.Lookup(rune(os2.UsBreakChar)) != 0 {
+		out.Break = os2.UsBreakChar
+	}
+	return out, nil
+}
+
+// ValidateCharIndexes checks that the character indexes of the 'OS/2'
+// table are consistent with the 'cmap' table.
+func (font *Font) ValidateCharIndexes() error {
+	os2, err := font.OS2Table()
+	if err != nil {
+		return err
+	}
+	expected, err := font.ComputeCharIndexes()
+	if err != nil {
+		return err
+	}
+
+	got := os2.CharIndexes()
+	if got.First != expected.First || got.Last != expected.Last {
+		return fmt.Errorf("invalid OS/2 character range: expected [%04X, %04X], got [%04X, %04X]",
+			expected.First, expected.Last, got.First, got.Last)
+	}
+	if !os2.hasDefaultChar() {
+		return nil
+	}
+	if got.Default != expected.Default {
+		return fmt.Errorf("invalid OS/2 default character %04X: not in the cmap", got.Default)
+	}
+	if got.Break != expected.Break {
+		return fmt.Errorf("invalid OS/2 break character %04X: not in the cmap", got.Break)
+	}
+	return nil
+}
+
+// ScriptMetrics are the recommended size and offset of the
+// subscripts or superscripts of the font, in font units.
+type ScriptMetrics struct {
+	XSize, YSize int16
+	// XOffset is the horizontal offset. YOffset is the vertical offset
+	// from the baseline, which is downwards for subscripts and upwards
+	// for superscripts.
+	XOffset, YOffset int16
+}
+
+// SubscriptMetrics returns the ySubscript* fields of the table.
+func (t *TableOS2) SubscriptMetrics() ScriptMetrics {
+	return ScriptMetrics{
+		XSize:   t.YSubscriptXSize,
+		YSize:   t.YSubscriptYSize,
+		XOffset: t.YSubscriptXOffset,
+		YOffset: t.YSubscriptYOffset,
+	}
+}
+
+// SuperscriptMetrics returns the ySuperscript* fields of the table.
+func (t *TableOS2) SuperscriptMetrics() ScriptMetrics {
+	return ScriptMetrics{
+		XSize:   t.YSuperscriptXSize,
+		YSize:   t.YSuperscriptYSize,
+		XOffset: t.YSuperscriptXOffset,
+		YOffset: t.YSuperscriptYOffset,
+	}
+}
+
+// default subscript and superscript metrics, as a fraction
+// of the em, used when the font doesn't define them
+const (
+	defaultScriptSize        = 0.65
+	defaultSubscriptOffset   = 0.075
+	defaultSuperscriptOffset = 0.35
+)
+
+// ScriptTransform is the transformation applied to regular glyphs to
+// synthesize subscripts or superscripts: the points are scaled
+// and then translated by the offset, expressed in font units.
+// The Y axis points upwards, so that OffsetY is negative for subscripts.
+type ScriptTransform struct {
+	ScaleX, ScaleY   float32
+	OffsetX, OffsetY float32
+}
+
+// Apply returns the transformed point.
+func (tr ScriptTransform) Apply(x, y float32) (float32, float32) {
+	return x*tr.ScaleX + tr.OffsetX, y*tr.ScaleY + tr.OffsetY
+}
+
+// SubscriptTransform returns the transformation to synthesize subscripts,
+// for fonts without dedicated glyphs. The metrics of the 'OS/2' table are
+// used if valid, and default values otherwise.
+func (font *Font) SubscriptTransform() (ScriptTransform, error) {
+	return font.scriptTransform(false)
+}
+
+// SuperscriptTransform returns the transformation to synthesize superscripts,
+// for fonts without dedicated glyphs. The metrics of the 'OS/2' table are
+// used if valid, and default values otherwise.
+func (font *Font) SuperscriptTransform() (ScriptTransform, error) {
+	return font.scriptTransform(true)
+}
+
+func (font *Font) scriptTransform(superscript bool) (ScriptTransform, error) {
+	head, err := font.HeadTable()
+	if err != nil {
+		return ScriptTransform{}, err
+	}
+	if head.UnitsPerEm == 0 {
+		return ScriptTransform{}, errInvalidHeadTable
+	}
+	unitsPerEm := float32(head.UnitsPerEm)
+
+	var metrics ScriptMetrics
+	if font.HasTable(TagOS2) {
+		os2, err := font.OS2Table()
+		if err != nil {
+			return ScriptTransform{}, err
+		}
+		if superscript {
+			metrics = os2.SuperscriptMetrics()
+		} else {
+			metrics = os2.SubscriptMetrics()
+		}
+	}
+
+	out := ScriptTransform{
+		ScaleX:  float32(metrics.XSize) / unitsPerEm,
+		ScaleY:  float32(metrics.YSize) / unitsPerEm,
+		OffsetX: float32(metrics.XOffset),
+		OffsetY: float32(metrics.YOffset),
+	}
+	if metrics.XSize <= 0 || metrics.YSize <= 0 {
+		out.ScaleX, out.ScaleY = defaultScriptSize, defaultScriptSize
+		out.OffsetX = 0
+		out.OffsetY = defaultSubscriptOffset * unitsPerEm
+		if superscript {
+			out.OffsetY = defaultSuperscriptOffset * unitsPerEm
+		}
+	}
+	if !superscript { // the subscript offset is positive downwards
+		out.OffsetY = -out.OffsetY
+	}
+	return out, nil
+}
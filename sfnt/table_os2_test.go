@@ -0,0 +1,141 @@
+package sfnt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestCharIndexes(t *testing.T) {
+	for _, test := range []struct {
+		file    string
+		indexes CharIndexes
+	}{
+		{"testdata/Roboto-BoldItalic.ttf", CharIndexes{0, 0xFFFF, 0, ' '}},
+		{"testdata/Castoro-Regular.ttf", CharIndexes{0, 0x25CC, 0, ' '}},
+		{"testdata/FreeSerif.ttf", CharIndexes{' ', 0xFFFF, 0, 0}}, // version 1
+		{"testdata/Raleway-v4020-Regular.otf", CharIndexes{0, 0xFB02, 0, ' '}},
+	} {
+		f, err := os.Open(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os2, err := font.OS2Table()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := os2.CharIndexes(); got != test.indexes {
+			t.Errorf("%s: expected %v, got %v", test.file, test.indexes, got)
+		}
+		if err := font.ValidateCharIndexes(); err != nil {
+			t.Errorf("%s: %s", test.file, err)
+		}
+		f.Close()
+	}
+}
+
+func TestComputeRanges(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	os2, err := font.OS2Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codePages, err := font.ComputeCodePageRanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [2]uint32{os2.UlCodePageRange1, os2.UlCodePageRange2}; codePages != expected {
+		t.Errorf("expected code pages %08x, got %08x", expected, codePages)
+	}
+	unicodeRanges, err := font.ComputeUnicodeRanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range os2.UlCharRange {
+		if unicodeRanges[i]&r != r {
+			t.Errorf("range %d: missing bits %08x", i+1, r&^unicodeRanges[i])
+		}
+	}
+
+	// the ranges are updated when the cmap is replaced
+	font.AddTable(TagCmap, NewTable(TagCmap, BuildCmap(map[rune]GlyphIndex{
+		'A': 1, 'Ж': 2, 'я': 3, 'Ђ': 4, 0x1F600: 5,
+	})))
+	var buf bytes.Buffer
+	if _, err := font.WriteOTF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	font, err = Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os2, err = font.OS2Table(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := [4]uint32{1<<0 | 1<<9, 1 << (57 - 32), 0, 0}; os2.UlCharRange != expected {
+		t.Errorf("expected ranges %08x, got %08x", expected, os2.UlCharRange)
+	}
+	if os2.UlCodePageRange1 != 1<<2 || os2.UlCodePageRange2 != 0 {
+		t.Errorf("expected the Cyrillic code page, got %08x %08x", os2.UlCodePageRange1, os2.UlCodePageRange2)
+	}
+}
+
+func TestScriptTransform(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	roboto, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os2, err := roboto.OS2Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := os2.SubscriptMetrics(); got != (ScriptMetrics{1434, 1331, 0, 287}) {
+		t.Errorf("unexpected subscript metrics %v", got)
+	}
+	if got := os2.SuperscriptMetrics(); got != (ScriptMetrics{1434, 1331, 0, 977}) {
+		t.Errorf("unexpected superscript metrics %v", got)
+	}
+
+	// without metrics, the default values are used
+	empty := New(TypeTrueType)
+	head, _ := empty.HeadTable()
+	head.UnitsPerEm = 1000
+	table, err := parseTableOS2(TagOS2, make([]byte, 78))
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty.AddTable(TagOS2, table)
+
+	for _, test := range []struct {
+		font        *Font
+		superscript bool
+		expected    ScriptTransform
+	}{
+		{roboto, false, ScriptTransform{1434. / 2048, 1331. / 2048, 0, -287}},
+		{roboto, true, ScriptTransform{1434. / 2048, 1331. / 2048, 0, 977}},
+		{empty, false, ScriptTransform{0.65, 0.65, 0, -75}},
+		{empty, true, ScriptTransform{0.65, 0.65, 0, 350}},
+	} {
+		got, err := test.font.scriptTransform(test.superscript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("expected %v, got %v", test.expected, got)
+		}
+	}
+
+	sup, _ := roboto.SuperscriptTransform()
+	if x, y := sup.Apply(1000, 1000); x != 1434000./2048 || y != 1331000./2048+977 {
+		t.Errorf("unexpected transformed point (%v, %v)", x, y)
+	}
+}
@@ -0,0 +1,67 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errInvalidPCLTTable = errors.New("invalid PCLT table")
+
+type tablePCLTFields struct {
+	Version             uint32
+	FontNumber          uint32
+	Pitch               uint16
+	XHeight             uint16
+	Style               uint16
+	TypeFamily          uint16
+	CapHeight           uint16
+	SymbolSet           uint16
+	Typeface            [16]byte
+	CharacterComplement [8]byte
+	FileName            [6]byte
+	StrokeWeight        int8
+	WidthType           int8
+	SerifStyle          uint8
+	Reserved            uint8
+}
+
+// TablePCLT is the PCL 5 table, which stores the information
+// used by the Hewlett-Packard printers.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/pclt
+type TablePCLT struct {
+	baseTable
+	tablePCLTFields
+	bytes []byte
+}
+
+// Bytes returns the bytes for this table. The TablePCLT is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TablePCLT) Bytes() []byte {
+	return t.bytes
+}
+
+// TypefaceName returns the Typeface field, without its padding.
+func (t *TablePCLT) TypefaceName() string {
+	return string(bytes.TrimRight(t.Typeface[:], "\x00 "))
+}
+
+func parseTablePCLT(tag Tag, buf []byte) (Table, error) {
+	var fields tablePCLTFields
+	if len(buf) < binary.Size(fields) {
+		return nil, errInvalidPCLTTable
+	}
+	if err := binary.Read(bytes.NewReader(buf), binary.BigEndian, &fields); err != nil {
+		return nil, err
+	}
+	return &TablePCLT{baseTable: baseTable(tag), tablePCLTFields: fields, bytes: buf}, nil
+}
+
+// PCLTTable returns the PCL 5 table.
+func (font *Font) PCLTTable() (*TablePCLT, error) {
+	t, err := font.Table(TagPCLT)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TablePCLT), nil
+}
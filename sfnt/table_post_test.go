@@ -43,3 +43,65 @@ func TestPost(t *testing.T) {
 		f.Close()
 	}
 }
+
+func TestGlyphIndexByName(t *testing.T) {
+	for _, file := range []string{
+		"testdata/Castoro-Regular.ttf",       // post names
+		"testdata/Raleway-v4020-Regular.otf", // CFF charset
+	} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmap, err := font.CmapTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, r := range map[string]rune{"A": 'A', "exclam": '!', "eacute": 'é'} {
+			gi, err := font.GlyphIndexByName(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp := cmap.Lookup(r); gi != exp {
+				t.Errorf("%s: expected glyph %d for %s, got %d", file, exp, name, gi)
+			}
+		}
+		if _, err := font.GlyphIndexByName("not-a-glyph"); err != ErrGlyphNameNotFound {
+			t.Errorf("expected ErrGlyphNameNotFound, got %v", err)
+		}
+		f.Close()
+	}
+}
+
+func TestGlyphIndexByNameDuplicates(t *testing.T) {
+	post := func(indexes ...byte) Table {
+		buf := make([]byte, 32, 32+2+2*len(indexes))
+		buf[1] = 2 // version 2.0
+		buf = append(buf, 0, byte(len(indexes)))
+		for _, index := range indexes {
+			buf = append(buf, 0, index)
+		}
+		return NewTable(TagPost, buf)
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, []byte{0, 0, 0x50, 0, 0, 3}))
+	font.AddTable(TagPost, post(0, 36, 36)) // .notdef, A, A
+	if gi, err := font.GlyphIndexByName("A"); err != nil || gi != 1 {
+		t.Errorf("expected the first glyph 1, got %d %v", gi, err)
+	}
+
+	// the names are indexed again when the font is modified
+	font.AddTable(TagPost, post(0, 0, 36))
+	if gi, err := font.GlyphIndexByName("A"); err != nil || gi != 2 {
+		t.Errorf("expected glyph 2, got %d %v", gi, err)
+	}
+	if _, err := font.GlyphIndexByName(""); err != ErrGlyphNameNotFound {
+		t.Errorf("expected ErrGlyphNameNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+)
+
+var errInvalidSbixTable = errors.New("invalid sbix table")
+
+var (
+	// GraphicPNG is the graphic type of PNG images
+	GraphicPNG = MustNamedTag("png ")
+	// GraphicJPEG is the graphic type of JPEG images
+	GraphicJPEG = MustNamedTag("jpg ")
+	// GraphicTIFF is the graphic type of TIFF images
+	GraphicTIFF = MustNamedTag("tiff")
+
+	graphicDupe = MustNamedTag("dupe") // resolved when fetching the glyphs
+)
+
+// TableSbix is the standard bitmap graphics table, which stores
+// color glyphs as images (usually PNG), for several sizes.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/sbix
+type TableSbix struct {
+	baseTable
+
+	bytes []byte
+
+	Flags uint16
+	// Strikes are sorted by increasing PPEM.
+	Strikes []SbixStrike
+}
+
+// SbixStrike stores the images of the glyphs for one size.
+type SbixStrike struct {
+	PPEM uint16 // the size the images are designed for, in pixels per em
+	PPI  uint16 // the density the images are designed for, in pixels per inch
+
+	data []byte // starting at the strike header
+}
+
+// SbixGlyph is the image of a glyph.
+type SbixGlyph struct {
+	// OriginX and OriginY is the position of the
+	// bottom left corner of the image, in pixels.
+	OriginX, OriginY int16
+	// GraphicType is the format of the image, such as GraphicPNG.
+	GraphicType Tag
+	Data        []byte
+}
+
+// Bytes returns the bytes for this table. The TableSbix is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableSbix) Bytes() []byte {
+	return t.bytes
+}
+
+// Glyph returns the image of the glyph in the strike, or false if
+// the strike has no image for the glyph.
+func (s SbixStrike) Glyph(gi GlyphIndex) (SbixGlyph, bool) {
+	glyph, ok := s.rawGlyph(gi)
+	if ok && glyph.GraphicType == graphicDupe { // the image of another glyph
+		if len(glyph.Data) < 2 {
+			return SbixGlyph{}, false
+		}
+		glyph, ok = s.rawGlyph(GlyphIndex(be.Uint16(glyph.Data)))
+		if glyph.GraphicType == graphicDupe {
+			return SbixGlyph{}, false
+		}
+	}
+	return glyph, ok
+}
+
+func (s SbixStrike) rawGlyph(gi GlyphIndex) (SbixGlyph, bool) {
+	const headerSize, glyphHeaderSize = 4, 8
+	if len(s.data) < headerSize+4*(int(gi)+2) {
+		return SbixGlyph{}, false
+	}
+	start := int(be.Uint32(s.data[headerSize+4*int(gi):]))
+	end := int(be.Uint32(s.data[headerSize+4*int(gi)+4:]))
+	if end-start < glyphHeaderSize || len(s.data) < end { // empty or invalid
+		return SbixGlyph{}, false
+	}
+	data := s.data[start:end]
+	return SbixGlyph{
+		OriginX:     int16(be.Uint16(data)),
+		OriginY:     int16(be.Uint16(data[2:])),
+		GraphicType: NewTag(data[4:]),
+		Data:        data[glyphHeaderSize:],
+	}, true
+}
+
+// Glyph returns the image of the glyph from the strike best suited to the
+// given size: the smallest strike at least as large as ppem, or the largest
+// one, moving to the other strikes if the glyph is missing.
+// The PPEM of the strike used is returned, so that the image may be scaled.
+func (t *TableSbix) Glyph(gi GlyphIndex, ppem uint16) (SbixGlyph, uint16, bool) {
+	first := sort.Search(len(t.Strikes), func(i int) bool { return t.Strikes[i].PPEM >= ppem })
+	// larger strikes, then smaller ones, from the closest
+	for i := first; i < len(t.Strikes); i++ {
+		if glyph, ok := t.Strikes[i].Glyph(gi); ok {
+			return glyph, t.Strikes[i].PPEM, true
+		}
+	}
+	for i := first - 1; i >= 0; i-- {
+		if glyph, ok := t.Strikes[i].Glyph(gi); ok {
+			return glyph, t.Strikes[i].PPEM, true
+		}
+	}
+	return SbixGlyph{}, 0, false
+}
+
+func parseTableSbix(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 8
+	if len(buf) < headerSize {
+		return nil, errInvalidSbixTable
+	}
+	numStrikes := int(be.Uint32(buf[4:]))
+	if len(buf) < headerSize+4*numStrikes {
+		return nil, errInvalidSbixTable
+	}
+	out := &TableSbix{baseTable: baseTable(tag), bytes: buf, Flags: be.Uint16(buf[2:]), Strikes: make([]SbixStrike, numStrikes)}
+	for i := range out.Strikes {
+		offset := int(be.Uint32(buf[headerSize+4*i:]))
+		if len(buf) < offset+4 {
+			return nil, errInvalidSbixTable
+		}
+		strike := buf[offset:]
+		out.Strikes[i] = SbixStrike{PPEM: be.Uint16(strike), PPI: be.Uint16(strike[2:]), data: strike}
+	}
+	sort.SliceStable(out.Strikes, func(i, j int) bool { return out.Strikes[i].PPEM < out.Strikes[j].PPEM })
+	return out, nil
+}
+
+// SbixTable returns the standard bitmap graphics table.
+func (font *Font) SbixTable() (*TableSbix, error) {
+	t, err := font.Table(TagSbix)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableSbix), nil
+}
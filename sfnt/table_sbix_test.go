@@ -0,0 +1,65 @@
+package sfnt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSbix(t *testing.T) {
+	// strikes of 3 glyphs: glyph 0 is empty, glyph 2 duplicates glyph 1
+	strike := func(ppem byte, image string) []byte {
+		out := []byte{
+			0, ppem, 0, 72,
+			0, 0, 0, 20, 0, 0, 0, 20, 0, 0, 0, 28 + byte(len(image)), 0, 0, 0, 38 + byte(len(image)),
+			0, 1, 0xFF, 0xFE, 'p', 'n', 'g', ' ',
+		}
+		out = append(out, image...)
+		return append(out, 0, 0, 0, 0, 'd', 'u', 'p', 'e', 0, 1)
+	}
+	small, large := strike(20, "small"), strike(64, "large image")
+
+	buf := []byte{
+		0, 1, 0, 1, 0, 0, 0, 2, // version, flags, numStrikes
+		0, 0, 0, 16, 0, 0, 0, 16 + byte(len(large)), // not sorted
+	}
+	buf = append(buf, large...)
+	buf = append(buf, small...)
+
+	table, err := parseTableSbix(TagSbix, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbix := table.(*TableSbix)
+	if len(sbix.Strikes) != 2 || sbix.Strikes[0].PPEM != 20 || sbix.Strikes[1].PPI != 72 {
+		t.Fatalf("unexpected strikes %v", sbix.Strikes)
+	}
+
+	for _, test := range []struct {
+		gi           GlyphIndex
+		ppem         uint16
+		expected     string
+		expectedPPEM uint16
+	}{
+		{1, 12, "small", 20},
+		{1, 20, "small", 20},
+		{1, 21, "large image", 64},
+		{2, 100, "large image", 64},
+	} {
+		glyph, ppem, ok := sbix.Glyph(test.gi, test.ppem)
+		if !ok {
+			t.Fatalf("missing glyph %d", test.gi)
+		}
+		if !bytes.Equal(glyph.Data, []byte(test.expected)) || ppem != test.expectedPPEM {
+			t.Errorf("glyph %d at %d: expected %s (%d), got %s (%d)", test.gi, test.ppem, test.expected, test.expectedPPEM, glyph.Data, ppem)
+		}
+		if glyph.OriginX != 1 || glyph.OriginY != -2 || glyph.GraphicType != GraphicPNG {
+			t.Errorf("unexpected glyph header %v", glyph)
+		}
+	}
+
+	for _, gi := range []GlyphIndex{0, 3, 100} {
+		if _, _, ok := sbix.Glyph(gi, 20); ok {
+			t.Errorf("unexpected glyph %d", gi)
+		}
+	}
+}
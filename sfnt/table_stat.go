@@ -0,0 +1,300 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var errInvalidSTATTable = errors.New("invalid STAT table")
+
+// flags of the axis values
+const (
+	// AxisValueOlderSiblingFontAttribute indicates that the axis value
+	// applies to older fonts of the family, which are not variable.
+	AxisValueOlderSiblingFontAttribute = 0x0001
+	// AxisValueElidable indicates that the name of the axis value may be
+	// omitted when building the name of a font (like "Regular").
+	AxisValueElidable = 0x0002
+)
+
+// TableSTAT is the style attributes table, which describes the
+// design axes of a font family and how to name its styles.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/stat
+type TableSTAT struct {
+	baseTable
+
+	bytes []byte
+
+	DesignAxes []DesignAxis
+	AxisValues []AxisValue
+	// ElidedFallbackNameID is the name used when all the axis
+	// values are elided, such as "Regular". It is zero for
+	// tables of version 1.0.
+	ElidedFallbackNameID NameID
+}
+
+// DesignAxis is an axis of the design space of a font family,
+// which may or may not be a variation axis.
+type DesignAxis struct {
+	Tag    Tag
+	NameID NameID
+	// Ordering is the position of the axis, used
+	// to build the name of the styles.
+	Ordering uint16
+}
+
+// AxisLocation is a value on a design axis.
+type AxisLocation struct {
+	AxisIndex int // index in DesignAxes
+	Value     float32
+}
+
+// AxisValue names a value, or a range of values, of one design
+// axis (formats 1, 2 and 3) or a combination of values of several axes (format 4).
+type AxisValue struct {
+	Format uint16
+	Flags  uint16
+	NameID NameID
+
+	// Locations stores the value of each axis: it has one
+	// element for formats 1, 2 and 3.
+	Locations []AxisLocation
+
+	RangeMin, RangeMax float32 // for format 2
+	LinkedValue        float32 // for format 3, such as the Bold matching a Regular
+}
+
+// Bytes returns the bytes for this table. The TableSTAT is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableSTAT) Bytes() []byte {
+	return t.bytes
+}
+
+func parseTableSTAT(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 18
+	if len(buf) < headerSize {
+		return nil, errInvalidSTATTable
+	}
+	minorVersion := be.Uint16(buf[2:])
+	axisSize := int(be.Uint16(buf[4:]))
+	axisCount := int(be.Uint16(buf[6:]))
+	axesOffset := int(be.Uint32(buf[8:]))
+	valueCount := int(be.Uint16(buf[12:]))
+	valuesOffset := int(be.Uint32(buf[14:]))
+
+	out := &TableSTAT{
+		baseTable:  baseTable(tag),
+		bytes:      buf,
+		DesignAxes: make([]DesignAxis, axisCount),
+		AxisValues: make([]AxisValue, valueCount),
+	}
+	if minorVersion >= 1 {
+		if len(buf) < headerSize+2 {
+			return nil, errInvalidSTATTable
+		}
+		out.ElidedFallbackNameID = NameID(be.Uint16(buf[18:]))
+	}
+
+	const axisRecordSize = 8
+	if axisCount != 0 && (axisSize < axisRecordSize || len(buf) < axesOffset+axisSize*axisCount) {
+		return nil, errInvalidSTATTable
+	}
+	for i := range out.DesignAxes {
+		b := buf[axesOffset+axisSize*i:]
+		out.DesignAxes[i] = DesignAxis{
+			Tag:      NewTag(b),
+			NameID:   NameID(be.Uint16(b[4:])),
+			Ordering: be.Uint16(b[6:]),
+		}
+	}
+
+	if len(buf) < valuesOffset+2*valueCount {
+		return nil, errInvalidSTATTable
+	}
+	for i := range out.AxisValues {
+		// offsets are relative to the start of the offsets array
+		offset := valuesOffset + int(be.Uint16(buf[valuesOffset+2*i:]))
+		value, err := parseAxisValue(buf, offset, axisCount)
+		if err != nil {
+			return nil, err
+		}
+		out.AxisValues[i] = value
+	}
+	return out, nil
+}
+
+func parseAxisValue(buf []byte, offset int, axisCount int) (AxisValue, error) {
+	if len(buf) < offset+8 {
+		return AxisValue{}, errInvalidSTATTable
+	}
+	b := buf[offset:]
+	out := AxisValue{
+		Format: be.Uint16(b),
+		Flags:  be.Uint16(b[4:]),
+		NameID: NameID(be.Uint16(b[6:])),
+	}
+	axisIndex := int(be.Uint16(b[2:]))
+
+	var size int
+	switch out.Format {
+	case 1:
+		size = 12
+	case 2:
+		size = 20
+	case 3:
+		size = 16
+	case 4:
+		size = 8 + 6*axisIndex // axisCount for this format
+	default:
+		return AxisValue{}, errInvalidSTATTable
+	}
+	if len(b) < size {
+		return AxisValue{}, errInvalidSTATTable
+	}
+
+	if out.Format == 4 {
+		out.Locations = make([]AxisLocation, axisIndex)
+		for i := range out.Locations {
+			out.Locations[i] = AxisLocation{
+				AxisIndex: int(be.Uint16(b[8+6*i:])),
+				Value:     fixedToFloat(be.Uint32(b[10+6*i:])),
+			}
+		}
+	} else {
+		out.Locations = []AxisLocation{{AxisIndex: axisIndex, Value: fixedToFloat(be.Uint32(b[8:]))}}
+	}
+	switch out.Format {
+	case 2:
+		out.RangeMin = fixedToFloat(be.Uint32(b[12:]))
+		out.RangeMax = fixedToFloat(be.Uint32(b[16:]))
+	case 3:
+		out.LinkedValue = fixedToFloat(be.Uint32(b[12:]))
+	}
+
+	for _, location := range out.Locations {
+		if location.AxisIndex >= axisCount {
+			return AxisValue{}, errInvalidSTATTable
+		}
+	}
+	return out, nil
+}
+
+// matches returns true if the axis value applies to the given
+// coordinates, indexed by design axis.
+func (v AxisValue) matches(coords map[int]float32) bool {
+	for _, location := range v.Locations {
+		coord, ok := coords[location.AxisIndex]
+		if !ok {
+			return false
+		}
+		if v.Format == 2 {
+			if coord < v.RangeMin || coord > v.RangeMax {
+				return false
+			}
+		} else if coord != location.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// matchingValue returns the axis value for the given axis matching the
+// coordinates, preferring the values of format 4, which combine several axes.
+func (t *TableSTAT) matchingValue(axisIndex int, coords map[int]float32) (AxisValue, bool) {
+	for _, combined := range [2]bool{true, false} {
+		for _, value := range t.AxisValues {
+			if (value.Format == 4) != combined || !value.matches(coords) {
+				continue
+			}
+			for _, location := range value.Locations {
+				if location.AxisIndex == axisIndex {
+					return value, true
+				}
+			}
+		}
+	}
+	return AxisValue{}, false
+}
+
+// OrderedAxes returns the indices of the design axes, sorted by their ordering.
+func (t *TableSTAT) OrderedAxes() []int {
+	out := make([]int, len(t.DesignAxes))
+	for i := range out {
+		out[i] = i
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return t.DesignAxes[out[i]].Ordering < t.DesignAxes[out[j]].Ordering
+	})
+	return out
+}
+
+// STATTable returns the style attributes table.
+func (font *Font) STATTable() (*TableSTAT, error) {
+	t, err := font.Table(TagSTAT)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableSTAT), nil
+}
+
+// StyleName builds the name of the style at the given user space coordinates,
+// one for each axis of the 'fvar' table, such as "Semi Bold Condensed".
+// The names of the axis values of the 'STAT' table are joined in the axis
+// ordering, skipping the elidable values.
+// The names are read from the 'name' table, using the English entries if available.
+func (font *Font) StyleName(userCoords []float32) (string, error) {
+	stat, err := font.STATTable()
+	if err != nil {
+		return "", err
+	}
+	names, err := font.NameTable()
+	if err != nil {
+		return "", err
+	}
+	axes, err := font.VariationAxes()
+	if err != nil {
+		return "", err
+	}
+
+	// map the fvar axes to the design axes
+	coords := map[int]float32{}
+	for i, axis := range axes {
+		for j, designAxis := range stat.DesignAxes {
+			if designAxis.Tag == axis.Tag {
+				coords[j] = axis.Default
+				if i < len(userCoords) {
+					coords[j] = userCoords[i]
+				}
+			}
+		}
+	}
+
+	var words []string
+	covered := make([]bool, len(stat.DesignAxes))
+	for _, axisIndex := range stat.OrderedAxes() {
+		if covered[axisIndex] {
+			continue
+		}
+		value, ok := stat.matchingValue(axisIndex, coords)
+		if !ok {
+			continue
+		}
+		for _, location := range value.Locations {
+			covered[location.AxisIndex] = true
+		}
+		if value.Flags&AxisValueElidable != 0 {
+			continue
+		}
+		if entry := names.Lookup(value.NameID); entry != nil {
+			words = append(words, entry.String())
+		}
+	}
+
+	if len(words) == 0 && stat.ElidedFallbackNameID != 0 {
+		if entry := names.Lookup(stat.ElidedFallbackNameID); entry != nil {
+			return entry.String(), nil
+		}
+	}
+	return strings.Join(words, " "), nil
+}
@@ -0,0 +1,78 @@
+package sfnt
+
+import (
+	"testing"
+)
+
+func statTable() []byte {
+	return []byte{
+		0, 1, 0, 2, // version 1.2
+		0, 8, // designAxisSize
+		0, 2, // designAxisCount
+		0, 0, 0, 20, // designAxesOffset
+		0, 4, // axisValueCount
+		0, 0, 0, 36, // offsetToAxisValueOffsets
+		1, 6, // elidedFallbackNameID
+		// design axes
+		'w', 'd', 't', 'h', 1, 0, 0, 1,
+		'w', 'g', 'h', 't', 1, 1, 0, 0,
+		// axis value offsets
+		0, 8, 0, 20, 0, 32, 0, 52,
+		// Regular
+		0, 1, 0, 1, 0, AxisValueElidable, 1, 2, 1, 144, 0, 0,
+		// Bold
+		0, 1, 0, 1, 0, 0, 1, 3, 2, 188, 0, 0,
+		// Condensed: [75, 90]
+		0, 2, 0, 0, 0, 0, 1, 4, 0, 75, 0, 0, 0, 75, 0, 0, 0, 90, 0, 0,
+		// Normal
+		0, 1, 0, 0, 0, AxisValueElidable, 1, 5, 0, 100, 0, 0,
+	}
+}
+
+func TestSTAT(t *testing.T) {
+	table, err := parseTableSTAT(TagSTAT, statTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := table.(*TableSTAT)
+	if len(stat.DesignAxes) != 2 || len(stat.AxisValues) != 4 || stat.ElidedFallbackNameID != 0x106 {
+		t.Fatalf("unexpected table %v", stat)
+	}
+	if value := stat.AxisValues[2]; value.Format != 2 || value.RangeMin != 75 || value.RangeMax != 90 {
+		t.Errorf("unexpected axis value %v", value)
+	}
+	if ordered := stat.OrderedAxes(); ordered[0] != 1 || ordered[1] != 0 {
+		t.Errorf("unexpected axis ordering %v", ordered)
+	}
+
+	fvar, err := parseTableFvar(TagFvar, fvarTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := NewTableName()
+	for id, name := range []string{"Width", "Weight", "Regular", "Bold", "Condensed", "Normal", "Regular"} {
+		names.AddMicrosoftEnglishEntry(NameID(0x100+id), name)
+	}
+	font := New(TypeTrueType)
+	font.AddTable(TagFvar, fvar)
+	font.AddTable(TagName, names)
+	font.AddTable(TagSTAT, stat)
+
+	for _, test := range []struct {
+		coords []float32
+		name   string
+	}{
+		{nil, "Regular"},
+		{[]float32{700, 100}, "Bold"},
+		{[]float32{700, 80}, "Bold Condensed"},
+		{[]float32{400, 75}, "Condensed"},
+	} {
+		name, err := font.StyleName(test.coords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != test.name {
+			t.Errorf("%v: expected %s, got %s", test.coords, test.name, name)
+		}
+	}
+}
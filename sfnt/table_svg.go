@@ -0,0 +1,108 @@
+package sfnt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"sort"
+)
+
+var errInvalidSVGTable = errors.New("invalid SVG table")
+
+// TableSVG is the SVG table, which defines color glyphs
+// with SVG documents.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/svg
+type TableSVG struct {
+	baseTable
+
+	bytes []byte
+
+	// Documents are sorted by glyph ranges.
+	Documents []SVGDocument
+}
+
+// SVGDocument is a SVG document, describing the glyphs
+// First to Last (included).
+type SVGDocument struct {
+	First, Last GlyphIndex
+	// Data is the content of the document, which may be gzip compressed.
+	// See Decompressed.
+	Data []byte
+}
+
+// Decompressed returns the content of the document,
+// uncompressing it if needed.
+func (doc SVGDocument) Decompressed() ([]byte, error) {
+	if !bytes.HasPrefix(doc.Data, []byte{0x1F, 0x8B}) { // gzip magic number
+		return doc.Data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(doc.Data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// Bytes returns the bytes for this table. The TableSVG is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableSVG) Bytes() []byte {
+	return t.bytes
+}
+
+// Document returns the document describing the glyph, if any.
+// In the document, the glyph is the element with id "glyph<gi>".
+func (t *TableSVG) Document(gi GlyphIndex) (SVGDocument, bool) {
+	i := sort.Search(len(t.Documents), func(i int) bool { return t.Documents[i].Last >= gi })
+	if i == len(t.Documents) || t.Documents[i].First > gi {
+		return SVGDocument{}, false
+	}
+	return t.Documents[i], true
+}
+
+func parseTableSVG(tag Tag, buf []byte) (Table, error) {
+	const headerSize, recordSize = 10, 12
+	if len(buf) < headerSize {
+		return nil, errInvalidSVGTable
+	}
+	listOffset := int(be.Uint32(buf[2:]))
+	if len(buf) < listOffset+2 {
+		return nil, errInvalidSVGTable
+	}
+	list := buf[listOffset:]
+	count := int(be.Uint16(list))
+	if len(list) < 2+recordSize*count {
+		return nil, errInvalidSVGTable
+	}
+
+	out := &TableSVG{baseTable: baseTable(tag), bytes: buf, Documents: make([]SVGDocument, count)}
+	for i := range out.Documents {
+		record := list[2+recordSize*i:]
+		offset, length := int(be.Uint32(record[4:])), int(be.Uint32(record[8:]))
+		if len(list) < offset+length {
+			return nil, errInvalidSVGTable
+		}
+		doc := SVGDocument{
+			First: GlyphIndex(be.Uint16(record)),
+			Last:  GlyphIndex(be.Uint16(record[2:])),
+			Data:  list[offset : offset+length],
+		}
+		if doc.First > doc.Last {
+			return nil, errInvalidSVGTable
+		}
+		out.Documents[i] = doc
+	}
+	// the records should be sorted, but don't trust the font
+	sort.SliceStable(out.Documents, func(i, j int) bool { return out.Documents[i].First < out.Documents[j].First })
+	return out, nil
+}
+
+// SVGTable returns the SVG table.
+func (font *Font) SVGTable() (*TableSVG, error) {
+	t, err := font.Table(TagSVG)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableSVG), nil
+}
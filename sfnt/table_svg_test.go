@@ -0,0 +1,51 @@
+package sfnt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestSVG(t *testing.T) {
+	doc1 := []byte(`<svg><g id="glyph3"/></svg>`)
+	doc2 := []byte(`<svg><g id="glyph8"/><g id="glyph9"/></svg>`)
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write(doc2)
+	w.Close()
+
+	buf := []byte{
+		0, 0, 0, 0, 0, 10, 0, 0, 0, 0, // version, svgDocumentListOffset, reserved
+		0, 2, // numEntries
+		0, 8, 0, 9, 0, 0, 0, 26, 0, 0, 0, byte(compressed.Len()), // not sorted
+		0, 3, 0, 3, 0, 0, 0, 26 + byte(compressed.Len()), 0, 0, 0, byte(len(doc1)),
+	}
+	buf = append(buf, compressed.Bytes()...)
+	buf = append(buf, doc1...)
+
+	table, err := parseTableSVG(TagSVG, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := table.(*TableSVG)
+	for gi, expected := range map[GlyphIndex][]byte{3: doc1, 8: doc2, 9: doc2, 2: nil, 5: nil, 10: nil} {
+		doc, ok := svg.Document(gi)
+		if ok != (expected != nil) {
+			t.Fatalf("glyph %d: unexpected document %v", gi, doc)
+		}
+		if !ok {
+			continue
+		}
+		content, err := doc.Decompressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(content, expected) {
+			t.Errorf("glyph %d: expected %s, got %s", gi, expected, content)
+		}
+	}
+
+	if _, err := parseTableSVG(TagSVG, buf[:40]); err != errInvalidSVGTable {
+		t.Errorf("expected error for truncated table, got %v", err)
+	}
+}
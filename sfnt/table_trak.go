@@ -0,0 +1,149 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+)
+
+var errInvalidTrakTable = errors.New("invalid trak table")
+
+// TableTrak is the AAT tracking table, which defines the spacing
+// to add between the glyphs, according to the point size.
+// See https://developer.apple.com/fonts/TrueType-Reference-Manual/RM06/Chap6trak.html
+type TableTrak struct {
+	baseTable
+
+	bytes []byte
+
+	Horizontal, Vertical TrackData
+}
+
+// TrackData stores the tracking for one direction.
+type TrackData struct {
+	// Sizes are the point sizes for which tracking is defined,
+	// in increasing order.
+	Sizes []float32
+	// Tracks are sorted by increasing value.
+	Tracks []Track
+}
+
+// Track is a tracking setting, such as "Normal" (0) or "Tight" (-1).
+type Track struct {
+	Value float32
+	Name  NameID
+	// PerSize stores the tracking for each size of the
+	// TrackData, in font units.
+	PerSize []int16
+}
+
+// Bytes returns the bytes for this table. The TableTrak is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableTrak) Bytes() []byte {
+	return t.bytes
+}
+
+// Tracking returns the adjustment to add to the advance of the glyphs,
+// in font units, for the track value (0 being the normal tracking) and
+// the point size. The values are interpolated between the tracks, and
+// between (or extrapolated beyond) the sizes, as done by HarfBuzz.
+// It returns 0 if there is no tracking data.
+func (td TrackData) Tracking(track, size float32) float32 {
+	if len(td.Tracks) == 0 || len(td.Sizes) == 0 {
+		return 0
+	}
+	i := sort.Search(len(td.Tracks), func(i int) bool { return td.Tracks[i].Value >= track })
+	switch {
+	case i == 0:
+		return td.Tracks[0].atSize(td.Sizes, size)
+	case i == len(td.Tracks):
+		return td.Tracks[i-1].atSize(td.Sizes, size)
+	}
+	t0, t1 := td.Tracks[i-1], td.Tracks[i]
+	v0, v1 := t0.atSize(td.Sizes, size), t1.atSize(td.Sizes, size)
+	return v0 + (v1-v0)*(track-t0.Value)/(t1.Value-t0.Value)
+}
+
+func (t Track) atSize(sizes []float32, size float32) float32 {
+	if len(sizes) == 1 {
+		return float32(t.PerSize[0])
+	}
+	// the two sizes used for interpolation
+	i := sort.Search(len(sizes), func(i int) bool { return sizes[i] >= size })
+	if i == 0 {
+		i = 1
+	} else if i == len(sizes) {
+		i = len(sizes) - 1
+	}
+	s0, s1 := sizes[i-1], sizes[i]
+	v0, v1 := float32(t.PerSize[i-1]), float32(t.PerSize[i])
+	if s1 == s0 {
+		return v0
+	}
+	return v0 + (v1-v0)*(size-s0)/(s1-s0)
+}
+
+func parseTableTrak(tag Tag, buf []byte) (Table, error) {
+	const headerSize = 12
+	if len(buf) < headerSize {
+		return nil, errInvalidTrakTable
+	}
+	out := &TableTrak{baseTable: baseTable(tag), bytes: buf}
+	var err error
+	if offset := int(be.Uint16(buf[6:])); offset != 0 {
+		if out.Horizontal, err = parseTrackData(buf, offset); err != nil {
+			return nil, err
+		}
+	}
+	if offset := int(be.Uint16(buf[8:])); offset != 0 {
+		if out.Vertical, err = parseTrackData(buf, offset); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// parseTrackData parses the data at buf[offset:]. The other
+// offsets are relative to the start of the table.
+func parseTrackData(buf []byte, offset int) (TrackData, error) {
+	const headerSize, entrySize = 8, 8
+	if len(buf) < offset+headerSize {
+		return TrackData{}, errInvalidTrakTable
+	}
+	nTracks, nSizes := int(be.Uint16(buf[offset:])), int(be.Uint16(buf[offset+2:]))
+	sizesOffset := int(be.Uint32(buf[offset+4:]))
+	if len(buf) < offset+headerSize+entrySize*nTracks || len(buf) < sizesOffset+4*nSizes {
+		return TrackData{}, errInvalidTrakTable
+	}
+
+	out := TrackData{Sizes: make([]float32, nSizes), Tracks: make([]Track, nTracks)}
+	for i := range out.Sizes {
+		out.Sizes[i] = fixedToFloat(be.Uint32(buf[sizesOffset+4*i:]))
+	}
+	for i := range out.Tracks {
+		entry := buf[offset+headerSize+entrySize*i:]
+		valuesOffset := int(be.Uint16(entry[6:]))
+		if len(buf) < valuesOffset+2*nSizes {
+			return TrackData{}, errInvalidTrakTable
+		}
+		track := Track{
+			Value:   fixedToFloat(be.Uint32(entry)),
+			Name:    NameID(be.Uint16(entry[4:])),
+			PerSize: make([]int16, nSizes),
+		}
+		for j := range track.PerSize {
+			track.PerSize[j] = int16(be.Uint16(buf[valuesOffset+2*j:]))
+		}
+		out.Tracks[i] = track
+	}
+	sort.SliceStable(out.Tracks, func(i, j int) bool { return out.Tracks[i].Value < out.Tracks[j].Value })
+	return out, nil
+}
+
+// TrakTable returns the tracking table.
+func (font *Font) TrakTable() (*TableTrak, error) {
+	t, err := font.Table(TagTrak)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableTrak), nil
+}
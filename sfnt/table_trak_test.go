@@ -0,0 +1,47 @@
+package sfnt
+
+import "testing"
+
+func TestTrak(t *testing.T) {
+	buf := []byte{
+		0, 1, 0, 0, 0, 0, 0, 12, 0, 0, 0, 0, // version, format, horizOffset, vertOffset, reserved
+		0, 2, 0, 2, 0, 0, 0, 36, // nTracks, nSizes, sizeTableOffset
+		0, 0, 0, 0, 1, 1, 0, 48, // normal track
+		0xFF, 0xFF, 0, 0, 1, 0, 0, 44, // tight track
+		0, 12, 0, 0, 0, 24, 0, 0, // sizes
+		0xFF, 0xF6, 0xFF, 0xEC, // tight values: -10, -20
+		0, 0, 0xFF, 0xFC, // normal values: 0, -4
+	}
+	table, err := parseTableTrak(TagTrak, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trak := table.(*TableTrak)
+	if len(trak.Vertical.Tracks) != 0 || trak.Vertical.Tracking(0, 12) != 0 {
+		t.Errorf("unexpected vertical data %v", trak.Vertical)
+	}
+	if tracks := trak.Horizontal.Tracks; len(tracks) != 2 || tracks[0].Value != -1 || tracks[0].Name != 256 {
+		t.Fatalf("unexpected tracks %v", tracks)
+	}
+	for _, test := range []struct {
+		track, size, expected float32
+	}{
+		{0, 12, 0},
+		{0, 24, -4},
+		{0, 18, -2},
+		{0, 36, -8}, // extrapolated
+		{-1, 12, -10},
+		{-1, 6, -5},
+		{-0.5, 12, -5},
+		{-2, 24, -20}, // clamped to the tightest track
+		{1, 24, -4},
+	} {
+		if got := trak.Horizontal.Tracking(test.track, test.size); got != test.expected {
+			t.Errorf("track %g at size %g: expected %g, got %g", test.track, test.size, test.expected, got)
+		}
+	}
+
+	if _, err := parseTableTrak(TagTrak, buf[:40]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
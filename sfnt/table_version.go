@@ -0,0 +1,116 @@
+package sfnt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errNoTableVersion      = errors.New("table has no version")
+	errInvalidTableVersion = errors.New("table too short for its version")
+)
+
+// TableVersion is the version (or the format) of a table.
+type TableVersion struct {
+	Major, Minor uint16
+}
+
+// String returns the version in the usual dotted form, such as "2.5".
+func (v TableVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// AtLeast returns true if the version is greater or equal to major.minor.
+func (v TableVersion) AtLeast(major, minor uint16) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+// the layouts of the version fields, at the start of the tables
+const (
+	versionMajorMinor = iota // uint16 major and minor versions, the most common layout
+	versionUint16            // single uint16 version
+	versionUint32            // single uint32 version
+	version16Dot16           // Version16Dot16, with the minor version in the high nibble
+	versionUint8Pair         // uint8 major and minor versions (CFF)
+	versionKern              // uint16 (Microsoft) or Fixed (Apple) version
+	versionNone              // tables without header
+)
+
+var tableVersionLayouts = map[Tag]uint8{
+	TagCmap: versionUint16,
+	TagOS2:  versionUint16,
+	TagName: versionUint16,
+	TagCOLR: versionUint16,
+	TagCPAL: versionUint16,
+	TagGasp: versionUint16,
+	TagHdmx: versionUint16,
+	TagLTSH: versionUint16,
+	TagVDMX: versionUint16,
+	TagSVG:  versionUint16,
+	TagSbix: versionUint16,
+	TagDSIG: versionUint32,
+	TagMeta: versionUint32,
+	TagMaxp: version16Dot16,
+	TagPost: version16Dot16,
+	TagVhea: version16Dot16,
+	TagCFF:  versionUint8Pair,
+	TagCFF2: versionUint8Pair,
+	TagKern: versionKern,
+	TagGlyf: versionNone,
+	TagLoca: versionNone,
+	TagCvt:  versionNone,
+	TagFpgm: versionNone,
+	TagPrep: versionNone,
+	TagHmtx: versionNone,
+	TagVmtx: versionNone,
+}
+
+// TableVersion returns the version of the table, read from its header
+// according to the layout defined by the specification, so that
+// compatibility checks (such as "OS/2 version < 4" or "post version 3")
+// may be done uniformly. The tables with a single version field
+// have a zero minor version.
+// An error is returned for the tables without version, such as 'glyf'.
+func (font *Font) TableVersion(tag Tag) (TableVersion, error) {
+	s, found := font.tables[tag]
+	if !found {
+		return TableVersion{}, ErrMissingTable
+	}
+	buf, err := font.findTableBuffer(s)
+	if err != nil {
+		return TableVersion{}, err
+	}
+	return parseTableVersion(tag, buf)
+}
+
+func parseTableVersion(tag Tag, buf []byte) (TableVersion, error) {
+	layout := tableVersionLayouts[tag]
+	if layout == versionNone {
+		return TableVersion{}, errNoTableVersion
+	}
+	if layout == versionKern && len(buf) >= 2 && be.Uint16(buf) != 0 {
+		layout = versionMajorMinor // Apple format, with a Fixed version
+	}
+
+	size := 4
+	switch layout {
+	case versionUint16, versionUint8Pair, versionKern:
+		size = 2
+	}
+	if len(buf) < size {
+		return TableVersion{}, errInvalidTableVersion
+	}
+
+	switch layout {
+	case versionUint16, versionKern:
+		return TableVersion{Major: be.Uint16(buf)}, nil
+	case versionUint32:
+		return TableVersion{Major: uint16(be.Uint32(buf))}, nil
+	case version16Dot16:
+		return TableVersion{Major: be.Uint16(buf), Minor: be.Uint16(buf[2:]) >> 12}, nil
+	case versionUint8Pair:
+		return TableVersion{Major: uint16(buf[0]), Minor: uint16(buf[1])}, nil
+	default:
+		return TableVersion{Major: be.Uint16(buf), Minor: be.Uint16(buf[2:])}, nil
+	}
+}
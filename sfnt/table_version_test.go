@@ -0,0 +1,59 @@
+package sfnt
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTableVersion(t *testing.T) {
+	f, err := os.Open("testdata/Raleway-v4020-Regular.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tag, expected := range map[Tag]TableVersion{
+		TagHead: {1, 0},
+		TagMaxp: {0, 5},
+		TagPost: {3, 0},
+		TagOS2:  {4, 0},
+		TagCFF:  {1, 0},
+		TagGpos: {1, 0},
+	} {
+		got, err := font.TableVersion(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("%s: expected version %s, got %s", tag, expected, got)
+		}
+	}
+
+	if _, err := font.TableVersion(TagGlyf); err != ErrMissingTable {
+		t.Errorf("expected missing table, got %v", err)
+	}
+	if _, err := parseTableVersion(TagHmtx, make([]byte, 8)); err != errNoTableVersion {
+		t.Errorf("expected no version, got %v", err)
+	}
+
+	// Microsoft and Apple kern tables
+	for _, test := range []struct {
+		buf      []byte
+		expected TableVersion
+	}{
+		{[]byte{0, 0, 0, 1}, TableVersion{0, 0}},
+		{[]byte{0, 1, 0, 0, 0, 0, 0, 1}, TableVersion{1, 0}},
+	} {
+		if got, _ := parseTableVersion(TagKern, test.buf); got != test.expected {
+			t.Errorf("kern: expected %s, got %s", test.expected, got)
+		}
+	}
+
+	if v := (TableVersion{2, 5}); !v.AtLeast(2, 0) || !v.AtLeast(1, 9) || v.AtLeast(3, 0) || v.AtLeast(2, 6) {
+		t.Errorf("invalid comparison for %s", v)
+	}
+}
@@ -0,0 +1,120 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errInvalidVmtxTable = errors.New("invalid vmtx table")
+
+// TableVhea is the vertical header table, which
+// is required by the vertical metrics ('vmtx') table.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/vhea
+type TableVhea struct {
+	baseTable
+	tableVheaFields
+}
+
+type tableVheaFields struct {
+	Version              fixed
+	VertTypoAscender     int16 // ascent in version 1.0
+	VertTypoDescender    int16 // descent in version 1.0
+	VertTypoLineGap      int16 // lineGap in version 1.0
+	AdvanceHeightMax     int16
+	MinTopSideBearing    int16
+	MinBottomSideBearing int16
+	YMaxExtent           int16
+	CaretSlopeRise       int16
+	CaretSlopeRun        int16
+	CaretOffset          int16
+	Reserved1            int16
+	Reserved2            int16
+	Reserved3            int16
+	Reserved4            int16
+	MetricDataFormat     int16
+	NumOfLongVerMetrics  uint16
+}
+
+func parseTableVhea(tag Tag, buf []byte) (Table, error) {
+	r := bytes.NewBuffer(buf)
+
+	var fields tableVheaFields
+	if err := binary.Read(r, binary.BigEndian, &fields); err != nil {
+		return nil, err
+	}
+	return &TableVhea{
+		baseTable:       baseTable(tag),
+		tableVheaFields: fields,
+	}, nil
+}
+
+// Bytes returns the byte representation of this header.
+func (table *TableVhea) Bytes() []byte {
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.BigEndian, table.tableVheaFields); err != nil {
+		panic(err) // should never happen
+	}
+	return buffer.Bytes()
+}
+
+// VheaTable returns the vertical header table.
+func (font *Font) VheaTable() (*TableVhea, error) {
+	t, err := font.Table(TagVhea)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableVhea), nil
+}
+
+// VMetric is the vertical metric of a glyph.
+type VMetric struct {
+	Advance uint16 // advance height
+	TSB     int16  // top side bearing
+}
+
+// parseVmtxMetrics returns the metrics of each glyph: the glyphs after
+// the numberOfVMetrics first ones share the last advance, and have their
+// own top side bearing.
+func parseVmtxMetrics(input []byte, numberOfVMetrics, numGlyphs int) ([]VMetric, error) {
+	if numberOfVMetrics == 0 || numberOfVMetrics > numGlyphs ||
+		len(input) < 4*numberOfVMetrics+2*(numGlyphs-numberOfVMetrics) {
+		return nil, errInvalidVmtxTable
+	}
+
+	out := make([]VMetric, numGlyphs)
+	for i := range out {
+		if i < numberOfVMetrics {
+			out[i] = VMetric{Advance: be.Uint16(input[4*i:]), TSB: int16(be.Uint16(input[4*i+2:]))}
+		} else {
+			out[i] = VMetric{
+				Advance: out[numberOfVMetrics-1].Advance,
+				TSB:     int16(be.Uint16(input[4*numberOfVMetrics+2*(i-numberOfVMetrics):])),
+			}
+		}
+	}
+	return out, nil
+}
+
+// VtmxTable returns the advance height and the top side bearing of each
+// glyph (array of size numGlyphs), as stored in the 'vmtx' table,
+// which is only present in fonts supporting vertical text.
+func (font *Font) VtmxTable() ([]VMetric, error) {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	vhea, err := font.VheaTable()
+	if err != nil {
+		return nil, err
+	}
+	section, found := font.tables[TagVmtx]
+	if !found {
+		return nil, ErrMissingTable
+	}
+	buf, err := font.findTableBuffer(section)
+	if err != nil {
+		return nil, err
+	}
+	return parseVmtxMetrics(buf, int(vhea.NumOfLongVerMetrics), int(numGlyphs))
+}
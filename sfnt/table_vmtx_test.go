@@ -0,0 +1,52 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVmtx(t *testing.T) {
+	vhea := make([]byte, 36)
+	copy(vhea, []byte{0, 1, 0x10, 0, 0x01, 0xF4, 0xFE, 0x0C}) // version 1.1, vertTypoAscender 500, vertTypoDescender -500
+	vhea[35] = 2                                              // numOfLongVerMetrics
+	vmtx := []byte{
+		0x03, 0xE8, 0, 100, // glyph 0: 1000, 100
+		0x04, 0x00, 0, 50, // glyph 1: 1024, 50
+		0xFF, 0xF6, // glyph 2: -10
+	}
+	maxp := []byte{0, 0, 0x50, 0, 0, 3} // version 0.5, 3 glyphs
+
+	font := New(TypeTrueType)
+	font.AddTable(TagMaxp, NewTable(TagMaxp, maxp))
+	table, err := ParseTable(TagVhea, vhea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagVhea, table)
+	font.AddTable(TagVmtx, NewTable(TagVmtx, vmtx))
+
+	header, err := font.VheaTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.VertTypoAscender != 500 || header.VertTypoDescender != -500 || header.NumOfLongVerMetrics != 2 {
+		t.Errorf("unexpected header %v", header.tableVheaFields)
+	}
+	if got := header.Bytes(); !reflect.DeepEqual(got, vhea) {
+		t.Errorf("expected %v, got %v", vhea, got)
+	}
+
+	metrics, err := font.VtmxTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []VMetric{{1000, 100}, {1024, 50}, {1024, -10}}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("expected %v, got %v", expected, metrics)
+	}
+
+	font.AddTable(TagVmtx, NewTable(TagVmtx, vmtx[:9]))
+	if _, err := font.VtmxTable(); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
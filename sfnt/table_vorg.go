@@ -0,0 +1,101 @@
+package sfnt
+
+import "sort"
+
+// TableVORG is the vertical origin table, which stores the y coordinate
+// of the vertical origin of the glyphs of CFF fonts.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/vorg
+type TableVORG struct {
+	baseTable
+
+	bytes []byte
+
+	// Default is used for the glyphs not found in Metrics.
+	Default int16
+	// Metrics are sorted by increasing Glyph.
+	Metrics []VertOriginMetric
+}
+
+// VertOriginMetric is the vertical origin of one glyph.
+type VertOriginMetric struct {
+	Glyph GlyphIndex
+	Y     int16
+}
+
+// Bytes returns the bytes for this table. The TableVORG is read only, so
+// the bytes will always be the same as what is read in.
+func (t *TableVORG) Bytes() []byte {
+	return t.bytes
+}
+
+// VertOriginY returns the y coordinate of the vertical origin of
+// the glyph, in font units.
+func (t *TableVORG) VertOriginY(gi GlyphIndex) int16 {
+	i := sort.Search(len(t.Metrics), func(i int) bool { return t.Metrics[i].Glyph >= gi })
+	if i < len(t.Metrics) && t.Metrics[i].Glyph == gi {
+		return t.Metrics[i].Y
+	}
+	return t.Default
+}
+
+func parseTableVORG(tag Tag, buf []byte) (Table, error) {
+	r := NewTableReader(tag, buf)
+	r.Skip(4) // majorVersion, minorVersion
+	defaultY := r.Int16()
+	num := int(r.Uint16())
+	records := r.Bytes(4 * num)
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	metrics := make([]VertOriginMetric, num)
+	for i := range metrics {
+		metrics[i] = VertOriginMetric{Glyph: GlyphIndex(be.Uint16(records[4*i:])), Y: int16(be.Uint16(records[4*i+2:]))}
+	}
+	// the spec requires the records to be sorted, but be lenient
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].Glyph < metrics[j].Glyph })
+
+	return &TableVORG{
+		baseTable: baseTable(tag),
+		bytes:     buf,
+		Default:   defaultY,
+		Metrics:   metrics,
+	}, nil
+}
+
+// VORGTable returns the vertical origin table.
+func (font *Font) VORGTable() (*TableVORG, error) {
+	t, err := font.Table(TagVORG)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*TableVORG), nil
+}
+
+// GlyphVerticalOrigin returns the y coordinate, in font units, of the
+// vertical origin of the glyph, that is the point aligned on the vertical
+// line of text, which is usually the top of the em box.
+// It is read from the 'VORG' table when present (in CFF fonts). Otherwise,
+// the ascender is used as a fallback: the typographic ascender of the
+// 'OS/2' table if any, or the ascent of the 'hhea' table.
+func (font *Font) GlyphVerticalOrigin(gi GlyphIndex) (int16, error) {
+	if font.HasTable(TagVORG) {
+		vorg, err := font.VORGTable()
+		if err != nil {
+			return 0, err
+		}
+		return vorg.VertOriginY(gi), nil
+	}
+	if font.HasTable(TagOS2) {
+		os2, err := font.OS2Table()
+		if err != nil {
+			return 0, err
+		}
+		return os2.STypoAscender, nil
+	}
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return 0, err
+	}
+	return hhea.Ascent, nil
+}
@@ -0,0 +1,47 @@
+package sfnt
+
+import "testing"
+
+func TestVORG(t *testing.T) {
+	vorg := []byte{
+		0, 1, 0, 0, // version
+		0x03, 0x70, 0, 2, // defaultVertOriginY: 880, numVertOriginYMetrics
+		0, 3, 0x03, 0x52, // glyph 3: 850
+		0, 7, 0x03, 0x84, // glyph 7: 900
+	}
+	font := New(TypeOpenType)
+	table, err := ParseTable(TagVORG, vorg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagVORG, table)
+
+	for gi, expected := range map[GlyphIndex]int16{0: 880, 3: 850, 5: 880, 7: 900} {
+		y, err := font.GlyphVerticalOrigin(gi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y != expected {
+			t.Errorf("glyph %d: expected %d, got %d", gi, expected, y)
+		}
+	}
+
+	if _, err := ParseTable(TagVORG, vorg[:12]); err == nil {
+		t.Error("expected error for truncated table")
+	}
+}
+
+func TestVerticalOriginFallback(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	os2, err := font.OS2Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := font.GlyphVerticalOrigin(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y != os2.STypoAscender {
+		t.Errorf("expected the typographic ascender %d, got %d", os2.STypoAscender, y)
+	}
+}
@@ -24,10 +24,99 @@ var (
 	TagGpos = MustNamedTag("GPOS")
 	// TagGsub represents the 'GSUB' table, which contains Glyph Substitution features
 	TagGsub = MustNamedTag("GSUB")
+	// TagFvar represents the 'fvar' table, which contains the axes of a variable font
+	TagFvar = MustNamedTag("fvar")
+	// TagDSIG represents the 'DSIG' table, which contains the digital signature of the font
+	TagDSIG = MustNamedTag("DSIG")
+	// TagGvar represents the 'gvar' table, which contains the variations of TrueType outlines
+	TagGvar = MustNamedTag("gvar")
+	// TagAvar represents the 'avar' table, which contains the axis variations of a variable font
+	TagAvar = MustNamedTag("avar")
+	// TagHvar represents the 'HVAR' table, which contains the variations of the horizontal metrics
+	TagHvar = MustNamedTag("HVAR")
+	// TagMvar represents the 'MVAR' table, which contains the variations of the global metrics
+	TagMvar = MustNamedTag("MVAR")
+	// TagSTAT represents the 'STAT' table, which contains the style attributes of a font family
+	TagSTAT = MustNamedTag("STAT")
+	// TagCFF represents the 'CFF ' table, which contains PostScript outlines
+	TagCFF = MustNamedTag("CFF ")
+	// TagBASE represents the 'BASE' table, which contains the baselines of the scripts
+	TagBASE = MustNamedTag("BASE")
+	// TagCOLR represents the 'COLR' table, which contains the layers of the color glyphs
+	TagCOLR = MustNamedTag("COLR")
+	// TagCPAL represents the 'CPAL' table, which contains the color palettes
+	TagCPAL = MustNamedTag("CPAL")
+	// TagFeat represents the 'Feat' table, which contains the Graphite features
+	TagFeat = MustNamedTag("Feat")
+	// TagSill represents the 'Sill' table, which contains the Graphite feature values of the languages
+	TagSill = MustNamedTag("Sill")
+	// TagTrak represents the 'trak' table, which contains the AAT tracking
+	TagTrak = MustNamedTag("trak")
+	// TagAATFeat represents the 'feat' table, which contains the AAT feature names
+	TagAATFeat = MustNamedTag("feat")
 
-	tagCmap = MustNamedTag("cmap") // not exported since not part of the Table API
-	tagKern = MustNamedTag("kern") // not exported since not part of the Table API
-	tagPost = MustNamedTag("post") // not exported since not part of the Table API
+	// TagCmap represents the 'cmap' table, which contains the character to glyph mapping
+	TagCmap = MustNamedTag("cmap")
+	// TagKern represents the 'kern' table, which contains the legacy kerning
+	TagKern = MustNamedTag("kern")
+	// TagPost represents the 'post' table, which contains PostScript information
+	TagPost = MustNamedTag("post")
+	// TagGlyf represents the 'glyf' table, which contains TrueType outlines
+	TagGlyf = MustNamedTag("glyf")
+	// TagLoca represents the 'loca' table, which contains the offsets of the TrueType outlines
+	TagLoca = MustNamedTag("loca")
+	// TagCvt represents the 'cvt ' table, which contains the TrueType control values
+	TagCvt = MustNamedTag("cvt ")
+	// TagFpgm represents the 'fpgm' table, which contains the TrueType font program
+	TagFpgm = MustNamedTag("fpgm")
+	// TagPrep represents the 'prep' table, which contains the TrueType control value program
+	TagPrep = MustNamedTag("prep")
+	// TagGasp represents the 'gasp' table, which contains the grid-fitting and scan-conversion procedures
+	TagGasp = MustNamedTag("gasp")
+	// TagCFF2 represents the 'CFF2' table, which contains variable PostScript outlines
+	TagCFF2 = MustNamedTag("CFF2")
+	// TagVORG represents the 'VORG' table, which contains the vertical origins of PostScript outlines
+	TagVORG = MustNamedTag("VORG")
+	// TagSVG represents the 'SVG ' table, which contains SVG outlines
+	TagSVG = MustNamedTag("SVG ")
+	// TagEBDT represents the 'EBDT' table, which contains embedded bitmaps
+	TagEBDT = MustNamedTag("EBDT")
+	// TagEBLC represents the 'EBLC' table, which contains the locations of the embedded bitmaps
+	TagEBLC = MustNamedTag("EBLC")
+	// TagEBSC represents the 'EBSC' table, which contains the scaling of the embedded bitmaps
+	TagEBSC = MustNamedTag("EBSC")
+	// TagCBDT represents the 'CBDT' table, which contains color bitmaps
+	TagCBDT = MustNamedTag("CBDT")
+	// TagCBLC represents the 'CBLC' table, which contains the locations of the color bitmaps
+	TagCBLC = MustNamedTag("CBLC")
+	// TagSbix represents the 'sbix' table, which contains the standard bitmap graphics
+	TagSbix = MustNamedTag("sbix")
+	// TagGDEF represents the 'GDEF' table, which contains the glyph definitions
+	TagGDEF = MustNamedTag("GDEF")
+	// TagJSTF represents the 'JSTF' table, which contains the justification data
+	TagJSTF = MustNamedTag("JSTF")
+	// TagMATH represents the 'MATH' table, which contains the math layout data
+	TagMATH = MustNamedTag("MATH")
+	// TagCvar represents the 'cvar' table, which contains the variations of the control values
+	TagCvar = MustNamedTag("cvar")
+	// TagVvar represents the 'VVAR' table, which contains the variations of the vertical metrics
+	TagVvar = MustNamedTag("VVAR")
+	// TagHdmx represents the 'hdmx' table, which contains the horizontal device metrics
+	TagHdmx = MustNamedTag("hdmx")
+	// TagLTSH represents the 'LTSH' table, which contains the linear threshold data
+	TagLTSH = MustNamedTag("LTSH")
+	// TagMERG represents the 'MERG' table, which contains the merge classes
+	TagMERG = MustNamedTag("MERG")
+	// TagMeta represents the 'meta' table, which contains metadata
+	TagMeta = MustNamedTag("meta")
+	// TagPCLT represents the 'PCLT' table, which contains the PCL 5 data
+	TagPCLT = MustNamedTag("PCLT")
+	// TagVDMX represents the 'VDMX' table, which contains the vertical device metrics
+	TagVDMX = MustNamedTag("VDMX")
+	// TagVhea represents the 'vhea' table, which contains the vertical header
+	TagVhea = MustNamedTag("vhea")
+	// TagVmtx represents the 'vmtx' table, which contains the vertical metrics
+	TagVmtx = MustNamedTag("vmtx")
 
 	// TypeTrueType is the first four bytes of an OpenType file containing a TrueType font
 	TypeTrueType = Tag{0x00010000}
@@ -45,6 +134,9 @@ var (
 
 	// SignatureWOFF2 is the magic number at the start of a WOFF2 file.
 	SignatureWOFF2 = MustNamedTag("wOF2")
+
+	// SignatureTTC is the magic number at the start of a TrueType Collection file.
+	SignatureTTC = MustNamedTag("ttcf")
 )
 
 // Tag represents an open-type table name.
@@ -79,6 +171,41 @@ func MustNamedTag(str string) Tag {
 	return t
 }
 
+// ParseTag returns the Tag corresponding to the acronym, which must be
+// made of 4 printable ASCII characters, space padded, as required by the
+// OpenType specification. Use NamedTag to build tags from arbitrary bytes.
+func ParseTag(str string) (Tag, error) {
+	tag, err := NamedTag(str)
+	if err != nil {
+		return Tag{}, fmt.Errorf("invalid tag %q: must be exactly 4 bytes", str)
+	}
+	if !tag.IsValid() {
+		return Tag{}, fmt.Errorf("invalid tag %q: must be printable ASCII, with trailing spaces only", str)
+	}
+	return tag, nil
+}
+
+// IsValid returns true if the tag is made of printable ASCII characters
+// (0x20 to 0x7E), without leading nor embedded spaces.
+func (tag Tag) IsValid() bool {
+	b := tag.bytes()
+	if b[0] == ' ' {
+		return false
+	}
+	padding := false
+	for _, c := range b {
+		if c < 0x20 || c > 0x7E {
+			return false
+		}
+		if c == ' ' {
+			padding = true
+		} else if padding {
+			return false
+		}
+	}
+	return true
+}
+
 func NewTag(bytes []byte) Tag {
 	return Tag{Number: binary.BigEndian.Uint32(bytes)}
 }
@@ -106,3 +233,32 @@ func (tag Tag) bytes() []byte {
 func (tag Tag) hex() string {
 	return "0x" + hex.EncodeToString(tag.bytes())
 }
+
+// MarshalText implements encoding.TextMarshaler, so that tags are
+// written as strings in JSON. The tags which are not valid (such as
+// TypeTrueType) are written in hexadecimal form.
+func (tag Tag) MarshalText() ([]byte, error) {
+	if !tag.IsValid() {
+		return []byte(tag.hex()), nil
+	}
+	return tag.bytes(), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting
+// the output of MarshalText.
+func (tag *Tag) UnmarshalText(text []byte) error {
+	if len(text) == 10 && text[0] == '0' && text[1] == 'x' {
+		var b [4]byte
+		if _, err := hex.Decode(b[:], text[2:]); err != nil {
+			return fmt.Errorf("invalid tag %q: %s", text, err)
+		}
+		*tag = NewTag(b[:])
+		return nil
+	}
+	t, err := ParseTag(string(text))
+	if err != nil {
+		return err
+	}
+	*tag = t
+	return nil
+}
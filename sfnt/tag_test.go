@@ -1,6 +1,10 @@
 package sfnt
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
 
 func TestParsedTag(t *testing.T) {
 	tag := MustNamedTag("head")
@@ -41,3 +45,44 @@ func TestTagEquality(t *testing.T) {
 		t.Errorf("equality failed %v %v", MustNamedTag("true"), t1)
 	}
 }
+
+func TestParseTag(t *testing.T) {
+	for _, str := range []string{"head", "OS/2", "cvt ", "SVG "} {
+		tag, err := ParseTag(str)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", str, err)
+		}
+		if tag.String() != str {
+			t.Errorf("expected %q, got %q", str, tag)
+		}
+	}
+	for _, str := range []string{"", "SVG", "heads", " cvt", "c vt", "ab\x00c", "abcé"} {
+		if _, err := ParseTag(str); err == nil {
+			t.Errorf("%q: expected error", str)
+		}
+	}
+}
+
+func TestTagMarshalJSON(t *testing.T) {
+	input := map[Tag]Tag{TagHead: TagCvt, TagOS2: TypeTrueType}
+	b, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"OS/2":"0x00010000","head":"cvt "}`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	var output map[Tag]Tag
+	if err := json.Unmarshal(b, &output); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(input, output) {
+		t.Errorf("expected %v, got %v", input, output)
+	}
+
+	var tag Tag
+	if err := json.Unmarshal([]byte(`"he\u0000d"`), &tag); err == nil {
+		t.Error("expected error for invalid tag")
+	}
+}
@@ -0,0 +1,47 @@
+package sfnt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatUnicodeRange returns the CSS unicode-range value (such as
+// "U+0020-007E, U+00E9") describing the given runes, such as the runes
+// of a subset, which may be unsorted and contain duplicates. Consecutive runes are merged
+// into ranges. It returns an empty string if there is no rune.
+func FormatUnicodeRange(runes []rune) string {
+	sorted := append([]rune(nil), runes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var items []string
+	for i := 0; i < len(sorted); {
+		start, end := sorted[i], sorted[i]
+		for i++; i < len(sorted) && sorted[i] <= end+1; i++ {
+			end = sorted[i]
+		}
+		if start == end {
+			items = append(items, fmt.Sprintf("U+%04X", start))
+		} else {
+			items = append(items, fmt.Sprintf("U+%04X-%04X", start, end))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// CSSUnicodeRange returns the CSS unicode-range value of the runes mapped
+// by the 'cmap' table (see FormatUnicodeRange), which is useful when
+// generating the @font-face rules of subsetted web fonts.
+func (font *Font) CSSUnicodeRange() (string, error) {
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return "", err
+	}
+	var runes []rune
+	for r, gi := range cmap.Compile() {
+		if gi != 0 {
+			runes = append(runes, r)
+		}
+	}
+	return FormatUnicodeRange(runes), nil
+}
@@ -0,0 +1,30 @@
+package sfnt
+
+import "testing"
+
+func TestFormatUnicodeRange(t *testing.T) {
+	for _, test := range []struct {
+		runes    []rune
+		expected string
+	}{
+		{nil, ""},
+		{[]rune{'a'}, "U+0061"},
+		{[]rune{'c', 'a', 'b', 'b', 'é', 0x1F600, 0x1F601}, "U+0061-0063, U+00E9, U+1F600-1F601"},
+	} {
+		if got := FormatUnicodeRange(test.runes); got != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.runes, test.expected, got)
+		}
+	}
+
+	font := New(TypeTrueType)
+	font.AddTable(TagCmap, NewTable(TagCmap, BuildCmap(map[rune]GlyphIndex{
+		' ': 1, '!': 2, 'A': 3, 'B': 0, 'C': 4,
+	})))
+	got, err := font.CSSUnicodeRange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "U+0020-0021, U+0041, U+0043"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
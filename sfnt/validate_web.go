@@ -0,0 +1,166 @@
+package sfnt
+
+import "fmt"
+
+const (
+	headMagicNumber = 0x5F0F3CF5
+	// maxWebFontSize is the size above which the
+	// browsers reject the fonts (30 MiB)
+	maxWebFontSize = 30 << 20
+	maxWebTables   = 4096
+)
+
+// webTables are the tables kept by the OpenType Sanitizer used by
+// Chrome and Firefox. The other tables are dropped.
+var webTables = map[Tag]bool{
+	TagCmap: true, TagHead: true, TagHhea: true, TagHmtx: true, TagMaxp: true,
+	TagName: true, TagOS2: true, TagPost: true,
+	TagGlyf: true, TagLoca: true, TagCvt: true, TagFpgm: true, TagPrep: true,
+	TagCFF: true, TagCFF2: true, TagVORG: true,
+	TagGasp: true, TagHdmx: true, TagKern: true, TagLTSH: true, TagVDMX: true,
+	TagVhea: true, TagVmtx: true,
+	TagGDEF: true, TagGpos: true, TagGsub: true, TagBASE: true, TagMATH: true,
+	TagAvar: true, TagCvar: true, TagFvar: true, TagGvar: true,
+	TagHvar: true, TagMvar: true, TagVvar: true, TagSTAT: true,
+	TagCBDT: true, TagCBLC: true, TagCOLR: true, TagCPAL: true, TagSbix: true,
+	// Graphite
+	TagFeat: true, TagSill: true, MustNamedTag("Silf"): true, MustNamedTag("Glat"): true, MustNamedTag("Gloc"): true,
+}
+
+// webRequiredTables are the tables without which the fonts are rejected.
+var webRequiredTables = [...]Tag{TagCmap, TagHead, TagHhea, TagHmtx, TagMaxp, TagName, TagOS2, TagPost}
+
+// WebValidation is the result of ValidateForWeb.
+type WebValidation struct {
+	// Rejections are the problems making the browsers reject the font.
+	Rejections []string
+	// Dropped are the tables removed by the sanitizer, because they are
+	// unknown or invalid, the font being still accepted.
+	Dropped []Tag
+}
+
+// Accepted returns true if the font is expected to be loaded by the browsers.
+func (v *WebValidation) Accepted() bool { return len(v.Rejections) == 0 }
+
+// ValidateForWeb mirrors the main checks of the OpenType Sanitizer (OTS),
+// which validates the web fonts in Chrome and Firefox, to predict whether
+// they will reject the font: the sfnt version, the number and total size
+// of the tables, the required tables and outlines, and the consistency of
+// the 'head', 'maxp', 'hhea' and 'hmtx' tables.
+// The tables unknown to OTS, and the optional tables which can't be
+// parsed, are reported as dropped.
+// The detailed validation of the outlines and of the layout tables
+// performed by OTS is not replicated.
+func (font *Font) ValidateForWeb() *WebValidation {
+	var out WebValidation
+	reject := func(format string, args ...interface{}) {
+		out.Rejections = append(out.Rejections, fmt.Sprintf(format, args...))
+	}
+
+	switch font.scalerType {
+	case TypeTrueType, TypeOpenType, TypeAppleTrueType:
+	default:
+		reject("unsupported sfnt version %q", font.scalerType)
+	}
+
+	tags := font.Tags()
+	if len(tags) == 0 || len(tags) >= maxWebTables {
+		reject("invalid number of tables %d", len(tags))
+	}
+	var size int
+	for _, tag := range tags {
+		buf, err := font.findTableBuffer(font.tables[tag])
+		if err != nil {
+			reject("the %q table can't be read: %s", tag, err)
+			continue
+		}
+		size += len(buf)
+		if !webTables[tag] {
+			out.Dropped = append(out.Dropped, tag)
+			continue
+		}
+		// the optional tables are dropped if invalid
+		if _, parsed := parsers[tag]; parsed && !isRequiredForWeb(tag) {
+			if _, err := font.Table(tag); err != nil {
+				out.Dropped = append(out.Dropped, tag)
+			}
+		}
+	}
+	if size > maxWebFontSize {
+		reject("the tables total %d bytes, more than the %d bytes limit", size, maxWebFontSize)
+	}
+
+	for _, tag := range webRequiredTables {
+		if !font.HasTable(tag) {
+			reject("missing required %q table", tag)
+		} else if _, err := font.Table(tag); err != nil {
+			reject("invalid %q table: %s", tag, err)
+		}
+	}
+	hasGlyf, hasLoca := font.HasTable(TagGlyf), font.HasTable(TagLoca)
+	switch {
+	case hasGlyf != hasLoca:
+		reject("the 'glyf' and 'loca' tables must be both present")
+	case !hasGlyf && !font.HasTable(TagCFF) && !font.HasTable(TagCFF2):
+		reject("no 'glyf', 'CFF ' or 'CFF2' outlines")
+	}
+
+	if out.Accepted() {
+		font.validateWebMetrics(reject)
+	}
+	return &out
+}
+
+func isRequiredForWeb(tag Tag) bool {
+	for _, required := range webRequiredTables {
+		if tag == required {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebMetrics checks the fields of the required tables rejected by OTS.
+func (font *Font) validateWebMetrics(reject func(format string, args ...interface{})) {
+	head, err := font.HeadTable()
+	if err != nil {
+		reject("invalid 'head' table: %s", err)
+		return
+	}
+	if head.MagicNumber != headMagicNumber {
+		reject("invalid 'head' magic number %#x", head.MagicNumber)
+	}
+	if head.UnitsPerEm < 16 || head.UnitsPerEm > 16384 {
+		reject("invalid unitsPerEm %d, outside of [16, 16384]", head.UnitsPerEm)
+	}
+	if font.HasTable(TagGlyf) && head.IndexToLocFormat != 0 && head.IndexToLocFormat != 1 {
+		reject("invalid indexToLocFormat %d", head.IndexToLocFormat)
+	}
+
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		reject("invalid 'maxp' table: %s", err)
+		return
+	}
+	if numGlyphs == 0 {
+		reject("the font has no glyph")
+	}
+	hhea, err := font.HheaTable()
+	if err != nil {
+		reject("invalid 'hhea' table: %s", err)
+		return
+	}
+	numberOfHMetrics := int(hhea.NumOfLongHorMetrics)
+	if numberOfHMetrics < 1 || numberOfHMetrics > int(numGlyphs) {
+		reject("invalid numberOfHMetrics %d, with %d glyphs", numberOfHMetrics, numGlyphs)
+		return
+	}
+	hmtx, err := font.findTableBuffer(font.tables[TagHmtx])
+	if err != nil {
+		reject("the 'hmtx' table can't be read: %s", err)
+		return
+	}
+	if expected := 4*numberOfHMetrics + 2*(int(numGlyphs)-numberOfHMetrics); len(hmtx) < expected {
+		reject("the 'hmtx' table is truncated: %d bytes instead of %d", len(hmtx), expected)
+	}
+}
@@ -0,0 +1,49 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateForWeb(t *testing.T) {
+	for _, test := range []struct {
+		file    string
+		dropped []Tag
+	}{
+		{"Roboto-BoldItalic.ttf", nil},
+		{"Raleway-v4020-Regular.otf", nil},
+		{"FreeSerif.ttf", []Tag{MustNamedTag("FFTM")}},
+	} {
+		validation := loadTestFont(t, test.file).ValidateForWeb()
+		if !validation.Accepted() || !reflect.DeepEqual(validation.Dropped, test.dropped) {
+			t.Errorf("%s: unexpected validation %v %v", test.file, validation.Rejections, validation.Dropped)
+		}
+	}
+
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	font.RemoveTable(TagPost)
+	font.RemoveTable(TagLoca)
+	font.AddTable(MustNamedTag("TEST"), NewTable(MustNamedTag("TEST"), []byte{1, 2}))
+	validation := font.ValidateForWeb()
+	expected := []string{"missing required \"post\" table", "the 'glyf' and 'loca' tables must be both present"}
+	if !reflect.DeepEqual(validation.Rejections, expected) {
+		t.Errorf("expected rejections %q, got %q", expected, validation.Rejections)
+	}
+	if !reflect.DeepEqual(validation.Dropped, []Tag{MustNamedTag("TEST")}) {
+		t.Errorf("unexpected dropped tables %v", validation.Dropped)
+	}
+
+	font = loadTestFont(t, "Raleway-v4020-Regular.otf")
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := *head
+	modified.UnitsPerEm = 8
+	font.AddTable(TagHead, &modified)
+	validation = font.ValidateForWeb()
+	expected = []string{"invalid unitsPerEm 8, outside of [16, 16384]"}
+	if !reflect.DeepEqual(validation.Rejections, expected) {
+		t.Errorf("expected rejections %q, got %q", expected, validation.Rejections)
+	}
+}
@@ -0,0 +1,241 @@
+package sfnt
+
+import "errors"
+
+var errInvalidVariationStore = errors.New("invalid item variation store")
+
+// variationRegion is a region of the design space,
+// defined by a [start, peak, end] triplet for each axis.
+type variationRegion [][3]float32
+
+// scalar returns the contribution of the region at the given
+// normalized coordinates.
+func (r variationRegion) scalar(coords []float32) float32 {
+	out := float32(1)
+	for i, axis := range r {
+		start, peak, end := axis[0], axis[1], axis[2]
+		if start > peak || peak > end || peak == 0 || (start < 0 && end > 0) {
+			continue // the axis is ignored
+		}
+		var coord float32
+		if i < len(coords) {
+			coord = coords[i]
+		}
+		switch {
+		case coord == peak:
+			continue
+		case coord <= start || coord >= end:
+			return 0
+		case coord < peak:
+			out *= (coord - start) / (peak - start)
+		default:
+			out *= (end - coord) / (end - peak)
+		}
+	}
+	return out
+}
+
+// itemVariationData stores the deltas of a set of items,
+// for a subset of the regions.
+type itemVariationData struct {
+	regionIndexes []uint16
+	deltas        [][]int32 // one delta per region for each item
+}
+
+// itemVariationStore stores the deltas of values varying
+// with the coordinates, such as advances or metrics.
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/otvarcommonformats#item-variation-store
+type itemVariationStore struct {
+	regions []variationRegion
+	data    []itemVariationData
+}
+
+// parseItemVariationStore parses the store starting at buf[offset:].
+func parseItemVariationStore(buf []byte, offset int) (itemVariationStore, error) {
+	if len(buf) < offset+8 {
+		return itemVariationStore{}, errInvalidVariationStore
+	}
+	regionListOffset := offset + int(be.Uint32(buf[offset+2:]))
+	dataCount := int(be.Uint16(buf[offset+6:]))
+	if len(buf) < offset+8+4*dataCount {
+		return itemVariationStore{}, errInvalidVariationStore
+	}
+
+	var (
+		out itemVariationStore
+		err error
+	)
+	out.regions, err = parseVariationRegions(buf, regionListOffset)
+	if err != nil {
+		return out, err
+	}
+	out.data = make([]itemVariationData, dataCount)
+	for i := range out.data {
+		dataOffset := offset + int(be.Uint32(buf[offset+8+4*i:]))
+		out.data[i], err = parseItemVariationData(buf, dataOffset, len(out.regions))
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func parseVariationRegions(buf []byte, offset int) ([]variationRegion, error) {
+	if len(buf) < offset+4 {
+		return nil, errInvalidVariationStore
+	}
+	axisCount := int(be.Uint16(buf[offset:]))
+	regionCount := int(be.Uint16(buf[offset+2:]))
+	offset += 4
+	if len(buf) < offset+6*axisCount*regionCount {
+		return nil, errInvalidVariationStore
+	}
+	out := make([]variationRegion, regionCount)
+	for i := range out {
+		region := make(variationRegion, axisCount)
+		for j := range region {
+			for k := range region[j] {
+				region[j][k] = f2dot14ToFloat(be.Uint16(buf[offset:]))
+				offset += 2
+			}
+		}
+		out[i] = region
+	}
+	return out, nil
+}
+
+func parseItemVariationData(buf []byte, offset int, regionCount int) (itemVariationData, error) {
+	if len(buf) < offset+6 {
+		return itemVariationData{}, errInvalidVariationStore
+	}
+	itemCount := int(be.Uint16(buf[offset:]))
+	wordDeltaCount := int(be.Uint16(buf[offset+2:]))
+	regionIndexCount := int(be.Uint16(buf[offset+4:]))
+	offset += 6
+
+	// with long words, the deltas are stored as int32 and int16,
+	// instead of int16 and int8
+	longWords := wordDeltaCount&0x8000 != 0
+	wordDeltaCount &= 0x7FFF
+	wordSize := 2
+	if longWords {
+		wordSize = 4
+	}
+	if wordDeltaCount > regionIndexCount {
+		return itemVariationData{}, errInvalidVariationStore
+	}
+	rowSize := wordSize*wordDeltaCount + wordSize/2*(regionIndexCount-wordDeltaCount)
+	if len(buf) < offset+2*regionIndexCount+rowSize*itemCount {
+		return itemVariationData{}, errInvalidVariationStore
+	}
+
+	out := itemVariationData{
+		regionIndexes: make([]uint16, regionIndexCount),
+		deltas:        make([][]int32, itemCount),
+	}
+	for i := range out.regionIndexes {
+		out.regionIndexes[i] = be.Uint16(buf[offset:])
+		if int(out.regionIndexes[i]) >= regionCount {
+			return itemVariationData{}, errInvalidVariationStore
+		}
+		offset += 2
+	}
+	for i := range out.deltas {
+		row := make([]int32, regionIndexCount)
+		for j := range row {
+			size := wordSize
+			if j >= wordDeltaCount {
+				size = wordSize / 2
+			}
+			switch size {
+			case 1:
+				row[j] = int32(int8(buf[offset]))
+			case 2:
+				row[j] = int32(int16(be.Uint16(buf[offset:])))
+			case 4:
+				row[j] = int32(be.Uint32(buf[offset:]))
+			}
+			offset += size
+		}
+		out.deltas[i] = row
+	}
+	return out, nil
+}
+
+// delta returns the adjustment of the item (outer, inner) at the
+// given normalized coordinates.
+func (store itemVariationStore) delta(outer, inner uint16, coords []float32) float32 {
+	if int(outer) >= len(store.data) {
+		return 0
+	}
+	data := store.data[outer]
+	if int(inner) >= len(data.deltas) {
+		return 0
+	}
+	var out float32
+	for i, delta := range data.deltas[inner] {
+		if delta == 0 {
+			continue
+		}
+		out += float32(delta) * store.regions[data.regionIndexes[i]].scalar(coords)
+	}
+	return out
+}
+
+// deltaSetMapping maps glyphs (or other items) to
+// (outer, inner) indices in an item variation store.
+type deltaSetMapping [][2]uint16
+
+// parseDeltaSetMapping parses the DeltaSetIndexMap starting at buf[offset:].
+func parseDeltaSetMapping(buf []byte, offset int) (deltaSetMapping, error) {
+	if len(buf) < offset+4 {
+		return nil, errInvalidVariationStore
+	}
+	format, entryFormat := buf[offset], buf[offset+1]
+	var mapCount int
+	switch format {
+	case 0:
+		mapCount = int(be.Uint16(buf[offset+2:]))
+		offset += 4
+	case 1:
+		if len(buf) < offset+6 {
+			return nil, errInvalidVariationStore
+		}
+		mapCount = int(be.Uint32(buf[offset+2:]))
+		offset += 6
+	default:
+		return nil, errInvalidVariationStore
+	}
+
+	innerBitCount := uint(entryFormat&0x0F) + 1
+	entrySize := int(entryFormat&0x30)>>4 + 1
+	if len(buf) < offset+entrySize*mapCount {
+		return nil, errInvalidVariationStore
+	}
+	out := make(deltaSetMapping, mapCount)
+	for i := range out {
+		var entry uint32
+		for _, b := range buf[offset : offset+entrySize] {
+			entry = entry<<8 | uint32(b)
+		}
+		offset += entrySize
+		out[i] = [2]uint16{uint16(entry >> innerBitCount), uint16(entry & (1<<innerBitCount - 1))}
+	}
+	return out, nil
+}
+
+// index returns the (outer, inner) indices of the item.
+// Without mapping, the item is used as inner index.
+func (m deltaSetMapping) index(item int) (uint16, uint16) {
+	if m == nil {
+		return 0, uint16(item)
+	}
+	if len(m) == 0 {
+		return 0, 0
+	}
+	// the last entry is used for items out of range
+	if item >= len(m) {
+		item = len(m) - 1
+	}
+	return m[item][0], m[item][1]
+}
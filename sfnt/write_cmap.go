@@ -0,0 +1,265 @@
+package sfnt
+
+import "sort"
+
+// sizes of the cmap subtables
+const (
+	cmapRecordSize   = 8
+	cmapHeaderSize   = 4
+	cmap4HeaderSize  = 14
+	cmap12HeaderSize = 16
+	cmap12GroupSize  = 12
+	cmap4SegmentSize = 8
+	cmap4ReservedPad = 2 // between endCode and startCode
+)
+
+// cmapSegment maps the runes [start, end] to the consecutive
+// glyphs starting at startGlyph.
+type cmapSegment struct {
+	start, end rune
+	startGlyph GlyphIndex
+}
+
+// cmapSegments groups the runes mapped to consecutive glyphs.
+func cmapSegments(chars map[rune]GlyphIndex) []cmapSegment {
+	runes := make([]rune, 0, len(chars))
+	for r := range chars {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	var out []cmapSegment
+	for _, r := range runes {
+		gi := chars[r]
+		if n := len(out); n != 0 {
+			last := &out[n-1]
+			if last.end+1 == r && rune(last.startGlyph)+(r-last.start) == rune(gi) {
+				last.end = r
+				continue
+			}
+		}
+		out = append(out, cmapSegment{start: r, end: r, startGlyph: gi})
+	}
+	return out
+}
+
+// BuildCmap compiles the mapping from runes to glyphs to a 'cmap' table,
+// using a format 4 subtable for the BMP, whose segmentation minimizes its size.
+// If some runes are outside the BMP, or if the BMP runes don't fit
+// in a format 4 subtable, which is then truncated, a format 12 subtable
+// for the full repertoire is added, for the Windows UCS-4 encoding.
+func BuildCmap(chars map[rune]GlyphIndex) []byte {
+	segs := cmapSegments(chars)
+
+	var bmp []cmapSegment
+	needsFull := false
+	for _, seg := range segs {
+		if seg.start > 0xFFFF {
+			needsFull = true
+			continue
+		}
+		if seg.end > 0xFFFF {
+			needsFull = true
+			seg.end = 0xFFFE
+		}
+		if seg.end == 0xFFFF { // reserved by the final segment
+			seg.end = 0xFFFE
+		}
+		if seg.start <= seg.end {
+			bmp = append(bmp, seg)
+		}
+	}
+
+	cmap4, complete := buildCmap4(bmp)
+	subtables := [][]byte{cmap4}
+	encodings := []uint16{psidWindowsUCS2}
+	if needsFull || !complete {
+		subtables = append(subtables, buildCmap12(segs))
+		encodings = append(encodings, psidWindowsUCS4)
+	}
+
+	offset := cmapHeaderSize + cmapRecordSize*len(subtables)
+	out := make([]byte, offset)
+	be.PutUint16(out[2:], uint16(len(subtables)))
+	for i, subtable := range subtables {
+		record := out[cmapHeaderSize+cmapRecordSize*i:]
+		be.PutUint16(record, pidWindows)
+		be.PutUint16(record[2:], encodings[i])
+		be.PutUint32(record[4:], uint32(offset))
+		offset += len(subtable)
+		out = append(out, subtable...)
+	}
+	return out
+}
+
+// cmap4Segment is a segment of a format 4 subtable. If glyphs is nil,
+// the runes are mapped using idDelta arithmetic, otherwise
+// glyphs stores the glyph of each rune, in the glyphIdArray.
+type cmap4Segment struct {
+	start, end rune
+	delta      uint16
+	glyphs     []GlyphIndex
+}
+
+// segmentCmap4 returns the segmentation of a format 4 subtable
+// minimizing its size.
+// Each segment costs 8 bytes, and each rune stored in the glyphIdArray
+// costs 2 bytes: it is thus cheaper to store short runs of consecutive
+// glyphs (and small gaps between them) in the glyphIdArray of a larger segment.
+// The delta segments are consecutive runes with consecutive glyphs,
+// as returned by cmapSegments.
+func segmentCmap4(segs []cmapSegment) []cmap4Segment {
+	if len(segs) == 0 {
+		return nil
+	}
+
+	// costs[i][mode] is the minimum size of the segmentation of segs[:i+1],
+	// where segs[i] uses idDelta (mode 0) or ends an array segment (mode 1)
+	const (
+		modeDelta = iota
+		modeArray
+	)
+	costs := make([][2]int, len(segs))
+	// previous[i][mode] is the mode chosen for segs[i-1]
+	previous := make([][2]int, len(segs))
+	// continues[i] is true if segs[i] extends the array segment of segs[i-1]
+	continues := make([]bool, len(segs))
+
+	costs[0] = [2]int{cmap4SegmentSize, cmap4SegmentSize + 2*int(segs[0].end-segs[0].start+1)}
+	for i := 1; i < len(segs); i++ {
+		seg := segs[i]
+		length := int(seg.end - seg.start + 1)
+		gap := int(seg.start - segs[i-1].end - 1)
+
+		best := modeDelta
+		if costs[i-1][modeArray] < costs[i-1][modeDelta] {
+			best = modeArray
+		}
+		costs[i][modeDelta] = costs[i-1][best] + cmap4SegmentSize
+		previous[i][modeDelta] = best
+
+		costs[i][modeArray] = costs[i-1][best] + cmap4SegmentSize + 2*length
+		previous[i][modeArray] = best
+		// the gap is filled with .notdef
+		if extended := costs[i-1][modeArray] + 2*(gap+length); extended < costs[i][modeArray] {
+			costs[i][modeArray] = extended
+			previous[i][modeArray] = modeArray
+			continues[i] = true
+		}
+	}
+
+	// backtrack the modes
+	modes := make([]int, len(segs))
+	last := len(segs) - 1
+	if costs[last][modeArray] < costs[last][modeDelta] {
+		modes[last] = modeArray
+	}
+	for i := last; i > 0; i-- {
+		modes[i-1] = previous[i][modes[i]]
+	}
+
+	var out []cmap4Segment
+	for i, seg := range segs {
+		if modes[i] == modeDelta {
+			out = append(out, cmap4Segment{start: seg.start, end: seg.end, delta: uint16(seg.startGlyph) - uint16(seg.start)})
+			continue
+		}
+		if !continues[i] {
+			out = append(out, cmap4Segment{start: seg.start, end: seg.start - 1, glyphs: []GlyphIndex{}})
+		}
+		current := &out[len(out)-1]
+		for r := current.end + 1; r < seg.start; r++ {
+			current.glyphs = append(current.glyphs, 0)
+		}
+		for r := seg.start; r <= seg.end; r++ {
+			current.glyphs = append(current.glyphs, seg.startGlyph+GlyphIndex(r-seg.start))
+		}
+		current.end = seg.end
+	}
+	return out
+}
+
+// cmap4MaxSegments is the number of delta segments fitting in
+// a format 4 subtable, whose length is stored in 16 bits.
+const cmap4MaxSegments = (0xFFFF - cmap4HeaderSize - cmap4ReservedPad) / cmap4SegmentSize
+
+// buildCmap4 returns a format 4 subtable for the BMP segments, and false
+// if it is truncated, since too many segments don't fit in 64 KiB.
+func buildCmap4(segs []cmapSegment) ([]byte, bool) {
+	optimized := segmentCmap4(segs)
+	arrayLength := 0
+	for _, seg := range optimized {
+		arrayLength += len(seg.glyphs)
+	}
+	// idRangeOffset must fit in 16 bits: use deltas only
+	// for very large subtables
+	complete := true
+	if 2*(len(optimized)+1+arrayLength) > 0xFFFF {
+		optimized = optimized[:0]
+		for _, seg := range segs {
+			optimized = append(optimized, cmap4Segment{start: seg.start, end: seg.end, delta: uint16(seg.startGlyph) - uint16(seg.start)})
+		}
+		arrayLength = 0
+		// the length must also fit in 16 bits: only the
+		// first segments are kept
+		if len(optimized) > cmap4MaxSegments-1 {
+			optimized = optimized[:cmap4MaxSegments-1]
+			complete = false
+		}
+	}
+	// maps 0xFFFF to glyph 0
+	optimized = append(optimized, cmap4Segment{start: 0xFFFF, end: 0xFFFF, delta: 1})
+	segCount := len(optimized)
+
+	length := cmap4HeaderSize + cmap4ReservedPad + cmap4SegmentSize*segCount + 2*arrayLength
+	out := make([]byte, length)
+	be.PutUint16(out, 4)
+	be.PutUint16(out[2:], uint16(length))
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= segCount {
+		entrySelector++
+	}
+	searchRange := 2 * (1 << entrySelector)
+	be.PutUint16(out[6:], uint16(2*segCount))
+	be.PutUint16(out[8:], uint16(searchRange))
+	be.PutUint16(out[10:], uint16(entrySelector))
+	be.PutUint16(out[12:], uint16(2*segCount-searchRange))
+
+	endCodes := out[cmap4HeaderSize:]
+	startCodes := endCodes[2*segCount+cmap4ReservedPad:]
+	deltas := startCodes[2*segCount:]
+	rangeOffsets := deltas[2*segCount:]
+	glyphIDs := rangeOffsets[2*segCount:]
+	arrayIndex := 0
+	for i, seg := range optimized {
+		be.PutUint16(endCodes[2*i:], uint16(seg.end))
+		be.PutUint16(startCodes[2*i:], uint16(seg.start))
+		be.PutUint16(deltas[2*i:], seg.delta)
+		if seg.glyphs == nil {
+			continue
+		}
+		// offset from the idRangeOffset entry to the glyph
+		be.PutUint16(rangeOffsets[2*i:], uint16(2*(segCount-i+arrayIndex)))
+		for _, gi := range seg.glyphs {
+			be.PutUint16(glyphIDs[2*arrayIndex:], uint16(gi))
+			arrayIndex++
+		}
+	}
+	return out, complete
+}
+
+func buildCmap12(segs []cmapSegment) []byte {
+	length := cmap12HeaderSize + cmap12GroupSize*len(segs)
+	out := make([]byte, length)
+	be.PutUint16(out, 12)
+	be.PutUint32(out[4:], uint32(length))
+	be.PutUint32(out[12:], uint32(len(segs)))
+	for i, seg := range segs {
+		group := out[cmap12HeaderSize+cmap12GroupSize*i:]
+		be.PutUint32(group, uint32(seg.start))
+		be.PutUint32(group[4:], uint32(seg.end))
+		be.PutUint32(group[8:], uint32(seg.startGlyph))
+	}
+	return out
+}
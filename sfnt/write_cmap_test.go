@@ -0,0 +1,96 @@
+package sfnt
+
+import "testing"
+
+func TestBuildCmap(t *testing.T) {
+	chars := map[rune]GlyphIndex{
+		'a': 1, 'b': 2, 'c': 3, 'e': 4, 'f': 10,
+		0xFFFF: 11, 0x1F600: 12, 0x1F601: 13,
+	}
+	segs := cmapSegments(chars)
+	if len(segs) != 5 {
+		t.Errorf("expected 5 segments, got %v", segs)
+	}
+
+	cmap, err := parseTableCmap(BuildCmap(chars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, gi := range chars {
+		if got := cmap.Lookup(r); got != gi {
+			t.Errorf("rune %x: expected %d, got %d", r, gi, got)
+		}
+	}
+}
+
+func TestSegmentCmap4(t *testing.T) {
+	// shuffled glyphs, with a long run and a small gap
+	chars := map[rune]GlyphIndex{
+		'a': 5, 'b': 2, 'c': 9, 'd': 1, 'f': 7, 'g': 3,
+	}
+	for r := rune(0x400); r < 0x420; r++ {
+		chars[r] = GlyphIndex(r - 0x400 + 20)
+	}
+
+	segs := segmentCmap4(cmapSegments(chars))
+	if len(segs) != 2 {
+		t.Fatalf("expected 2 segments, got %v", segs)
+	}
+	if segs[0].start != 'a' || segs[0].end != 'g' || len(segs[0].glyphs) != 7 || segs[0].glyphs[4] != 0 {
+		t.Errorf("unexpected array segment %v", segs[0])
+	}
+	if segs[1].glyphs != nil {
+		t.Errorf("expected delta segment, got %v", segs[1])
+	}
+
+	// 'a'-'g' array (8+14 bytes), delta segment and final segment
+	table, complete := buildCmap4(cmapSegments(chars))
+	if !complete {
+		t.Error("unexpected truncated subtable")
+	}
+	if exp := cmap4HeaderSize + cmap4ReservedPad + 3*cmap4SegmentSize + 14; len(table) != exp {
+		t.Errorf("expected %d bytes, got %d", exp, len(table))
+	}
+
+	cmap, err := parseTableCmap(BuildCmap(chars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, gi := range chars {
+		if got := cmap.Lookup(r); got != gi {
+			t.Errorf("rune %x: expected %d, got %d", r, gi, got)
+		}
+	}
+	if cmap.Lookup('e') != 0 {
+		t.Error("unexpected glyph for 'e'")
+	}
+}
+
+func TestBuildCmapLarge(t *testing.T) {
+	// scattered runes, which don't fit in a format 4 subtable
+	chars := make(map[rune]GlyphIndex)
+	for i := 0; i < 27000; i++ {
+		chars[rune(0x20+2*i)] = GlyphIndex(i + 1)
+	}
+	table, complete := buildCmap4(cmapSegments(chars))
+	if complete {
+		t.Error("expected a truncated subtable")
+	}
+	if length := int(be.Uint16(table[2:])); length != len(table) {
+		t.Errorf("invalid length %d for %d bytes", length, len(table))
+	}
+
+	buf := BuildCmap(chars)
+	if numTables := be.Uint16(buf[2:]); numTables != 2 {
+		t.Fatalf("expected 2 subtables, got %d", numTables)
+	}
+	cmap, err := parseTableCmap(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, gi := range chars {
+		if got := cmap.Lookup(r); got != gi {
+			t.Fatalf("rune %x: expected %d, got %d", r, gi, got)
+		}
+	}
+}
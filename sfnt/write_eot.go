@@ -0,0 +1,112 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	eotVersion        = 0x00020001
+	eotDefaultCharset = 1
+	fsSelectionItalic = 1 << 0
+)
+
+// eotHeader is the fixed part of an EOT header, version 0x00020001.
+// See https://www.w3.org/Submission/EOT/
+type eotHeader struct {
+	EOTSize            uint32
+	FontDataSize       uint32
+	Version            uint32
+	Flags              uint32
+	FontPANOSE         [10]byte
+	Charset            byte
+	Italic             byte
+	Weight             uint32
+	FsType             uint16
+	MagicNumber        uint16
+	UnicodeRange       [4]uint32
+	CodePageRange      [2]uint32
+	CheckSumAdjustment uint32
+	Reserved           [4]uint32
+}
+
+// WriteEOT serializes a Font into the Embedded OpenType format, required
+// by old versions of Internet Explorer. The font data is neither compressed
+// nor obfuscated. The names stored in the header are read from the 'name'
+// table, and the 'OS/2' table is required.
+// The root strings are the URLs of the pages allowed to use the font, which
+// may be empty to not restrict it.
+func (font *Font) WriteEOT(w io.Writer, rootStrings []string) (n int, err error) {
+	layout, err := font.layoutOTF(WriteOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var data bytes.Buffer
+	if _, err := layout.writeTo(&data); err != nil {
+		return 0, err
+	}
+	os2, err := font.OS2Table()
+	if err != nil {
+		return 0, err
+	}
+	var names *TableName
+	if font.HasTable(TagName) {
+		if names, err = font.NameTable(); err != nil {
+			return 0, err
+		}
+	}
+
+	header := eotHeader{
+		FontDataSize:       uint32(data.Len()),
+		Version:            eotVersion,
+		FontPANOSE:         os2.Panose,
+		Charset:            eotDefaultCharset,
+		Weight:             uint32(os2.USWeightClass),
+		FsType:             os2.FSType,
+		MagicNumber:        eotMagicNumber,
+		UnicodeRange:       os2.UlCharRange,
+		CodePageRange:      [2]uint32{os2.UlCodePageRange1, os2.UlCodePageRange2},
+		CheckSumAdjustment: layout.head.CheckSumAdjustment,
+	}
+	if os2.FsSelection&fsSelectionItalic != 0 {
+		header.Italic = 1
+	}
+
+	// the variable length fields, each one preceded by a padding
+	var fields bytes.Buffer
+	for _, id := range [...]NameID{NameFontFamily, NameFontSubfamily, NameVersion, NameFull} {
+		var value string
+		if names != nil {
+			if entry := names.Lookup(id); entry != nil {
+				value = entry.String()
+			}
+		}
+		writeEOTString(&fields, value)
+	}
+	// the URLs are separated and terminated by a null character
+	var rootString string
+	if len(rootStrings) != 0 {
+		rootString = strings.Join(rootStrings, "\x00") + "\x00"
+	}
+	writeEOTString(&fields, rootString)
+
+	header.EOTSize = uint32(binary.Size(header) + fields.Len() + data.Len())
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, header)
+	out.Write(fields.Bytes())
+	out.Write(data.Bytes())
+	return w.Write(out.Bytes())
+}
+
+// writeEOTString writes a padding, the size and the
+// UTF-16 (little endian) encoding of s.
+func writeEOTString(w *bytes.Buffer, s string) {
+	units := utf16.Encode([]rune(s))
+	binary.Write(w, binary.LittleEndian, uint16(0))
+	binary.Write(w, binary.LittleEndian, uint16(2*len(units)))
+	binary.Write(w, binary.LittleEndian, units)
+}
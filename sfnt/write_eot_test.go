@@ -0,0 +1,57 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestWriteEOT(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	var buf bytes.Buffer
+	if _, err := font.WriteEOT(&buf, []string{"https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	eot := buf.Bytes()
+
+	if format, err := DetectFormat(bytes.NewReader(eot)); err != nil || format != FormatEOT {
+		t.Fatalf("expected EOT format, got %s (%v)", format, err)
+	}
+	var header eotHeader
+	if err := binary.Read(bytes.NewReader(eot), binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if int(header.EOTSize) != len(eot) || header.Italic != 1 || header.Weight != 700 {
+		t.Errorf("unexpected header %v", header)
+	}
+
+	// read the variable length fields
+	fields := eot[binary.Size(header):]
+	var strs []string
+	for i := 0; i < 5; i++ {
+		size := int(binary.LittleEndian.Uint16(fields[2:]))
+		units := make([]uint16, size/2)
+		binary.Read(bytes.NewReader(fields[4:4+size]), binary.LittleEndian, units)
+		strs = append(strs, string(utf16.Decode(units)))
+		fields = fields[4+size:]
+	}
+	if strs[0] != "Roboto" || strs[1] != "Bold Italic" || strs[4] != "https://example.com\x00" {
+		t.Errorf("unexpected names %q", strs)
+	}
+
+	data, err := Parse(bytes.NewReader(fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != int(header.FontDataSize) || !data.HasTable(TagGlyf) {
+		t.Error("invalid font data")
+	}
+	head, err := data.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.CheckSumAdjustment != header.CheckSumAdjustment {
+		t.Errorf("expected the checksum adjustment 0x%08X, got 0x%08X", head.CheckSumAdjustment, header.CheckSumAdjustment)
+	}
+}
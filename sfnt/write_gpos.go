@@ -0,0 +1,226 @@
+package sfnt
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errPairPosOverflow = errors.New("too many kerning pairs for one PairPos subtable")
+
+// BuildPairPosFormat2 compiles a list of kerning pairs, mapping [left, right]
+// glyphs to their kerning value (expressed in glyph units), to a GPOS
+// Pair Adjustment Positioning subtable using the class based format 2.
+// The classes are computed by grouping the glyphs sharing the same kerning values,
+// and the values are stored as X_ADVANCE adjustments of the first glyph.
+// An error is returned if the subtable does not fit in 64KB: the
+// pairs should then be split across several subtables.
+func BuildPairPosFormat2(pairs map[[2]GlyphIndex]int16) ([]byte, error) {
+	// rows[left][right] = value, ignoring null values
+	rows := map[GlyphIndex]map[GlyphIndex]int16{}
+	columns := map[GlyphIndex]map[GlyphIndex]int16{}
+	for pair, value := range pairs {
+		if value == 0 {
+			continue
+		}
+		left, right := pair[0], pair[1]
+		if rows[left] == nil {
+			rows[left] = map[GlyphIndex]int16{}
+		}
+		rows[left][right] = value
+		if columns[right] == nil {
+			columns[right] = map[GlyphIndex]int16{}
+		}
+		columns[right][left] = value
+	}
+
+	// the default class 0 of the second glyphs is used for the
+	// glyphs with no kerning, so that the classes start at 1
+	classDef2, numClass2 := groupGlyphs(columns, 1)
+	// the rows only depend on the classes of the second glyphs
+	classRows := make(map[GlyphIndex]map[GlyphIndex]int16, len(rows))
+	for left, row := range rows {
+		classRow := map[GlyphIndex]int16{}
+		for right, value := range row {
+			classRow[GlyphIndex(classDef2[right])] = value
+		}
+		classRows[left] = classRow
+	}
+	// all the first glyphs are covered, so that the class 0 may be used
+	classDef1, numClass1 := groupGlyphs(classRows, 0)
+
+	matrix := make([]int16, numClass1*numClass2)
+	for left, row := range classRows {
+		for class2, value := range row {
+			matrix[classDef1[left]*numClass2+int(class2)] = value
+		}
+	}
+
+	lefts := make([]GlyphIndex, 0, len(rows))
+	for left := range rows {
+		lefts = append(lefts, left)
+	}
+	sort.Slice(lefts, func(i, j int) bool { return lefts[i] < lefts[j] })
+
+	// class 0 is implicit
+	for gi, class := range classDef1 {
+		if class == 0 {
+			delete(classDef1, gi)
+		}
+	}
+
+	const headerSize = 16
+	out := make([]byte, headerSize, headerSize+2*len(matrix))
+	be.PutUint16(out, 2)        // posFormat
+	be.PutUint16(out[4:], 0x04) // valueFormat1: X_ADVANCE
+	be.PutUint16(out[6:], 0)    // valueFormat2
+	be.PutUint16(out[12:], uint16(numClass1))
+	be.PutUint16(out[14:], uint16(numClass2))
+	for _, value := range matrix {
+		out = append(out, byte(uint16(value)>>8), byte(value))
+	}
+
+	for _, sub := range [...]struct {
+		offsetPosition int
+		data           []byte
+	}{
+		{2, buildCoverage(lefts)},
+		{8, buildClassDef(classDef1)},
+		{10, buildClassDef(classDef2)},
+	} {
+		if len(out) > 0xFFFF {
+			return nil, errPairPosOverflow
+		}
+		be.PutUint16(out[sub.offsetPosition:], uint16(len(out)))
+		out = append(out, sub.data...)
+	}
+
+	return out, nil
+}
+
+// groupGlyphs assigns the same class to the glyphs with the same values,
+// starting at firstClass. It returns the classes and the number of
+// classes (including the ones before firstClass).
+func groupGlyphs(values map[GlyphIndex]map[GlyphIndex]int16, firstClass int) (map[GlyphIndex]int, int) {
+	glyphs := make([]GlyphIndex, 0, len(values))
+	for gi := range values {
+		glyphs = append(glyphs, gi)
+	}
+	// process the glyphs in order, for deterministic classes
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	classes := map[string]int{}
+	out := make(map[GlyphIndex]int, len(glyphs))
+	for _, gi := range glyphs {
+		key := valuesKey(values[gi])
+		class, ok := classes[key]
+		if !ok {
+			class = firstClass + len(classes)
+			classes[key] = class
+		}
+		out[gi] = class
+	}
+	return out, firstClass + len(classes)
+}
+
+// valuesKey returns a canonical representation of a set of values.
+func valuesKey(values map[GlyphIndex]int16) string {
+	keys := make([]GlyphIndex, 0, len(values))
+	for gi := range values {
+		keys = append(keys, gi)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var b strings.Builder
+	for _, gi := range keys {
+		b.WriteString(strconv.Itoa(int(gi)))
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(int(values[gi])))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// buildCoverage returns a Coverage table for the given sorted glyphs,
+// choosing the smallest format.
+func buildCoverage(glyphs []GlyphIndex) []byte {
+	var ranges []coverageRange
+	for i, gi := range glyphs {
+		if n := len(ranges); n != 0 && ranges[n-1].end+1 == gi {
+			ranges[n-1].end = gi
+			continue
+		}
+		ranges = append(ranges, coverageRange{start: gi, end: gi, startCoverage: i})
+	}
+
+	if 6*len(ranges) < 2*len(glyphs) {
+		out := make([]byte, 4+6*len(ranges))
+		be.PutUint16(out, 2)
+		be.PutUint16(out[2:], uint16(len(ranges)))
+		for i, rang := range ranges {
+			be.PutUint16(out[4+6*i:], uint16(rang.start))
+			be.PutUint16(out[4+6*i+2:], uint16(rang.end))
+			be.PutUint16(out[4+6*i+4:], uint16(rang.startCoverage))
+		}
+		return out
+	}
+
+	out := make([]byte, 4+2*len(glyphs))
+	be.PutUint16(out, 1)
+	be.PutUint16(out[2:], uint16(len(glyphs)))
+	for i, gi := range glyphs {
+		be.PutUint16(out[4+2*i:], uint16(gi))
+	}
+	return out
+}
+
+// buildClassDef returns a Class Definition table,
+// choosing the smallest format.
+func buildClassDef(classes map[GlyphIndex]int) []byte {
+	glyphs := make([]GlyphIndex, 0, len(classes))
+	for gi := range classes {
+		glyphs = append(glyphs, gi)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+
+	var ranges class2
+	for _, gi := range glyphs {
+		if n := len(ranges); n != 0 && ranges[n-1].end+1 == gi && ranges[n-1].targetClassID == classes[gi] {
+			ranges[n-1].end = gi
+			continue
+		}
+		ranges = append(ranges, classRangeRecord{start: gi, end: gi, targetClassID: classes[gi]})
+	}
+
+	var format1Size int
+	if len(glyphs) != 0 {
+		format1Size = 6 + 2*(int(glyphs[len(glyphs)-1])-int(glyphs[0])+1)
+	} else {
+		format1Size = 6
+	}
+
+	if format1Size <= 4+6*len(ranges) {
+		out := make([]byte, format1Size)
+		be.PutUint16(out, 1)
+		if len(glyphs) != 0 {
+			start := glyphs[0]
+			be.PutUint16(out[2:], uint16(start))
+			be.PutUint16(out[4:], uint16(glyphs[len(glyphs)-1]-start+1))
+			for _, gi := range glyphs {
+				be.PutUint16(out[6+2*int(gi-start):], uint16(classes[gi]))
+			}
+		}
+		return out
+	}
+
+	out := make([]byte, 4+6*len(ranges))
+	be.PutUint16(out, 2)
+	be.PutUint16(out[2:], uint16(len(ranges)))
+	for i, rang := range ranges {
+		be.PutUint16(out[4+6*i:], uint16(rang.start))
+		be.PutUint16(out[4+6*i+2:], uint16(rang.end))
+		be.PutUint16(out[4+6*i+4:], uint16(rang.targetClassID))
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package sfnt
+
+import (
+	"testing"
+)
+
+func TestBuildPairPosFormat2(t *testing.T) {
+	pairs := map[[2]GlyphIndex]int16{
+		{1, 10}: -50, {1, 11}: -50, {1, 12}: 20,
+		{2, 10}: -50, {2, 11}: -50, {2, 12}: 20,
+		{3, 10}:   5,
+		{300, 11}: -7,
+		{4, 10}:   0, // ignored
+	}
+
+	subtable, err := BuildPairPosFormat2(pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cov, err := fetchCoverage(subtable, int(be.Uint16(subtable[2:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kerns, err := parsePairPosFormat2(subtable, cov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kerns.numClass2 != 4 { // 0, {10}, {11}, {12}
+		t.Errorf("expected 4 classes, got %d", kerns.numClass2)
+	}
+	if len(kerns.kerns) != 3*4 { // {1, 2}, {3}, {300}
+		t.Errorf("expected 3 first classes, got %d", len(kerns.kerns)/kerns.numClass2)
+	}
+	// only the kerning values are stored for X_ADVANCE subtables
+	if kerns.records != nil {
+		t.Error("unexpected value records")
+	}
+	if adjustment, ok := kerns.PairAdjustment(1, 12); !ok || adjustment != (PairAdjustment{{XAdvance: 20}}) {
+		t.Errorf("unexpected adjustment %v", adjustment)
+	}
+
+	for pair, exp := range pairs {
+		got, _ := kerns.KernPair(pair[0], pair[1])
+		if got != exp {
+			t.Errorf("pair %v: expected %d, got %d", pair, exp, got)
+		}
+	}
+	for _, pair := range [][2]GlyphIndex{{1, 13}, {5, 10}, {3, 11}} {
+		if got, _ := kerns.KernPair(pair[0], pair[1]); got != 0 {
+			t.Errorf("pair %v: expected no kerning, got %d", pair, got)
+		}
+	}
+}
+
+func TestBuildCoverageClassDef(t *testing.T) {
+	for _, glyphs := range [][]GlyphIndex{
+		{1, 2, 3, 4, 5, 6, 10},
+		{1, 200, 400},
+		{},
+	} {
+		cov, err := fetchCoverage(buildCoverage(glyphs), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes := map[GlyphIndex]int{}
+		for i, gi := range glyphs {
+			if index, ok := cov.tableIndex(gi); !ok || index != i {
+				t.Errorf("invalid coverage index for %d", gi)
+			}
+			classes[gi] = i % 2
+		}
+
+		class, err := fetchClassLookup(buildClassDef(classes), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for gi, exp := range classes {
+			if got := class.glyphClassID(gi); got != exp {
+				t.Errorf("glyph %d: expected class %d, got %d", gi, exp, got)
+			}
+		}
+	}
+}
@@ -21,108 +21,270 @@ var outputOrder = map[Tag]int{
 // You can also use this to write to files called *.ttf if the
 // font contains TrueType glyphs.
 func (font *Font) WriteOTF(w io.Writer) (n int, err error) {
+	m, err := font.WriteTo(w)
+	return int(m), err
+}
 
-	todo := font.Tags()
-	sort.Slice(todo, func(i, j int) bool {
-		iScore, ok := outputOrder[todo[i]]
-		if !ok {
-			iScore = int(todo[i].Number)
-		}
-		jScore, ok := outputOrder[todo[j]]
-		if !ok {
-			jScore = int(todo[j].Number)
-		}
+// WriteTo serializes a Font into a valid sfnt file (.otf or .ttf).
+// The table directory is rebuilt from the current tables of the font,
+// each table is padded to a 4-byte boundary and the per-table and
+// whole font checksums are recomputed. The font itself is not modified,
+// so that the fonts of a Collection sharing their tables may be written
+// concurrently: see RecomputeChecksums to update its 'head' table.
+// It implements io.WriterTo.
+func (font *Font) WriteTo(w io.Writer) (n int64, err error) {
+	return font.WriteWithOptions(w, WriteOptions{})
+}
 
-		return iScore < jScore
-	})
+// WriteOptions controls how a font is serialized by WriteWithOptions.
+type WriteOptions struct {
+	// Deterministic guarantees that identical fonts are written
+	// as identical bytes, so that builds embedding fonts are reproducible:
+	// the modification date of the 'head' table is zeroed and the
+	// 'name' records are sorted by platform, encoding, language and name ID,
+	// regardless of the order they were added in.
+	// The tables are always written in a fixed order.
+	// As in the default mode, the font itself is left untouched: its
+	// 'head' table keeps its modification date.
+	Deterministic bool
 
-	headTable, err := font.HeadTable()
+	// MonospaceAdvance, if not zero, is written as the advance of every
+	// glyph with a non zero advance, and the font is marked as monospaced
+	// in its 'post' and 'OS/2' tables (see IsMonospace).
+	// The font itself is not modified.
+	MonospaceAdvance uint16
+}
+
+// WriteWithOptions is the same as WriteTo, with the given options.
+func (font *Font) WriteWithOptions(w io.Writer, opts WriteOptions) (n int64, err error) {
+	layout, err := font.layoutOTF(opts)
 	if err != nil {
 		return n, err
 	}
+	return layout.writeTo(w)
+}
 
-	headTable.ClearExpectedChecksum()
-
-	header := newOTFHeader(font.scalerType, uint16(len(todo)))
-
-	fragments := make([][]byte, len(todo))
-
-	offset := otfHeaderLength + directoryEntryLength*len(todo)
-	checksum := header.checkSum()
-
-	err = binary.Write(w, binary.BigEndian, header)
+// writeTo writes the header, the table directory and the tables.
+func (layout otfLayout) writeTo(w io.Writer) (n int64, err error) {
+	err = binary.Write(w, binary.BigEndian, layout.header)
 	if err != nil {
 		return n, err
 	}
 	n += otfHeaderLength
 
-	for i, tag := range todo {
-		t, err := font.Table(tag)
+	for _, tag := range layout.tags {
+		err = binary.Write(w, binary.BigEndian, layout.entries[tag])
 		if err != nil {
 			return n, err
 		}
-		fragments[i] = t.Bytes()
-		entry := directoryEntry{
-			Tag:      tag,
-			CheckSum: checkSum(fragments[i]),
-			Offset:   uint32(offset),
-			Length:   uint32(len(fragments[i])),
-		}
+		n += directoryEntryLength
+	}
 
-		offset += len(fragments[i])
-		if len(fragments[i])%4 != 0 {
-			offset += 4 - (len(fragments[i]) % 4)
+	for _, tag := range layout.todo {
+		fragment := layout.fragments[tag]
+		if tag == TagHead {
+			fragment = layout.head.Bytes()
+		}
 
+		m, err := w.Write(fragment)
+		n += int64(m)
+		if err != nil {
+			return n, err
 		}
-		checksum += entry.CheckSum + entry.checkSum()
 
-		err = binary.Write(w, binary.BigEndian, entry)
+		m, err = w.Write(make([]byte, padding(len(fragment))))
+		n += int64(m)
 		if err != nil {
 			return n, err
 		}
-		n += directoryEntryLength
 	}
 
-	for i, tag := range todo {
+	return n, nil
+}
 
-		var fragment []byte
+// otfLayout is the content of a font serialized as an sfnt file.
+type otfLayout struct {
+	header    *otfHeader
+	head      *TableHead // with the updated checkSumAdjustment
+	tags      []Tag      // the directory order, sorted by tag
+	todo      []Tag      // the output order of the tables
+	fragments map[Tag][]byte
+	entries   map[Tag]directoryEntry
+}
 
-		if tag == TagHead {
-			headTable.SetExpectedChecksum(checksum)
-			fragment = headTable.Bytes()
-			headTable.SetExpectedChecksum(0)
-		} else {
-			fragment = fragments[i]
-		}
+// layoutOTF computes the table directory of the font, and the
+// checkSumAdjustment field of a copy of its 'head' table, which
+// may be shared with other fonts of a Collection.
+func (font *Font) layoutOTF(opts WriteOptions) (otfLayout, error) {
+	// the table directory must be sorted by tag...
+	tags := font.Tags()
 
-		m, err := w.Write(fragment)
-		n += m
+	// ... but the tables themselves are laid out in the recommended order
+	todo := sortOutputOrder(tags)
+
+	headTable, err := font.HeadTable()
+	if err != nil {
+		return otfLayout{}, err
+	}
+	copied := *headTable
+	headTable = &copied
+	if opts.Deterministic {
+		headTable.Updated = longdatetime{}
+	}
+
+	// the checksums are computed with a zero checkSumAdjustment
+	headTable.ClearExpectedChecksum()
+
+	var overrides map[Tag][]byte
+	if opts.MonospaceAdvance != 0 {
+		overrides, err = font.monospaceTables(opts.MonospaceAdvance)
 		if err != nil {
-			return n, err
+			return otfLayout{}, err
 		}
+	}
 
-		var extra []byte
+	layout := otfLayout{
+		header:    newOTFHeader(font.scalerType, uint16(len(tags))),
+		head:      headTable,
+		tags:      tags,
+		todo:      todo,
+		fragments: make(map[Tag][]byte, len(todo)),
+		entries:   make(map[Tag]directoryEntry, len(todo)),
+	}
 
-		if len(fragment)%4 == 1 {
-			extra = []byte{0, 0, 0}
+	offset := otfHeaderLength + directoryEntryLength*len(todo)
+	checksum := layout.header.checkSum()
 
-		} else if len(fragment)%4 == 2 {
-			extra = []byte{0, 0}
-		} else if len(fragment)%4 == 3 {
-			extra = []byte{0}
-		} else {
-			continue
+	for _, tag := range todo {
+		fragment, err := font.layoutTableBytes(tag, headTable, overrides, opts)
+		if err != nil {
+			return otfLayout{}, err
+		}
+		entry := directoryEntry{
+			Tag:      tag,
+			CheckSum: checkSum(fragment),
+			Offset:   uint32(offset),
+			Length:   uint32(len(fragment)),
 		}
 
-		m, err = w.Write(extra)
-		n += m
+		offset += len(fragment) + padding(len(fragment))
+		checksum += entry.CheckSum + entry.checkSum()
+
+		layout.fragments[tag] = fragment
+		layout.entries[tag] = entry
+	}
+
+	headTable.SetExpectedChecksum(checksum)
+	return layout, nil
+}
+
+// RecomputeChecksums computes the checksum of each table, as written by
+// WriteTo, and updates the checkSumAdjustment field of the 'head' table to
+// match the whole font, so that a font whose tables have been added or
+// modified with AddTable is consistent again, for instance before
+// exposing its tables to another writer.
+// WriteTo always recomputes the checksums, so that it is not needed to
+// call this method before writing.
+func (font *Font) RecomputeChecksums() (map[Tag]uint32, error) {
+	layout, err := font.layoutOTF(WriteOptions{})
+	if err != nil {
+		return nil, err
+	}
+	font.AddTable(TagHead, layout.head)
+	out := make(map[Tag]uint32, len(layout.entries))
+	for tag, entry := range layout.entries {
+		out[tag] = entry.CheckSum
+	}
+	return out, nil
+}
+
+// layoutTableBytes returns the content of the table to write,
+// using head as the 'head' table and the content of overrides, if any,
+// and applying the options.
+func (font *Font) layoutTableBytes(tag Tag, head *TableHead, overrides map[Tag][]byte, opts WriteOptions) ([]byte, error) {
+	if content, ok := overrides[tag]; ok {
+		return content, nil
+	}
+	switch {
+	case tag == TagHead:
+		return head.Bytes(), nil
+	case tag == TagName && opts.Deterministic:
+		name, err := font.NameTable()
 		if err != nil {
-			return n, err
+			return nil, err
 		}
+		return name.sorted().Bytes(), nil
+	}
+	return font.tableBytes(tag)
+}
+
+// tableBytes returns the content of the table to write.
+// When the 'cmap' table has been added or replaced, the character
+// indexes and the Unicode and code page ranges of the 'OS/2' table are updated.
+func (font *Font) tableBytes(tag Tag) ([]byte, error) {
+	t, err := font.Table(tag)
+	if err != nil {
+		return nil, err
+	}
+	os2, isOS2 := t.(*TableOS2)
+	if !isOS2 || !font.isAdded(TagCmap) {
+		return t.Bytes(), nil
+	}
 
+	indexes, err := font.ComputeCharIndexes()
+	if err != nil {
+		return nil, err
+	}
+	os2, err = os2.setCharIndexes(indexes)
+	if err != nil {
+		return nil, err
+	}
+	unicodeRanges, err := font.ComputeUnicodeRanges()
+	if err != nil {
+		return nil, err
+	}
+	codePages, err := font.ComputeCodePageRanges()
+	if err != nil {
+		return nil, err
 	}
+	os2, err = os2.setRanges(unicodeRanges, codePages)
+	if err != nil {
+		return nil, err
+	}
+	return os2.Bytes(), nil
+}
+
+// isAdded returns true if the table has been added
+// with AddTable, instead of being read from a file.
+func (font *Font) isAdded(tag Tag) bool {
+	s, found := font.tables[tag]
+	return found && s.length == 0
+}
+
+// sortOutputOrder returns a copy of tags, sorted
+// in the order the tables should be written.
+func sortOutputOrder(tags []Tag) []Tag {
+	todo := make([]Tag, len(tags))
+	copy(todo, tags)
+	sort.SliceStable(todo, func(i, j int) bool {
+		iScore, ok := outputOrder[todo[i]]
+		if !ok {
+			iScore = int(todo[i].Number)
+		}
+		jScore, ok := outputOrder[todo[j]]
+		if !ok {
+			jScore = int(todo[j].Number)
+		}
+
+		return iScore < jScore
+	})
+	return todo
+}
 
-	return 0, nil
+// padding returns the number of zero bytes needed to
+// align a table of the given length on 4 bytes.
+func padding(length int) int {
+	return (4 - length%4) % 4
 }
 
 func checkSum(buffer []byte) uint32 {
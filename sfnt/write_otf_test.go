@@ -0,0 +1,167 @@
+package sfnt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	for _, file := range []string{
+		"testdata/Roboto-BoldItalic.ttf",
+		"testdata/Raleway-v4020-Regular.otf",
+		"testdata/open-sans-v15-latin-regular.woff",
+	} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		font, err := Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		n, err := font.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(n) != buf.Len() {
+			t.Errorf("%s: WriteTo returned %d, but wrote %d bytes", file, n, buf.Len())
+		}
+		if buf.Len()%4 != 0 {
+			t.Errorf("%s: output is not padded", file)
+		}
+		if sum := checkSum(buf.Bytes()); sum != 0xB1B0AFBA {
+			t.Errorf("%s: invalid font checksum 0x%x", file, sum)
+		}
+
+		font2, err := StrictParse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(font2.Tags()) != len(font.Tags()) {
+			t.Errorf("%s: expected %d tables, got %d", file, len(font.Tags()), len(font2.Tags()))
+		}
+		for _, tag := range font.Tags() {
+			t1, _ := font.Table(tag)
+			t2, _ := font2.Table(tag)
+			if tag == TagHead { // checkSumAdjustment is updated
+				continue
+			}
+			if !bytes.Equal(t1.Bytes(), t2.Bytes()) {
+				t.Errorf("%s: table %s differs after round trip", file, tag)
+			}
+		}
+
+		f.Close()
+	}
+}
+
+func TestRecomputeChecksums(t *testing.T) {
+	font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+	name := NewTableName()
+	if err := name.AddMicrosoftEnglishEntry(NameFontFamily, "Modified"); err != nil {
+		t.Fatal(err)
+	}
+	font.AddTable(TagName, name)
+
+	checksums, err := font.RecomputeChecksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksums[TagName] != checkSum(name.Bytes()) {
+		t.Errorf("unexpected name checksum %d", checksums[TagName])
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	adjustment := head.CheckSumAdjustment
+
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if head.CheckSumAdjustment != adjustment {
+		t.Errorf("expected the adjustment 0x%08X, got 0x%08X", adjustment, head.CheckSumAdjustment)
+	}
+
+	written, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := written.VerifyChecksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches %v", mismatches)
+	}
+	writtenHead, err := written.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writtenHead.CheckSumAdjustment != adjustment {
+		t.Errorf("expected the adjustment 0x%08X, got 0x%08X", adjustment, writtenHead.CheckSumAdjustment)
+	}
+}
+
+func TestWriteDeterministic(t *testing.T) {
+	write := func(updated uint64, names []NameID) []byte {
+		font := loadTestFont(t, "Roboto-BoldItalic.ttf")
+		head, err := font.HeadTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		head.Updated.SecondsSince1904 = updated
+
+		name := NewTableName()
+		for _, id := range names {
+			if err := name.AddMicrosoftEnglishEntry(id, id.String()); err != nil {
+				t.Fatal(err)
+			}
+			if err := name.AddMacEnglishEntry(id, id.String()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		font.AddTable(TagName, name)
+
+		var buf bytes.Buffer
+		if _, err := font.WriteWithOptions(&buf, WriteOptions{Deterministic: true}); err != nil {
+			t.Fatal(err)
+		}
+		if head.Updated.SecondsSince1904 != updated {
+			t.Error("the font should not be modified")
+		}
+		return buf.Bytes()
+	}
+
+	first := write(3600, []NameID{NameFontFamily, NameFontSubfamily, NameFull})
+	second := write(7200, []NameID{NameFull, NameFontFamily, NameFontSubfamily})
+	if !bytes.Equal(first, second) {
+		t.Fatal("expected identical outputs")
+	}
+
+	written, err := Parse(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mismatches, err := written.VerifyChecksums(); err != nil || len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches %v (%v)", mismatches, err)
+	}
+	head, err := written.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Updated.SecondsSince1904 != 0 {
+		t.Errorf("expected a zero modification date, got %d", head.Updated.SecondsSince1904)
+	}
+	name, err := written.NameTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name.entries[0].PlatformID != PlatformMac || name.entries[0].NameID != NameFontFamily {
+		t.Errorf("unexpected first record %v", name.entries[0])
+	}
+}
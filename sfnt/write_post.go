@@ -0,0 +1,147 @@
+package sfnt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	postHeaderSize    = 32
+	postMaxCustomName = 32767 - numBuiltInPostNames // the indexes above 32767 are reserved
+)
+
+// BuildPost compiles a 'post' table, using the header fields of post,
+// whose Version and Names fields are ignored.
+// If names is nil, a version 3 table, without glyph names, is returned.
+// Otherwise, a version 2 table is returned, storing the names of
+// each glyph: the standard Macintosh names are referenced by index,
+// and the other names are stored once.
+func BuildPost(post PostTable, names []string) ([]byte, error) {
+	out := make([]byte, postHeaderSize, postHeaderSize+2+2*len(names))
+	be.PutUint32(out, 0x30000)
+	be.PutUint32(out[4:], uint32(int32(math.Round(post.ItalicAngle*0x10000))))
+	be.PutUint16(out[8:], uint16(post.UnderlinePosition))
+	be.PutUint16(out[10:], uint16(post.UnderlineThickness))
+	if post.IsFixedPitch {
+		be.PutUint32(out[12:], 1)
+	}
+	if names == nil {
+		return out, nil
+	}
+	if len(names) > math.MaxUint16 {
+		return nil, fmt.Errorf("invalid number of glyph names %d", len(names))
+	}
+
+	builtIn := make(map[string]uint16, numBuiltInPostNames)
+	for i, name := range builtInPostNames {
+		builtIn[name] = uint16(i)
+	}
+	custom := map[string]uint16{}
+	var data []byte // the custom names, as Pascal strings
+
+	be.PutUint32(out, 0x20000)
+	out = append(out, byte(len(names)>>8), byte(len(names)))
+	for _, name := range names {
+		index, ok := builtIn[name]
+		if !ok {
+			index, ok = custom[name]
+		}
+		if !ok {
+			if len(name) > 255 {
+				return nil, fmt.Errorf("invalid glyph name %q: more than 255 bytes", name)
+			}
+			if len(custom) == postMaxCustomName {
+				return nil, fmt.Errorf("too many glyph names: more than %d custom names", postMaxCustomName)
+			}
+			index = uint16(numBuiltInPostNames + len(custom))
+			custom[name] = index
+			data = append(append(data, byte(len(name))), name...)
+		}
+		out = append(out, byte(index>>8), byte(index))
+	}
+	return append(out, data...), nil
+}
+
+// SynthesizeGlyphNames builds glyph names following the conventions of
+// the Adobe Glyph List: the glyphs mapped by chars are named after
+// their smallest rune, using the AGL name if any, or uniXXXX (uXXXXX
+// outside of the BMP). The other glyphs are named glyphN,
+// and glyph 0 is always .notdef.
+func SynthesizeGlyphNames(numGlyphs int, chars map[rune]GlyphIndex) []string {
+	runes := make([]rune, numGlyphs)
+	for r, gi := range chars {
+		if int(gi) < numGlyphs && (runes[gi] == 0 || r < runes[gi]) {
+			runes[gi] = r
+		}
+	}
+	out := make([]string, numGlyphs)
+	for gi, r := range runes {
+		out[gi] = runeGlyphName(GlyphIndex(gi), r)
+	}
+	if numGlyphs != 0 {
+		out[0] = ".notdef"
+	}
+	return out
+}
+
+// runeGlyphName returns the AGL name of r, or glyphN if r is 0.
+func runeGlyphName(gi GlyphIndex, r rune) string {
+	if name, ok := aglNames[r]; ok {
+		return name
+	}
+	switch {
+	case r == 0:
+		return fmt.Sprintf("glyph%d", gi)
+	case r <= 0xFFFF:
+		return fmt.Sprintf("uni%04X", r)
+	default:
+		return fmt.Sprintf("u%X", r)
+	}
+}
+
+// CompleteGlyphNames returns the name of each glyph, as returned by
+// GlyphNames, the missing names being synthesized from the cmap
+// (see SynthesizeGlyphNames). The synthesized names conflicting with
+// existing ones are suffixed by .N.
+// The result is suitable for BuildPost.
+func (font *Font) CompleteGlyphNames() ([]string, error) {
+	numGlyphs, err := font.numGlyphs()
+	if err != nil {
+		return nil, err
+	}
+	names, err := font.GlyphNames()
+	if err != nil && !errors.Is(err, ErrMissingTable) {
+		return nil, err
+	}
+	var chars map[rune]GlyphIndex
+	if font.HasTable(TagCmap) {
+		cmap, err := font.CmapTable()
+		if err != nil {
+			return nil, err
+		}
+		chars = cmap.Compile()
+	}
+	synthesized := SynthesizeGlyphNames(int(numGlyphs), chars)
+
+	out := make([]string, numGlyphs)
+	used := map[string]bool{}
+	if names != nil {
+		for gi := range out {
+			out[gi] = names.GlyphName(GlyphIndex(gi))
+			used[out[gi]] = true
+		}
+	}
+	for gi, name := range out {
+		if name != "" {
+			continue
+		}
+		name = synthesized[gi]
+		for i := 1; used[name]; i++ {
+			name = fmt.Sprintf("%s.%d", synthesized[gi], i)
+		}
+		out[gi] = name
+		used[name] = true
+	}
+	return out, nil
+}
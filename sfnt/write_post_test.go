@@ -0,0 +1,94 @@
+package sfnt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildPost(t *testing.T) {
+	header := PostTable{ItalicAngle: -12.5, UnderlinePosition: -100, UnderlineThickness: 50, IsFixedPitch: true}
+	names := []string{".notdef", "A", "A.alt", "uni0411", "A.alt"}
+	for _, names := range [][]string{nil, names} {
+		buf, err := BuildPost(header, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		post, err := parseTablePost(buf, uint16(len(names)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.ItalicAngle != header.ItalicAngle || post.UnderlinePosition != header.UnderlinePosition ||
+			post.UnderlineThickness != header.UnderlineThickness || !post.IsFixedPitch {
+			t.Errorf("unexpected header %v", post)
+		}
+		if names == nil {
+			if post.Version != 0x30000 || post.Names != nil {
+				t.Errorf("expected a version 3 table, got %v", post)
+			}
+			continue
+		}
+		if post.Version != 0x20000 {
+			t.Errorf("expected a version 2 table, got %x", post.Version)
+		}
+		for gi, name := range names {
+			if got := post.Names.GlyphName(GlyphIndex(gi)); got != name {
+				t.Errorf("glyph %d: expected %s, got %s", gi, name, got)
+			}
+		}
+		// the header, the indexes, and 'A.alt' and 'uni0411' stored once
+		if expected := 32 + 2 + 2*len(names) + 6 + 8; len(buf) != expected {
+			t.Errorf("expected %d bytes, got %d", expected, len(buf))
+		}
+	}
+
+	if _, err := BuildPost(header, []string{string(make([]byte, 256))}); err == nil {
+		t.Error("expected an error for a too long name")
+	}
+}
+
+func TestSynthesizeGlyphNames(t *testing.T) {
+	names := SynthesizeGlyphNames(6, map[rune]GlyphIndex{
+		'A': 1, 'é': 2, 'Б': 3, 0x1F600: 4, 0x391: 1, 'X': 0,
+	})
+	expected := []string{".notdef", "A", "eacute", "uni0411", "u1F600", "glyph5"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestCompleteGlyphNames(t *testing.T) {
+	font := loadTestFont(t, "Castoro-Regular.ttf")
+	names, err := font.GlyphNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	complete, err := font.CompleteGlyphNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gi, name := range complete {
+		if expected := names.GlyphName(GlyphIndex(gi)); expected != "" && name != expected {
+			t.Errorf("glyph %d: expected %s, got %s", gi, expected, name)
+		}
+	}
+
+	font = loadTestFont(t, "Roboto-BoldItalic.ttf") // without glyph names
+	complete, err = font.CompleteGlyphNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap, err := font.CmapTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := complete[cmap.Lookup('a')]; name != "a" {
+		t.Errorf("expected a synthesized name for 'a', got %s", name)
+	}
+	seen := map[string]bool{}
+	for _, name := range complete {
+		if seen[name] {
+			t.Errorf("duplicated name %s", name)
+		}
+		seen[name] = true
+	}
+}
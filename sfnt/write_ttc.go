@@ -0,0 +1,149 @@
+package sfnt
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var errEmptyCollection = errors.New("empty font collection")
+
+// WriteCollection serializes several fonts into a TrueType Collection
+// (.ttc or .otc file).
+// The tables with identical content are only stored once, and shared
+// by the fonts. The 'head' tables are never shared, since they store the
+// checksum of each font.
+func WriteCollection(w io.Writer, fonts []*Font) (n int64, err error) {
+	if len(fonts) == 0 {
+		return 0, errEmptyCollection
+	}
+
+	type fontLayout struct {
+		header  *otfHeader
+		head    *TableHead
+		tags    []Tag // sorted by tag
+		entries map[Tag]directoryEntry
+	}
+
+	layouts := make([]fontLayout, len(fonts))
+	// start of the table data
+	offset := 12 + 4*len(fonts)
+	for i, font := range fonts {
+		tags := font.Tags()
+		layouts[i] = fontLayout{
+			header:  newOTFHeader(font.scalerType, uint16(len(tags))),
+			tags:    tags,
+			entries: make(map[Tag]directoryEntry, len(tags)),
+		}
+		offset += otfHeaderLength + directoryEntryLength*len(tags)
+	}
+
+	type tableData struct {
+		fragment []byte
+		headOf   int // index of the font, for 'head' tables, or -1
+	}
+
+	var (
+		data    []tableData        // the table content, in output order
+		offsets = map[string]int{} // offsets of the shared tables
+	)
+	for i, font := range fonts {
+		layout := &layouts[i]
+
+		head, err := font.HeadTable()
+		if err != nil {
+			return n, err
+		}
+		// the 'head' table may be shared with other collections
+		copied := *head
+		copied.ClearExpectedChecksum()
+		layout.head = &copied
+
+		todo := sortOutputOrder(layout.tags)
+		for _, tag := range todo {
+			fragment, err := font.layoutTableBytes(tag, layout.head, nil, WriteOptions{})
+			if err != nil {
+				return n, err
+			}
+
+			tableOffset, shared := offsets[string(fragment)]
+			if tag == TagHead || !shared {
+				tableOffset = offset
+				offset += len(fragment) + padding(len(fragment))
+				if tag == TagHead {
+					data = append(data, tableData{fragment, i})
+				} else {
+					data = append(data, tableData{fragment, -1})
+					offsets[string(fragment)] = tableOffset
+				}
+			}
+
+			layout.entries[tag] = directoryEntry{
+				Tag:      tag,
+				CheckSum: checkSum(fragment),
+				Offset:   uint32(tableOffset),
+				Length:   uint32(len(fragment)),
+			}
+		}
+	}
+
+	// now that the offsets are known, compute the checksum of each font
+	heads := make([][]byte, len(fonts))
+	for i, layout := range layouts {
+		checksum := layout.header.checkSum()
+		for _, entry := range layout.entries {
+			checksum += entry.CheckSum + entry.checkSum()
+		}
+		layout.head.SetExpectedChecksum(checksum)
+		heads[i] = layout.head.Bytes()
+	}
+
+	header := ttcHeader{Tag: SignatureTTC, MajorVersion: 1, NumFonts: uint32(len(fonts))}
+	if err = binary.Write(w, binary.BigEndian, header); err != nil {
+		return n, err
+	}
+	n += 12
+
+	fontOffset := 12 + 4*len(fonts)
+	for _, layout := range layouts {
+		if err = binary.Write(w, binary.BigEndian, uint32(fontOffset)); err != nil {
+			return n, err
+		}
+		n += 4
+		fontOffset += otfHeaderLength + directoryEntryLength*len(layout.tags)
+	}
+
+	for _, layout := range layouts {
+		if err = binary.Write(w, binary.BigEndian, layout.header); err != nil {
+			return n, err
+		}
+		n += otfHeaderLength
+		for _, tag := range layout.tags {
+			if err = binary.Write(w, binary.BigEndian, layout.entries[tag]); err != nil {
+				return n, err
+			}
+			n += directoryEntryLength
+		}
+	}
+
+	for _, table := range data {
+		fragment := table.fragment
+		if table.headOf != -1 {
+			fragment = heads[table.headOf]
+		}
+
+		m, err := w.Write(fragment)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+
+		m, err = w.Write(make([]byte, padding(len(fragment))))
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
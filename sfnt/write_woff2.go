@@ -0,0 +1,146 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// woff2KnownTags are the tags which may be encoded with a
+// single byte in a WOFF2 table directory.
+// See https://www.w3.org/TR/WOFF2/#table_dir_format
+var woff2KnownTags = [...]string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill",
+}
+
+// woff2ArbitraryTag is the flag value indicating that the tag
+// is written after the flags.
+const woff2ArbitraryTag = 0x3f
+
+// woff2NullTransform is the transform version indicating
+// that a 'glyf' or 'loca' table is stored as is.
+const woff2NullTransform = 3 << 6
+
+type woff2Header struct {
+	Signature           Tag
+	Flavor              Tag
+	Length              uint32
+	NumTables           uint16
+	Reserved            uint16
+	TotalSfntSize       uint32
+	TotalCompressedSize uint32
+	MajorVersion        uint16
+	MinorVersion        uint16
+	MetaOffset          uint32
+	MetaLength          uint32
+	MetaOrigLength      uint32
+	PrivOffset          uint32
+	PrivLength          uint32
+}
+
+// woff2TagFlags returns the flags byte of a directory entry,
+// and whether the tag is a known one.
+func woff2TagFlags(tag Tag) (byte, bool) {
+	name := tag.String()
+	for i, known := range woff2KnownTags {
+		if known == name {
+			return byte(i), true
+		}
+	}
+	return woff2ArbitraryTag, false
+}
+
+// appendUintBase128 appends the variable-length encoding of v.
+// See https://www.w3.org/TR/WOFF2/#DataTypes
+func appendUintBase128(buf []byte, v uint32) []byte {
+	var tmp [5]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7f)
+	for v >>= 7; v != 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7f) | 0x80
+	}
+	return append(buf, tmp[i:]...)
+}
+
+// WriteWOFF2 serializes a Font into the WOFF2 format, suitable for the web.
+// All the tables are stored without transformation, in a single
+// Brotli compressed stream.
+func (font *Font) WriteWOFF2(w io.Writer) (n int, err error) {
+	// serialize to sfnt first, so that the checksums are valid
+	// for the font reconstructed by the decoders
+	var sfnt bytes.Buffer
+	if _, err := font.WriteTo(&sfnt); err != nil {
+		return 0, err
+	}
+	reconstructed, err := parseOTF(bytes.NewReader(sfnt.Bytes()))
+	if err != nil {
+		return 0, err
+	}
+	tags := reconstructed.Tags()
+
+	head, err := font.HeadTable()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		directory []byte
+		stream    []byte
+	)
+	for _, tag := range tags {
+		s := reconstructed.tables[tag]
+		table := sfnt.Bytes()[s.offset : s.offset+s.length]
+
+		flags, known := woff2TagFlags(tag)
+		if tag.String() == "glyf" || tag.String() == "loca" {
+			flags |= woff2NullTransform
+		}
+		directory = append(directory, flags)
+		if !known {
+			directory = append(directory, tag.bytes()...)
+		}
+		directory = appendUintBase128(directory, uint32(len(table)))
+
+		stream = append(stream, table...)
+	}
+
+	var compressed bytes.Buffer
+	bw := brotli.NewWriterLevel(&compressed, brotli.BestCompression)
+	if _, err := bw.Write(stream); err != nil {
+		return 0, err
+	}
+	if err := bw.Close(); err != nil {
+		return 0, err
+	}
+
+	headerLength := binary.Size(woff2Header{})
+	length := headerLength + len(directory) + compressed.Len()
+	header := woff2Header{
+		Signature:           SignatureWOFF2,
+		Flavor:              font.scalerType,
+		Length:              uint32(length + padding(length)),
+		NumTables:           uint16(len(tags)),
+		TotalSfntSize:       uint32(sfnt.Len()),
+		TotalCompressedSize: uint32(compressed.Len()),
+		MajorVersion:        uint16(head.FontRevision.Major),
+		MinorVersion:        head.FontRevision.Minor,
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, header)
+	out.Write(directory)
+	out.Write(compressed.Bytes())
+	out.Write(make([]byte, padding(length)))
+
+	return w.Write(out.Bytes())
+}
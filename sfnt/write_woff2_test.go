@@ -0,0 +1,110 @@
+package sfnt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUintBase128(t *testing.T) {
+	for v, exp := range map[uint32][]byte{
+		0:      {0},
+		127:    {127},
+		128:    {0x81, 0},
+		63:     {63},
+		0x3FFF: {0xFF, 0x7F},
+	} {
+		if got := appendUintBase128(nil, v); !bytes.Equal(got, exp) {
+			t.Errorf("%d: expected %v, got %v", v, exp, got)
+		}
+	}
+}
+
+func TestWriteWOFF2(t *testing.T) {
+	f, err := os.Open("testdata/Roboto-BoldItalic.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	font, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := font.WriteWOFF2(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var header woff2Header
+	r := bytes.NewReader(buf.Bytes())
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Signature != SignatureWOFF2 || int(header.Length) != buf.Len() {
+		t.Fatalf("invalid header %v", header)
+	}
+	if int(header.NumTables) != len(font.Tags()) {
+		t.Errorf("expected %d tables, got %d", len(font.Tags()), header.NumTables)
+	}
+
+	// glyf and loca are stored with the null transform
+	glyf, loca := MustNamedTag("glyf"), MustNamedTag("loca")
+	for _, tag := range font.Tags() {
+		flags, _ := r.ReadByte()
+		if flags&0x3f == woff2ArbitraryTag {
+			r.Read(make([]byte, 4))
+		}
+		for b := byte(0x80); b&0x80 != 0; {
+			b, _ = r.ReadByte()
+		}
+		if (tag == glyf || tag == loca) && flags&woff2NullTransform != woff2NullTransform {
+			t.Errorf("table %s is transformed", tag)
+		}
+	}
+
+	// the tables are stored as is
+	woff2, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(woff2.Tags(), font.Tags()) {
+		t.Fatalf("expected tables %v, got %v", font.Tags(), woff2.Tags())
+	}
+	var otf bytes.Buffer
+	if _, err := font.WriteTo(&otf); err != nil {
+		t.Fatal(err)
+	}
+	written, err := parseOTF(bytes.NewReader(otf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writtenHead, err := written.HeadTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range font.Tags() {
+		expected, err := font.findTableBuffer(font.tables[tag])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := woff2.findTableBuffer(woff2.tables[tag])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag == TagHead && len(got) >= 12 {
+			// checkSumAdjustment is the one of the font written by WriteTo
+			if got := be.Uint32(got[8:]); got != writtenHead.CheckSumAdjustment {
+				t.Errorf("expected checkSumAdjustment %d, got %d", writtenHead.CheckSumAdjustment, got)
+			}
+			expected = append([]byte(nil), expected...)
+			copy(expected[8:12], got[8:12])
+		}
+		if !bytes.Equal(expected, got) {
+			t.Errorf("table %s differs", tag)
+		}
+	}
+}
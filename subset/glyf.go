@@ -0,0 +1,179 @@
+package subset
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var be = binary.BigEndian
+
+var (
+	errInvalidMaxpTable = errors.New("invalid maxp table")
+	errInvalidLocaTable = errors.New("invalid loca table")
+	errInvalidGlyfTable = errors.New("invalid glyf table")
+)
+
+// composite glyph flags
+// See https://docs.microsoft.com/en-us/typography/opentype/spec/glyf#composite-glyph-description
+const (
+	arg1And2AreWords    = 0x0001
+	weHaveAScale        = 0x0008
+	moreComponents      = 0x0020
+	weHaveAnXAndYScale  = 0x0040
+	weHaveATwoByTwo     = 0x0080
+	compositeHeaderSize = 10 // numberOfContours and bounding box
+)
+
+func readNumGlyphs(font *sfnt.Font) (int, error) {
+	maxp, err := font.Table(sfnt.TagMaxp)
+	if err != nil {
+		return 0, err
+	}
+	b := maxp.Bytes()
+	if len(b) < 6 {
+		return 0, errInvalidMaxpTable
+	}
+	return int(be.Uint16(b[4:])), nil
+}
+
+// glyphs stores the raw data of each glyph, indexed by glyph index.
+type glyphs [][]byte
+
+// readGlyphs splits the glyf table using the loca table.
+func readGlyphs(font *sfnt.Font, locaFormat int16, numGlyphs int) (glyphs, error) {
+	loca, err := font.Table(tagLoca)
+	if err != nil {
+		return nil, err
+	}
+	glyf, err := font.Table(tagGlyf)
+	if err != nil {
+		return nil, err
+	}
+	locaBytes, glyfBytes := loca.Bytes(), glyf.Bytes()
+
+	offsets := make([]int, numGlyphs+1)
+	if locaFormat == 0 {
+		if len(locaBytes) < 2*len(offsets) {
+			return nil, errInvalidLocaTable
+		}
+		for i := range offsets {
+			offsets[i] = 2 * int(be.Uint16(locaBytes[2*i:]))
+		}
+	} else {
+		if len(locaBytes) < 4*len(offsets) {
+			return nil, errInvalidLocaTable
+		}
+		for i := range offsets {
+			offsets[i] = int(be.Uint32(locaBytes[4*i:]))
+		}
+	}
+
+	out := make(glyphs, numGlyphs)
+	for i := range out {
+		start, end := offsets[i], offsets[i+1]
+		if start > end || end > len(glyfBytes) {
+			return nil, errInvalidLocaTable
+		}
+		out[i] = glyfBytes[start:end]
+	}
+	return out, nil
+}
+
+// components returns the offsets, in the glyph data, of
+// the glyph indices of the components of a composite glyph.
+// It returns nil for simple (or empty) glyphs.
+func components(glyph []byte) ([]int, error) {
+	if len(glyph) < compositeHeaderSize || int16(be.Uint16(glyph)) >= 0 {
+		return nil, nil
+	}
+
+	var out []int
+	for offset := compositeHeaderSize; ; {
+		if len(glyph) < offset+4 {
+			return nil, errInvalidGlyfTable
+		}
+		flags := be.Uint16(glyph[offset:])
+		out = append(out, offset+2)
+
+		offset += 4
+		if flags&arg1And2AreWords != 0 {
+			offset += 4
+		} else {
+			offset += 2
+		}
+		switch {
+		case flags&weHaveAScale != 0:
+			offset += 2
+		case flags&weHaveAnXAndYScale != 0:
+			offset += 4
+		case flags&weHaveATwoByTwo != 0:
+			offset += 8
+		}
+
+		if flags&moreComponents == 0 {
+			return out, nil
+		}
+	}
+}
+
+// closure adds gi and the glyphs it depends on to set.
+func (gs glyphs) closure(gi sfnt.GlyphIndex, set *sfnt.GlyphSet) error {
+	if int(gi) >= len(gs) || set.Contains(gi) {
+		return nil
+	}
+	set.Add(gi)
+
+	offsets, err := components(gs[gi])
+	if err != nil {
+		return err
+	}
+	for _, offset := range offsets {
+		component := sfnt.GlyphIndex(be.Uint16(gs[gi][offset:]))
+		if err := gs.closure(component, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subset returns the glyf and loca tables for the given glyphs, which are
+// renumbered according to their position in the set, as well as the
+// components of the composite glyphs.
+// The glyphs are padded to 4 bytes, and the short loca format is used when possible.
+func (gs glyphs) subset(kept *sfnt.GlyphSet) (glyf, loca []byte, locaFormat int16) {
+	oldGlyphs := kept.Glyphs()
+	offsets := make([]int, len(oldGlyphs)+1)
+	for i, oldGlyph := range oldGlyphs {
+		glyph := append([]byte(nil), gs[oldGlyph]...)
+		componentOffsets, _ := components(glyph) // errors are checked in closure
+		for _, offset := range componentOffsets {
+			newGlyph, found := kept.Index(sfnt.GlyphIndex(be.Uint16(glyph[offset:])))
+			if !found { // invalid component, skipped by closure
+				newGlyph = 0
+			}
+			be.PutUint16(glyph[offset:], uint16(newGlyph))
+		}
+
+		glyf = append(glyf, glyph...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		offsets[i+1] = len(glyf)
+	}
+
+	if len(glyf) <= 2*0xFFFF {
+		loca = make([]byte, 2*len(offsets))
+		for i, offset := range offsets {
+			be.PutUint16(loca[2*i:], uint16(offset/2))
+		}
+		return glyf, loca, 0
+	}
+
+	loca = make([]byte, 4*len(offsets))
+	for i, offset := range offsets {
+		be.PutUint32(loca[4*i:], uint32(offset))
+	}
+	return glyf, loca, 1
+}
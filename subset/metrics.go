@@ -0,0 +1,33 @@
+package subset
+
+import "github.com/ConradIrwin/font/sfnt"
+
+// subsetMetrics adds the hhea and hmtx tables to out.
+// All the glyphs are written with a full metric record.
+func subsetMetrics(font, out *sfnt.Font, oldGlyphs []sfnt.GlyphIndex) error {
+	if !font.HasTable(sfnt.TagHhea) || !font.HasTable(sfnt.TagHmtx) {
+		return nil
+	}
+
+	hhea, err := font.HheaTable()
+	if err != nil {
+		return err
+	}
+	metrics, err := font.HmtxMetrics()
+	if err != nil {
+		return err
+	}
+
+	newHmtx := make([]byte, 4*len(oldGlyphs))
+	for i, gi := range oldGlyphs {
+		be.PutUint16(newHmtx[4*i:], metrics[gi].Advance)
+		be.PutUint16(newHmtx[4*i+2:], uint16(metrics[gi].LSB))
+	}
+
+	newHhea := *hhea
+	newHhea.NumOfLongHorMetrics = int16(len(oldGlyphs))
+
+	out.AddTable(sfnt.TagHhea, &newHhea)
+	out.AddTable(sfnt.TagHmtx, sfnt.NewTable(sfnt.TagHmtx, newHmtx))
+	return nil
+}
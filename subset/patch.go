@@ -0,0 +1,153 @@
+package subset
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var (
+	errIncompatibleSubsets = errors.New("subsets with different number of glyphs")
+	errInvalidPatch        = errors.New("the patch should have one outline for each glyph")
+)
+
+// Patch is the difference between two subsets of the same font,
+// retaining the glyph indexes (see GlyphsRetainingIDs).
+// It is used to extend a subset already loaded by a client with
+// the glyphs of new runes, as in the patch-subset method of
+// Incremental Font Transfer, without sending the whole font again.
+type Patch struct {
+	// Glyphs are the glyphs modified by the patch, usually the new glyphs.
+	Glyphs *sfnt.GlyphSet
+	// Outlines stores the outlines ('glyf' data) of the modified
+	// glyphs, in the order of Glyphs.
+	Outlines [][]byte
+	// Tables stores the content of the tables, other than 'glyf' and
+	// 'loca', added or modified by the patch.
+	Tables map[sfnt.Tag][]byte
+	// Removed are the tables of the base subset missing
+	// from the extended one.
+	Removed []sfnt.Tag
+}
+
+// Diff returns the patch transforming base into extended, which must be two
+// subsets retaining the glyph indexes of the same font.
+func Diff(base, extended *sfnt.Font) (Patch, error) {
+	baseGlyphs, err := readAllGlyphs(base)
+	if err != nil {
+		return Patch{}, err
+	}
+	extendedGlyphs, err := readAllGlyphs(extended)
+	if err != nil {
+		return Patch{}, err
+	}
+	if len(baseGlyphs) != len(extendedGlyphs) {
+		return Patch{}, errIncompatibleSubsets
+	}
+
+	out := Patch{Glyphs: sfnt.NewGlyphSet(), Tables: map[sfnt.Tag][]byte{}}
+	for i, glyph := range extendedGlyphs {
+		if !bytes.Equal(glyph, baseGlyphs[i]) {
+			out.Glyphs.Add(sfnt.GlyphIndex(i))
+			out.Outlines = append(out.Outlines, glyph)
+		}
+	}
+
+	for _, tag := range extended.Tags() {
+		if tag == tagGlyf || tag == tagLoca {
+			continue
+		}
+		table, err := extended.Table(tag)
+		if err != nil {
+			return Patch{}, err
+		}
+		if base.HasTable(tag) {
+			baseTable, err := base.Table(tag)
+			if err != nil {
+				return Patch{}, err
+			}
+			if bytes.Equal(table.Bytes(), baseTable.Bytes()) {
+				continue
+			}
+		}
+		out.Tables[tag] = table.Bytes()
+	}
+	for _, tag := range base.Tags() {
+		if !extended.HasTable(tag) {
+			out.Removed = append(out.Removed, tag)
+		}
+	}
+	return out, nil
+}
+
+// Apply returns a copy of base, a subset retaining the glyph indexes,
+// extended with the patch: the tables of the patch are added or
+// replaced, and the removed tables are dropped.
+func (p Patch) Apply(base *sfnt.Font) (*sfnt.Font, error) {
+	outlines, err := readAllGlyphs(base)
+	if err != nil {
+		return nil, err
+	}
+
+	out := sfnt.New(base.Type())
+	for _, tag := range base.Tags() {
+		table, err := base.Table(tag)
+		if err != nil {
+			return nil, err
+		}
+		out.AddTable(tag, table)
+	}
+	for tag, content := range p.Tables {
+		// parse the known tables, as for a font read from a file
+		table, err := sfnt.ParseTable(tag, content)
+		if err != nil {
+			return nil, err
+		}
+		out.AddTable(tag, table)
+	}
+	for _, tag := range p.Removed {
+		out.RemoveTable(tag)
+	}
+
+	if p.Glyphs != nil {
+		glyphs := p.Glyphs.Glyphs()
+		if len(glyphs) != len(p.Outlines) {
+			return nil, errInvalidPatch
+		}
+		for i, gi := range glyphs {
+			if int(gi) >= len(outlines) {
+				return nil, errIncompatibleSubsets
+			}
+			outlines[gi] = p.Outlines[i]
+		}
+	}
+	glyf, loca, locaFormat := outlines.subset(allGlyphs(len(outlines)))
+	out.AddTable(tagGlyf, sfnt.NewTable(tagGlyf, glyf))
+	out.AddTable(tagLoca, sfnt.NewTable(tagLoca, loca))
+
+	head, err := out.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	newHead := *head
+	newHead.IndexToLocFormat = locaFormat
+	out.AddTable(sfnt.TagHead, &newHead)
+	return out, nil
+}
+
+// readAllGlyphs returns the outlines of all the glyphs of the font.
+func readAllGlyphs(font *sfnt.Font) (glyphs, error) {
+	if !font.HasTable(tagGlyf) || !font.HasTable(tagLoca) {
+		return nil, ErrUnsupportedOutlines
+	}
+	head, err := font.HeadTable()
+	if err != nil {
+		return nil, err
+	}
+	numGlyphs, err := readNumGlyphs(font)
+	if err != nil {
+		return nil, err
+	}
+	return readGlyphs(font, head.IndexToLocFormat, numGlyphs)
+}
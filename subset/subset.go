@@ -0,0 +1,231 @@
+// Package subset builds fonts containing only a subset of the glyphs
+// of a source font, which is useful to embed fonts in PDF files or
+// to reduce the size of web fonts.
+//
+// Only fonts with TrueType outlines (glyf and loca tables) are supported.
+package subset
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+var (
+	tagCmap = sfnt.MustNamedTag("cmap")
+	tagGlyf = sfnt.MustNamedTag("glyf")
+	tagLoca = sfnt.MustNamedTag("loca")
+	tagPost = sfnt.MustNamedTag("post")
+	tagCvt  = sfnt.MustNamedTag("cvt ")
+	tagFpgm = sfnt.MustNamedTag("fpgm")
+	tagPrep = sfnt.MustNamedTag("prep")
+	tagGasp = sfnt.MustNamedTag("gasp")
+)
+
+// ErrUnsupportedOutlines is returned when the font has no TrueType outlines.
+var ErrUnsupportedOutlines = errors.New("subsetting is only supported for TrueType outlines")
+
+// tables which are copied without modification, since they
+// don't depend on glyph indices
+var copiedTables = []sfnt.Tag{sfnt.TagOS2, tagCvt, tagFpgm, tagPrep, tagGasp}
+
+// Subset is a font restricted to some glyphs of a source font.
+type Subset struct {
+	Font *sfnt.Font
+
+	// Glyphs maps the glyphs of the subset to the glyphs of
+	// the source font: Glyphs[newGlyph] = oldGlyph, in ascending order.
+	// Glyph 0 (.notdef) is always kept.
+	Glyphs []sfnt.GlyphIndex
+}
+
+// NewGlyph returns the index in the subset of the glyph
+// oldGlyph of the source font, or false if it is not included.
+func (s Subset) NewGlyph(oldGlyph sfnt.GlyphIndex) (sfnt.GlyphIndex, bool) {
+	i := sort.Search(len(s.Glyphs), func(i int) bool { return s.Glyphs[i] >= oldGlyph })
+	if i < len(s.Glyphs) && s.Glyphs[i] == oldGlyph {
+		return sfnt.GlyphIndex(i), true
+	}
+	return 0, false
+}
+
+// Runes returns a font containing only the glyphs required
+// to display the given runes.
+func Runes(font *sfnt.Font, runes []rune) (Subset, error) {
+	glyphs, err := runeGlyphs(font, runes)
+	if err != nil {
+		return Subset{}, err
+	}
+	return Glyphs(font, glyphs)
+}
+
+// runeGlyphs returns the glyphs of the runes, ignoring the missing ones.
+func runeGlyphs(font *sfnt.Font, runes []rune) (*sfnt.GlyphSet, error) {
+	cmap, err := font.CmapTable()
+	if err != nil {
+		return nil, err
+	}
+
+	glyphs := sfnt.NewGlyphSet()
+	for _, r := range runes {
+		if gi := cmap.Lookup(r); gi != 0 {
+			glyphs.Add(gi)
+		}
+	}
+	return glyphs, nil
+}
+
+// Glyphs returns a font containing only the given glyphs, and
+// the glyphs they depend on (through composite glyphs).
+// The glyphs are renumbered, preserving their relative order.
+// The glyf, loca, cmap, hmtx and name tables are trimmed, the
+// layout tables (GPOS, GSUB, kern, etc.) are dropped.
+func Glyphs(font *sfnt.Font, glyphs *sfnt.GlyphSet) (Subset, error) {
+	return subsetGlyphs(font, glyphs, false)
+}
+
+// RunesRetainingIDs is the same as Runes, but the glyphs are not renumbered.
+func RunesRetainingIDs(font *sfnt.Font, runes []rune) (Subset, error) {
+	glyphs, err := runeGlyphs(font, runes)
+	if err != nil {
+		return Subset{}, err
+	}
+	return GlyphsRetainingIDs(font, glyphs)
+}
+
+// GlyphsRetainingIDs is the same as Glyphs, but the glyphs are not
+// renumbered: the glyphs which are not included are kept empty.
+// The subsets of a font are then compatible, and a subset may be
+// extended with a Patch.
+func GlyphsRetainingIDs(font *sfnt.Font, glyphs *sfnt.GlyphSet) (Subset, error) {
+	return subsetGlyphs(font, glyphs, true)
+}
+
+func subsetGlyphs(font *sfnt.Font, included *sfnt.GlyphSet, retainIDs bool) (Subset, error) {
+	if !font.HasTable(tagGlyf) || !font.HasTable(tagLoca) {
+		return Subset{}, ErrUnsupportedOutlines
+	}
+
+	head, err := font.HeadTable()
+	if err != nil {
+		return Subset{}, err
+	}
+	numGlyphs, err := readNumGlyphs(font)
+	if err != nil {
+		return Subset{}, err
+	}
+
+	outlines, err := readGlyphs(font, head.IndexToLocFormat, numGlyphs)
+	if err != nil {
+		return Subset{}, err
+	}
+
+	// resolve the composite glyphs and renumber
+	kept := sfnt.NewGlyphSet()
+	for _, gi := range append([]sfnt.GlyphIndex{0}, included.Glyphs()...) {
+		if err := outlines.closure(gi, kept); err != nil {
+			return Subset{}, err
+		}
+	}
+	// the glyphs of the subset, renumbered according to their index
+	written := kept
+	if retainIDs {
+		// only the kept glyphs have an outline
+		retained := make(glyphs, numGlyphs)
+		kept.ForEach(func(gi sfnt.GlyphIndex) { retained[gi] = outlines[gi] })
+		outlines = retained
+		written = allGlyphs(numGlyphs)
+	}
+	oldGlyphs := written.Glyphs()
+
+	out := sfnt.New(font.Type())
+
+	glyf, loca, locaFormat := outlines.subset(written)
+	out.AddTable(tagGlyf, sfnt.NewTable(tagGlyf, glyf))
+	out.AddTable(tagLoca, sfnt.NewTable(tagLoca, loca))
+
+	newHead := *head
+	newHead.IndexToLocFormat = locaFormat
+	out.AddTable(sfnt.TagHead, &newHead)
+
+	if err := subsetMetrics(font, out, oldGlyphs); err != nil {
+		return Subset{}, err
+	}
+
+	maxp, err := font.Table(sfnt.TagMaxp)
+	if err != nil {
+		return Subset{}, err
+	}
+	newMaxp := append([]byte(nil), maxp.Bytes()...)
+	be.PutUint16(newMaxp[4:], uint16(len(oldGlyphs)))
+	out.AddTable(sfnt.TagMaxp, sfnt.NewTable(sfnt.TagMaxp, newMaxp))
+
+	if font.HasTable(tagCmap) {
+		cmap, err := font.CmapTable()
+		if err != nil {
+			return Subset{}, err
+		}
+		chars := make(map[rune]sfnt.GlyphIndex)
+		for r, gi := range cmap.Compile() {
+			if kept.Contains(gi) && gi != 0 {
+				newGlyph, _ := written.Index(gi)
+				chars[r] = sfnt.GlyphIndex(newGlyph)
+			}
+		}
+		out.AddTable(tagCmap, sfnt.NewTable(tagCmap, sfnt.BuildCmap(chars)))
+	}
+
+	if font.HasTable(sfnt.TagName) {
+		name, err := font.NameTable()
+		if err != nil {
+			return Subset{}, err
+		}
+		out.AddTable(sfnt.TagName, subsetName(name))
+	}
+
+	// the glyph names are dropped, returning a version 3 table
+	if font.HasTable(tagPost) {
+		if post, err := font.PostTable(); err == nil {
+			newPost, err := sfnt.BuildPost(post, nil)
+			if err != nil {
+				return Subset{}, err
+			}
+			out.AddTable(tagPost, sfnt.NewTable(tagPost, newPost))
+		}
+	}
+
+	for _, tag := range copiedTables {
+		if !font.HasTable(tag) {
+			continue
+		}
+		table, err := font.Table(tag)
+		if err != nil {
+			return Subset{}, err
+		}
+		out.AddTable(tag, table)
+	}
+
+	return Subset{Font: out, Glyphs: oldGlyphs}, nil
+}
+
+// subsetName only keeps the names required to identify the font.
+func subsetName(name *sfnt.TableName) *sfnt.TableName {
+	out := sfnt.NewTableName()
+	for _, entry := range name.List() {
+		if entry.NameID <= sfnt.NamePostscript {
+			out.Add(entry)
+		}
+	}
+	return out
+}
+
+// allGlyphs returns the set of the glyphs of a font,
+// which is empty if the font has no glyph.
+func allGlyphs(numGlyphs int) *sfnt.GlyphSet {
+	out := sfnt.NewGlyphSet()
+	if numGlyphs > 0 {
+		out.AddRange(0, sfnt.GlyphIndex(numGlyphs-1))
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package subset
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ConradIrwin/font/sfnt"
+)
+
+func parseFont(t *testing.T, filename string) *sfnt.Font {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	font, err := sfnt.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+// roundTrip writes and parses the font.
+func roundTrip(t *testing.T, font *sfnt.Font) *sfnt.Font {
+	var buf bytes.Buffer
+	if _, err := font.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out, err := sfnt.StrictParse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRunes(t *testing.T) {
+	for _, file := range []string{
+		"../sfnt/testdata/Roboto-BoldItalic.ttf",
+		"../sfnt/testdata/Castoro-Regular.ttf",
+		"../sfnt/testdata/FreeSerif.ttf",
+	} {
+		font := parseFont(t, file)
+		text := []rune("Hello, world ! éàç")
+
+		subset, err := Runes(font, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := subset.Font.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		out, err := sfnt.StrictParse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		widths, err := out.HtmxTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(widths) != len(subset.Glyphs) {
+			t.Errorf("expected %d widths, got %d", len(subset.Glyphs), len(widths))
+		}
+		originalWidths, err := font.HtmxTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmap, err := out.CmapTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		originalCmap, err := font.CmapTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range text {
+			gi := cmap.Lookup(r)
+			if gi == 0 {
+				t.Errorf("%s: missing glyph for %q", file, r)
+				continue
+			}
+			if old := subset.Glyphs[gi]; old != originalCmap.Lookup(r) {
+				t.Errorf("%s: invalid glyph for %q", file, r)
+			} else if widths[gi] != originalWidths[old] {
+				t.Errorf("%s: invalid width for %q", file, r)
+			}
+		}
+		if cmap.Lookup('z') != 0 {
+			t.Errorf("%s: unexpected glyph for 'z'", file)
+		}
+
+		// the OS/2 table is updated with the new cmap
+		os2, err := out.OS2Table()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the last rune may be a combining mark, used as component
+		if indexes := os2.CharIndexes(); indexes.First != ' ' || indexes.Last < 'é' {
+			t.Errorf("%s: unexpected character indexes %v", file, indexes)
+		}
+		if err := out.ValidateCharIndexes(); err != nil {
+			t.Errorf("%s: %s", file, err)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	font := parseFont(t, "../sfnt/testdata/Roboto-BoldItalic.ttf")
+	base, err := RunesRetainingIDs(font, []rune("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	extended, err := RunesRetainingIDs(font, []rune("abcdé"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(base.Glyphs) != len(extended.Glyphs) {
+		t.Fatalf("glyph indexes are not retained: %d and %d glyphs", len(base.Glyphs), len(extended.Glyphs))
+	}
+
+	patch, err := Diff(base.Font, extended.Font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// d, é, and its components
+	if n := patch.Glyphs.Len(); n == 0 || n > 4 || len(patch.Outlines) != n {
+		t.Errorf("unexpected patched glyphs %v", patch.Glyphs.Glyphs())
+	}
+	if _, ok := patch.Tables[tagCmap]; !ok {
+		t.Error("cmap should be patched")
+	}
+	if _, ok := patch.Tables[sfnt.TagHmtx]; ok {
+		t.Error("hmtx should not be patched")
+	}
+	if len(patch.Removed) != 0 {
+		t.Errorf("unexpected removed tables %v", patch.Removed)
+	}
+
+	// the tables missing from the extended subset are removed
+	if !base.Font.HasTable(tagPost) {
+		t.Fatal("missing post table")
+	}
+	extended.Font.RemoveTable(tagPost)
+	patch, err = Diff(base.Font, extended.Font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch.Removed) != 1 || patch.Removed[0] != tagPost {
+		t.Errorf("expected the post table to be removed, got %v", patch.Removed)
+	}
+
+	patched, err := patch.Apply(base.Font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched = roundTrip(t, patched)
+	expected := roundTrip(t, extended.Font)
+	if !reflect.DeepEqual(patched.Tags(), expected.Tags()) {
+		t.Errorf("expected tables %v, got %v", expected.Tags(), patched.Tags())
+	}
+	for _, tag := range expected.Tags() {
+		t1, err := expected.Table(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t2, err := patched.Table(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(t1.Bytes(), t2.Bytes()) {
+			t.Errorf("table %s differs", tag)
+		}
+	}
+}
+
+func TestSubsetInvalidGlyphs(t *testing.T) {
+	if set := allGlyphs(0); set.Len() != 0 {
+		t.Errorf("expected no glyph, got %v", set.Glyphs())
+	}
+	glyf, _, _ := glyphs(nil).subset(allGlyphs(0))
+	if len(glyf) != 0 {
+		t.Errorf("unexpected glyf table %v", glyf)
+	}
+
+	// the glyph 1 has an out of range component, glyph 7
+	composite := []byte{
+		0xFF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0, // numberOfContours and bounding box
+		0, 2, 0, 7, 0, 0, // flags, glyphIndex, arguments
+	}
+	outlines := glyphs{nil, composite, {0, 0}}
+	kept := sfnt.NewGlyphSet()
+	for _, gi := range []sfnt.GlyphIndex{0, 1, 2} {
+		if err := outlines.closure(gi, kept); err != nil {
+			t.Fatal(err)
+		}
+	}
+	glyf, _, _ = outlines.subset(kept)
+	if component := be.Uint16(glyf[compositeHeaderSize+2:]); component != 0 {
+		t.Errorf("expected the missing component to map to .notdef, got %d", component)
+	}
+}
@@ -0,0 +1,239 @@
+package type1
+
+import (
+	"strconv"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF     tokenKind = iota
+	tokName              // a literal name such as /FontName, without the slash
+	tokKeyword           // an executable name such as def
+	tokNumber
+	tokString // a literal or hexadecimal string
+	tokBinary // the raw bytes following RD or -|
+	tokOpen   // [ or {
+	tokClose  // ] or }
+)
+
+type token struct {
+	kind  tokenKind
+	value string // the name, keyword or string content
+	num   float64
+}
+
+// lexer splits a PostScript program in tokens.
+// It reads the binary data of the charstrings and subroutines,
+// written as "<length> RD <bytes>", as one tokBinary token.
+type lexer struct {
+	data []byte
+	pos  int
+
+	prevNumber int // the last integer, or -1
+}
+
+func newLexer(data []byte) *lexer { return &lexer{data: data, prevNumber: -1} }
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipSpaces() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%': // comment
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// regular reads the characters up to the next delimiter or space.
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *lexer) next() (token, error) {
+	prevNumber := l.prevNumber
+	l.prevNumber = -1
+
+	l.skipSpaces()
+	if l.pos >= len(l.data) {
+		return token{kind: tokEOF}, nil
+	}
+
+	switch c := l.data[l.pos]; c {
+	case '/':
+		l.pos++
+		return token{kind: tokName, value: l.regular()}, nil
+	case '[', '{':
+		l.pos++
+		return token{kind: tokOpen, value: string(c)}, nil
+	case ']', '}':
+		l.pos++
+		return token{kind: tokClose, value: string(c)}, nil
+	case '(':
+		return l.literalString()
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return token{kind: tokKeyword, value: "<<"}, nil
+		}
+		return l.hexString()
+	case '>':
+		l.pos++
+		if l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+			return token{kind: tokKeyword, value: ">>"}, nil
+		}
+		return token{}, errInvalidSyntax
+	case ')':
+		return token{}, errInvalidSyntax
+	}
+
+	word := l.regular()
+	if num, ok := parseNumber(word); ok {
+		if num >= 0 && num == float64(int(num)) {
+			l.prevNumber = int(num)
+		}
+		return token{kind: tokNumber, value: word, num: num}, nil
+	}
+	if (word == "RD" || word == "-|") && prevNumber != -1 {
+		// a single space separates the operator from the data
+		start := l.pos + 1
+		if start+prevNumber > len(l.data) {
+			return token{}, errInvalidSyntax
+		}
+		l.pos = start + prevNumber
+		return token{kind: tokBinary, value: string(l.data[start:l.pos])}, nil
+	}
+	return token{kind: tokKeyword, value: word}, nil
+}
+
+// parseNumber parses an integer, a real or a radix number (such as 8#777).
+func parseNumber(word string) (float64, bool) {
+	if word == "" || !strings.ContainsRune("+-.0123456789", rune(word[0])) {
+		return 0, false
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, true
+	}
+	if i := strings.IndexByte(word, '#'); i > 0 {
+		base, err := strconv.Atoi(word[:i])
+		if err != nil || base < 2 || base > 36 {
+			return 0, false
+		}
+		n, err := strconv.ParseInt(word[i+1:], base, 64)
+		if err != nil {
+			return 0, false
+		}
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func (l *lexer) literalString() (token, error) {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return token{kind: tokString, value: string(out)}, nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return token{}, errInvalidSyntax
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n': // line continuation
+				continue
+			default:
+				if '0' <= c && c <= '7' {
+					code := c - '0'
+					for i := 0; i < 2 && l.pos < len(l.data) && '0' <= l.data[l.pos] && l.data[l.pos] <= '7'; i++ {
+						code = code*8 + l.data[l.pos] - '0'
+						l.pos++
+					}
+					c = code
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return token{}, errInvalidSyntax
+}
+
+func (l *lexer) hexString() (token, error) {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch {
+		case c == '>':
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+			out := make([]byte, len(digits)/2)
+			for i := range out {
+				out[i] = hexValue(digits[2*i])<<4 | hexValue(digits[2*i+1])
+			}
+			return token{kind: tokString, value: string(out)}, nil
+		case isHexDigit(c):
+			digits = append(digits, c)
+		case !isSpace(c):
+			return token{}, errInvalidSyntax
+		}
+	}
+	return token{}, errInvalidSyntax
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	default:
+		return c - 'a' + 10
+	}
+}
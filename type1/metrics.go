@@ -0,0 +1,143 @@
+package type1
+
+import "encoding/binary"
+
+// GlyphMetrics are the metrics of a glyph, in glyph space units,
+// as defined by the hsbw or sbw operator of its charstring.
+type GlyphMetrics struct {
+	// SideBearingX and SideBearingY are the coordinates
+	// of the left sidebearing point.
+	SideBearingX, SideBearingY float64
+	// AdvanceX and AdvanceY are the components of the advance
+	// vector, AdvanceY being zero for most fonts.
+	AdvanceX, AdvanceY float64
+}
+
+// Type 1 charstring operators
+const (
+	t1OpCallSubr = 10
+	t1OpReturn   = 11
+	t1OpEscape   = 12
+	t1OpHsbw     = 13
+	// escaped operators
+	t1OpSbw = 7
+	t1OpDiv = 12
+)
+
+// maximum depth of nested subroutine calls,
+// as recommended by the specification
+const maxSubrDepth = 10
+
+// GlyphMetrics returns the metrics of the glyph with the given name,
+// or ErrGlyphNotFound.
+func (f *Font) GlyphMetrics(name string) (GlyphMetrics, error) {
+	cs, ok := f.CharStrings[name]
+	if !ok {
+		return GlyphMetrics{}, ErrGlyphNotFound
+	}
+	var stack []float64
+	metrics, found, err := f.readMetrics(cs, &stack, 0)
+	if err != nil {
+		return GlyphMetrics{}, err
+	}
+	if !found {
+		return GlyphMetrics{}, errInvalidCharstring
+	}
+	return metrics, nil
+}
+
+// Advance returns the horizontal advance of the glyph
+// used for the character code, or false if the code is not mapped.
+func (f *Font) Advance(code byte) (float64, bool) {
+	name := f.GlyphName(code)
+	if name == "" {
+		return 0, false
+	}
+	metrics, err := f.GlyphMetrics(name)
+	if err != nil {
+		return 0, false
+	}
+	return metrics.AdvanceX, true
+}
+
+// readMetrics interprets the charstring up to the hsbw or sbw operator,
+// which must come first, besides subroutine calls and divisions.
+// found is false if the charstring (or the subroutine) returns before.
+func (f *Font) readMetrics(cs []byte, stack *[]float64, depth int) (metrics GlyphMetrics, found bool, err error) {
+	pop := func(n int) ([]float64, error) {
+		if len(*stack) < n {
+			return nil, errInvalidCharstring
+		}
+		args := (*stack)[len(*stack)-n:]
+		*stack = (*stack)[:len(*stack)-n]
+		return args, nil
+	}
+
+	for i := 0; i < len(cs); {
+		b := cs[i]
+		switch {
+		case b >= 32 && b <= 246:
+			*stack = append(*stack, float64(int(b)-139))
+			i++
+		case b >= 247 && b <= 250:
+			if i+1 >= len(cs) {
+				return metrics, false, errInvalidCharstring
+			}
+			*stack = append(*stack, float64((int(b)-247)*256+int(cs[i+1])+108))
+			i += 2
+		case b >= 251 && b <= 254:
+			if i+1 >= len(cs) {
+				return metrics, false, errInvalidCharstring
+			}
+			*stack = append(*stack, float64(-(int(b)-251)*256-int(cs[i+1])-108))
+			i += 2
+		case b == 255:
+			if i+4 >= len(cs) {
+				return metrics, false, errInvalidCharstring
+			}
+			*stack = append(*stack, float64(int32(binary.BigEndian.Uint32(cs[i+1:]))))
+			i += 5
+		case b == t1OpHsbw:
+			args, err := pop(2)
+			if err != nil {
+				return metrics, false, err
+			}
+			return GlyphMetrics{SideBearingX: args[0], AdvanceX: args[1]}, true, nil
+		case b == t1OpCallSubr:
+			args, err := pop(1)
+			if err != nil {
+				return metrics, false, err
+			}
+			index := int(args[0])
+			if depth >= maxSubrDepth || index < 0 || index >= len(f.Subrs) {
+				return metrics, false, errInvalidCharstring
+			}
+			metrics, found, err = f.readMetrics(f.Subrs[index], stack, depth+1)
+			if err != nil || found {
+				return metrics, found, err
+			}
+			i++
+		case b == t1OpReturn:
+			return metrics, false, nil
+		case b == t1OpEscape && i+1 < len(cs) && cs[i+1] == t1OpSbw:
+			args, err := pop(4)
+			if err != nil {
+				return metrics, false, err
+			}
+			return GlyphMetrics{SideBearingX: args[0], SideBearingY: args[1], AdvanceX: args[2], AdvanceY: args[3]}, true, nil
+		case b == t1OpEscape && i+1 < len(cs) && cs[i+1] == t1OpDiv:
+			args, err := pop(2)
+			if err != nil {
+				return metrics, false, err
+			}
+			if args[1] == 0 {
+				return metrics, false, errInvalidCharstring
+			}
+			*stack = append(*stack, args[0]/args[1])
+			i += 2
+		default: // drawing operator before the metrics
+			return metrics, false, errInvalidCharstring
+		}
+	}
+	return metrics, false, nil
+}
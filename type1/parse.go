@@ -0,0 +1,317 @@
+package type1
+
+// fontParser reads the entries of the font dictionary,
+// from the clear text and the decrypted portion of the font.
+type fontParser struct {
+	font  *Font
+	lex   *lexer
+	lenIV int
+
+	fontType    int
+	subrs       [][]byte // encrypted
+	charStrings map[string][]byte
+
+	// truncated accepts the fonts whose CharStrings
+	// dictionary is truncated, see ParsePDFFont
+	truncated bool
+}
+
+func (p *fontParser) next() (token, error) { return p.lex.next() }
+
+func (p *fontParser) number() (float64, error) {
+	t, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	if t.kind != tokNumber {
+		return 0, errInvalidSyntax
+	}
+	return t.num, nil
+}
+
+func (p *fontParser) integer() (int, error) {
+	n, err := p.number()
+	if err != nil {
+		return 0, err
+	}
+	if n != float64(int(n)) {
+		return 0, errInvalidSyntax
+	}
+	return int(n), nil
+}
+
+// str reads a string or a literal name.
+func (p *fontParser) str() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.kind != tokString && t.kind != tokName {
+		return "", errInvalidSyntax
+	}
+	return t.value, nil
+}
+
+func (p *fontParser) boolean() (bool, error) {
+	t, err := p.next()
+	if err != nil {
+		return false, err
+	}
+	if t.kind != tokKeyword || (t.value != "true" && t.value != "false") {
+		return false, errInvalidSyntax
+	}
+	return t.value == "true", nil
+}
+
+// array reads an array or a procedure of numbers, filling out.
+func (p *fontParser) array(out []float64) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokOpen {
+		return errInvalidSyntax
+	}
+	for i := 0; ; i++ {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == tokClose && i == len(out):
+			return nil
+		case t.kind != tokNumber || i == len(out):
+			return errInvalidSyntax
+		}
+		out[i] = t.num
+	}
+}
+
+// parse reads the entries of interest in the program.
+// For the private (decrypted) portion, it stops after the CharStrings
+// dictionary, since garbage may follow the end of the program.
+func (p *fontParser) parse(lex *lexer, private bool) (err error) {
+	p.lex = lex
+	f := p.font
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokEOF {
+			if private {
+				return errMissingCharset
+			}
+			return nil
+		}
+		if t.kind != tokName {
+			continue
+		}
+
+		switch t.value {
+		case "FontType":
+			p.fontType, err = p.integer()
+		case "FontName":
+			f.FontName, err = p.str()
+		case "version":
+			f.Version, err = p.str()
+		case "Notice":
+			f.Notice, err = p.str()
+		case "FullName":
+			f.FullName, err = p.str()
+		case "FamilyName":
+			f.FamilyName, err = p.str()
+		case "Weight":
+			f.Weight, err = p.str()
+		case "ItalicAngle":
+			f.ItalicAngle, err = p.number()
+		case "isFixedPitch":
+			f.IsFixedPitch, err = p.boolean()
+		case "UnderlinePosition":
+			f.UnderlinePosition, err = p.number()
+		case "UnderlineThickness":
+			f.UnderlineThickness, err = p.number()
+		case "FontMatrix":
+			err = p.array(f.FontMatrix[:])
+		case "FontBBox":
+			err = p.array(f.FontBBox[:])
+		case "Encoding":
+			err = p.encoding()
+		case "lenIV":
+			p.lenIV, err = p.integer()
+		case "Subrs":
+			if private {
+				err = p.readSubrs()
+			}
+		case "CharStrings":
+			if private {
+				return p.readCharStrings()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// encoding reads either StandardEncoding, or an array
+// filled by "dup <code> /<name> put" statements.
+func (p *fontParser) encoding() error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind == tokKeyword && t.value == "StandardEncoding" {
+		p.font.Encoding = standardEncoding
+		return nil
+	}
+	if t.kind != tokNumber {
+		return errInvalidSyntax
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return errInvalidSyntax
+		case t.kind == tokKeyword && t.value == "def":
+			return nil
+		case t.kind == tokKeyword && t.value == "dup":
+			code, err := p.next()
+			if err != nil {
+				return err
+			}
+			if code.kind != tokNumber {
+				continue // not a put statement
+			}
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			if name.kind == tokName && 0 <= code.num && code.num < 256 {
+				p.font.Encoding[int(code.num)] = name.value
+			}
+		}
+	}
+}
+
+// readSubrs reads the "dup <index> <length> RD <bytes> NP"
+// statements defining the subroutines.
+func (p *fontParser) readSubrs() error {
+	count, err := p.integer()
+	if err != nil {
+		return err
+	}
+	if count < 0 || count > len(p.lex.data) {
+		return errInvalidSyntax
+	}
+	p.subrs = make([][]byte, count)
+	for found := 0; found < count; {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == tokEOF:
+			return errInvalidSyntax
+		case t.kind == tokKeyword && t.value == "dup":
+			index, err := p.integer()
+			if err != nil {
+				return err
+			}
+			if _, err := p.integer(); err != nil { // length
+				return err
+			}
+			data, err := p.next()
+			if err != nil {
+				return err
+			}
+			if data.kind != tokBinary || index < 0 || index >= count {
+				return errInvalidSyntax
+			}
+			p.subrs[index] = []byte(data.value)
+			found++
+		}
+	}
+	return nil
+}
+
+// readCharStrings reads the "/<name> <length> RD <bytes> ND"
+// statements of the CharStrings dictionary, up to its end.
+func (p *fontParser) readCharStrings() error {
+	p.charStrings = make(map[string][]byte)
+	for {
+		t, err := p.next()
+		if err != nil {
+			return p.endCharStrings(err)
+		}
+		switch {
+		case t.kind == tokEOF:
+			return p.endCharStrings(errInvalidSyntax)
+		case t.kind == tokKeyword && t.value == "end":
+			return nil
+		case t.kind == tokName:
+			if _, err := p.integer(); err != nil { // length
+				return p.endCharStrings(err)
+			}
+			data, err := p.next()
+			if err != nil {
+				return p.endCharStrings(err)
+			}
+			if data.kind != tokBinary {
+				return p.endCharStrings(errInvalidSyntax)
+			}
+			p.charStrings[t.value] = []byte(data.value)
+		}
+	}
+}
+
+// endCharStrings returns err, or nil if the CharStrings
+// dictionary may be truncated and the end of the data is reached:
+// the glyphs read so far are kept.
+func (p *fontParser) endCharStrings(err error) error {
+	if p.truncated && p.lex.pos >= len(p.lex.data) && len(p.charStrings) != 0 {
+		return nil
+	}
+	return err
+}
+
+// finish checks the font and decrypts the charstrings.
+func (p *fontParser) finish() (*Font, error) {
+	if p.fontType != 1 {
+		return nil, ErrUnsupportedFontType
+	}
+	if p.charStrings == nil {
+		return nil, errMissingCharset
+	}
+
+	f := p.font
+	var err error
+	f.Subrs = make([][]byte, len(p.subrs))
+	for i, subr := range p.subrs {
+		if f.Subrs[i], err = p.decryptCharstring(subr); err != nil {
+			return nil, err
+		}
+	}
+	f.CharStrings = make(map[string][]byte, len(p.charStrings))
+	for name, cs := range p.charStrings {
+		if f.CharStrings[name], err = p.decryptCharstring(cs); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// decryptCharstring decrypts a charstring, unless lenIV is -1,
+// and discards the lenIV leading bytes.
+func (p *fontParser) decryptCharstring(cs []byte) ([]byte, error) {
+	if p.lenIV < 0 {
+		return cs, nil
+	}
+	if len(cs) < p.lenIV {
+		return nil, errInvalidCharstring
+	}
+	return decrypt(cs, charstringKey)[p.lenIV:], nil
+}
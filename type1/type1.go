@@ -0,0 +1,245 @@
+// Package type1 parses PostScript Type 1 fonts, stored in PFB (binary)
+// or PFA (ASCII) files, which are still found in PDF workflows.
+//
+// The font program is decrypted and its font dictionary is read, giving
+// access to the font metrics, the encoding and the glyph names.
+// The glyph outlines are not interpreted, besides their metrics.
+package type1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+var (
+	errInvalidPFBSegment = errors.New("invalid PFB segment")
+	errMissingEexec      = errors.New("missing eexec section")
+	errInvalidEexec      = errors.New("invalid eexec section")
+	errMissingCharset    = errors.New("missing CharStrings dictionary")
+	errInvalidCharstring = errors.New("invalid Type 1 charstring")
+	errInvalidSyntax     = errors.New("invalid PostScript syntax")
+)
+
+// ErrUnsupportedFontType is returned by Parse when the font is
+// a PostScript font, but not a Type 1 font.
+var ErrUnsupportedFontType = errors.New("unsupported PostScript font type")
+
+// ErrGlyphNotFound is returned when no glyph has the given name.
+var ErrGlyphNotFound = errors.New("glyph not found")
+
+// Font is a parsed Type 1 font.
+// The distances are expressed in glyph space units, which are
+// converted to text space by the FontMatrix (usually 1/1000 em).
+type Font struct {
+	FontName string
+
+	// from the FontInfo dictionary
+	Version            string
+	Notice             string
+	FullName           string
+	FamilyName         string
+	Weight             string
+	ItalicAngle        float64
+	IsFixedPitch       bool
+	UnderlinePosition  float64
+	UnderlineThickness float64
+
+	FontMatrix [6]float64
+	// FontBBox is the union of the glyph bounding boxes,
+	// as xMin, yMin, xMax, yMax.
+	FontBBox [4]float64
+
+	// Encoding maps the character codes to the glyph names;
+	// the unmapped codes have an empty name.
+	Encoding [256]string
+
+	// Subrs are the decrypted subroutines of the charstrings.
+	Subrs [][]byte
+	// CharStrings are the decrypted charstrings, by glyph name.
+	CharStrings map[string][]byte
+}
+
+// Parse parses a PFB or a PFA font file.
+func Parse(r io.Reader) (*Font, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data, false)
+}
+
+// ParsePDFFont parses a Type 1 font program embedded in a PDF file,
+// as found in the FontFile streams of the font descriptors, where
+// the clear text portion is directly followed by the binary encrypted
+// portion. The trailing zeros and cleartomark are not needed.
+// Since PDF producers commonly truncate the streams, a truncated
+// CharStrings dictionary is accepted: the glyphs defined before the
+// truncation are returned.
+// See sfnt.ParsePDFFont for the other font programs.
+func ParsePDFFont(data []byte) (*Font, error) {
+	return parse(data, true)
+}
+
+func parse(data []byte, truncated bool) (*Font, error) {
+	var (
+		clear, encrypted []byte
+		err              error
+	)
+	if len(data) != 0 && data[0] == pfbMarker {
+		clear, encrypted, err = splitPFB(data)
+	} else {
+		clear, encrypted, err = splitPFA(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < 4 {
+		return nil, errInvalidEexec
+	}
+
+	p := fontParser{font: &Font{FontMatrix: [6]float64{0.001, 0, 0, 0.001, 0, 0}}, lenIV: 4, truncated: truncated}
+	if err := p.parse(newLexer(clear), false); err != nil {
+		return nil, err
+	}
+	if err := p.parse(newLexer(decrypt(encrypted, eexecKey)[4:]), true); err != nil {
+		return nil, err
+	}
+	return p.finish()
+}
+
+const (
+	pfbMarker   = 0x80
+	pfbASCII    = 1
+	pfbBinary   = 2
+	pfbEOF      = 3
+	pfbHeaderLn = 6
+)
+
+// splitPFB returns the clear text and the encrypted portion of a PFB file,
+// made of segments starting with a marker, a type and a little-endian length.
+func splitPFB(data []byte) (clear, encrypted []byte, err error) {
+	for len(data) != 0 {
+		if len(data) < 2 || data[0] != pfbMarker {
+			return nil, nil, errInvalidPFBSegment
+		}
+		kind := data[1]
+		if kind == pfbEOF {
+			break
+		}
+		if len(data) < pfbHeaderLn {
+			return nil, nil, errInvalidPFBSegment
+		}
+		length := binary.LittleEndian.Uint32(data[2:])
+		data = data[pfbHeaderLn:]
+		if uint32(len(data)) < length {
+			return nil, nil, errInvalidPFBSegment
+		}
+		segment := data[:length]
+		data = data[length:]
+
+		switch kind {
+		case pfbASCII:
+			// the trailing segment (zeros and cleartomark) is ignored
+			if encrypted == nil {
+				clear = append(clear, segment...)
+			}
+		case pfbBinary:
+			encrypted = append(encrypted, segment...)
+		default:
+			return nil, nil, errInvalidPFBSegment
+		}
+	}
+	if encrypted == nil {
+		return nil, nil, errMissingEexec
+	}
+	return clear, encrypted, nil
+}
+
+// splitPFA returns the clear text and the encrypted portion of a PFA file,
+// where the encrypted portion usually follows the eexec operator
+// as hexadecimal digits.
+func splitPFA(data []byte) (clear, encrypted []byte, err error) {
+	index := bytes.Index(data, []byte("eexec"))
+	if index == -1 {
+		return nil, nil, errMissingEexec
+	}
+	clear, data = data[:index], data[index+len("eexec"):]
+	// only the end of the eexec line is skipped, since the
+	// binary encrypted portion may start with whitespace bytes
+	if bytes.HasPrefix(data, []byte("\r\n")) {
+		data = data[2:]
+	} else if len(data) != 0 && isSpace(data[0]) {
+		data = data[1:]
+	}
+
+	// the hexadecimal digits may be preceded by blank lines
+	hexData := data
+	for len(hexData) != 0 && isSpace(hexData[0]) {
+		hexData = hexData[1:]
+	}
+	if len(hexData) < 4 || !isHexDigit(hexData[0]) || !isHexDigit(hexData[1]) || !isHexDigit(hexData[2]) || !isHexDigit(hexData[3]) {
+		return clear, data, nil // binary encrypted portion
+	}
+
+	digits := make([]byte, 0, len(hexData))
+	for _, c := range hexData {
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		} else if !isSpace(c) {
+			break // cleartomark
+		}
+	}
+	encrypted = make([]byte, len(digits)/2)
+	if _, err := hex.Decode(encrypted, digits[:2*len(encrypted)]); err != nil {
+		return nil, nil, errInvalidEexec
+	}
+	return clear, encrypted, nil
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+const (
+	eexecKey      = 55665
+	charstringKey = 4330
+)
+
+// decrypt applies the Type 1 decryption algorithm, without
+// discarding the random leading bytes.
+func decrypt(cipher []byte, r uint16) []byte {
+	const c1, c2 = 52845, 22719
+	out := make([]byte, len(cipher))
+	for i, c := range cipher {
+		out[i] = c ^ byte(r>>8)
+		r = (uint16(c)+r)*c1 + c2
+	}
+	return out
+}
+
+// GlyphNames returns the names of the glyphs defined by the
+// font, sorted in alphabetical order.
+func (f *Font) GlyphNames() []string {
+	names := make([]string, 0, len(f.CharStrings))
+	for name := range f.CharStrings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GlyphName returns the name of the glyph used to display the given
+// character code, or an empty string if the code is not mapped
+// (or mapped to a missing glyph).
+func (f *Font) GlyphName(code byte) string {
+	name := f.Encoding[code]
+	if _, ok := f.CharStrings[name]; !ok {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,291 @@
+package type1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// encrypt is the inverse of decrypt.
+func encrypt(plain []byte, r uint16) []byte {
+	const c1, c2 = 52845, 22719
+	out := make([]byte, len(plain))
+	for i, p := range plain {
+		c := p ^ byte(r>>8)
+		out[i] = c
+		r = (uint16(c)+r)*c1 + c2
+	}
+	return out
+}
+
+// charstring returns an encrypted charstring, with the default lenIV
+func charstring(cs ...byte) []byte {
+	return encrypt(append([]byte{1, 2, 3, 4}, cs...), charstringKey)
+}
+
+const testClearText = `%!PS-AdobeFont-1.0: Test-Regular 001.000
+%%Title: Test
+11 dict begin
+/FontInfo 9 dict dup begin
+/version (001.000) readonly def
+/Notice (Copyright \(c\) test) readonly def
+/FullName (Test Regular) readonly def
+/FamilyName (Test) readonly def
+/Weight (Regular) readonly def
+/ItalicAngle -12.5 def
+/isFixedPitch false def
+/UnderlinePosition -100 def
+/UnderlineThickness 50 def
+end readonly def
+/FontName /Test-Regular def
+/Encoding 256 array
+0 1 255 {1 index exch /.notdef put} for
+dup 65 /A put
+dup 66 /B put
+dup 67 /C put
+readonly def
+/PaintType 0 def
+/FontType 1 def
+/FontMatrix [0.001 0 0 0.001 0 0] readonly def
+/FontBBox {-10 -200 700 800} readonly def
+currentdict end
+currentfile eexec
+`
+
+// testFontProgram returns the clear text and the encrypted portion of a font
+// with the glyphs .notdef, A (using hsbw), and B (using a subroutine and sbw).
+func testFontProgram() (clear, encrypted []byte) {
+	var private bytes.Buffer
+	private.WriteString("dup /Private 8 dict dup begin\n/RD {string currentfile exch readstring pop} executeonly def\n/ND {noaccess def} executeonly def\n/NP {noaccess put} executeonly def\n/lenIV 4 def\n")
+
+	subrs := [][]byte{
+		charstring(139+1, t1OpCallSubr, t1OpReturn), // 1 callsubr
+		charstring(t1OpEscape, t1OpSbw, t1OpReturn), // sbw
+	}
+	fmt.Fprintf(&private, "/Subrs %d array\n", len(subrs))
+	for i, subr := range subrs {
+		fmt.Fprintf(&private, "dup %d %d RD %s NP\n", i, len(subr), subr)
+	}
+	private.WriteString("ND\n")
+
+	glyphs := []struct {
+		name string
+		cs   []byte
+	}{
+		{".notdef", charstring(139, 247, 142, t1OpHsbw, 14)},                   // 0 250 hsbw endchar
+		{"A", charstring(139+30, 249, 10, t1OpHsbw, 14)},                       // 30 630 hsbw endchar
+		{"B", charstring(139+15, 139+5, 248, 136, 139, 139, t1OpCallSubr, 14)}, // 15 5 500 0 0 callsubr endchar
+	}
+	fmt.Fprintf(&private, "2 index /CharStrings %d dict dup begin\n", len(glyphs))
+	for _, glyph := range glyphs {
+		fmt.Fprintf(&private, "/%s %d RD %s ND\n", glyph.name, len(glyph.cs), glyph.cs)
+	}
+	private.WriteString("end\nend\nreadonly put\nnoaccess put\ndup /FontName get exch definefont pop\nmark currentfile closefile\n")
+
+	return []byte(testClearText), encrypt(append([]byte{0xA, 0xB, 0xC, 0xD}, private.Bytes()...), eexecKey)
+}
+
+const trailer = "\n0000000000000000000000000000000000000000000000000000000000000000\ncleartomark\n"
+
+func pfa() []byte {
+	clear, encrypted := testFontProgram()
+	out := append([]byte(nil), clear...)
+	digits := hex.EncodeToString(encrypted)
+	for len(digits) > 64 {
+		out = append(out, digits[:64]+"\n"...)
+		digits = digits[64:]
+	}
+	out = append(out, digits...)
+	return append(out, trailer...)
+}
+
+func pfb() []byte {
+	clear, encrypted := testFontProgram()
+	var out []byte
+	for _, segment := range []struct {
+		kind byte
+		data []byte
+	}{{pfbASCII, clear}, {pfbBinary, encrypted}, {pfbASCII, []byte(trailer)}} {
+		out = append(out, pfbMarker, segment.kind, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[len(out)-4:], uint32(len(segment.data)))
+		out = append(out, segment.data...)
+	}
+	return append(out, pfbMarker, pfbEOF)
+}
+
+func TestParse(t *testing.T) {
+	for _, file := range [][]byte{pfa(), pfb()} {
+		font, err := Parse(bytes.NewReader(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if font.FontName != "Test-Regular" || font.FullName != "Test Regular" || font.FamilyName != "Test" ||
+			font.Weight != "Regular" || font.Version != "001.000" || font.Notice != "Copyright (c) test" {
+			t.Errorf("unexpected names %q %q %q %q %q %q", font.FontName, font.FullName, font.FamilyName, font.Weight, font.Version, font.Notice)
+		}
+		if font.ItalicAngle != -12.5 || font.IsFixedPitch || font.UnderlinePosition != -100 || font.UnderlineThickness != 50 {
+			t.Errorf("unexpected FontInfo %v %v %v %v", font.ItalicAngle, font.IsFixedPitch, font.UnderlinePosition, font.UnderlineThickness)
+		}
+		if font.FontMatrix != [6]float64{0.001, 0, 0, 0.001, 0, 0} {
+			t.Errorf("unexpected FontMatrix %v", font.FontMatrix)
+		}
+		if font.FontBBox != [4]float64{-10, -200, 700, 800} {
+			t.Errorf("unexpected FontBBox %v", font.FontBBox)
+		}
+
+		if names := font.GlyphNames(); !reflect.DeepEqual(names, []string{".notdef", "A", "B"}) {
+			t.Errorf("unexpected glyph names %v", names)
+		}
+		for code, expected := range map[byte]string{'A': "A", 'B': "B", 'C': "", 'a': ""} {
+			if name := font.GlyphName(code); name != expected {
+				t.Errorf("code %d: expected %q, got %q", code, expected, name)
+			}
+		}
+
+		for name, expected := range map[string]GlyphMetrics{
+			".notdef": {AdvanceX: 250},
+			"A":       {SideBearingX: 30, AdvanceX: 630},
+			"B":       {SideBearingX: 15, SideBearingY: 5, AdvanceX: 500},
+		} {
+			metrics, err := font.GlyphMetrics(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metrics != expected {
+				t.Errorf("glyph %s: expected %v, got %v", name, expected, metrics)
+			}
+		}
+		if _, err := font.GlyphMetrics("C"); err != ErrGlyphNotFound {
+			t.Errorf("expected ErrGlyphNotFound, got %v", err)
+		}
+		if advance, ok := font.Advance('A'); !ok || advance != 630 {
+			t.Errorf("expected 630, got %v %v", advance, ok)
+		}
+	}
+}
+
+func TestParsePDFFont(t *testing.T) {
+	// FontFile streams have a binary encrypted portion, and no trailer
+	clear, encrypted := testFontProgram()
+	stream := append(append([]byte(nil), clear...), encrypted...)
+	font, err := ParsePDFFont(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := font.GlyphNames(); !reflect.DeepEqual(names, []string{".notdef", "A", "B"}) {
+		t.Errorf("unexpected glyph names %v", names)
+	}
+
+	// truncated in the charstring of B
+	plain := decrypt(encrypted, eexecKey)
+	stream = stream[:len(clear)+bytes.Index(plain, []byte("/B "))+6]
+	if _, err := Parse(bytes.NewReader(stream)); err == nil {
+		t.Error("expected an error for a truncated font")
+	}
+	font, err = ParsePDFFont(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := font.GlyphNames(); !reflect.DeepEqual(names, []string{".notdef", "A"}) {
+		t.Errorf("unexpected glyph names %v", names)
+	}
+
+	// truncated before the CharStrings dictionary
+	stream = stream[:len(clear)+bytes.Index(plain, []byte("/CharStrings"))]
+	if _, err := ParsePDFFont(stream); err == nil {
+		t.Error("expected an error for a font without glyphs")
+	}
+}
+
+func TestParsePDFFontLeadingSpace(t *testing.T) {
+	clear, encrypted := testFontProgram()
+	// choose the first random byte so that the ciphertext starts with a space
+	plain := decrypt(encrypted, eexecKey)
+	plain[0] = ' ' ^ byte(eexecKey>>8)
+	encrypted = encrypt(plain, eexecKey)
+	if encrypted[0] != ' ' {
+		t.Fatalf("unexpected first byte %d", encrypted[0])
+	}
+
+	for _, eol := range []string{"\n", "\r\n"} {
+		stream := append(bytes.TrimSuffix(clear, []byte("\n")), eol...)
+		font, err := ParsePDFFont(append(stream, encrypted...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := font.GlyphNames(); !reflect.DeepEqual(names, []string{".notdef", "A", "B"}) {
+			t.Errorf("unexpected glyph names %v", names)
+		}
+	}
+}
+
+func TestParseStandardEncoding(t *testing.T) {
+	clear, encrypted := testFontProgram()
+	start := bytes.Index(clear, []byte("/Encoding"))
+	end := bytes.Index(clear, []byte("/PaintType"))
+	clear = append(append(append([]byte(nil), clear[:start]...), "/Encoding StandardEncoding def\n"...), clear[end:]...)
+
+	font, err := Parse(bytes.NewReader(append(clear, encrypted...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.Encoding['A'] != "A" || font.Encoding[0xE8] != "Lslash" || font.Encoding[0x80] != "" {
+		t.Errorf("unexpected encoding %q %q %q", font.Encoding['A'], font.Encoding[0xE8], font.Encoding[0x80])
+	}
+
+	clear = bytes.Replace(clear, []byte("/FontType 1"), []byte("/FontType 3"), 1)
+	if _, err := Parse(bytes.NewReader(append(clear, encrypted...))); err != ErrUnsupportedFontType {
+		t.Errorf("expected ErrUnsupportedFontType, got %v", err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	valid := pfb()
+	for _, file := range [][]byte{
+		nil,
+		[]byte("%!PS-AdobeFont-1.0\n"),
+		valid[:len(valid)/2],
+		{pfbMarker, 7, 0, 0, 0, 0},
+		pfa()[:len(testClearText)+40],
+	} {
+		if _, err := Parse(bytes.NewReader(file)); err == nil {
+			t.Errorf("expected an error for %q", file)
+		}
+	}
+}
+
+func TestLexer(t *testing.T) {
+	lex := newLexer([]byte("/Name (a (nested) \\101\\n) <48 65> 16#FF -1.5 [{ }] def % comment\n3 RD abc end"))
+	var got []token
+	for {
+		tok, err := lex.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.kind == tokEOF {
+			break
+		}
+		got = append(got, tok)
+	}
+	expected := []token{
+		{kind: tokName, value: "Name"},
+		{kind: tokString, value: "a (nested) A\n"},
+		{kind: tokString, value: "He"},
+		{kind: tokNumber, value: "16#FF", num: 255},
+		{kind: tokNumber, value: "-1.5", num: -1.5},
+		{kind: tokOpen, value: "["},
+		{kind: tokOpen, value: "{"},
+		{kind: tokClose, value: "}"},
+		{kind: tokClose, value: "]"},
+		{kind: tokKeyword, value: "def"},
+		{kind: tokNumber, value: "3", num: 3},
+		{kind: tokBinary, value: "abc"},
+		{kind: tokKeyword, value: "end"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}